@@ -0,0 +1,21 @@
+// Package auth guards the parts of the API that aren't open to everyone.
+package auth
+
+import (
+	"crypto/subtle"
+
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+)
+
+// AdminKey only lets through requests sending key as a bearer token in the
+// Authorization header. With no key configured every request is refused, so
+// the admin API stays closed until it is set up.
+func AdminKey(key string) echo.MiddlewareFunc {
+	return middleware.KeyAuth(func(got string, c echo.Context) (bool, error) {
+		if key == "" {
+			return false, nil
+		}
+		return subtle.ConstantTimeCompare([]byte(got), []byte(key)) == 1, nil
+	})
+}
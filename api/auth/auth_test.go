@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminKey(t *testing.T) {
+	e := echo.New()
+	ok := func(c echo.Context) error {
+		return c.String(http.StatusOK, "OK")
+	}
+
+	call := func(key, header string) error {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if header != "" {
+			req.Header.Set(echo.HeaderAuthorization, header)
+		}
+		rec := httptest.NewRecorder()
+		return AdminKey(key)(ok)(e.NewContext(req, rec))
+	}
+
+	t.Run("when the key matches, the request should be let through", func(t *testing.T) {
+		assert.NoError(t, call("s3cret", "Bearer s3cret"))
+	})
+
+	t.Run("when the key is wrong we should return a 401 error", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, statusCode(call("s3cret", "Bearer guess")))
+	})
+
+	t.Run("when the key is missing we should return a 400 error", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, statusCode(call("s3cret", "")))
+	})
+
+	t.Run("when no key is configured every request should be refused", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, statusCode(call("", "Bearer anything")))
+	})
+}
+
+func statusCode(err error) int {
+	if err == nil {
+		return http.StatusOK
+	}
+
+	hterr := &echo.HTTPError{}
+	if errors.As(err, &hterr) {
+		return hterr.Code
+	}
+
+	return 500
+}
@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/music-tribe/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserTokens(t *testing.T) {
+	e := echo.New()
+	userId := uuid.New()
+
+	// authenticate checks a request for userId sent to target with header
+	authenticate := func(users *UserTokens, target, header string) error {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		if header != "" {
+			req.Header.Set(echo.HeaderAuthorization, header)
+		}
+		return users.Authenticate(e.NewContext(req, httptest.NewRecorder()), userId)
+	}
+
+	users := NewUserTokens("s3cret")
+	token := users.Token(userId)
+
+	t.Run("when the user's token is sent as a bearer token, the request should be let through", func(t *testing.T) {
+		assert.NoError(t, authenticate(users, "/", "Bearer "+token))
+	})
+
+	t.Run("when the user's token is sent in the query, the request should be let through", func(t *testing.T) {
+		assert.NoError(t, authenticate(users, "/?token="+token, ""))
+	})
+
+	t.Run("when the token is another user's we should return a 401 error", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, statusCode(authenticate(users, "/", "Bearer "+users.Token(uuid.New()))))
+	})
+
+	t.Run("when the token was signed with another secret we should return a 401 error", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, statusCode(authenticate(users, "/", "Bearer "+NewUserTokens("other").Token(userId))))
+	})
+
+	t.Run("when the token is missing we should return a 401 error", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, statusCode(authenticate(users, "/", "")))
+	})
+
+	t.Run("when no secret is configured, user ids should be taken as given", func(t *testing.T) {
+		open := NewUserTokens("")
+		assert.False(t, open.Enabled())
+		assert.NoError(t, authenticate(open, "/", ""))
+	})
+}
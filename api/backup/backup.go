@@ -0,0 +1,192 @@
+// Package backup writes snapshots of the feature data, features with their
+// votes and the audit trail, to local files, and restores them.
+//
+// A backup is a gzip compressed JSON Lines file. Its first line is a header
+// naming the format and its version, each feature follows on a line of its
+// own with its votes, then each audit event, and a trailer counting them
+// all closes the file, so a truncated copy is caught before anything is
+// restored from it.
+package backup
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/uuid"
+)
+
+const (
+	// Format names the file format in every header.
+	Format = "features-backup"
+	// Version is the version of the format written. Restore reads this
+	// version and every one before it.
+	Version = 1
+	// Ext ends the name of every backup file.
+	Ext = ".jsonl.gz"
+)
+
+// ErrInvalid is returned when a file isn't a complete backup this build can
+// read.
+var ErrInvalid = errors.New("the backup is invalid")
+
+//go:generate mockgen -destination=./mocks/backup.go -package=backupmocks -source=backup.go
+type Database interface {
+	Snapshot(ctx context.Context, features func(feature *domain.Feature, votes []*domain.Vote) error, audit func(event *domain.AuditEvent) error) error
+	RestoreFeatures(ctx context.Context, features []*domain.Feature, votes []*domain.Vote) ([]uuid.UUID, error)
+	RestoreAudit(ctx context.Context, events []*domain.AuditEvent) (int, error)
+	ClearFeatures(ctx context.Context) error
+}
+
+// Manifest describes a backup, from its header and trailer.
+type Manifest struct {
+	Path      string    `json:"path"`
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"createdAt"`
+	Features  int       `json:"features"`
+	Votes     int       `json:"votes"`
+	Audit     int       `json:"audit"`
+}
+
+type header struct {
+	Format    string    `json:"format"`
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type trailer struct {
+	Features int `json:"features"`
+	Votes    int `json:"votes"`
+	Audit    int `json:"audit"`
+}
+
+// entry is one line of a backup; exactly one of its fields is set, but for
+// a feature's votes.
+type entry struct {
+	Header  *header            `json:"header,omitempty"`
+	Feature *domain.Feature    `json:"feature,omitempty"`
+	Votes   []*domain.Vote     `json:"votes,omitempty"`
+	Audit   *domain.AuditEvent `json:"audit,omitempty"`
+	Trailer *trailer           `json:"trailer,omitempty"`
+}
+
+// FileName is the name of the backup taken at t.
+func FileName(t time.Time) string {
+	return "features-" + t.UTC().Format("20060102T150405Z") + Ext
+}
+
+// Create writes a snapshot of db to a new file in dir, named by FileName
+// for now, and returns its manifest. The snapshot is consistent, as of the
+// moment it started, and the file only appears under its name once it is
+// complete.
+func Create(ctx context.Context, db Database, dir string, now time.Time) (*Manifest, error) {
+	path := filepath.Join(dir, FileName(now))
+	if _, err := os.Stat(path); err == nil {
+		return nil, fmt.Errorf("backup.Create: %s already exists", path)
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	tmp, err := os.CreateTemp(dir, ".features-*"+Ext)
+	if err != nil {
+		return nil, err
+	}
+	// a no-op once the file has been renamed
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	m := &Manifest{Path: path, Version: Version, CreatedAt: now.UTC()}
+	if err := write(ctx, db, tmp, m); err != nil {
+		return nil, err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		return nil, err
+	}
+	if err := tmp.Close(); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// write streams the snapshot to w, counting what it writes into m.
+func write(ctx context.Context, db Database, w io.Writer, m *Manifest) error {
+	gz := gzip.NewWriter(w)
+	enc := json.NewEncoder(gz)
+
+	if err := enc.Encode(entry{Header: &header{Format: Format, Version: m.Version, CreatedAt: m.CreatedAt}}); err != nil {
+		return err
+	}
+
+	err := db.Snapshot(ctx, func(feature *domain.Feature, votes []*domain.Vote) error {
+		m.Features++
+		m.Votes += len(votes)
+		return enc.Encode(entry{Feature: feature, Votes: votes})
+	}, func(event *domain.AuditEvent) error {
+		m.Audit++
+		return enc.Encode(entry{Audit: event})
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := enc.Encode(entry{Trailer: &trailer{Features: m.Features, Votes: m.Votes, Audit: m.Audit}}); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// reader reads the entries of a backup in order.
+type reader struct {
+	file *os.File
+	gz   *gzip.Reader
+	dec  *json.Decoder
+	// line is the line of the last entry read, counting from 1
+	line int
+}
+
+func open(path string) (*reader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+
+	return &reader{file: f, gz: gz, dec: json.NewDecoder(gz)}, nil
+}
+
+// next returns the next entry, or io.EOF after the last. Reading to the end
+// also checks the file's checksum.
+func (r *reader) next() (*entry, error) {
+	e := new(entry)
+	if err := r.dec.Decode(e); err != nil {
+		if err == io.EOF {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: line %d: %v", ErrInvalid, r.line+1, err)
+	}
+	r.line++
+	return e, nil
+}
+
+func (r *reader) Close() error {
+	r.gz.Close()
+	return r.file.Close()
+}
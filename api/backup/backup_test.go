@@ -0,0 +1,278 @@
+package backup
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/music-tribe/react-pairing-challenge/database"
+	"github.com/music-tribe/react-pairing-challenge/database/sqldb"
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/react-pairing-challenge/migrate"
+	"github.com/music-tribe/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// newStore opens an empty SQLite database, which behaves as every other
+// backend does.
+func newStore(t *testing.T) database.Store {
+	db, err := sqldb.Open(sqldb.SQLite, "sqlite://"+filepath.Join(t.TempDir(), "features.db"), echo.New().Logger)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	t.Cleanup(func() { db.Close() })
+
+	_, err = migrate.New(migrate.Config{Store: db, Migrations: db.Migrations()}).Up(context.Background(), migrate.Options{})
+	assert.NoError(t, err)
+	return db
+}
+
+// snapshot is everything Snapshot walks, in order.
+type snapshot struct {
+	Features []*domain.Feature
+	Votes    [][]*domain.Vote
+	Audit    []*domain.AuditEvent
+}
+
+func take(t *testing.T, db database.Store) snapshot {
+	var s snapshot
+	err := db.Snapshot(context.Background(), func(f *domain.Feature, vs []*domain.Vote) error {
+		s.Features = append(s.Features, f)
+		s.Votes = append(s.Votes, vs)
+		return nil
+	}, func(e *domain.AuditEvent) error {
+		s.Audit = append(s.Audit, e)
+		return nil
+	})
+	assert.NoError(t, err)
+	return s
+}
+
+// seed adds features with tags, votes and a trip through the trash.
+func seed(t *testing.T, db database.Store) []*domain.Feature {
+	var features []*domain.Feature
+	for _, name := range []string{"dark mode", "offline sync", "midi learn"} {
+		f := &domain.Feature{Id: uuid.New(), UserId: uuid.New(), Name: name, Description: "d", Board: "mixer", Tags: []string{"ui"}}
+		assert.NoError(t, db.Add(f))
+		features = append(features, f)
+	}
+
+	for i := 0; i < 3; i++ {
+		_, err := db.AddVote(&domain.Vote{Id: uuid.New(), FeatureId: features[0].Id, VoterId: uuid.New()}, domain.VoteBudget{})
+		assert.NoError(t, err)
+	}
+	_, err := db.AddVote(&domain.Vote{Id: uuid.New(), FeatureId: features[1].Id, VoterId: uuid.New(), Direction: domain.Downvote}, domain.VoteBudget{})
+	assert.NoError(t, err)
+	assert.NoError(t, db.Delete(features[2].UserId, features[2].Id))
+
+	return features
+}
+
+var errRestoreFailed = errors.New("the store went away")
+
+// failingStore fails the failAt'th batch of features it is asked to restore.
+type failingStore struct {
+	database.Store
+	failAt  int
+	batches int
+}
+
+func (s *failingStore) RestoreFeatures(ctx context.Context, features []*domain.Feature, votes []*domain.Vote) ([]uuid.UUID, error) {
+	s.batches++
+	if s.batches == s.failAt {
+		return nil, errRestoreFailed
+	}
+	return s.Store.RestoreFeatures(ctx, features, votes)
+}
+
+func TestCreateAndRestore(t *testing.T) {
+	ctx := context.Background()
+	src := newStore(t)
+	features := seed(t, src)
+	want := take(t, src)
+
+	dir := filepath.Join(t.TempDir(), "backups")
+	now := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	m, err := Create(ctx, src, dir, now)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	t.Run("when a backup is created, its manifest should count what it holds", func(t *testing.T) {
+		assert.Equal(t, filepath.Join(dir, "features-20240301T093000Z.jsonl.gz"), m.Path)
+		assert.Equal(t, 3, m.Features)
+		assert.Equal(t, 4, m.Votes)
+		assert.Equal(t, len(want.Audit), m.Audit)
+
+		verified, err := Verify(m.Path)
+		assert.NoError(t, err)
+		assert.Equal(t, m, verified)
+
+		entries, _ := os.ReadDir(dir)
+		assert.Len(t, entries, 1)
+	})
+
+	t.Run("when a backup is created twice in a second, the second should be refused", func(t *testing.T) {
+		_, err := Create(ctx, src, dir, now)
+		assert.ErrorContains(t, err, "already exists")
+	})
+
+	t.Run("when a backup is restored into an empty database, it should match the original", func(t *testing.T) {
+		dst := newStore(t)
+		report, err := Restore(ctx, dst, m.Path, Options{Mode: Replace, BatchSize: 2, BackupDir: t.TempDir()})
+		assert.NoError(t, err)
+		assert.Equal(t, 3, report.Features)
+		assert.Equal(t, 4, report.Votes)
+		assert.Equal(t, m.Audit, report.Audit)
+
+		assert.Equal(t, want, take(t, dst))
+	})
+
+	t.Run("when a backup replaces a database, what was added since should be gone", func(t *testing.T) {
+		dst := newStore(t)
+		assert.NoError(t, dst.Add(&domain.Feature{Id: uuid.New(), UserId: uuid.New(), Name: "later", Description: "d"}))
+
+		report, err := Restore(ctx, dst, m.Path, Options{Mode: Replace, BackupDir: t.TempDir()})
+		assert.NoError(t, err)
+		assert.Equal(t, want, take(t, dst))
+		if assert.NotNil(t, report.PreRestoreBackup) {
+			assert.Equal(t, 1, report.PreRestoreBackup.Features)
+		}
+	})
+
+	t.Run("when a replace fails part way, its pre-restore backup should bring the database back", func(t *testing.T) {
+		dst := newStore(t)
+		before := seed(t, dst)
+		had := take(t, dst)
+
+		failing := &failingStore{Store: dst, failAt: 2}
+		report, err := Restore(ctx, failing, m.Path, Options{Mode: Replace, BatchSize: 1, BackupDir: t.TempDir()})
+		assert.ErrorIs(t, err, errRestoreFailed)
+		if !assert.NotNil(t, report.PreRestoreBackup) {
+			return
+		}
+		assert.Equal(t, 1, report.Features)
+		assert.Len(t, take(t, dst).Features, 1)
+
+		_, err = Restore(ctx, dst, report.PreRestoreBackup.Path, Options{Mode: Replace, BackupDir: t.TempDir()})
+		assert.NoError(t, err)
+		assert.Equal(t, had, take(t, dst))
+		_, err = dst.GetById(before[0].Id)
+		assert.NoError(t, err)
+	})
+
+	t.Run("when a backup is merged, features still in the database should be kept as they are", func(t *testing.T) {
+		dst := newStore(t)
+		kept := *features[0]
+		kept.Name = "renamed since"
+		_, err := dst.ImportFeatures("admin", []*domain.Feature{&kept})
+		assert.NoError(t, err)
+
+		report, err := Restore(ctx, dst, m.Path, Options{})
+		assert.NoError(t, err)
+		assert.Equal(t, Merge, report.Mode)
+		assert.Equal(t, 2, report.Features)
+		assert.Equal(t, 1, report.SkippedFeatures)
+		assert.Equal(t, 1, report.Votes)
+
+		got, err := dst.GetById(features[0].Id)
+		if assert.NoError(t, err) {
+			assert.Equal(t, "renamed since", got.Name)
+			assert.Equal(t, int64(0), got.VoteCount)
+		}
+		trashed, err := dst.GetTrash(features[2].UserId)
+		assert.NoError(t, err)
+		assert.Len(t, trashed, 1)
+
+		again, err := Restore(ctx, dst, m.Path, Options{Mode: Merge})
+		assert.NoError(t, err)
+		assert.Equal(t, 0, again.Features)
+		assert.Equal(t, 0, again.Audit)
+		assert.Equal(t, m.Audit, again.SkippedAudit)
+	})
+
+	t.Run("when a restore is a dry run, nothing should be written", func(t *testing.T) {
+		dst := newStore(t)
+		report, err := Restore(ctx, dst, m.Path, Options{Mode: Replace, DryRun: true})
+		assert.NoError(t, err)
+		assert.Equal(t, m, report.Manifest)
+		assert.Empty(t, take(t, dst).Features)
+	})
+}
+
+func TestVerify(t *testing.T) {
+	// write stores lines as a compressed backup would hold them
+	write := func(t *testing.T, lines ...interface{}) string {
+		path := filepath.Join(t.TempDir(), "backup"+Ext)
+		f, err := os.Create(path)
+		assert.NoError(t, err)
+		defer f.Close()
+
+		gz := gzip.NewWriter(f)
+		enc := json.NewEncoder(gz)
+		for _, l := range lines {
+			assert.NoError(t, enc.Encode(l))
+		}
+		assert.NoError(t, gz.Close())
+		return path
+	}
+
+	head := entry{Header: &header{Format: Format, Version: Version}}
+	feature := &domain.Feature{Id: uuid.New()}
+
+	t.Run("when a backup checks out, we should get its manifest", func(t *testing.T) {
+		path := write(t, head,
+			entry{Feature: feature, Votes: []*domain.Vote{{Id: uuid.New(), FeatureId: feature.Id}}},
+			entry{Audit: &domain.AuditEvent{Id: uuid.New()}},
+			entry{Trailer: &trailer{Features: 1, Votes: 1, Audit: 1}})
+
+		m, err := Verify(path)
+		assert.NoError(t, err)
+		assert.Equal(t, &Manifest{Path: path, Version: Version, CreatedAt: time.Time{}, Features: 1, Votes: 1, Audit: 1}, m)
+	})
+
+	tests := []struct {
+		name  string
+		lines []interface{}
+		want  string
+	}{
+		{"the file has no trailer", []interface{}{head, entry{Feature: feature}}, "without a trailer"},
+		{"the trailer disagrees", []interface{}{head, entry{Feature: feature}, entry{Trailer: &trailer{Features: 2}}}, "the trailer counts 2 features"},
+		{"the version is newer", []interface{}{entry{Header: &header{Format: Format, Version: Version + 1}}}, "can't be read"},
+		{"there is no header", []interface{}{entry{Feature: feature}}, "no features-backup header"},
+		{"a feature repeats", []interface{}{head, entry{Feature: feature}, entry{Feature: feature}}, "appears twice"},
+		{"a vote is misfiled", []interface{}{head, entry{Feature: feature, Votes: []*domain.Vote{{Id: uuid.New(), FeatureId: uuid.New()}}}}, "isn't its own"},
+		{"something follows the trailer", []interface{}{head, entry{Trailer: &trailer{}}, entry{Trailer: &trailer{}}}, "after its trailer"},
+	}
+	for _, tt := range tests {
+		t.Run("when "+tt.name+", we should get ErrInvalid", func(t *testing.T) {
+			_, err := Verify(write(t, tt.lines...))
+			assert.ErrorIs(t, err, ErrInvalid)
+			assert.ErrorContains(t, err, tt.want)
+		})
+	}
+
+	t.Run("when the file isn't compressed, we should get ErrInvalid", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "backup"+Ext)
+		assert.NoError(t, os.WriteFile(path, []byte(`{"header":{}}`), 0o644))
+
+		_, err := Verify(path)
+		assert.ErrorIs(t, err, ErrInvalid)
+	})
+
+	t.Run("when the file is cut short, we should get ErrInvalid", func(t *testing.T) {
+		path := write(t, head, entry{Feature: feature}, entry{Trailer: &trailer{Features: 1}})
+		b, err := os.ReadFile(path)
+		assert.NoError(t, err)
+		assert.NoError(t, os.WriteFile(path, b[:len(b)-6], 0o644))
+
+		_, err = Verify(path)
+		assert.ErrorIs(t, err, ErrInvalid)
+	})
+}
@@ -0,0 +1,285 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"time"
+
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/uuid"
+)
+
+const DefaultBatchSize = 500
+
+type Mode string
+
+const (
+	// Merge restores the features missing from the database and keeps
+	// the ones it still has as they are now.
+	Merge Mode = "merge"
+	// Replace deletes every feature, vote and audit event first, leaving
+	// the database as it was when the backup was taken.
+	Replace Mode = "replace"
+)
+
+// ParseMode reads a mode as given on the command line.
+func ParseMode(s string) (Mode, error) {
+	switch m := Mode(s); m {
+	case Merge, Replace:
+		return m, nil
+	}
+	return "", fmt.Errorf("unknown restore mode %q, want merge or replace", s)
+}
+
+type Options struct {
+	Mode Mode
+	// DryRun checks the backup without writing anything.
+	DryRun    bool
+	BatchSize int
+	// BackupDir is where a replace backs up the database before clearing
+	// it, by default the directory of the backup being restored.
+	BackupDir string
+}
+
+// Report sums up a restore. In a dry run nothing is restored or skipped.
+type Report struct {
+	Manifest *Manifest `json:"manifest"`
+	Mode     Mode      `json:"mode"`
+	DryRun   bool      `json:"dryRun"`
+	Features int       `json:"features"`
+	Votes    int       `json:"votes"`
+	Audit    int       `json:"audit"`
+	// SkippedFeatures are the features, with their votes, and SkippedAudit
+	// the audit events, that a merge left alone as the database already
+	// had them.
+	SkippedFeatures int `json:"skippedFeatures"`
+	SkippedAudit    int `json:"skippedAudit"`
+	// PreRestoreBackup is the backup a replace took of the database before
+	// clearing it; restore it in replace mode to undo the restore.
+	PreRestoreBackup *Manifest `json:"preRestoreBackup,omitempty"`
+}
+
+// Verify reads the backup at path through and returns its manifest, or an
+// error wrapping ErrInvalid when it is damaged, incomplete or written by a
+// newer version of the format.
+func Verify(path string) (*Manifest, error) {
+	r, err := open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	first, err := r.next()
+	if err == io.EOF {
+		return nil, fmt.Errorf("%w: the file is empty", ErrInvalid)
+	}
+	if err != nil {
+		return nil, err
+	}
+	h := first.Header
+	if h == nil || h.Format != Format {
+		return nil, fmt.Errorf("%w: the file has no %s header", ErrInvalid, Format)
+	}
+	if h.Version < 1 || h.Version > Version {
+		return nil, fmt.Errorf("%w: version %d can't be read, only versions up to %d", ErrInvalid, h.Version, Version)
+	}
+
+	m := &Manifest{Path: path, Version: h.Version, CreatedAt: h.CreatedAt}
+	seen := map[uuid.UUID]bool{}
+	for {
+		e, err := r.next()
+		if err == io.EOF {
+			return nil, fmt.Errorf("%w: the file ends without a trailer, so it is incomplete", ErrInvalid)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case e.Feature != nil:
+			if err := checkFeature(e, seen); err != nil {
+				return nil, fmt.Errorf("%w: line %d: %v", ErrInvalid, r.line, err)
+			}
+			m.Features++
+			m.Votes += len(e.Votes)
+		case e.Audit != nil:
+			if e.Audit.Id == uuid.Nil {
+				return nil, fmt.Errorf("%w: line %d: an audit event has no id", ErrInvalid, r.line)
+			}
+			m.Audit++
+		case e.Trailer != nil:
+			t := e.Trailer
+			if t.Features != m.Features || t.Votes != m.Votes || t.Audit != m.Audit {
+				return nil, fmt.Errorf("%w: the trailer counts %d features, %d votes and %d audit events, but the file has %d, %d and %d",
+					ErrInvalid, t.Features, t.Votes, t.Audit, m.Features, m.Votes, m.Audit)
+			}
+			if _, err := r.next(); err != io.EOF {
+				return nil, fmt.Errorf("%w: line %d: the file goes on after its trailer", ErrInvalid, r.line+1)
+			}
+			return m, nil
+		default:
+			return nil, fmt.Errorf("%w: line %d: the entry is neither a feature, an audit event nor the trailer", ErrInvalid, r.line)
+		}
+	}
+}
+
+func checkFeature(e *entry, seen map[uuid.UUID]bool) error {
+	f := e.Feature
+	if f.Id == uuid.Nil {
+		return fmt.Errorf("a feature has no id")
+	}
+	if seen[f.Id] {
+		return fmt.Errorf("feature %s appears twice", f.Id)
+	}
+	seen[f.Id] = true
+
+	for _, v := range e.Votes {
+		if v.Id == uuid.Nil || v.FeatureId != f.Id {
+			return fmt.Errorf("feature %s has a vote that isn't its own", f.Id)
+		}
+	}
+	return nil
+}
+
+// Restore checks the backup at path with Verify, then writes it to db in
+// batches. Nothing is written unless the whole file checks out, but a
+// restore that fails part way leaves the batches written until then; as
+// they are skipped by a merge, running it again in merge mode finishes it.
+//
+// A replace can't be finished that way once the database is cleared, so it
+// first backs the database up into opts.BackupDir, and names that backup
+// in the report, which is returned even when the restore fails.
+//
+// Restored features are written exactly as they were backed up, without
+// recording any change, so they raise no events.
+func Restore(ctx context.Context, db Database, path string, opts Options) (*Report, error) {
+	if opts.Mode == "" {
+		opts.Mode = Merge
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultBatchSize
+	}
+
+	m, err := Verify(path)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{Manifest: m, Mode: opts.Mode, DryRun: opts.DryRun}
+	if opts.DryRun {
+		return report, nil
+	}
+
+	if opts.Mode == Replace {
+		dir := opts.BackupDir
+		if dir == "" {
+			dir = filepath.Dir(path)
+		}
+		pre, err := Create(ctx, db, dir, time.Now())
+		if err != nil {
+			return report, fmt.Errorf("backup.Restore: backing up the database before replacing it: %w", err)
+		}
+		report.PreRestoreBackup = pre
+
+		if err := db.ClearFeatures(ctx); err != nil {
+			return report, err
+		}
+	}
+
+	r, err := open(path)
+	if err != nil {
+		return report, err
+	}
+	defer r.Close()
+
+	run := &run{ctx: ctx, db: db, size: opts.BatchSize, report: report}
+	for {
+		e, err := r.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return report, err
+		}
+
+		switch {
+		case e.Feature != nil:
+			run.features = append(run.features, e.Feature)
+			run.votes = append(run.votes, e.Votes...)
+			if len(run.features) >= run.size {
+				err = run.flushFeatures()
+			}
+		case e.Audit != nil:
+			run.audit = append(run.audit, e.Audit)
+			if len(run.audit) >= run.size {
+				err = run.flushAudit()
+			}
+		}
+		if err != nil {
+			return report, err
+		}
+	}
+
+	if err := run.flushFeatures(); err != nil {
+		return report, err
+	}
+	if err := run.flushAudit(); err != nil {
+		return report, err
+	}
+
+	return report, nil
+}
+
+type run struct {
+	ctx      context.Context
+	db       Database
+	size     int
+	report   *Report
+	features []*domain.Feature
+	votes    []*domain.Vote
+	audit    []*domain.AuditEvent
+}
+
+func (r *run) flushFeatures() error {
+	if len(r.features) == 0 {
+		return nil
+	}
+
+	skipped, err := r.db.RestoreFeatures(r.ctx, r.features, r.votes)
+	if err != nil {
+		return err
+	}
+
+	left := make(map[uuid.UUID]bool, len(skipped))
+	for _, id := range skipped {
+		left[id] = true
+	}
+	r.report.Features += len(r.features) - len(skipped)
+	r.report.SkippedFeatures += len(skipped)
+	for _, v := range r.votes {
+		if !left[v.FeatureId] {
+			r.report.Votes++
+		}
+	}
+
+	r.features, r.votes = nil, nil
+	return nil
+}
+
+func (r *run) flushAudit() error {
+	if len(r.audit) == 0 {
+		return nil
+	}
+
+	n, err := r.db.RestoreAudit(r.ctx, r.audit)
+	if err != nil {
+		return err
+	}
+	r.report.Audit += n
+	r.report.SkippedAudit += len(r.audit) - n
+
+	r.audit = nil
+	return nil
+}
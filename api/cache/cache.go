@@ -0,0 +1,205 @@
+// Package cache keeps recently read features in memory in front of the
+// database.
+package cache
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/music-tribe/react-pairing-challenge/database"
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/uuid"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	DefaultSize = 10000
+	DefaultTTL  = 30 * time.Second
+)
+
+type Options struct {
+	// Enabled turns caching on; when off every call goes to the database.
+	Enabled bool
+	// Size is the most features held at once.
+	Size int
+	// TTL is how long a feature may be served from memory. It bounds how
+	// stale a read can be after a write made through another replica.
+	TTL time.Duration
+}
+
+// Stats describes how well the cache is doing.
+type Stats struct {
+	Enabled   bool   `json:"enabled" example:"true"`
+	Size      int    `json:"size" example:"812"`
+	Capacity  int    `json:"capacity" example:"10000"`
+	Hits      uint64 `json:"hits" example:"48211"`
+	Misses    uint64 `json:"misses" example:"2034"`
+	Loads     uint64 `json:"loads" example:"1990"`
+	Evictions uint64 `json:"evictions" example:"0"`
+}
+
+// Database serves Get and GetById from memory and passes every other call
+// through to the wrapped database. Writes to a feature drop it from the
+// cache, as does Handle for changes seen elsewhere.
+type Database struct {
+	database.Store
+
+	enabled bool
+	size    int
+	lru     *lru
+	group   singleflight.Group
+	// getById reads a feature on a miss.
+	getById func(featureId uuid.UUID) (*domain.Feature, error)
+
+	// epoch is bumped by every invalidation. A load only stores its result
+	// if no invalidation happened while it was in flight, so a slow read
+	// can't put back a feature a write has just dropped.
+	epochMu sync.Mutex
+	epoch   uint64
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+	loads  atomic.Uint64
+}
+
+func New(db database.Store, opts Options) *Database {
+	if db == nil {
+		panic("cache.New: db has nil value")
+	}
+	if opts.Size <= 0 {
+		opts.Size = DefaultSize
+	}
+	if opts.TTL <= 0 {
+		opts.TTL = DefaultTTL
+	}
+
+	return &Database{
+		Store:   db,
+		enabled: opts.Enabled,
+		size:    opts.Size,
+		lru:     newLRU(opts.Size, opts.TTL, time.Now),
+		getById: db.GetById,
+	}
+}
+
+func (d *Database) Get(userId, featureId uuid.UUID) (*domain.Feature, error) {
+	if !d.enabled {
+		return d.Store.Get(userId, featureId)
+	}
+
+	feature, err := d.load(featureId)
+	if err != nil {
+		return nil, err
+	}
+	if feature.UserId != userId {
+		return nil, database.ErrNotFound
+	}
+
+	return feature, nil
+}
+
+func (d *Database) GetById(featureId uuid.UUID) (*domain.Feature, error) {
+	if !d.enabled {
+		return d.Store.GetById(featureId)
+	}
+
+	return d.load(featureId)
+}
+
+// load returns a copy of the feature, from memory when possible. Concurrent
+// misses for the same feature share one database read.
+func (d *Database) load(featureId uuid.UUID) (*domain.Feature, error) {
+	if feature, ok := d.lru.get(featureId); ok {
+		d.hits.Add(1)
+		return clone(feature), nil
+	}
+	d.misses.Add(1)
+
+	v, err, _ := d.group.Do(featureId.String(), func() (interface{}, error) {
+		epoch := d.currentEpoch()
+
+		d.loads.Add(1)
+		feature, err := d.getById(featureId)
+		if err != nil {
+			return nil, err
+		}
+
+		d.epochMu.Lock()
+		if d.epoch == epoch {
+			d.lru.set(feature)
+		}
+		d.epochMu.Unlock()
+
+		return feature, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return clone(v.(*domain.Feature)), nil
+}
+
+func (d *Database) currentEpoch() uint64 {
+	d.epochMu.Lock()
+	defer d.epochMu.Unlock()
+
+	return d.epoch
+}
+
+// Invalidate drops a feature from the cache.
+func (d *Database) Invalidate(featureId uuid.UUID) {
+	d.epochMu.Lock()
+	defer d.epochMu.Unlock()
+
+	d.epoch++
+	d.lru.delete(featureId)
+	// a load already in flight would otherwise still be shared
+	d.group.Forget(featureId.String())
+}
+
+// Flush drops every feature from the cache.
+func (d *Database) Flush() {
+	d.epochMu.Lock()
+	defer d.epochMu.Unlock()
+
+	d.epoch++
+	d.lru.clear()
+}
+
+// Handle drops the feature an event is about. It is an events.Handler, for
+// hearing about writes made through other replicas.
+func (d *Database) Handle(_ context.Context, event *domain.Event) error {
+	d.Invalidate(event.FeatureId)
+	return nil
+}
+
+func (d *Database) CacheStats() Stats {
+	size, evictions := d.lru.stats()
+
+	return Stats{
+		Enabled:   d.enabled,
+		Size:      size,
+		Capacity:  d.size,
+		Hits:      d.hits.Load(),
+		Misses:    d.misses.Load(),
+		Loads:     d.loads.Load(),
+		Evictions: evictions,
+	}
+}
+
+// clone copies a feature so callers can't change the cached one.
+func clone(f *domain.Feature) *domain.Feature {
+	c := *f
+	if f.Tags != nil {
+		c.Tags = append([]string(nil), f.Tags...)
+	}
+	for _, t := range []**time.Time{&c.LastVotedAt, &c.DeletedAt} {
+		if *t != nil {
+			v := **t
+			*t = &v
+		}
+	}
+	return &c
+}
@@ -0,0 +1,298 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/music-tribe/react-pairing-challenge/database"
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDatabase(t *testing.T) {
+	now := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+
+	// newDatabase returns a cache reading features through getById
+	newDatabase := func(size int, getById func(uuid.UUID) (*domain.Feature, error)) *Database {
+		return &Database{
+			enabled: true,
+			size:    size,
+			lru:     newLRU(size, time.Minute, func() time.Time { return now }),
+			getById: getById,
+		}
+	}
+
+	t.Run("when the db has a nil value, we should panic", func(t *testing.T) {
+		assert.Panics(t, func() {
+			New(nil, Options{Enabled: true})
+		})
+	})
+
+	t.Run("when a feature is read twice, the second read should be a hit", func(t *testing.T) {
+		feature := &domain.Feature{Id: uuid.New(), UserId: uuid.New(), Name: "cached"}
+		reads := 0
+		d := newDatabase(10, func(uuid.UUID) (*domain.Feature, error) {
+			reads++
+			return feature, nil
+		})
+
+		got, err := d.GetById(feature.Id)
+		assert.NoError(t, err)
+		assert.Equal(t, "cached", got.Name)
+
+		got, err = d.Get(feature.UserId, feature.Id)
+		assert.NoError(t, err)
+		assert.Equal(t, "cached", got.Name)
+
+		assert.Equal(t, 1, reads)
+		stats := d.CacheStats()
+		assert.Equal(t, uint64(1), stats.Hits)
+		assert.Equal(t, uint64(1), stats.Misses)
+		assert.Equal(t, 1, stats.Size)
+	})
+
+	t.Run("when a cached feature is read by another user, we should return ErrNotFound", func(t *testing.T) {
+		feature := &domain.Feature{Id: uuid.New(), UserId: uuid.New()}
+		d := newDatabase(10, func(uuid.UUID) (*domain.Feature, error) { return feature, nil })
+
+		_, err := d.Get(uuid.New(), feature.Id)
+		assert.ErrorIs(t, err, database.ErrNotFound)
+	})
+
+	t.Run("when a caller changes a returned feature, the cached one should be unchanged", func(t *testing.T) {
+		feature := &domain.Feature{Id: uuid.New(), Name: "original", Tags: []string{"mixer"}}
+		d := newDatabase(10, func(uuid.UUID) (*domain.Feature, error) { return feature, nil })
+
+		got, _ := d.GetById(feature.Id)
+		got.Name = "changed"
+		got.Tags[0] = "changed"
+
+		got, _ = d.GetById(feature.Id)
+		assert.Equal(t, "original", got.Name)
+		assert.Equal(t, []string{"mixer"}, got.Tags)
+	})
+
+	t.Run("when a read fails, nothing should be cached", func(t *testing.T) {
+		reads := 0
+		d := newDatabase(10, func(uuid.UUID) (*domain.Feature, error) {
+			reads++
+			return nil, database.ErrNotFound
+		})
+
+		id := uuid.New()
+		_, err := d.GetById(id)
+		assert.ErrorIs(t, err, database.ErrNotFound)
+		_, err = d.GetById(id)
+		assert.ErrorIs(t, err, database.ErrNotFound)
+		assert.Equal(t, 2, reads)
+	})
+
+	t.Run("when a feature is invalidated, the next read should go to the database", func(t *testing.T) {
+		feature := &domain.Feature{Id: uuid.New()}
+		reads := 0
+		d := newDatabase(10, func(uuid.UUID) (*domain.Feature, error) {
+			reads++
+			return feature, nil
+		})
+
+		d.GetById(feature.Id)
+		assert.NoError(t, d.Handle(context.Background(), &domain.Event{FeatureId: feature.Id}))
+		d.GetById(feature.Id)
+		d.Flush()
+		d.GetById(feature.Id)
+
+		assert.Equal(t, 3, reads)
+	})
+
+	t.Run("when an invalidation happens during a read, the read should not be cached", func(t *testing.T) {
+		feature := &domain.Feature{Id: uuid.New()}
+		reads := 0
+		var d *Database
+		d = newDatabase(10, func(uuid.UUID) (*domain.Feature, error) {
+			reads++
+			if reads == 1 {
+				// a write lands while the first read is in flight
+				d.Invalidate(feature.Id)
+			}
+			return feature, nil
+		})
+
+		d.GetById(feature.Id)
+		d.GetById(feature.Id)
+		assert.Equal(t, 2, reads)
+	})
+
+	t.Run("when many callers miss on the same feature at once, it should be read once", func(t *testing.T) {
+		feature := &domain.Feature{Id: uuid.New()}
+		release := make(chan struct{})
+		var mu sync.Mutex
+		reads := 0
+		d := newDatabase(10, func(uuid.UUID) (*domain.Feature, error) {
+			mu.Lock()
+			reads++
+			mu.Unlock()
+			<-release
+			return feature, nil
+		})
+
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := d.GetById(feature.Id)
+				assert.NoError(t, err)
+			}()
+		}
+		for d.CacheStats().Misses < 20 {
+			time.Sleep(time.Millisecond)
+		}
+		close(release)
+		wg.Wait()
+
+		assert.Equal(t, 1, reads)
+		assert.Equal(t, uint64(1), d.CacheStats().Loads)
+	})
+
+	t.Run("when the cache is full, the least recently used feature should be evicted", func(t *testing.T) {
+		d := newDatabase(2, func(id uuid.UUID) (*domain.Feature, error) {
+			return &domain.Feature{Id: id}, nil
+		})
+
+		a, b, c := uuid.New(), uuid.New(), uuid.New()
+		d.GetById(a)
+		d.GetById(b)
+		d.GetById(a)
+		d.GetById(c)
+
+		_, aCached := d.lru.get(a)
+		_, bCached := d.lru.get(b)
+		assert.True(t, aCached)
+		assert.False(t, bCached)
+		assert.Equal(t, uint64(1), d.CacheStats().Evictions)
+	})
+
+	t.Run("when a feature's ttl has passed, it should be read again", func(t *testing.T) {
+		reads := 0
+		d := newDatabase(10, func(id uuid.UUID) (*domain.Feature, error) {
+			reads++
+			return &domain.Feature{Id: id}, nil
+		})
+		clock := now
+		d.lru.now = func() time.Time { return clock }
+
+		id := uuid.New()
+		d.GetById(id)
+		clock = clock.Add(59 * time.Second)
+		d.GetById(id)
+		clock = clock.Add(time.Second)
+		d.GetById(id)
+
+		assert.Equal(t, 2, reads)
+	})
+
+	t.Run("when the read fails with another error, it should be returned", func(t *testing.T) {
+		errBoom := errors.New("boom")
+		d := newDatabase(10, func(uuid.UUID) (*domain.Feature, error) { return nil, errBoom })
+
+		_, err := d.GetById(uuid.New())
+		assert.ErrorIs(t, err, errBoom)
+	})
+}
+
+// writeStore accepts every write, for checking what the cache drops.
+type writeStore struct {
+	database.Store
+}
+
+func (writeStore) ImportFeatures(string, []*domain.Feature) ([]uuid.UUID, error) {
+	return nil, nil
+}
+
+func (writeStore) MergeFeatures(uuid.UUID, uuid.UUID, string) (*domain.Feature, error) {
+	return nil, nil
+}
+
+func (writeStore) RecountVotes(uuid.UUID, string) (*domain.Feature, bool, error) {
+	return nil, false, nil
+}
+
+func (writeStore) RestoreFeatures(context.Context, []*domain.Feature, []*domain.Vote) ([]uuid.UUID, error) {
+	return nil, nil
+}
+
+func (writeStore) ClearFeatures(context.Context) error {
+	return nil
+}
+
+func TestDatabase_Writes(t *testing.T) {
+	// cached returns a cache holding a feature for each id
+	cached := func(ids ...uuid.UUID) *Database {
+		d := &Database{
+			Store:   writeStore{},
+			enabled: true,
+			size:    10,
+			lru:     newLRU(10, time.Minute, time.Now),
+			getById: func(id uuid.UUID) (*domain.Feature, error) { return &domain.Feature{Id: id}, nil },
+		}
+		for _, id := range ids {
+			d.GetById(id)
+		}
+		assert.Equal(t, len(ids), d.CacheStats().Size)
+		return d
+	}
+	isCached := func(d *Database, id uuid.UUID) bool {
+		_, ok := d.lru.get(id)
+		return ok
+	}
+
+	t.Run("when features are imported, they should be dropped", func(t *testing.T) {
+		a, b, other := uuid.New(), uuid.New(), uuid.New()
+		d := cached(a, b, other)
+
+		_, err := d.ImportFeatures("admin", []*domain.Feature{{Id: a}, {Id: b}})
+		assert.NoError(t, err)
+		assert.False(t, isCached(d, a))
+		assert.False(t, isCached(d, b))
+		assert.True(t, isCached(d, other))
+	})
+
+	t.Run("when features are merged, both should be dropped", func(t *testing.T) {
+		target, source := uuid.New(), uuid.New()
+		d := cached(target, source)
+
+		_, err := d.MergeFeatures(target, source, "admin")
+		assert.NoError(t, err)
+		assert.False(t, isCached(d, target))
+		assert.False(t, isCached(d, source))
+	})
+
+	t.Run("when a feature's votes are recounted, it should be dropped", func(t *testing.T) {
+		id, other := uuid.New(), uuid.New()
+		d := cached(id, other)
+
+		_, _, err := d.RecountVotes(id, "admin")
+		assert.NoError(t, err)
+		assert.False(t, isCached(d, id))
+		assert.True(t, isCached(d, other))
+	})
+
+	t.Run("when features are restored from a backup, the cache should be flushed", func(t *testing.T) {
+		d := cached(uuid.New(), uuid.New())
+
+		_, err := d.RestoreFeatures(context.Background(), []*domain.Feature{{Id: uuid.New()}}, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, d.CacheStats().Size)
+	})
+
+	t.Run("when the features are cleared, the cache should be flushed", func(t *testing.T) {
+		d := cached(uuid.New(), uuid.New())
+
+		assert.NoError(t, d.ClearFeatures(context.Background()))
+		assert.Equal(t, 0, d.CacheStats().Size)
+	})
+}
@@ -0,0 +1,108 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/uuid"
+)
+
+// lru holds up to size features, dropping the least recently used first.
+// Entries also expire ttl after they were stored. It is safe for
+// concurrent use.
+type lru struct {
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration
+	now     func() time.Time
+	order   *list.List
+	entries map[uuid.UUID]*list.Element
+	// evictions counts entries dropped to make room.
+	evictions uint64
+}
+
+type entry struct {
+	feature *domain.Feature
+	expires time.Time
+}
+
+func newLRU(size int, ttl time.Duration, now func() time.Time) *lru {
+	return &lru{
+		size:    size,
+		ttl:     ttl,
+		now:     now,
+		order:   list.New(),
+		entries: map[uuid.UUID]*list.Element{},
+	}
+}
+
+func (c *lru) get(id uuid.UUID) (*domain.Feature, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[id]
+	if !ok {
+		return nil, false
+	}
+
+	e := el.Value.(*entry)
+	if !c.now().Before(e.expires) {
+		c.remove(el)
+		return nil, false
+	}
+
+	c.order.MoveToFront(el)
+	return e.feature, true
+}
+
+func (c *lru) set(feature *domain.Feature) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expires := c.now().Add(c.ttl)
+	if el, ok := c.entries[feature.Id]; ok {
+		e := el.Value.(*entry)
+		e.feature, e.expires = feature, expires
+		c.order.MoveToFront(el)
+		return
+	}
+
+	c.entries[feature.Id] = c.order.PushFront(&entry{feature: feature, expires: expires})
+	for c.order.Len() > c.size {
+		c.remove(c.order.Back())
+		c.evictions++
+	}
+}
+
+func (c *lru) delete(id uuid.UUID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[id]; ok {
+		c.remove(el)
+	}
+}
+
+func (c *lru) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.order.Init()
+	c.entries = map[uuid.UUID]*list.Element{}
+}
+
+// stats returns the number of entries and evictions so far.
+func (c *lru) stats() (int, uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.order.Len(), c.evictions
+}
+
+// remove must be called with the cache locked.
+func (c *lru) remove(el *list.Element) {
+	c.order.Remove(el)
+	delete(c.entries, el.Value.(*entry).feature.Id)
+}
@@ -0,0 +1,94 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/uuid"
+)
+
+// Every write that changes a feature drops it once the write is done,
+// whether or not it succeeded, since a failed write may still have
+// partly applied.
+
+func (d *Database) Update(feature *domain.Feature) error {
+	defer d.Invalidate(feature.Id)
+	return d.Store.Update(feature)
+}
+
+func (d *Database) Delete(userId, featureId uuid.UUID) error {
+	defer d.Invalidate(featureId)
+	return d.Store.Delete(userId, featureId)
+}
+
+func (d *Database) Restore(userId, featureId uuid.UUID) (*domain.Feature, error) {
+	defer d.Invalidate(featureId)
+	return d.Store.Restore(userId, featureId)
+}
+
+func (d *Database) SetStatus(featureId uuid.UUID, status domain.FeatureStatus, actor string) (*domain.Feature, error) {
+	defer d.Invalidate(featureId)
+	return d.Store.SetStatus(featureId, status, actor)
+}
+
+func (d *Database) AddVote(vote *domain.Vote, budget domain.VoteBudget) (*domain.Feature, error) {
+	defer d.Invalidate(vote.FeatureId)
+	return d.Store.AddVote(vote, budget)
+}
+
+func (d *Database) RemoveVote(featureId, voterId uuid.UUID) (*domain.Feature, error) {
+	defer d.Invalidate(featureId)
+	return d.Store.RemoveVote(featureId, voterId)
+}
+
+func (d *Database) ChangeVoteDirection(featureId, voterId uuid.UUID, direction domain.VoteDirection) (*domain.Feature, error) {
+	defer d.Invalidate(featureId)
+	return d.Store.ChangeVoteDirection(featureId, voterId, direction)
+}
+
+// Purging only touches features already in the trash, which are never
+// cached, but it is rare enough to flush to be safe.
+func (d *Database) PurgeDeleted(before time.Time) (int64, error) {
+	defer d.Flush()
+	return d.Store.PurgeDeleted(before)
+}
+
+// Deleting a category removes its tag from any number of features.
+func (d *Database) DeleteCategory(slug string) error {
+	defer d.Flush()
+	return d.Store.DeleteCategory(slug)
+}
+
+func (d *Database) ImportFeatures(actor string, features []*domain.Feature) ([]uuid.UUID, error) {
+	defer func() {
+		for _, f := range features {
+			d.Invalidate(f.Id)
+		}
+	}()
+	return d.Store.ImportFeatures(actor, features)
+}
+
+// Merging moves the source's votes to the target and trashes the source.
+func (d *Database) MergeFeatures(targetId, sourceId uuid.UUID, actor string) (*domain.Feature, error) {
+	defer d.Invalidate(sourceId)
+	defer d.Invalidate(targetId)
+	return d.Store.MergeFeatures(targetId, sourceId, actor)
+}
+
+func (d *Database) RecountVotes(featureId uuid.UUID, actor string) (*domain.Feature, bool, error) {
+	defer d.Invalidate(featureId)
+	return d.Store.RecountVotes(featureId, actor)
+}
+
+// Restoring a backup writes features in bulk, and clearing the database
+// ahead of it drops every one of them.
+func (d *Database) RestoreFeatures(ctx context.Context, features []*domain.Feature, votes []*domain.Vote) ([]uuid.UUID, error) {
+	defer d.Flush()
+	return d.Store.RestoreFeatures(ctx, features, votes)
+}
+
+func (d *Database) ClearFeatures(ctx context.Context) error {
+	defer d.Flush()
+	return d.Store.ClearFeatures(ctx)
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/music-tribe/react-pairing-challenge/backup"
+)
+
+func runBackup(e *env, args []string) error {
+	fs := e.flags("backup", "")
+	dir := fs.String("dir", "backups", "directory the backup is written to")
+	if err := parse(fs, args, 0, 0); err != nil {
+		return err
+	}
+
+	db, err := e.open()
+	if err != nil {
+		return err
+	}
+
+	m, err := backup.Create(context.Background(), db, *dir, time.Now())
+	if err != nil {
+		return err
+	}
+
+	return e.print(m, func(w io.Writer) {
+		fmt.Fprintf(w, "wrote %s: %d features, %d votes, %d audit events\n", m.Path, m.Features, m.Votes, m.Audit)
+	})
+}
+
+func runRestore(e *env, args []string) error {
+	fs := e.flags("restore", "<file>")
+	mode := fs.String("mode", string(backup.Merge), "merge to add what is missing, or replace to delete every feature first")
+	dryRun := fs.Bool("dry-run", false, "check the backup without restoring anything")
+	batch := fs.Int("batch", backup.DefaultBatchSize, "features written per transaction")
+	backupDir := fs.String("backup-dir", "", "directory a replace backs the database up to first, by default that of <file>")
+	if err := parse(fs, args, 1, 1); err != nil {
+		return err
+	}
+
+	m, err := backup.ParseMode(*mode)
+	if err != nil {
+		return err
+	}
+
+	// a dry run only reads the file, so it needs no database
+	var db backup.Database
+	if !*dryRun {
+		if db, err = e.open(); err != nil {
+			return err
+		}
+	}
+
+	report, err := backup.Restore(context.Background(), db, fs.Arg(0), backup.Options{
+		Mode:      m,
+		DryRun:    *dryRun,
+		BatchSize: *batch,
+		BackupDir: *backupDir,
+	})
+	if report != nil {
+		e.print(report, func(w io.Writer) { printRestore(w, report) })
+	}
+	return err
+}
+
+func printRestore(w io.Writer, r *backup.Report) {
+	m := r.Manifest
+	fmt.Fprintf(w, "%s, taken %s: %d features, %d votes, %d audit events\n",
+		m.Path, m.CreatedAt.Format(time.RFC3339), m.Features, m.Votes, m.Audit)
+	if r.DryRun {
+		fmt.Fprintln(w, "the backup checks out; nothing was restored")
+		return
+	}
+	if pre := r.PreRestoreBackup; pre != nil {
+		fmt.Fprintf(w, "backed the database up to %s first; restore it with -mode replace to undo\n", pre.Path)
+	}
+	fmt.Fprintf(w, "%s: restored %d features, %d votes and %d audit events", r.Mode, r.Features, r.Votes, r.Audit)
+	if r.Mode == backup.Merge {
+		fmt.Fprintf(w, ", kept %d features and %d audit events already there", r.SkippedFeatures, r.SkippedAudit)
+	}
+	fmt.Fprintln(w)
+}
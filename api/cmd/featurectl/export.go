@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/music-tribe/react-pairing-challenge/database"
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/react-pairing-challenge/exporter"
+	"github.com/music-tribe/react-pairing-challenge/ranking"
+)
+
+func runExport(e *env, args []string) error {
+	fs := e.flags("export", "")
+	format := fs.String("format", "csv", "csv or jsonl")
+	columns := fs.String("columns", "", "comma separated columns, defaults to every feature field; voters, upvoters and downvoters list votes")
+	out := fs.String("out", "-", "file to write, - for standard output")
+	board := fs.String("board", "", "only features on this board")
+	var tags listFlag
+	fs.Var(&tags, "tag", "only features with this tag (repeatable)")
+	matchAll := fs.Bool("all-tags", false, "only features with all of the tags, rather than any")
+	status := fs.String("status", "", "only features with this status")
+	query := fs.String("q", "", "free text to match against names and descriptions")
+	sort := fs.String("sort", "new", "hot, top, new or rising")
+	if err := parse(fs, args, 0, 0); err != nil {
+		return err
+	}
+
+	f, err := exporter.ParseFormat(*format)
+	if err != nil {
+		return err
+	}
+	cols, err := exporter.ParseColumns(*columns)
+	if err != nil {
+		return err
+	}
+	if *status != "" && !domain.FeatureStatus(*status).Valid() {
+		return fmt.Errorf("unknown status %q", *status)
+	}
+
+	db, err := e.open()
+	if err != nil {
+		return err
+	}
+
+	dst := e.stdout
+	if *out != "-" {
+		file, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		dst = file
+	}
+
+	w := exporter.NewWriter(f, dst, cols)
+	if err := w.Header(); err != nil {
+		return err
+	}
+
+	rows := 0
+	err = db.EachFeature(context.Background(), database.ListOptions{
+		Sort:         ranking.Sort(*sort),
+		Board:        *board,
+		Tags:         domain.NormalizeTags(tags),
+		MatchAllTags: *matchAll,
+		Text:         *query,
+		Status:       domain.FeatureStatus(*status),
+	}, exporter.NeedVotes(cols), func(feature *domain.Feature, votes []*domain.Vote) error {
+		rows++
+		return w.Row(feature, votes)
+	})
+	if err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if *out != "-" {
+		fmt.Fprintf(e.stderr, "exported %d features to %s\n", rows, *out)
+	}
+	return nil
+}
@@ -0,0 +1,373 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/music-tribe/react-pairing-challenge/database"
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/react-pairing-challenge/dto"
+	"github.com/music-tribe/react-pairing-challenge/ranking"
+	"github.com/music-tribe/uuid"
+)
+
+func runList(e *env, args []string) error {
+	fs := e.flags("list", "")
+	userId := fs.String("user", "", "only this user's features")
+	board := fs.String("board", "", "only features on this board")
+	var tags listFlag
+	fs.Var(&tags, "tag", "only features with this tag (repeatable)")
+	matchAll := fs.Bool("all-tags", false, "only features with all of the tags, rather than any")
+	status := fs.String("status", "", "only features with this status")
+	query := fs.String("q", "", "free text to match against names and descriptions")
+	sort := fs.String("sort", "new", "hot, top, new or rising")
+	limit := fs.Int("limit", 50, "most features to list, 0 for all")
+	if err := parse(fs, args, 0, 0); err != nil {
+		return err
+	}
+
+	if *status != "" && !domain.FeatureStatus(*status).Valid() {
+		return fmt.Errorf("unknown status %q", *status)
+	}
+	switch ranking.Sort(*sort) {
+	case ranking.SortHot, ranking.SortTop, ranking.SortNew, ranking.SortRising:
+	default:
+		return fmt.Errorf("unknown sort %q", *sort)
+	}
+
+	db, err := e.open()
+	if err != nil {
+		return err
+	}
+
+	opts := database.ListOptions{
+		Sort:         ranking.Sort(*sort),
+		Board:        *board,
+		Tags:         domain.NormalizeTags(tags),
+		MatchAllTags: *matchAll,
+		Text:         *query,
+		Status:       domain.FeatureStatus(*status),
+	}
+
+	var features []*domain.Feature
+	if *userId != "" {
+		id, err := uuid.Parse(*userId)
+		if err != nil {
+			return fmt.Errorf("%q is not a user id", *userId)
+		}
+		features, err = db.GetAll(id, opts)
+		if err != nil {
+			return err
+		}
+	} else if features, err = db.Search(opts); err != nil {
+		return err
+	}
+
+	if *limit > 0 && len(features) > *limit {
+		features = features[:*limit]
+	}
+
+	return e.print(features, func(w io.Writer) { featureTable(w, features) })
+}
+
+func runShow(e *env, args []string) error {
+	fs := e.flags("show", "<featureId>")
+	if err := parse(fs, args, 1, 1); err != nil {
+		return err
+	}
+	id, err := parseId(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	db, err := e.open()
+	if err != nil {
+		return err
+	}
+
+	feature, err := db.GetById(id)
+	if err != nil {
+		return err
+	}
+	votes, err := db.GetVotes(id)
+	if err != nil {
+		return err
+	}
+
+	out := struct {
+		Feature *domain.Feature `json:"feature"`
+		Votes   []*domain.Vote  `json:"votes"`
+	}{feature, votes}
+
+	return e.print(out, func(w io.Writer) {
+		featureDetails(w, feature)
+		if len(votes) == 0 {
+			return
+		}
+		fmt.Fprintln(w, "\nVOTER\tDIRECTION\tWEIGHT\tCAST AT\tSOURCE")
+		for _, v := range votes {
+			direction := v.Direction
+			if direction == "" {
+				direction = domain.Upvote
+			}
+			weight := v.Weight
+			if weight == 0 {
+				weight = 1
+			}
+			fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\n", v.VoterId, direction, weight, timestamp(&v.CastAt), v.Source)
+		}
+	})
+}
+
+func runCreate(e *env, args []string) error {
+	fs := e.flags("create", "")
+	userId := fs.String("user", "", "owner of the feature (required)")
+	name := fs.String("name", "", "name of the feature (required)")
+	description := fs.String("description", "", "what the feature should do (required)")
+	board := fs.String("board", "", "board to put the feature on")
+	var tags listFlag
+	fs.Var(&tags, "tag", "category to tag the feature with (repeatable)")
+	if err := parse(fs, args, 0, 0); err != nil {
+		return err
+	}
+
+	owner, err := uuid.Parse(*userId)
+	if err != nil {
+		return fmt.Errorf("-user %q is not a user id", *userId)
+	}
+
+	req := dto.CreateFeature{UserId: owner, Name: *name, Description: *description, Board: *board, Tags: tags}
+	if err := validator.New().Struct(&req); err != nil {
+		return err
+	}
+
+	db, err := e.open()
+	if err != nil {
+		return err
+	}
+
+	feature := dto.NewFeature(uuid.New(), &req)
+	if err := checkTags(db, feature.Tags); err != nil {
+		return err
+	}
+	if err := db.Add(feature); err != nil {
+		return err
+	}
+
+	return e.print(feature, func(w io.Writer) { featureDetails(w, feature) })
+}
+
+func runUpdate(e *env, args []string) error {
+	fs := e.flags("update", "<featureId>")
+	name := fs.String("name", "", "new name")
+	description := fs.String("description", "", "new description")
+	var tags listFlag
+	fs.Var(&tags, "tag", "category to tag the feature with, replacing its tags (repeatable)")
+	clearTags := fs.Bool("clear-tags", false, "remove every tag")
+	if err := parse(fs, args, 1, 1); err != nil {
+		return err
+	}
+	id, err := parseId(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if *name == "" && *description == "" && len(tags) == 0 && !*clearTags {
+		return errors.New("nothing to update; give -name, -description, -tag or -clear-tags")
+	}
+
+	db, err := e.open()
+	if err != nil {
+		return err
+	}
+
+	feature, err := db.GetById(id)
+	if err != nil {
+		return err
+	}
+
+	// changes are made as the owner, the only user allowed to make them
+	req := dto.UpdateFeature{Id: id, UserId: feature.UserId, Name: feature.Name, Description: feature.Description}
+	if *name != "" {
+		req.Name = *name
+	}
+	if *description != "" {
+		req.Description = *description
+	}
+	if *clearTags {
+		req.Tags = &[]string{}
+	}
+	if len(tags) > 0 {
+		req.Tags = (*[]string)(&tags)
+	}
+	if err := validator.New().Struct(&req); err != nil {
+		return err
+	}
+
+	dto.ApplyUpdate(feature, &req)
+	if err := checkTags(db, feature.Tags); err != nil {
+		return err
+	}
+	if err := db.Update(feature); err != nil {
+		return err
+	}
+
+	return e.print(feature, func(w io.Writer) { featureDetails(w, feature) })
+}
+
+func runDelete(e *env, args []string) error {
+	fs := e.flags("delete", "<featureId>")
+	if err := parse(fs, args, 1, 1); err != nil {
+		return err
+	}
+	id, err := parseId(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	db, err := e.open()
+	if err != nil {
+		return err
+	}
+
+	feature, err := db.GetById(id)
+	if err != nil {
+		return err
+	}
+	if err := db.Delete(feature.UserId, id); err != nil {
+		return err
+	}
+
+	return e.print(map[string]string{"deleted": id.String(), "userId": feature.UserId.String()}, func(w io.Writer) {
+		fmt.Fprintf(w, "moved %s to the trash of %s\n", id, feature.UserId)
+	})
+}
+
+func runStatus(e *env, args []string) error {
+	fs := e.flags("status", "<featureId> <open|planned|in-progress|done|declined>")
+	if err := parse(fs, args, 2, 2); err != nil {
+		return err
+	}
+	id, err := parseId(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	status := domain.FeatureStatus(fs.Arg(1))
+	if !status.Valid() {
+		return fmt.Errorf("unknown status %q", status)
+	}
+
+	db, err := e.open()
+	if err != nil {
+		return err
+	}
+
+	feature, err := db.SetStatus(id, status, actor)
+	if err != nil {
+		return err
+	}
+
+	return e.print(feature, func(w io.Writer) { featureDetails(w, feature) })
+}
+
+func runMerge(e *env, args []string) error {
+	fs := e.flags("merge", "<targetId> <duplicateId>")
+	if err := parse(fs, args, 2, 2); err != nil {
+		return err
+	}
+	target, err := parseId(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	duplicate, err := parseId(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	db, err := e.open()
+	if err != nil {
+		return err
+	}
+
+	feature, err := db.MergeFeatures(target, duplicate, actor)
+	if err != nil {
+		return err
+	}
+
+	return e.print(feature, func(w io.Writer) { featureDetails(w, feature) })
+}
+
+func runRecount(e *env, args []string) error {
+	fs := e.flags("recount", "[featureId...]")
+	if err := parse(fs, args, 0, -1); err != nil {
+		return err
+	}
+
+	ids := make([]uuid.UUID, 0, fs.NArg())
+	for _, arg := range fs.Args() {
+		id, err := parseId(arg)
+		if err != nil {
+			return err
+		}
+		ids = append(ids, id)
+	}
+
+	db, err := e.open()
+	if err != nil {
+		return err
+	}
+
+	// with no ids given every feature is recounted
+	if len(ids) == 0 {
+		err := db.EachFeature(context.Background(), database.ListOptions{}, false, func(f *domain.Feature, _ []*domain.Vote) error {
+			ids = append(ids, f.Id)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	changed := make([]*domain.Feature, 0)
+	for _, id := range ids {
+		feature, ok, err := db.RecountVotes(id, actor)
+		if err != nil {
+			return fmt.Errorf("%s >> %w", id, err)
+		}
+		if ok {
+			changed = append(changed, feature)
+		}
+	}
+
+	out := struct {
+		Recounted int               `json:"recounted"`
+		Changed   []*domain.Feature `json:"changed"`
+	}{len(ids), changed}
+
+	return e.print(out, func(w io.Writer) {
+		fmt.Fprintf(w, "recounted %d features, %d changed\n", len(ids), len(changed))
+		if len(changed) > 0 {
+			fmt.Fprintln(w)
+			featureTable(w, changed)
+		}
+	})
+}
+
+// checkTags makes sure every tag names a category, as the API does.
+func checkTags(db database.Store, tags []string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	missing, err := db.MissingCategories(tags)
+	if err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("unknown tags %s; tags must be existing categories", strings.Join(missing, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/music-tribe/react-pairing-challenge/importer"
+)
+
+func runImport(e *env, args []string) error {
+	fs := e.flags("import", "<file|->")
+	format := fs.String("format", "", "csv or jsonl; taken from the file extension when absent")
+	var mapping listFlag
+	fs.Var(&mapping, "map", "read a field from a differently named column, as field=column (repeatable)")
+	userId := fs.String("user", "", "owner of the rows that don't name one")
+	dryRun := fs.Bool("dry-run", false, "check the file without importing anything")
+	batch := fs.Int("batch", importer.DefaultBatchSize, "features written per transaction")
+	if err := parse(fs, args, 1, 1); err != nil {
+		return err
+	}
+
+	m, err := importer.ParseMapping(mapping)
+	if err != nil {
+		return err
+	}
+
+	path := fs.Arg(0)
+	if *format == "" {
+		*format = strings.TrimPrefix(filepath.Ext(path), ".")
+	}
+	f, err := importer.ParseFormat(*format)
+	if err != nil {
+		return err
+	}
+
+	var r io.Reader = e.stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		r = file
+	}
+
+	db, err := e.open()
+	if err != nil {
+		return err
+	}
+
+	report, err := importer.Import(db, r, importer.Options{
+		Format:    f,
+		Mapping:   m,
+		UserId:    *userId,
+		Actor:     actor,
+		DryRun:    *dryRun,
+		BatchSize: *batch,
+	})
+	if report != nil {
+		e.print(report, func(w io.Writer) { printReport(w, report) })
+	}
+	return err
+}
+
+func printReport(w io.Writer, r *importer.Report) {
+	verb := "imported"
+	if r.DryRun {
+		verb = "would import"
+	}
+	fmt.Fprintf(w, "%d rows: %s %d, %d duplicates, %d failed\n", r.Rows, verb, r.Imported, r.Duplicates, r.Failed)
+	if len(r.Errors) == 0 {
+		return
+	}
+
+	fmt.Fprintln(w, "\nROW\tRESULT\tERROR")
+	for _, e := range r.Errors {
+		kind := "failed"
+		if e.Duplicate {
+			kind = "skipped"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\n", e.Row, kind, e.Error)
+	}
+	if r.Truncated {
+		fmt.Fprintf(w, "(only the first %d rows are listed)\n", importer.MaxReportedErrors)
+	}
+}
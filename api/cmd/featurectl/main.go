@@ -0,0 +1,174 @@
+// Command featurectl operates the features service from the command line,
+// working on the database directly with the same configuration as the
+// server. Changes are audited and published like the API's, as made by
+// "featurectl"; replicas without the change-stream watcher may serve stale
+// cached features until their cache TTL runs out.
+//
+// Usage:
+//
+//	featurectl [-v] [-o table|json] <command> [flags] [args]
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/music-tribe/react-pairing-challenge/config"
+	"github.com/music-tribe/react-pairing-challenge/database"
+	"github.com/music-tribe/react-pairing-challenge/storage"
+)
+
+type command struct {
+	summary string
+	run     func(env *env, args []string) error
+}
+
+var commands = map[string]command{
+	"list":    {"list features, filtered like the search API", runList},
+	"show":    {"show a feature and its votes", runShow},
+	"create":  {"add a feature for a user", runCreate},
+	"update":  {"edit a feature's name, description or tags", runUpdate},
+	"delete":  {"move a feature to its owner's trash", runDelete},
+	"status":  {"move a feature to a new status", runStatus},
+	"merge":   {"fold a duplicate feature and its votes into another", runMerge},
+	"recount": {"repair vote totals and scores from the stored votes", runRecount},
+	"migrate": {"run, roll back or list schema migrations", runMigrate},
+	"seed":    {"add demo features and votes", runSeed},
+	"import":  {"add features in bulk from a CSV or JSON Lines file", runImport},
+	"export":  {"write features out as CSV or JSON Lines", runExport},
+	"backup":  {"snapshot features, votes and audit to a compressed file", runBackup},
+	"restore": {"check a backup and restore it, merging or replacing", runRestore},
+	"token":   {"issue the token a user votes with", runToken},
+}
+
+// actor is recorded as the author of every change featurectl makes.
+const actor = "featurectl"
+
+// env is what every command runs against.
+type env struct {
+	cfg    *config.Config
+	logger *logger
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	// json prints results as JSON rather than tables
+	json bool
+	db   database.Store
+}
+
+// open connects to the database, once.
+func (e *env) open() (database.Store, error) {
+	if e.db != nil {
+		return e.db, nil
+	}
+
+	db, err := storage.Open(e.cfg.DBURL, e.logger)
+	if err != nil {
+		return nil, err
+	}
+	e.db = db
+	return db, nil
+}
+
+// errUsage reports a command line that doesn't make sense; the command's
+// usage has already been printed.
+var errUsage = errors.New("usage")
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("featurectl", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	verbose := fs.Bool("v", false, "log what the database is doing")
+	output := fs.String("o", "table", "output format, table or json")
+	fs.Usage = func() { usage(stderr) }
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	if *output != "table" && *output != "json" {
+		fmt.Fprintf(stderr, "featurectl: unknown output format %q, want table or json\n", *output)
+		return 2
+	}
+
+	if fs.NArg() == 0 {
+		usage(stderr)
+		return 2
+	}
+	cmd, ok := commands[fs.Arg(0)]
+	if !ok {
+		fmt.Fprintf(stderr, "featurectl: unknown command %q\n", fs.Arg(0))
+		usage(stderr)
+		return 2
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(stderr, "featurectl: %v\n", err)
+		return 1
+	}
+
+	e := &env{
+		cfg:    cfg,
+		logger: &logger{w: stderr, verbose: *verbose},
+		stdin:  stdin,
+		stdout: stdout,
+		stderr: stderr,
+		json:   *output == "json",
+	}
+	defer func() {
+		if e.db != nil {
+			e.db.Close()
+		}
+	}()
+
+	if err := cmd.run(e, fs.Args()[1:]); err != nil {
+		if err == errUsage || err == flag.ErrHelp {
+			return 2
+		}
+		fmt.Fprintf(stderr, "featurectl %s: %v\n", fs.Arg(0), err)
+		return 1
+	}
+	return 0
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "usage: featurectl [-v] [-o table|json] <command> [flags] [args]")
+	fmt.Fprintln(w, "\nThe database is configured from the environment, as for the server.")
+	fmt.Fprintln(w, "\nCommands:")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-10s %s\n", name, commands[name].summary)
+	}
+	fmt.Fprintln(w, "\nRun 'featurectl <command> -h' for a command's flags.")
+}
+
+// logger keeps the database quiet unless asked to be verbose; commands
+// report the errors it would log themselves.
+type logger struct {
+	w       io.Writer
+	verbose bool
+}
+
+func (l *logger) Infof(format string, args ...interface{}) {
+	if l.verbose {
+		fmt.Fprintf(l.w, format+"\n", args...)
+	}
+}
+
+func (l *logger) Errorf(format string, args ...interface{}) {
+	if l.verbose {
+		fmt.Fprintf(l.w, format+"\n", args...)
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/music-tribe/react-pairing-challenge/database"
+	"github.com/music-tribe/react-pairing-challenge/migrate"
+)
+
+func runMigrate(e *env, args []string) error {
+	action := "up"
+	if len(args) > 0 && (args[0] == "up" || args[0] == "down" || args[0] == "status") {
+		action, args = args[0], args[1:]
+	}
+
+	fs := e.flags("migrate "+action, "")
+	to := fs.Int("to", 0, "version to stop at; up defaults to the latest, down must be given one, 0 rolling back everything")
+	dryRun := fs.Bool("dry-run", false, "list the migrations that would run without running them")
+	if err := parse(fs, args, 0, 0); err != nil {
+		return err
+	}
+	if action == "down" && !flagSet(fs, "to") {
+		return errors.New("give the version to roll back to with -to")
+	}
+
+	db, err := e.open()
+	if err != nil {
+		return err
+	}
+	migrator := newMigrator(db, e.logger)
+
+	if action == "status" {
+		statuses, err := migrator.Status(context.Background())
+		if err != nil {
+			return err
+		}
+		return e.print(statuses, func(w io.Writer) {
+			fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+			for _, s := range statuses {
+				applied := "pending"
+				if s.AppliedAt != nil {
+					applied = timestamp(s.AppliedAt)
+				}
+				if s.Unknown {
+					applied += " (unknown to this build)"
+				}
+				fmt.Fprintf(w, "%d\t%s\t%s\n", s.Version, s.Name, applied)
+			}
+		})
+	}
+
+	opts := migrate.Options{To: *to, DryRun: *dryRun}
+	var steps []migrate.Step
+	if action == "up" {
+		steps, err = migrator.Up(context.Background(), opts)
+	} else {
+		steps, err = migrator.Down(context.Background(), opts)
+	}
+	if err != nil {
+		return err
+	}
+
+	return e.print(steps, func(w io.Writer) {
+		switch {
+		case len(steps) == 0:
+			fmt.Fprintln(w, "nothing to migrate")
+		case *dryRun:
+			fmt.Fprintln(w, "would run:")
+		}
+		for _, s := range steps {
+			fmt.Fprintf(w, "%s\t%d\t%s\n", s.Direction, s.Version, s.Name)
+		}
+	})
+}
+
+func newMigrator(db database.Store, logger migrate.Logger) *migrate.Migrator {
+	return migrate.New(migrate.Config{Store: db, Migrations: db.Migrations(), Logger: logger})
+}
+
+// flagSet reports whether the flag name was given on the command line.
+func flagSet(fs *flag.FlagSet, name string) bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/uuid"
+)
+
+// print writes v as JSON, or as the table drawn by table.
+func (e *env) print(v interface{}, table func(w io.Writer)) error {
+	if e.json {
+		enc := json.NewEncoder(e.stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+
+	tw := tabwriter.NewWriter(e.stdout, 0, 4, 2, ' ', 0)
+	table(tw)
+	return tw.Flush()
+}
+
+// flags returns a flag set for a command, printing its usage line with the
+// defaults when asked for help.
+func (e *env) flags(name, args string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(e.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: featurectl %s [flags] %s\n", name, args)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parse parses args, checking the command got between min and max
+// arguments after its flags.
+func parse(fs *flag.FlagSet, args []string, min, max int) error {
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() < min || (max >= 0 && fs.NArg() > max) {
+		fs.Usage()
+		return errUsage
+	}
+	return nil
+}
+
+func parseId(s string) (uuid.UUID, error) {
+	id, err := uuid.Parse(s)
+	if err != nil {
+		return uuid.UUID{}, fmt.Errorf("%q is not a feature id", s)
+	}
+	return id, nil
+}
+
+// listFlag collects a flag given more than once.
+type listFlag []string
+
+func (l *listFlag) String() string     { return strings.Join(*l, ",") }
+func (l *listFlag) Set(v string) error { *l = append(*l, v); return nil }
+
+func featureTable(w io.Writer, features []*domain.Feature) {
+	fmt.Fprintln(w, "ID\tBOARD\tSTATUS\tVOTES\tSCORE\tNAME")
+	for _, f := range features {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%d\t%s\n", f.Id, f.Board, f.Status, f.VoteCount, f.Score, truncate(f.Name, 60))
+	}
+}
+
+func featureDetails(w io.Writer, f *domain.Feature) {
+	fmt.Fprintf(w, "ID\t%s\n", f.Id)
+	fmt.Fprintf(w, "Owner\t%s\n", f.UserId)
+	fmt.Fprintf(w, "Name\t%s\n", f.Name)
+	fmt.Fprintf(w, "Description\t%s\n", truncate(f.Description, 200))
+	fmt.Fprintf(w, "Board\t%s\n", f.Board)
+	fmt.Fprintf(w, "Tags\t%s\n", strings.Join(f.Tags, ", "))
+	fmt.Fprintf(w, "Status\t%s\n", f.Status)
+	fmt.Fprintf(w, "Votes\t%d (+%d -%d, score %d)\n", f.VoteCount, f.Upvotes, f.Downvotes, f.Score)
+	fmt.Fprintf(w, "Created\t%s\n", timestamp(&f.CreatedAt))
+	fmt.Fprintf(w, "Updated\t%s\n", timestamp(&f.UpdatedAt))
+	fmt.Fprintf(w, "Last voted\t%s\n", timestamp(f.LastVotedAt))
+	if f.DeletedAt != nil {
+		fmt.Fprintf(w, "Deleted\t%s\n", timestamp(f.DeletedAt))
+	}
+}
+
+func timestamp(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return "-"
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func truncate(s string, n int) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if r := []rune(s); len(r) > n {
+		return string(r[:n-1]) + "…"
+	}
+	return s
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/uuid"
+)
+
+// demoFeatures are the requests seed picks from.
+var demoFeatures = []struct{ name, description string }{
+	{"Dark mode", "A dark theme for working in dim studios."},
+	{"Offline sync", "Keep working without a network and sync when it comes back."},
+	{"Scene presets", "Save and recall whole mixer scenes."},
+	{"MIDI learn", "Map any control to a MIDI controller by moving it."},
+	{"Multitrack export", "Export every channel as its own file."},
+	{"Keyboard shortcuts", "Shortcuts for the common mixing actions."},
+	{"Shared sessions", "Let two people work on one session at once."},
+	{"Undo history", "Step back through every change made to a mix."},
+	{"Loudness meter", "Show integrated LUFS on the master bus."},
+	{"Plugin favourites", "Pin the plugins used most to the top of the list."},
+	{"Tablet layout", "A layout sized for tablets in landscape."},
+	{"Cloud backups", "Back sessions up automatically every night."},
+}
+
+func runSeed(e *env, args []string) error {
+	fs := e.flags("seed", "")
+	count := fs.Int("features", 12, "features to add")
+	voters := fs.Int("voters", 10, "demo users who vote")
+	board := fs.String("board", "demo", "board to put the features on")
+	seed := fs.Int64("seed", 1, "seed for the random votes, so runs can be repeated")
+	if err := parse(fs, args, 0, 0); err != nil {
+		return err
+	}
+	if *count < 1 || *voters < 0 {
+		return fmt.Errorf("-features must be at least 1 and -voters not negative")
+	}
+
+	db, err := e.open()
+	if err != nil {
+		return err
+	}
+
+	rnd := rand.New(rand.NewSource(*seed))
+	users := make([]uuid.UUID, *voters)
+	for i := range users {
+		users[i] = uuid.New()
+	}
+
+	features := make([]*domain.Feature, 0, *count)
+	for i := 0; i < *count; i++ {
+		demo := demoFeatures[i%len(demoFeatures)]
+		name := demo.name
+		if i >= len(demoFeatures) {
+			name = fmt.Sprintf("%s %d", demo.name, i/len(demoFeatures)+1)
+		}
+
+		owner := uuid.New()
+		if len(users) > 0 {
+			owner = users[rnd.Intn(len(users))]
+		}
+		feature := &domain.Feature{
+			Id:          uuid.New(),
+			UserId:      owner,
+			Name:        name,
+			Description: demo.description,
+			Board:       *board,
+		}
+		if err := db.Add(feature); err != nil {
+			return err
+		}
+
+		// popularity falls away down the list, like real boards
+		for _, voter := range users {
+			if voter == owner || rnd.Float64() > 1/float64(1+i/2) {
+				continue
+			}
+			vote := &domain.Vote{Id: uuid.New(), FeatureId: feature.Id, VoterId: voter, Direction: domain.Upvote, Weight: 1, Source: "seed"}
+			if feature, err = db.AddVote(vote, domain.VoteBudget{}); err != nil {
+				return err
+			}
+		}
+		features = append(features, feature)
+	}
+
+	return e.print(features, func(w io.Writer) { featureTable(w, features) })
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/music-tribe/react-pairing-challenge/auth"
+	"github.com/music-tribe/uuid"
+)
+
+func runToken(e *env, args []string) error {
+	fs := e.flags("token", "<userId>")
+	if err := parse(fs, args, 1, 1); err != nil {
+		return err
+	}
+	userId, err := uuid.Parse(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("%q is not a user id", fs.Arg(0))
+	}
+
+	users := auth.NewUserTokens(e.cfg.UserTokenSecret)
+	if !users.Enabled() {
+		return errors.New("USER_TOKEN_SECRET isn't set, so the API takes user ids as given")
+	}
+
+	out := struct {
+		UserId uuid.UUID `json:"userId"`
+		Token  string    `json:"token"`
+	}{userId, users.Token(userId)}
+
+	return e.print(out, func(w io.Writer) {
+		fmt.Fprintln(w, out.Token)
+	})
+}
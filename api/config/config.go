@@ -0,0 +1,373 @@
+// Package config loads the service's settings from the environment.
+package config
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Config struct {
+	// DBURL is the connection string of the backing database; its scheme,
+	// mongodb://, postgres:// or sqlite://, picks the storage backend.
+	DBURL string
+	// AdminAPIKey is the bearer token the admin API expects. The admin API
+	// refuses every request while it is empty.
+	AdminAPIKey string
+	// UserTokenSecret signs the tokens users prove their id with when they
+	// vote. While it is empty user ids are taken as given.
+	UserTokenSecret string
+	// AllowOrigins lists the origins browsers may call the API from, over
+	// REST and WebSocket alike. Each is an exact origin, such as
+	// https://features.example.com, or has * wildcards; "*" allows any.
+	AllowOrigins []string
+	// Voting configures how many votes users get and what they are worth.
+	Voting Voting
+	// Trash configures how long deleted features are kept.
+	Trash Trash
+	// Watch configures the change-stream watcher.
+	Watch Watch
+	// Cache configures the in-memory feature cache.
+	Cache Cache
+	// RateLimit configures how fast clients may write and vote.
+	RateLimit RateLimit
+	// IdempotencyTTL is how long responses to requests made with an
+	// Idempotency-Key are kept for replay.
+	IdempotencyTTL time.Duration
+	// MigrateOnStart runs pending schema migrations before serving. When
+	// it is off they are left to featurectl migrate.
+	MigrateOnStart bool
+}
+
+type Voting struct {
+	// Budget is how many points each user may have spent on one board at a
+	// time. Zero means unlimited.
+	Budget int
+	// MaxWeight is the most points a single vote may carry.
+	MaxWeight int
+	// Quadratic makes a vote of weight w cost w*w points of the budget.
+	Quadratic bool
+	// DownvoteBoards lists the boards that accept downvotes, "*" for all.
+	DownvoteBoards []string
+}
+
+type Trash struct {
+	// Retention is how long a deleted feature stays restorable before it
+	// is purged for good.
+	Retention time.Duration
+	// PurgeInterval is how often the purger looks for expired features.
+	PurgeInterval time.Duration
+}
+
+type Watch struct {
+	// Enabled feeds live subscribers from Mongo change streams, so they see
+	// writes made by every replica rather than only this one.
+	Enabled bool
+	// Consumer names this replica's resume token. Each replica needs its
+	// own; it defaults to the host name.
+	Consumer string
+}
+
+type Cache struct {
+	// Enabled serves feature reads from memory where possible.
+	Enabled bool
+	// Size is the most features held at once.
+	Size int
+	// TTL is how long a feature may be served from memory.
+	TTL time.Duration
+}
+
+type RateLimit struct {
+	// Enabled turns rate limiting on.
+	Enabled bool
+	// WritesPerUser and WritesPerIP limit creating, editing and deleting
+	// features.
+	WritesPerUser Rate
+	WritesPerIP   Rate
+	// VotesPerUser and VotesPerIP limit casting and removing votes, over
+	// REST and the WebSocket alike.
+	VotesPerUser Rate
+	VotesPerIP   Rate
+	// AdminPerKey limits the admin API per bearer token.
+	AdminPerKey Rate
+	// TrustedProxies are the addresses of the proxies in front of the
+	// server, whose X-Forwarded-For header is believed. With none, the
+	// header is ignored and clients are limited by their own address.
+	TrustedProxies []*net.IPNet
+}
+
+// Rate allows Requests per Period. A zero Rate is unlimited.
+type Rate struct {
+	Requests int
+	Period   time.Duration
+}
+
+// Load reads the configuration from environment variables, applying
+// defaults for anything unset.
+func Load() (*Config, error) {
+	cfg := &Config{
+		DBURL:           os.Getenv("DB_URL"),
+		AdminAPIKey:     os.Getenv("ADMIN_API_KEY"),
+		UserTokenSecret: os.Getenv("USER_TOKEN_SECRET"),
+		Voting: Voting{
+			MaxWeight: 1,
+		},
+		Trash: Trash{
+			Retention:     30 * 24 * time.Hour,
+			PurgeInterval: time.Hour,
+		},
+		Cache: Cache{
+			Size: 10000,
+			TTL:  30 * time.Second,
+		},
+		RateLimit: RateLimit{
+			Enabled:       true,
+			WritesPerUser: Rate{Requests: 30, Period: time.Minute},
+			WritesPerIP:   Rate{Requests: 120, Period: time.Minute},
+			VotesPerUser:  Rate{Requests: 60, Period: time.Minute},
+			VotesPerIP:    Rate{Requests: 300, Period: time.Minute},
+			AdminPerKey:   Rate{Requests: 600, Period: time.Minute},
+		},
+		IdempotencyTTL: 24 * time.Hour,
+		MigrateOnStart: true,
+	}
+
+	var err error
+	if cfg.Voting.Budget, err = intEnv("VOTE_BUDGET", 0); err != nil {
+		return nil, err
+	}
+	if cfg.Voting.MaxWeight, err = intEnv("VOTE_MAX_WEIGHT", 1); err != nil {
+		return nil, err
+	}
+	if cfg.Voting.Quadratic, err = boolEnv("VOTE_QUADRATIC", false); err != nil {
+		return nil, err
+	}
+
+	if cfg.Trash.Retention, err = durationEnv("TRASH_RETENTION", cfg.Trash.Retention); err != nil {
+		return nil, err
+	}
+	if cfg.Trash.PurgeInterval, err = durationEnv("TRASH_PURGE_INTERVAL", cfg.Trash.PurgeInterval); err != nil {
+		return nil, err
+	}
+
+	cfg.Voting.DownvoteBoards = listEnv("DOWNVOTE_BOARDS")
+
+	if cfg.AllowOrigins = listEnv("CORS_ALLOW_ORIGINS"); len(cfg.AllowOrigins) == 0 {
+		cfg.AllowOrigins = []string{"*"}
+	}
+
+	if cfg.Watch.Enabled, err = boolEnv("WATCH_CHANGES", false); err != nil {
+		return nil, err
+	}
+	if cfg.Cache.Enabled, err = boolEnv("CACHE_ENABLED", false); err != nil {
+		return nil, err
+	}
+	if cfg.Cache.Size, err = intEnv("CACHE_SIZE", cfg.Cache.Size); err != nil {
+		return nil, err
+	}
+	if cfg.Cache.TTL, err = durationEnv("CACHE_TTL", cfg.Cache.TTL); err != nil {
+		return nil, err
+	}
+
+	if cfg.RateLimit.Enabled, err = boolEnv("RATE_LIMIT_ENABLED", cfg.RateLimit.Enabled); err != nil {
+		return nil, err
+	}
+	if cfg.RateLimit.WritesPerUser, err = rateEnv("RATE_LIMIT_WRITES_USER", cfg.RateLimit.WritesPerUser); err != nil {
+		return nil, err
+	}
+	if cfg.RateLimit.WritesPerIP, err = rateEnv("RATE_LIMIT_WRITES_IP", cfg.RateLimit.WritesPerIP); err != nil {
+		return nil, err
+	}
+	if cfg.RateLimit.VotesPerUser, err = rateEnv("RATE_LIMIT_VOTES_USER", cfg.RateLimit.VotesPerUser); err != nil {
+		return nil, err
+	}
+	if cfg.RateLimit.VotesPerIP, err = rateEnv("RATE_LIMIT_VOTES_IP", cfg.RateLimit.VotesPerIP); err != nil {
+		return nil, err
+	}
+	if cfg.RateLimit.AdminPerKey, err = rateEnv("RATE_LIMIT_ADMIN_KEY", cfg.RateLimit.AdminPerKey); err != nil {
+		return nil, err
+	}
+	if cfg.RateLimit.TrustedProxies, err = networksEnv("TRUSTED_PROXIES"); err != nil {
+		return nil, err
+	}
+	if cfg.IdempotencyTTL, err = durationEnv("IDEMPOTENCY_TTL", cfg.IdempotencyTTL); err != nil {
+		return nil, err
+	}
+
+	if cfg.MigrateOnStart, err = boolEnv("MIGRATE_ON_START", cfg.MigrateOnStart); err != nil {
+		return nil, err
+	}
+
+	if cfg.Watch.Consumer = os.Getenv("WATCH_CONSUMER"); cfg.Watch.Consumer == "" {
+		if cfg.Watch.Consumer, err = os.Hostname(); err != nil {
+			return nil, fmt.Errorf("config.Load: WATCH_CONSUMER >> %w", err)
+		}
+	}
+
+	if cfg.Voting.Budget < 0 {
+		return nil, fmt.Errorf("config.Load: VOTE_BUDGET must not be negative, got %d", cfg.Voting.Budget)
+	}
+	if cfg.Voting.MaxWeight < 1 {
+		return nil, fmt.Errorf("config.Load: VOTE_MAX_WEIGHT must be at least 1, got %d", cfg.Voting.MaxWeight)
+	}
+
+	if cfg.Trash.Retention < 0 {
+		return nil, fmt.Errorf("config.Load: TRASH_RETENTION must not be negative, got %s", cfg.Trash.Retention)
+	}
+	if cfg.Trash.PurgeInterval <= 0 {
+		return nil, fmt.Errorf("config.Load: TRASH_PURGE_INTERVAL must be positive, got %s", cfg.Trash.PurgeInterval)
+	}
+
+	if cfg.Cache.Size < 1 {
+		return nil, fmt.Errorf("config.Load: CACHE_SIZE must be at least 1, got %d", cfg.Cache.Size)
+	}
+	if cfg.Cache.TTL <= 0 {
+		return nil, fmt.Errorf("config.Load: CACHE_TTL must be positive, got %s", cfg.Cache.TTL)
+	}
+
+	if cfg.IdempotencyTTL <= 0 {
+		return nil, fmt.Errorf("config.Load: IDEMPOTENCY_TTL must be positive, got %s", cfg.IdempotencyTTL)
+	}
+
+	return cfg, nil
+}
+
+// OriginAllowed reports whether a browser on origin may call the API.
+func (c *Config) OriginAllowed(origin string) bool {
+	for _, pattern := range c.AllowOrigins {
+		if matchOrigin(pattern, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchOrigin matches origin against pattern, where each * stands for any
+// run of characters.
+func matchOrigin(pattern, origin string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == origin
+	}
+
+	if !strings.HasPrefix(origin, parts[0]) {
+		return false
+	}
+	rest := origin[len(parts[0]):]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(rest, part)
+		if i < 0 {
+			return false
+		}
+		rest = rest[i+len(part):]
+	}
+	return strings.HasSuffix(rest, parts[len(parts)-1])
+}
+
+func intEnv(key string, def int) (int, error) {
+	v, ok := os.LookupEnv(key)
+	if !ok || v == "" {
+		return def, nil
+	}
+
+	i, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("config.Load: %s >> %w", key, err)
+	}
+	return i, nil
+}
+
+func boolEnv(key string, def bool) (bool, error) {
+	v, ok := os.LookupEnv(key)
+	if !ok || v == "" {
+		return def, nil
+	}
+
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("config.Load: %s >> %w", key, err)
+	}
+	return b, nil
+}
+
+func durationEnv(key string, def time.Duration) (time.Duration, error) {
+	v, ok := os.LookupEnv(key)
+	if !ok || v == "" {
+		return def, nil
+	}
+
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("config.Load: %s >> %w", key, err)
+	}
+	return d, nil
+}
+
+// rateEnv parses a rate written as "requests/period", e.g. "30/1m". "off"
+// or "0" leave the rate unlimited.
+func rateEnv(key string, def Rate) (Rate, error) {
+	v, ok := os.LookupEnv(key)
+	if !ok || v == "" {
+		return def, nil
+	}
+	if v == "off" || v == "0" {
+		return Rate{}, nil
+	}
+
+	n, p, found := strings.Cut(v, "/")
+	if !found {
+		return Rate{}, fmt.Errorf("config.Load: %s must look like 30/1m, got %q", key, v)
+	}
+	requests, err := strconv.Atoi(n)
+	if err != nil {
+		return Rate{}, fmt.Errorf("config.Load: %s >> %w", key, err)
+	}
+	period, err := time.ParseDuration(p)
+	if err != nil {
+		return Rate{}, fmt.Errorf("config.Load: %s >> %w", key, err)
+	}
+	if requests < 1 || period <= 0 {
+		return Rate{}, fmt.Errorf("config.Load: %s must allow at least 1 request over a positive period, got %q", key, v)
+	}
+	return Rate{Requests: requests, Period: period}, nil
+}
+
+// networksEnv parses a comma separated list of CIDR ranges, where a bare
+// address stands for itself alone.
+func networksEnv(key string) ([]*net.IPNet, error) {
+	var networks []*net.IPNet
+	for _, v := range listEnv(key) {
+		if !strings.Contains(v, "/") {
+			ip := net.ParseIP(v)
+			if ip == nil {
+				return nil, fmt.Errorf("config.Load: %s has %q, which is neither an address nor a CIDR range", key, v)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			networks = append(networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(v)
+		if err != nil {
+			return nil, fmt.Errorf("config.Load: %s >> %w", key, err)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+func listEnv(key string) []string {
+	var list []string
+	for _, v := range strings.Split(os.Getenv(key), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
+	}
+	return list
+}
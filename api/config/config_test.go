@@ -0,0 +1,214 @@
+package config
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad(t *testing.T) {
+	t.Run("when nothing is set, we should get the defaults", func(t *testing.T) {
+		t.Setenv("DB_URL", "mongodb://localhost:27017")
+		t.Setenv("ADMIN_API_KEY", "")
+		t.Setenv("USER_TOKEN_SECRET", "")
+		t.Setenv("CORS_ALLOW_ORIGINS", "")
+		t.Setenv("VOTE_BUDGET", "")
+		t.Setenv("VOTE_MAX_WEIGHT", "")
+		t.Setenv("VOTE_QUADRATIC", "")
+		t.Setenv("DOWNVOTE_BOARDS", "")
+		t.Setenv("TRASH_RETENTION", "")
+		t.Setenv("TRASH_PURGE_INTERVAL", "")
+		t.Setenv("WATCH_CHANGES", "")
+		t.Setenv("WATCH_CONSUMER", "")
+		t.Setenv("CACHE_ENABLED", "")
+		t.Setenv("CACHE_SIZE", "")
+		t.Setenv("CACHE_TTL", "")
+		t.Setenv("RATE_LIMIT_ENABLED", "")
+		t.Setenv("RATE_LIMIT_WRITES_USER", "")
+		t.Setenv("RATE_LIMIT_WRITES_IP", "")
+		t.Setenv("RATE_LIMIT_VOTES_USER", "")
+		t.Setenv("RATE_LIMIT_VOTES_IP", "")
+		t.Setenv("RATE_LIMIT_ADMIN_KEY", "")
+		t.Setenv("TRUSTED_PROXIES", "")
+		t.Setenv("IDEMPOTENCY_TTL", "")
+		t.Setenv("MIGRATE_ON_START", "")
+
+		host, err := os.Hostname()
+		assert.NoError(t, err)
+
+		cfg, err := Load()
+		assert.NoError(t, err)
+		assert.Equal(t, &Config{
+			DBURL:        "mongodb://localhost:27017",
+			AllowOrigins: []string{"*"},
+			Voting:       Voting{MaxWeight: 1},
+			Trash:        Trash{Retention: 30 * 24 * time.Hour, PurgeInterval: time.Hour},
+			Watch:        Watch{Consumer: host},
+			Cache:        Cache{Size: 10000, TTL: 30 * time.Second},
+			RateLimit: RateLimit{
+				Enabled:       true,
+				WritesPerUser: Rate{Requests: 30, Period: time.Minute},
+				WritesPerIP:   Rate{Requests: 120, Period: time.Minute},
+				VotesPerUser:  Rate{Requests: 60, Period: time.Minute},
+				VotesPerIP:    Rate{Requests: 300, Period: time.Minute},
+				AdminPerKey:   Rate{Requests: 600, Period: time.Minute},
+			},
+			IdempotencyTTL: 24 * time.Hour,
+			MigrateOnStart: true,
+		}, cfg)
+	})
+
+	t.Run("when the admin key is set, it should be loaded", func(t *testing.T) {
+		t.Setenv("ADMIN_API_KEY", "s3cret")
+
+		cfg, err := Load()
+		assert.NoError(t, err)
+		assert.Equal(t, "s3cret", cfg.AdminAPIKey)
+	})
+
+	t.Run("when the user token secret is set, it should be loaded", func(t *testing.T) {
+		t.Setenv("USER_TOKEN_SECRET", "signing")
+
+		cfg, err := Load()
+		assert.NoError(t, err)
+		assert.Equal(t, "signing", cfg.UserTokenSecret)
+	})
+
+	t.Run("when the allowed origins are set, only those origins should be allowed", func(t *testing.T) {
+		t.Setenv("CORS_ALLOW_ORIGINS", "https://features.example.com, https://*.example.org")
+
+		cfg, err := Load()
+		assert.NoError(t, err)
+		assert.True(t, cfg.OriginAllowed("https://features.example.com"))
+		assert.True(t, cfg.OriginAllowed("https://beta.example.org"))
+		assert.False(t, cfg.OriginAllowed("https://example.org"))
+		assert.False(t, cfg.OriginAllowed("https://features.example.com.evil.net"))
+		assert.False(t, cfg.OriginAllowed("http://features.example.com"))
+
+		t.Setenv("CORS_ALLOW_ORIGINS", "")
+		cfg, err = Load()
+		assert.NoError(t, err)
+		assert.True(t, cfg.OriginAllowed("https://anywhere.net"))
+	})
+
+	t.Run("when the voting policy is set, it should be loaded", func(t *testing.T) {
+		t.Setenv("VOTE_BUDGET", "10")
+		t.Setenv("VOTE_MAX_WEIGHT", "3")
+		t.Setenv("VOTE_QUADRATIC", "true")
+		t.Setenv("DOWNVOTE_BOARDS", "mixer, cloud,")
+
+		cfg, err := Load()
+		assert.NoError(t, err)
+		assert.Equal(t, Voting{Budget: 10, MaxWeight: 3, Quadratic: true, DownvoteBoards: []string{"mixer", "cloud"}}, cfg.Voting)
+	})
+
+	t.Run("when a value can't be parsed, we should get an error", func(t *testing.T) {
+		t.Setenv("VOTE_BUDGET", "lots")
+
+		_, err := Load()
+		assert.ErrorContains(t, err, "VOTE_BUDGET")
+	})
+
+	t.Run("when the max weight is below one, we should get an error", func(t *testing.T) {
+		t.Setenv("VOTE_BUDGET", "")
+		t.Setenv("VOTE_MAX_WEIGHT", "0")
+
+		_, err := Load()
+		assert.ErrorContains(t, err, "VOTE_MAX_WEIGHT must be at least 1")
+	})
+
+	t.Run("when migrations are left to the CLI, it should be loaded", func(t *testing.T) {
+		t.Setenv("MIGRATE_ON_START", "false")
+
+		cfg, err := Load()
+		assert.NoError(t, err)
+		assert.False(t, cfg.MigrateOnStart)
+	})
+
+	t.Run("when the trash retention is set, it should be loaded", func(t *testing.T) {
+		t.Setenv("TRASH_RETENTION", "168h")
+		t.Setenv("TRASH_PURGE_INTERVAL", "15m")
+
+		cfg, err := Load()
+		assert.NoError(t, err)
+		assert.Equal(t, Trash{Retention: 168 * time.Hour, PurgeInterval: 15 * time.Minute}, cfg.Trash)
+	})
+
+	t.Run("when the purge interval isn't positive, we should get an error", func(t *testing.T) {
+		t.Setenv("TRASH_PURGE_INTERVAL", "0s")
+
+		_, err := Load()
+		assert.ErrorContains(t, err, "TRASH_PURGE_INTERVAL must be positive")
+	})
+
+	t.Run("when the watcher is enabled, it should be loaded with its consumer name", func(t *testing.T) {
+		t.Setenv("WATCH_CHANGES", "true")
+		t.Setenv("WATCH_CONSUMER", "api-1")
+
+		cfg, err := Load()
+		assert.NoError(t, err)
+		assert.Equal(t, Watch{Enabled: true, Consumer: "api-1"}, cfg.Watch)
+	})
+
+	t.Run("when the cache is configured, it should be loaded", func(t *testing.T) {
+		t.Setenv("CACHE_ENABLED", "true")
+		t.Setenv("CACHE_SIZE", "500")
+		t.Setenv("CACHE_TTL", "5s")
+
+		cfg, err := Load()
+		assert.NoError(t, err)
+		assert.Equal(t, Cache{Enabled: true, Size: 500, TTL: 5 * time.Second}, cfg.Cache)
+	})
+
+	t.Run("when the cache size is below one, we should get an error", func(t *testing.T) {
+		t.Setenv("CACHE_SIZE", "0")
+
+		_, err := Load()
+		assert.ErrorContains(t, err, "CACHE_SIZE must be at least 1")
+	})
+
+	t.Run("when the rate limits are set, they should be loaded", func(t *testing.T) {
+		t.Setenv("RATE_LIMIT_ENABLED", "false")
+		t.Setenv("RATE_LIMIT_WRITES_USER", "5/10s")
+		t.Setenv("RATE_LIMIT_VOTES_IP", "off")
+
+		cfg, err := Load()
+		assert.NoError(t, err)
+		assert.False(t, cfg.RateLimit.Enabled)
+		assert.Equal(t, Rate{Requests: 5, Period: 10 * time.Second}, cfg.RateLimit.WritesPerUser)
+		assert.Equal(t, Rate{}, cfg.RateLimit.VotesPerIP)
+	})
+
+	t.Run("when a rate can't be parsed, we should get an error", func(t *testing.T) {
+		for _, v := range []string{"30", "x/1m", "30/x", "-1/1m", "5/0s"} {
+			t.Setenv("RATE_LIMIT_WRITES_IP", v)
+
+			_, err := Load()
+			assert.ErrorContains(t, err, "RATE_LIMIT_WRITES_IP", v)
+		}
+	})
+
+	t.Run("when trusted proxies are set, they should be loaded as ranges", func(t *testing.T) {
+		t.Setenv("TRUSTED_PROXIES", "10.0.0.0/8, 192.0.2.7")
+
+		cfg, err := Load()
+		assert.NoError(t, err)
+		if assert.Len(t, cfg.RateLimit.TrustedProxies, 2) {
+			assert.Equal(t, "10.0.0.0/8", cfg.RateLimit.TrustedProxies[0].String())
+			assert.Equal(t, "192.0.2.7/32", cfg.RateLimit.TrustedProxies[1].String())
+		}
+
+		t.Setenv("TRUSTED_PROXIES", "proxy.internal")
+		_, err = Load()
+		assert.ErrorContains(t, err, "TRUSTED_PROXIES")
+	})
+
+	t.Run("when the idempotency TTL isn't positive, we should get an error", func(t *testing.T) {
+		t.Setenv("IDEMPOTENCY_TTL", "0s")
+
+		_, err := Load()
+		assert.ErrorContains(t, err, "IDEMPOTENCY_TTL must be positive")
+	})
+}
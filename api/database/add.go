@@ -1,10 +1,10 @@
 package database
 
 import (
-	"context"
 	"errors"
 
 	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/react-pairing-challenge/ranking"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 )
@@ -12,24 +12,39 @@ import (
 func (mdb *MongoDatabase) Add(feature *domain.Feature) error {
 	coll := mdb.client.Database("pair-challenge").Collection("features")
 
+	now := mdb.now()
+	feature.CreatedAt = now
+	feature.UpdatedAt = now
+	feature.VoteCount = 0
+	feature.Upvotes = 0
+	feature.Downvotes = 0
+	feature.Score = 0
+	feature.LastVotedAt = nil
+	feature.DeletedAt = nil
+	feature.Status = domain.StatusOpen
+
+	scores := ranking.Initial(now)
+	feature.HotScore = scores.Hot
+	feature.RisingScore = scores.Rising
+
 	b, err := bson.Marshal(feature)
 	if err != nil {
 		mdb.logger.Errorf("database.Add: bson.Marshal >> %v", err)
 		return err
 	}
 
-	_, err = coll.InsertOne(context.Background(), b)
-	if err != nil {
-		mdb.logger.Errorf("database.Add: mongo.InsertOne >> %v", err)
-		wrEx := mongo.WriteException{}
-		if errors.As(err, &wrEx) {
-			if wrEx.HasErrorCode(11000) {
-				return ErrDuplicate
+	return mdb.transact(func(ctx mongo.SessionContext) error {
+		if _, err := coll.InsertOne(ctx, b); err != nil {
+			mdb.logger.Errorf("database.Add: mongo.InsertOne >> %v", err)
+			wrEx := mongo.WriteException{}
+			if errors.As(err, &wrEx) {
+				if wrEx.HasErrorCode(11000) {
+					return ErrDuplicate
+				}
 			}
+			return err
 		}
 
-		return err
-	}
-
-	return nil
+		return mdb.record(ctx, feature.UserId.String(), domain.AuditCreated, feature.Id, nil, feature)
+	})
 }
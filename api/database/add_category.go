@@ -0,0 +1,30 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func (mdb *MongoDatabase) AddCategory(category *domain.Category) error {
+	coll := mdb.client.Database("pair-challenge").Collection("categories")
+
+	now := mdb.now()
+	category.CreatedAt = now
+	category.UpdatedAt = now
+
+	if _, err := coll.InsertOne(context.Background(), category); err != nil {
+		mdb.logger.Errorf("database.AddCategory: mongo.InsertOne >> %v", err)
+		wrEx := mongo.WriteException{}
+		if errors.As(err, &wrEx) {
+			if wrEx.HasErrorCode(11000) {
+				return ErrDuplicate
+			}
+		}
+		return err
+	}
+
+	return nil
+}
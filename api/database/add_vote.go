@@ -0,0 +1,104 @@
+package database
+
+import (
+	"errors"
+
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/react-pairing-challenge/ranking"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AddVote stores vote, stamping its CastAt and Board, and folds it into the
+// feature's totals, scores and LastVotedAt. ErrDuplicate is returned when the
+// voter has already voted for the feature, and ErrBudgetExhausted when the
+// vote would take them past budget on the feature's board.
+func (mdb *MongoDatabase) AddVote(vote *domain.Vote, budget domain.VoteBudget) (*domain.Feature, error) {
+	db := mdb.client.Database("pair-challenge")
+
+	t := new(domain.Feature)
+	err := mdb.transact(func(ctx mongo.SessionContext) error {
+		feature, err := mdb.findFeature(ctx, live(bson.M{"_id": vote.FeatureId}))
+		if err != nil {
+			return err
+		}
+
+		vote.Board = feature.Board
+		vote.CastAt = mdb.now()
+		if err := mdb.checkBudget(ctx, vote, budget); err != nil {
+			return err
+		}
+		if _, err := db.Collection("votes").InsertOne(ctx, vote); err != nil {
+			mdb.logger.Errorf("database.AddVote: mongo.InsertOne >> %v", err)
+			wrEx := mongo.WriteException{}
+			if errors.As(err, &wrEx) {
+				if wrEx.HasErrorCode(11000) {
+					return ErrDuplicate
+				}
+			}
+			return err
+		}
+
+		// a pipeline update lets mongo fold the vote into the totals and scores
+		// atomically, so concurrent votes can't overwrite each other
+		set := bson.M{
+			"voteCount":   incExpr("voteCount", 1),
+			"score":       incExpr("score", vote.Direction.Sign()*ranking.Weight(vote.Weight)),
+			"lastVotedAt": vote.CastAt,
+		}
+		if vote.Direction == domain.Downvote {
+			set["downvotes"] = incExpr("downvotes", 1)
+		} else {
+			set["upvotes"] = incExpr("upvotes", 1)
+			set["hotScore"] = logAddExpExpr("hotScore", ranking.Exponent(vote.CastAt, vote.Weight, ranking.HotHalfLife))
+			set["risingScore"] = logAddExpExpr("risingScore", ranking.Exponent(vote.CastAt, vote.Weight, ranking.RisingHalfLife))
+		}
+		update := bson.A{bson.M{"$set": set}}
+		opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+		q := db.Collection("features").FindOneAndUpdate(ctx, bson.M{"_id": vote.FeatureId}, update, opts)
+		if err := q.Decode(t); err != nil {
+			mdb.logger.Errorf("database.AddVote: mongo.Decode >> %v", err)
+			if err == mongo.ErrNoDocuments {
+				return ErrNotFound
+			}
+			return err
+		}
+
+		return mdb.record(ctx, vote.VoterId.String(), domain.AuditVoted, vote.FeatureId, feature, t)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// checkBudget returns ErrBudgetExhausted when vote would take its voter past
+// budget on its board. It first writes the voter's lock document for the
+// board, so two votes checked at once conflict and one of them is retried,
+// seeing the other, rather than both passing.
+func (mdb *MongoDatabase) checkBudget(ctx mongo.SessionContext, vote *domain.Vote, budget domain.VoteBudget) error {
+	if budget.Unlimited() {
+		return nil
+	}
+	db := mdb.client.Database("pair-challenge")
+
+	lock := bson.D{{Key: "voterId", Value: vote.VoterId}, {Key: "board", Value: vote.Board}}
+	opts := options.Update().SetUpsert(true)
+	if _, err := db.Collection("voteLocks").UpdateOne(ctx, bson.M{"_id": lock}, bson.M{"$set": bson.M{"lockedAt": vote.CastAt}}, opts); err != nil {
+		mdb.logger.Errorf("database.AddVote: mongo.UpdateOne >> %v", err)
+		return err
+	}
+
+	votes, err := mdb.liveVotes(ctx, "AddVote", bson.M{"voterId": vote.VoterId, "board": vote.Board})
+	if err != nil {
+		return err
+	}
+
+	if budget.Spent(votes, vote.Board)+budget.Cost(vote.Weight) > budget.Points {
+		return ErrBudgetExhausted
+	}
+	return nil
+}
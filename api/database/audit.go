@@ -0,0 +1,87 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AuditFilter narrows an audit query. Zero fields match everything.
+type AuditFilter struct {
+	FeatureId uuid.UUID
+	Actor     string
+	Action    domain.AuditAction
+	Since     time.Time
+	Until     time.Time
+	// Limit caps the number of events returned, newest first.
+	Limit int64
+}
+
+// GetHistory returns every audit event for a feature, oldest first.
+func (mdb *MongoDatabase) GetHistory(featureId uuid.UUID) ([]*domain.AuditEvent, error) {
+	coll := mdb.client.Database("pair-challenge").Collection("audit")
+
+	opts := options.Find().SetSort(bson.D{{Key: "at", Value: 1}})
+	q, err := coll.Find(context.Background(), bson.M{"featureId": featureId}, opts)
+	if err != nil {
+		mdb.logger.Errorf("database.GetHistory: mongo.Find >> %v", err)
+		return nil, err
+	}
+
+	es := make([]*domain.AuditEvent, 0)
+	if err = q.All(context.Background(), &es); err != nil {
+		mdb.logger.Errorf("database.GetHistory: mongo.All >> %v", err)
+		return nil, err
+	}
+
+	return es, nil
+}
+
+// QueryAudit returns the audit events matching filter, newest first.
+func (mdb *MongoDatabase) QueryAudit(filter AuditFilter) ([]*domain.AuditEvent, error) {
+	coll := mdb.client.Database("pair-challenge").Collection("audit")
+
+	q := bson.M{}
+	if filter.FeatureId != uuid.Nil {
+		q["featureId"] = filter.FeatureId
+	}
+	if filter.Actor != "" {
+		q["actor"] = filter.Actor
+	}
+	if filter.Action != "" {
+		q["action"] = filter.Action
+	}
+	at := bson.M{}
+	if !filter.Since.IsZero() {
+		at["$gte"] = filter.Since
+	}
+	if !filter.Until.IsZero() {
+		at["$lt"] = filter.Until
+	}
+	if len(at) > 0 {
+		q["at"] = at
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "at", Value: -1}})
+	if filter.Limit > 0 {
+		opts.SetLimit(filter.Limit)
+	}
+
+	cur, err := coll.Find(context.Background(), q, opts)
+	if err != nil {
+		mdb.logger.Errorf("database.QueryAudit: mongo.Find >> %v", err)
+		return nil, err
+	}
+
+	es := make([]*domain.AuditEvent, 0)
+	if err = cur.All(context.Background(), &es); err != nil {
+		mdb.logger.Errorf("database.QueryAudit: mongo.All >> %v", err)
+		return nil, err
+	}
+
+	return es, nil
+}
@@ -0,0 +1,117 @@
+package database
+
+import (
+	"context"
+
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// BackfillTimestamps stamps CreatedAt and UpdatedAt on features stored before
+// the fields existed. It is safe to run on every start up.
+func (mdb *MongoDatabase) BackfillTimestamps() error {
+	coll := mdb.client.Database("pair-challenge").Collection("features")
+
+	now := mdb.now()
+	res, err := coll.UpdateMany(context.Background(),
+		bson.M{"createdAt": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"createdAt": now, "updatedAt": now}},
+	)
+	if err != nil {
+		mdb.logger.Errorf("database.BackfillTimestamps: mongo.UpdateMany >> %v", err)
+		return err
+	}
+
+	if res.ModifiedCount > 0 {
+		mdb.logger.Infof("database.BackfillTimestamps: backfilled %d features", res.ModifiedCount)
+	}
+
+	return nil
+}
+
+// BackfillScores calculates the vote totals and ranking scores of features
+// stored before those fields existed, from their recorded votes.
+func (mdb *MongoDatabase) BackfillScores() error {
+	coll := mdb.client.Database("pair-challenge").Collection("features")
+	ctx := context.Background()
+
+	filter := bson.M{"$or": bson.A{
+		bson.M{"hotScore": bson.M{"$exists": false}},
+		bson.M{"score": bson.M{"$exists": false}},
+	}}
+	opts := options.Find().SetProjection(bson.M{"_id": 1})
+	cur, err := coll.Find(ctx, filter, opts)
+	if err != nil {
+		mdb.logger.Errorf("database.BackfillScores: mongo.Find >> %v", err)
+		return err
+	}
+	defer cur.Close(ctx)
+
+	backfilled := 0
+	for cur.Next(ctx) {
+		feature := new(domain.Feature)
+		if err := cur.Decode(feature); err != nil {
+			mdb.logger.Errorf("database.BackfillScores: mongo.Decode >> %v", err)
+			return err
+		}
+
+		if _, _, err := mdb.recount(ctx, feature.Id, nil); err != nil {
+			return err
+		}
+		backfilled++
+	}
+
+	if err := cur.Err(); err != nil {
+		mdb.logger.Errorf("database.BackfillScores: mongo.Cursor >> %v", err)
+		return err
+	}
+
+	if backfilled > 0 {
+		mdb.logger.Infof("database.BackfillScores: backfilled %d features", backfilled)
+	}
+
+	return nil
+}
+
+// BackfillBoards puts features and votes stored before boards existed onto
+// the default board.
+func (mdb *MongoDatabase) BackfillBoards() error {
+	db := mdb.client.Database("pair-challenge")
+	missing := bson.M{"board": bson.M{"$exists": false}}
+	set := bson.M{"$set": bson.M{"board": domain.DefaultBoard}}
+
+	for _, name := range []string{"features", "votes"} {
+		res, err := db.Collection(name).UpdateMany(context.Background(), missing, set)
+		if err != nil {
+			mdb.logger.Errorf("database.BackfillBoards: mongo.UpdateMany >> %v", err)
+			return err
+		}
+
+		if res.ModifiedCount > 0 {
+			mdb.logger.Infof("database.BackfillBoards: backfilled %d %s", res.ModifiedCount, name)
+		}
+	}
+
+	return nil
+}
+
+// BackfillStatuses marks features stored before statuses existed as open.
+func (mdb *MongoDatabase) BackfillStatuses() error {
+	coll := mdb.client.Database("pair-challenge").Collection("features")
+
+	res, err := coll.UpdateMany(context.Background(),
+		bson.M{"status": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"status": domain.StatusOpen}},
+	)
+	if err != nil {
+		mdb.logger.Errorf("database.BackfillStatuses: mongo.UpdateMany >> %v", err)
+		return err
+	}
+
+	if res.ModifiedCount > 0 {
+		mdb.logger.Infof("database.BackfillStatuses: backfilled %d features", res.ModifiedCount)
+	}
+
+	return nil
+}
@@ -0,0 +1,271 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Snapshot walks every feature, in the trash or not, with all its votes
+// oldest first, then every audit event oldest first, all as they stood at
+// the moment it started. It stops at the first error either callback
+// returns.
+//
+// The reads share a snapshot session, which needs MongoDB 5.0 or later on
+// a replica set, and must finish within the server's snapshot history
+// window, five minutes by default.
+func (mdb *MongoDatabase) Snapshot(ctx context.Context, features func(feature *domain.Feature, votes []*domain.Vote) error, audit func(event *domain.AuditEvent) error) error {
+	sess, err := mdb.client.StartSession(options.Session().SetSnapshot(true))
+	if err != nil {
+		mdb.logger.Errorf("database.Snapshot: mongo.StartSession >> %v", err)
+		return err
+	}
+	defer sess.EndSession(context.Background())
+
+	sctx := mongo.NewSessionContext(ctx, sess)
+	if err := mdb.snapshotFeatures(sctx, features); err != nil {
+		return err
+	}
+	return mdb.snapshotAudit(sctx, audit)
+}
+
+func (mdb *MongoDatabase) snapshotFeatures(ctx context.Context, fn func(feature *domain.Feature, votes []*domain.Vote) error) error {
+	coll := mdb.client.Database("pair-challenge").Collection("features")
+
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetBatchSize(eachFeatureBatch)
+	q, err := coll.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		mdb.logger.Errorf("database.Snapshot: mongo.Find >> %v", err)
+		return err
+	}
+	defer q.Close(context.Background())
+
+	// votes are fetched a batch of features at a time rather than joined,
+	// as $lookup can't read from a snapshot
+	batch := make([]*domain.Feature, 0, eachFeatureBatch)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		votes, err := mdb.votesOf(ctx, batch)
+		if err != nil {
+			return err
+		}
+		for _, feature := range batch {
+			vs := votes[feature.Id]
+			if vs == nil {
+				vs = []*domain.Vote{}
+			}
+			if err := fn(feature, vs); err != nil {
+				return err
+			}
+		}
+		batch = batch[:0]
+		return nil
+	}
+
+	for q.Next(ctx) {
+		feature := new(domain.Feature)
+		if err := q.Decode(feature); err != nil {
+			mdb.logger.Errorf("database.Snapshot: mongo.Decode >> %v", err)
+			return err
+		}
+		batch = append(batch, feature)
+
+		if len(batch) == eachFeatureBatch {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := q.Err(); err != nil {
+		mdb.logger.Errorf("database.Snapshot: mongo.Next >> %v", err)
+		return err
+	}
+
+	return flush()
+}
+
+// votesOf returns the votes of features, oldest first, keyed by feature.
+func (mdb *MongoDatabase) votesOf(ctx context.Context, features []*domain.Feature) (map[uuid.UUID][]*domain.Vote, error) {
+	coll := mdb.client.Database("pair-challenge").Collection("votes")
+
+	ids := make([]uuid.UUID, len(features))
+	for i, feature := range features {
+		ids[i] = feature.Id
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "castAt", Value: 1}, {Key: "_id", Value: 1}})
+	q, err := coll.Find(ctx, bson.M{"featureId": bson.M{"$in": ids}}, opts)
+	if err != nil {
+		mdb.logger.Errorf("database.votesOf: mongo.Find >> %v", err)
+		return nil, err
+	}
+
+	vs := make([]*domain.Vote, 0)
+	if err = q.All(ctx, &vs); err != nil {
+		mdb.logger.Errorf("database.votesOf: mongo.All >> %v", err)
+		return nil, err
+	}
+
+	votes := make(map[uuid.UUID][]*domain.Vote, len(features))
+	for _, v := range vs {
+		votes[v.FeatureId] = append(votes[v.FeatureId], v)
+	}
+
+	return votes, nil
+}
+
+func (mdb *MongoDatabase) snapshotAudit(ctx context.Context, fn func(event *domain.AuditEvent) error) error {
+	coll := mdb.client.Database("pair-challenge").Collection("audit")
+
+	opts := options.Find().SetSort(bson.D{{Key: "at", Value: 1}, {Key: "_id", Value: 1}}).SetBatchSize(eachFeatureBatch)
+	q, err := coll.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		mdb.logger.Errorf("database.Snapshot: mongo.Find >> %v", err)
+		return err
+	}
+	defer q.Close(context.Background())
+
+	for q.Next(ctx) {
+		event := new(domain.AuditEvent)
+		if err := q.Decode(event); err != nil {
+			mdb.logger.Errorf("database.Snapshot: mongo.Decode >> %v", err)
+			return err
+		}
+		if err := fn(event); err != nil {
+			return err
+		}
+	}
+
+	if err := q.Err(); err != nil {
+		mdb.logger.Errorf("database.Snapshot: mongo.Next >> %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// RestoreFeatures writes a batch of features and their votes in one
+// transaction, exactly as given: unlike ImportFeatures nothing is reset or
+// recorded, so the features come back as they were backed up. Features
+// whose id is already taken are skipped along with their votes, and their
+// ids returned.
+func (mdb *MongoDatabase) RestoreFeatures(ctx context.Context, features []*domain.Feature, votes []*domain.Vote) ([]uuid.UUID, error) {
+	db := mdb.client.Database("pair-challenge")
+
+	var skipped []uuid.UUID
+	err := mdb.transact(func(sctx mongo.SessionContext) error {
+		// the transaction may be retried, so start from scratch each time
+		skipped = nil
+
+		existing, err := mdb.existingFeatureIds(sctx, features)
+		if err != nil {
+			return err
+		}
+
+		docs := make([]interface{}, 0, len(features))
+		for _, feature := range features {
+			if existing[feature.Id] {
+				skipped = append(skipped, feature.Id)
+				continue
+			}
+			docs = append(docs, feature)
+		}
+
+		voteDocs := make([]interface{}, 0, len(votes))
+		for _, v := range votes {
+			if !existing[v.FeatureId] {
+				voteDocs = append(voteDocs, v)
+			}
+		}
+
+		if len(docs) > 0 {
+			if _, err := db.Collection("features").InsertMany(sctx, docs); err != nil {
+				mdb.logger.Errorf("database.RestoreFeatures: mongo.InsertMany >> %v", err)
+				return duplicateOr(err)
+			}
+		}
+
+		if len(voteDocs) > 0 {
+			if _, err := db.Collection("votes").InsertMany(sctx, voteDocs); err != nil {
+				mdb.logger.Errorf("database.RestoreFeatures: mongo.InsertMany >> %v", err)
+				return duplicateOr(err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return skipped, nil
+}
+
+// RestoreAudit writes a batch of audit events exactly as given, skipping
+// any whose id is already taken, and returns how many it wrote.
+func (mdb *MongoDatabase) RestoreAudit(ctx context.Context, events []*domain.AuditEvent) (int, error) {
+	coll := mdb.client.Database("pair-challenge").Collection("audit")
+
+	if len(events) == 0 {
+		return 0, nil
+	}
+
+	docs := make([]interface{}, len(events))
+	for i, e := range events {
+		docs[i] = e
+	}
+
+	// unordered, so every event is tried and the duplicates are the only
+	// failures
+	res, err := coll.InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
+	if err != nil {
+		bwEx := mongo.BulkWriteException{}
+		if !errors.As(err, &bwEx) || bwEx.WriteConcernError != nil {
+			mdb.logger.Errorf("database.RestoreAudit: mongo.InsertMany >> %v", err)
+			return 0, err
+		}
+		for _, we := range bwEx.WriteErrors {
+			if we.Code != 11000 {
+				mdb.logger.Errorf("database.RestoreAudit: mongo.InsertMany >> %v", err)
+				return 0, err
+			}
+		}
+		return len(events) - len(bwEx.WriteErrors), nil
+	}
+
+	return len(res.InsertedIDs), nil
+}
+
+// ClearFeatures deletes every feature, vote and audit event, ahead of a
+// restore replacing them. It isn't a transaction: should it fail part way,
+// run it again.
+func (mdb *MongoDatabase) ClearFeatures(ctx context.Context) error {
+	db := mdb.client.Database("pair-challenge")
+
+	for _, name := range []string{"votes", "features", "audit"} {
+		if _, err := db.Collection(name).DeleteMany(ctx, bson.M{}); err != nil {
+			mdb.logger.Errorf("database.ClearFeatures: mongo.DeleteMany %s >> %v", name, err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// duplicateOr maps a duplicate key failure of a bulk insert onto
+// ErrDuplicate, passing anything else back unchanged.
+func duplicateOr(err error) error {
+	bwEx := mongo.BulkWriteException{}
+	if errors.As(err, &bwEx) && bwEx.HasErrorCode(11000) {
+		return ErrDuplicate
+	}
+	return err
+}
@@ -0,0 +1,51 @@
+package database
+
+import (
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ChangeVoteDirection switches voterId's existing vote for the feature
+// between up and down. The switch counts as a fresh vote, so its CastAt is
+// moved to now before the feature's totals and scores are recomputed.
+// Votes on a feature in the trash stay as they are, and ErrNotFound is
+// returned for them.
+func (mdb *MongoDatabase) ChangeVoteDirection(featureId, voterId uuid.UUID, direction domain.VoteDirection) (*domain.Feature, error) {
+	coll := mdb.client.Database("pair-challenge").Collection("votes")
+
+	now := mdb.now()
+	var after *domain.Feature
+	err := mdb.transact(func(ctx mongo.SessionContext) error {
+		if _, err := mdb.findFeature(ctx, live(bson.M{"_id": featureId})); err != nil {
+			return err
+		}
+
+		res, err := coll.UpdateOne(ctx,
+			bson.M{"featureId": featureId, "voterId": voterId},
+			bson.M{"$set": bson.M{"direction": direction, "castAt": now}},
+		)
+		if err != nil {
+			mdb.logger.Errorf("database.ChangeVoteDirection: mongo.UpdateOne >> %v", err)
+			return err
+		}
+
+		if res.MatchedCount == 0 {
+			mdb.logger.Errorf("database.ChangeVoteDirection: mongo.UpdateOne >> %v", ErrNotFound)
+			return ErrNotFound
+		}
+
+		var before *domain.Feature
+		if before, after, err = mdb.recount(ctx, featureId, bson.M{"lastVotedAt": now}); err != nil {
+			return err
+		}
+
+		return mdb.record(ctx, voterId.String(), domain.AuditVoteChanged, featureId, before, after)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return after, nil
+}
@@ -5,4 +5,7 @@ import "errors"
 var (
 	ErrDuplicate = errors.New("record already exists")
 	ErrNotFound  = errors.New("record not found")
+	// ErrBudgetExhausted is returned by AddVote when the vote would take the
+	// voter past their budget for the board.
+	ErrBudgetExhausted = errors.New("vote budget exhausted")
 )
@@ -1,25 +1,35 @@
 package database
 
 import (
-	"context"
-
+	"github.com/music-tribe/react-pairing-challenge/domain"
 	"github.com/music-tribe/uuid"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
+// Delete moves a feature to its owner's trash. The feature and its votes are
+// kept until Restore brings it back or PurgeDeleted removes it for good.
 func (mdb *MongoDatabase) Delete(userId, featureId uuid.UUID) error {
 	coll := mdb.client.Database("pair-challenge").Collection("features")
 
-	res, err := coll.DeleteOne(context.Background(), bson.M{"_id": featureId, "userId": userId})
-	if err != nil {
-		mdb.logger.Errorf("database.Delete: mongo.DeleteOne >> %v", err)
-		return err
-	}
+	now := mdb.now()
+	return mdb.transact(func(ctx mongo.SessionContext) error {
+		q := coll.FindOneAndUpdate(ctx,
+			live(bson.M{"_id": featureId, "userId": userId}),
+			bson.M{"$set": bson.M{"deletedAt": now}})
+
+		before := new(domain.Feature)
+		if err := q.Decode(before); err != nil {
+			mdb.logger.Errorf("database.Delete: mongo.Decode >> %v", err)
+			if err == mongo.ErrNoDocuments {
+				return ErrNotFound
+			}
+			return err
+		}
 
-	if res.DeletedCount == 0 {
-		mdb.logger.Errorf("database.Delete: mongo.DeleteOne >> %v", ErrNotFound)
-		return ErrNotFound
-	}
+		after := *before
+		after.DeletedAt = &now
 
-	return nil
+		return mdb.record(ctx, userId.String(), domain.AuditDeleted, featureId, before, &after)
+	})
 }
@@ -0,0 +1,56 @@
+package database
+
+import (
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// DeleteCategory removes a category from the taxonomy and untags every
+// feature that carried it, recording each untagging as an update by the
+// system.
+func (mdb *MongoDatabase) DeleteCategory(slug string) error {
+	db := mdb.client.Database("pair-challenge")
+
+	return mdb.transact(func(ctx mongo.SessionContext) error {
+		res, err := db.Collection("categories").DeleteOne(ctx, bson.M{"_id": slug})
+		if err != nil {
+			mdb.logger.Errorf("database.DeleteCategory: mongo.DeleteOne >> %v", err)
+			return err
+		}
+
+		if res.DeletedCount == 0 {
+			mdb.logger.Errorf("database.DeleteCategory: mongo.DeleteOne >> %v", ErrNotFound)
+			return ErrNotFound
+		}
+
+		q, err := db.Collection("features").Find(ctx, bson.M{"tags": slug})
+		if err != nil {
+			mdb.logger.Errorf("database.DeleteCategory: mongo.Find >> %v", err)
+			return err
+		}
+		tagged := make([]*domain.Feature, 0)
+		if err := q.All(ctx, &tagged); err != nil {
+			mdb.logger.Errorf("database.DeleteCategory: mongo.All >> %v", err)
+			return err
+		}
+		if len(tagged) == 0 {
+			return nil
+		}
+
+		if _, err := db.Collection("features").UpdateMany(ctx,
+			bson.M{"tags": slug}, bson.M{"$pull": bson.M{"tags": slug}}); err != nil {
+			mdb.logger.Errorf("database.DeleteCategory: mongo.UpdateMany >> %v", err)
+			return err
+		}
+
+		for _, before := range tagged {
+			after := *before
+			after.Tags = domain.WithoutTag(before.Tags, slug)
+			if err := mdb.record(ctx, domain.SystemActor, domain.AuditUpdated, before.Id, before, &after); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -41,5 +41,19 @@ func TestMongoDatabase_Delete(t *testing.T) {
 
 		_, err = db.Get(expect.UserId, expect.Id)
 		assert.ErrorIs(t, err, ErrNotFound)
+
+		trash, err := db.GetTrash(expect.UserId)
+		assert.NoError(t, err)
+		assert.Len(t, trash, 1)
+		assert.NotNil(t, trash[0].DeletedAt)
+	})
+
+	t.Run("When the feature is already in the trash, we should get an error", func(t *testing.T) {
+		expect := domain.Feature{Id: uuid.New(), UserId: uuid.New(), Name: "done", Description: "exists"}
+		assert.NoError(t, db.Add(&expect))
+		assert.NoError(t, db.Delete(expect.UserId, expect.Id))
+
+		err := db.Delete(expect.UserId, expect.Id)
+		assert.ErrorIs(t, err, ErrNotFound)
 	})
 }
@@ -0,0 +1,73 @@
+package database
+
+import (
+	"context"
+	"sort"
+
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// eachFeatureBatch is how many features are fetched from mongo at a time.
+const eachFeatureBatch = 500
+
+// EachFeature calls fn for every feature from every user matching opts, one
+// at a time, so any number of features can be walked without holding them
+// all in memory. With withVotes fn also gets each feature's votes, oldest
+// first; otherwise votes is nil. It stops at the first error fn returns.
+func (mdb *MongoDatabase) EachFeature(ctx context.Context, opts ListOptions, withVotes bool, fn func(feature *domain.Feature, votes []*domain.Vote) error) error {
+	coll := mdb.client.Database("pair-challenge").Collection("features")
+
+	pipeline := []bson.M{
+		{"$match": listFilter(bson.M{}, opts)},
+		{"$sort": sortFor(opts.Sort)},
+	}
+	if withVotes {
+		pipeline = append(pipeline, bson.M{"$lookup": bson.M{
+			"from":         "votes",
+			"localField":   "_id",
+			"foreignField": "featureId",
+			"as":           "votes",
+		}})
+	}
+
+	aggOpts := options.Aggregate().SetBatchSize(eachFeatureBatch).SetAllowDiskUse(true)
+	q, err := coll.Aggregate(ctx, pipeline, aggOpts)
+	if err != nil {
+		mdb.logger.Errorf("database.EachFeature: mongo.Aggregate >> %v", err)
+		return err
+	}
+	defer q.Close(context.Background())
+
+	for q.Next(ctx) {
+		doc := struct {
+			domain.Feature `bson:",inline"`
+			Votes          []*domain.Vote `bson:"votes,omitempty"`
+		}{}
+		if err := q.Decode(&doc); err != nil {
+			mdb.logger.Errorf("database.EachFeature: mongo.Decode >> %v", err)
+			return err
+		}
+
+		var votes []*domain.Vote
+		if withVotes {
+			votes = doc.Votes
+			if votes == nil {
+				votes = []*domain.Vote{}
+			}
+			sort.SliceStable(votes, func(i, j int) bool { return votes[i].CastAt.Before(votes[j].CastAt) })
+		}
+
+		if err := fn(&doc.Feature, votes); err != nil {
+			return err
+		}
+	}
+
+	if err := q.Err(); err != nil {
+		mdb.logger.Errorf("database.EachFeature: mongo.Next >> %v", err)
+		return err
+	}
+
+	return nil
+}
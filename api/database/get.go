@@ -12,7 +12,7 @@ import (
 func (mdb *MongoDatabase) Get(userId, id uuid.UUID) (*domain.Feature, error) {
 	coll := mdb.client.Database("pair-challenge").Collection("features")
 
-	q := coll.FindOne(context.Background(), bson.M{"_id": id, "userId": userId})
+	q := coll.FindOne(context.Background(), live(bson.M{"_id": id, "userId": userId}))
 
 	t := new(domain.Feature)
 	err := q.Decode(t)
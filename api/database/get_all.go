@@ -7,12 +7,14 @@ import (
 	"github.com/music-tribe/uuid"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-func (mdb *MongoDatabase) GetAll(userId uuid.UUID) ([]*domain.Feature, error) {
+func (mdb *MongoDatabase) GetAll(userId uuid.UUID, opts ListOptions) ([]*domain.Feature, error) {
 	coll := mdb.client.Database("pair-challenge").Collection("features")
 
-	q, err := coll.Find(context.Background(), bson.M{"userId": userId})
+	findOpts := options.Find().SetSort(sortFor(opts.Sort))
+	q, err := coll.Find(context.Background(), listFilter(bson.M{"userId": userId}, opts), findOpts)
 	if err != nil {
 		return nil, err
 	}
@@ -12,7 +12,7 @@ import (
 func (mdb *MongoDatabase) GetById(featureId uuid.UUID) (*domain.Feature, error) {
 	coll := mdb.client.Database("pair-challenge").Collection("features")
 
-	q := coll.FindOne(context.Background(), bson.M{"_id": featureId})
+	q := coll.FindOne(context.Background(), live(bson.M{"_id": featureId}))
 
 	t := new(domain.Feature)
 	err := q.Decode(t)
@@ -0,0 +1,88 @@
+package database
+
+import (
+	"context"
+
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetCategories returns the whole taxonomy ordered by slug.
+func (mdb *MongoDatabase) GetCategories() ([]*domain.Category, error) {
+	coll := mdb.client.Database("pair-challenge").Collection("categories")
+
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}})
+	q, err := coll.Find(context.Background(), bson.M{}, opts)
+	if err != nil {
+		mdb.logger.Errorf("database.GetCategories: mongo.Find >> %v", err)
+		return nil, err
+	}
+
+	cs := make([]*domain.Category, 0)
+	if err = q.All(context.Background(), &cs); err != nil {
+		mdb.logger.Errorf("database.GetCategories: mongo.All >> %v", err)
+		return nil, err
+	}
+
+	return cs, nil
+}
+
+// MissingCategories returns the slugs that aren't in the taxonomy.
+func (mdb *MongoDatabase) MissingCategories(slugs []string) ([]string, error) {
+	coll := mdb.client.Database("pair-challenge").Collection("categories")
+
+	found, err := coll.Distinct(context.Background(), "_id", bson.M{"_id": bson.M{"$in": slugs}})
+	if err != nil {
+		mdb.logger.Errorf("database.MissingCategories: mongo.Distinct >> %v", err)
+		return nil, err
+	}
+
+	exists := map[string]bool{}
+	for _, f := range found {
+		if slug, ok := f.(string); ok {
+			exists[slug] = true
+		}
+	}
+
+	missing := make([]string, 0)
+	for _, slug := range slugs {
+		if !exists[slug] {
+			missing = append(missing, slug)
+		}
+	}
+
+	return missing, nil
+}
+
+// TagCounts returns how many features carry each tag.
+func (mdb *MongoDatabase) TagCounts() (map[string]int64, error) {
+	coll := mdb.client.Database("pair-challenge").Collection("features")
+
+	pipeline := bson.A{
+		bson.M{"$match": live(bson.M{})},
+		bson.M{"$unwind": "$tags"},
+		bson.M{"$group": bson.M{"_id": "$tags", "count": bson.M{"$sum": 1}}},
+	}
+	q, err := coll.Aggregate(context.Background(), pipeline)
+	if err != nil {
+		mdb.logger.Errorf("database.TagCounts: mongo.Aggregate >> %v", err)
+		return nil, err
+	}
+
+	rows := []struct {
+		Tag   string `bson:"_id"`
+		Count int64  `bson:"count"`
+	}{}
+	if err = q.All(context.Background(), &rows); err != nil {
+		mdb.logger.Errorf("database.TagCounts: mongo.All >> %v", err)
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Tag] = row.Count
+	}
+
+	return counts, nil
+}
@@ -0,0 +1,27 @@
+package database
+
+import (
+	"context"
+
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// GetVote returns voterId's vote for the feature.
+func (mdb *MongoDatabase) GetVote(featureId, voterId uuid.UUID) (*domain.Vote, error) {
+	coll := mdb.client.Database("pair-challenge").Collection("votes")
+
+	q := coll.FindOne(context.Background(), bson.M{"featureId": featureId, "voterId": voterId})
+	v := new(domain.Vote)
+	if err := q.Decode(v); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrNotFound
+		}
+		mdb.logger.Errorf("database.GetVote: mongo.Decode >> %v", err)
+		return nil, err
+	}
+
+	return v, nil
+}
@@ -0,0 +1,34 @@
+package database
+
+import (
+	"context"
+
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetVotes returns every vote cast for the feature, oldest first.
+func (mdb *MongoDatabase) GetVotes(featureId uuid.UUID) ([]*domain.Vote, error) {
+	return mdb.getVotes(context.Background(), featureId)
+}
+
+func (mdb *MongoDatabase) getVotes(ctx context.Context, featureId uuid.UUID) ([]*domain.Vote, error) {
+	coll := mdb.client.Database("pair-challenge").Collection("votes")
+
+	opts := options.Find().SetSort(bson.D{{Key: "castAt", Value: 1}})
+	q, err := coll.Find(ctx, bson.M{"featureId": featureId}, opts)
+	if err != nil {
+		mdb.logger.Errorf("database.GetVotes: mongo.Find >> %v", err)
+		return nil, err
+	}
+
+	vs := make([]*domain.Vote, 0)
+	if err = q.All(ctx, &vs); err != nil {
+		mdb.logger.Errorf("database.GetVotes: mongo.All >> %v", err)
+		return nil, err
+	}
+
+	return vs, nil
+}
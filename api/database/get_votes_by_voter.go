@@ -0,0 +1,72 @@
+package database
+
+import (
+	"context"
+
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetVotesByVoter returns every active vote cast by voterId, across all
+// boards, oldest first. Votes on features in the trash are left out, so
+// they neither show nor count against the voter's budget until the feature
+// is restored.
+func (mdb *MongoDatabase) GetVotesByVoter(voterId uuid.UUID) ([]*domain.Vote, error) {
+	return mdb.liveVotes(context.Background(), "GetVotesByVoter", bson.M{"voterId": voterId})
+}
+
+// liveVotes returns the votes filter matches, oldest first, dropping those
+// on features in the trash.
+func (mdb *MongoDatabase) liveVotes(ctx context.Context, op string, filter bson.M) ([]*domain.Vote, error) {
+	db := mdb.client.Database("pair-challenge")
+
+	opts := options.Find().SetSort(bson.D{{Key: "castAt", Value: 1}, {Key: "_id", Value: 1}})
+	q, err := db.Collection("votes").Find(ctx, filter, opts)
+	if err != nil {
+		mdb.logger.Errorf("database.%s: mongo.Find >> %v", op, err)
+		return nil, err
+	}
+
+	vs := make([]*domain.Vote, 0)
+	if err = q.All(ctx, &vs); err != nil {
+		mdb.logger.Errorf("database.%s: mongo.All >> %v", op, err)
+		return nil, err
+	}
+	if len(vs) == 0 {
+		return vs, nil
+	}
+
+	ids := make([]uuid.UUID, len(vs))
+	for i, v := range vs {
+		ids[i] = v.FeatureId
+	}
+
+	q, err = db.Collection("features").Find(ctx, live(bson.M{"_id": bson.M{"$in": ids}}), options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		mdb.logger.Errorf("database.%s: mongo.Find >> %v", op, err)
+		return nil, err
+	}
+
+	found := []struct {
+		Id uuid.UUID `bson:"_id"`
+	}{}
+	if err = q.All(ctx, &found); err != nil {
+		mdb.logger.Errorf("database.%s: mongo.All >> %v", op, err)
+		return nil, err
+	}
+
+	alive := make(map[uuid.UUID]bool, len(found))
+	for _, f := range found {
+		alive[f.Id] = true
+	}
+
+	votes := make([]*domain.Vote, 0, len(vs))
+	for _, v := range vs {
+		if alive[v.FeatureId] {
+			votes = append(votes, v)
+		}
+	}
+	return votes, nil
+}
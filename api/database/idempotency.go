@@ -0,0 +1,101 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ReserveIdempotencyKey claims req.Key for a request about to be handled.
+// ErrDuplicate is returned while another request holds the key or its
+// response is still stored; an expired claim is taken over.
+func (mdb *MongoDatabase) ReserveIdempotencyKey(req *domain.IdempotentRequest) error {
+	coll := mdb.client.Database("pair-challenge").Collection("idempotency_keys")
+
+	req.CreatedAt = mdb.now()
+
+	_, err := coll.InsertOne(context.Background(), req)
+	if err == nil {
+		return nil
+	}
+
+	wrEx := mongo.WriteException{}
+	if !errors.As(err, &wrEx) || !wrEx.HasErrorCode(11000) {
+		mdb.logger.Errorf("database.ReserveIdempotencyKey: mongo.InsertOne >> %v", err)
+		return err
+	}
+
+	// the TTL monitor only runs every minute, so the key may still be held
+	// by a request that has expired
+	filter := bson.M{"_id": req.Key, "expiresAt": bson.M{"$lte": req.CreatedAt}}
+	res, err := coll.ReplaceOne(context.Background(), filter, req)
+	if err != nil {
+		mdb.logger.Errorf("database.ReserveIdempotencyKey: mongo.ReplaceOne >> %v", err)
+		return err
+	}
+
+	if res.MatchedCount == 0 {
+		return ErrDuplicate
+	}
+
+	return nil
+}
+
+// GetIdempotencyKey returns the request holding key.
+func (mdb *MongoDatabase) GetIdempotencyKey(key string) (*domain.IdempotentRequest, error) {
+	coll := mdb.client.Database("pair-challenge").Collection("idempotency_keys")
+
+	req := domain.IdempotentRequest{}
+	if err := coll.FindOne(context.Background(), bson.M{"_id": key}).Decode(&req); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrNotFound
+		}
+		mdb.logger.Errorf("database.GetIdempotencyKey: mongo.FindOne >> %v", err)
+		return nil, err
+	}
+
+	return &req, nil
+}
+
+// CompleteIdempotencyKey stores the response to req, keeping it until
+// req.ExpiresAt. ErrNotFound is returned if req no longer holds its key.
+func (mdb *MongoDatabase) CompleteIdempotencyKey(req *domain.IdempotentRequest) error {
+	coll := mdb.client.Database("pair-challenge").Collection("idempotency_keys")
+
+	update := bson.M{"$set": bson.M{
+		"status":      req.Status,
+		"contentType": req.ContentType,
+		"body":        req.Body,
+		"expiresAt":   req.ExpiresAt,
+	}}
+
+	res, err := coll.UpdateOne(context.Background(), bson.M{"_id": req.Key, "createdAt": req.CreatedAt}, update)
+	if err != nil {
+		mdb.logger.Errorf("database.CompleteIdempotencyKey: mongo.UpdateOne >> %v", err)
+		return err
+	}
+
+	if res.MatchedCount == 0 {
+		mdb.logger.Errorf("database.CompleteIdempotencyKey: mongo.UpdateOne >> %v", ErrNotFound)
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// ReleaseIdempotencyKey frees the key held by req after it failed, so the
+// request may be retried.
+func (mdb *MongoDatabase) ReleaseIdempotencyKey(req *domain.IdempotentRequest) error {
+	coll := mdb.client.Database("pair-challenge").Collection("idempotency_keys")
+
+	filter := bson.M{"_id": req.Key, "createdAt": req.CreatedAt, "status": 0}
+	if _, err := coll.DeleteOne(context.Background(), filter); err != nil {
+		mdb.logger.Errorf("database.ReleaseIdempotencyKey: mongo.DeleteOne >> %v", err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,148 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/react-pairing-challenge/ranking"
+	"github.com/music-tribe/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ImportFeatures adds a batch of features in one transaction, recording each
+// as created by actor. Features whose id is already taken, including by one
+// in the trash, are skipped and their ids returned.
+//
+// Unlike Add, an imported feature keeps the status and creation time it was
+// given, so requests moved from another tool don't all start over as new.
+func (mdb *MongoDatabase) ImportFeatures(actor string, features []*domain.Feature) ([]uuid.UUID, error) {
+	coll := mdb.client.Database("pair-challenge").Collection("features")
+
+	now := mdb.now()
+	for _, feature := range features {
+		if feature.CreatedAt.IsZero() {
+			feature.CreatedAt = now
+		}
+		feature.UpdatedAt = now
+		feature.VoteCount = 0
+		feature.Upvotes = 0
+		feature.Downvotes = 0
+		feature.Score = 0
+		feature.LastVotedAt = nil
+		feature.DeletedAt = nil
+		if feature.Status == "" {
+			feature.Status = domain.StatusOpen
+		}
+
+		scores := ranking.Initial(feature.CreatedAt)
+		feature.HotScore = scores.Hot
+		feature.RisingScore = scores.Rising
+	}
+
+	var duplicates []uuid.UUID
+	err := mdb.transact(func(ctx mongo.SessionContext) error {
+		// the transaction may be retried, so start from scratch each time
+		duplicates = nil
+
+		existing, err := mdb.existingFeatureIds(ctx, features)
+		if err != nil {
+			return err
+		}
+
+		docs := make([]interface{}, 0, len(features))
+		fresh := make([]*domain.Feature, 0, len(features))
+		for _, feature := range features {
+			if existing[feature.Id] {
+				duplicates = append(duplicates, feature.Id)
+				continue
+			}
+			docs = append(docs, feature)
+			fresh = append(fresh, feature)
+		}
+
+		if len(docs) == 0 {
+			return nil
+		}
+
+		if _, err := coll.InsertMany(ctx, docs); err != nil {
+			mdb.logger.Errorf("database.ImportFeatures: mongo.InsertMany >> %v", err)
+			bwEx := mongo.BulkWriteException{}
+			if errors.As(err, &bwEx) {
+				if bwEx.HasErrorCode(11000) {
+					return ErrDuplicate
+				}
+			}
+			return err
+		}
+
+		for _, feature := range fresh {
+			if err := mdb.record(ctx, actor, domain.AuditCreated, feature.Id, nil, feature); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return duplicates, nil
+}
+
+// ExistingFeatureIds returns which of ids are already taken by a feature,
+// including one in the trash.
+func (mdb *MongoDatabase) ExistingFeatureIds(ids []uuid.UUID) ([]uuid.UUID, error) {
+	features := make([]*domain.Feature, len(ids))
+	for i, id := range ids {
+		features[i] = &domain.Feature{Id: id}
+	}
+
+	existing, err := mdb.existingFeatureIds(context.Background(), features)
+	if err != nil {
+		return nil, err
+	}
+
+	taken := make([]uuid.UUID, 0, len(existing))
+	for _, id := range ids {
+		if existing[id] {
+			taken = append(taken, id)
+		}
+	}
+
+	return taken, nil
+}
+
+func (mdb *MongoDatabase) existingFeatureIds(ctx context.Context, features []*domain.Feature) (map[uuid.UUID]bool, error) {
+	coll := mdb.client.Database("pair-challenge").Collection("features")
+
+	ids := make([]uuid.UUID, len(features))
+	for i, feature := range features {
+		ids[i] = feature.Id
+	}
+
+	opts := options.Find().SetProjection(bson.M{"_id": 1})
+	q, err := coll.Find(ctx, bson.M{"_id": bson.M{"$in": ids}}, opts)
+	if err != nil {
+		mdb.logger.Errorf("database.existingFeatureIds: mongo.Find >> %v", err)
+		return nil, err
+	}
+
+	found := []struct {
+		Id uuid.UUID `bson:"_id"`
+	}{}
+	if err = q.All(ctx, &found); err != nil {
+		mdb.logger.Errorf("database.existingFeatureIds: mongo.All >> %v", err)
+		return nil, err
+	}
+
+	existing := make(map[uuid.UUID]bool, len(found))
+	for _, f := range found {
+		existing[f.Id] = true
+	}
+
+	return existing, nil
+}
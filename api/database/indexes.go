@@ -0,0 +1,211 @@
+package database
+
+import (
+	"context"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// index declares an index the storage layer relies on, for correctness or
+// to keep a query from scanning its collection.
+type index struct {
+	name   string
+	keys   bson.D
+	unique bool
+	sparse bool
+	// ttl, when set, expires documents this many seconds after the time
+	// in the indexed field.
+	ttl *int32
+}
+
+func ttl(seconds int32) *int32 {
+	return &seconds
+}
+
+func (i index) model() mongo.IndexModel {
+	opts := options.Index().SetName(i.name)
+	if i.unique {
+		opts.SetUnique(true)
+	}
+	if i.sparse {
+		opts.SetSparse(true)
+	}
+	if i.ttl != nil {
+		opts.SetExpireAfterSeconds(*i.ttl)
+	}
+	return mongo.IndexModel{Keys: i.keys, Options: opts}
+}
+
+// matches reports whether spec, read back from mongo, is the index i
+// declares. Mongo stores text indexes by their own internal keys, so only
+// their kind is compared.
+func (i index) matches(spec *mongo.IndexSpecification) bool {
+	if i.unique != (spec.Unique != nil && *spec.Unique) || i.sparse != (spec.Sparse != nil && *spec.Sparse) {
+		return false
+	}
+	if (i.ttl == nil) != (spec.ExpireAfterSeconds == nil) || (i.ttl != nil && *i.ttl != *spec.ExpireAfterSeconds) {
+		return false
+	}
+
+	elems, err := spec.KeysDocument.Elements()
+	if err != nil {
+		return false
+	}
+
+	for _, k := range i.keys {
+		if k.Value == "text" {
+			kind, _ := spec.KeysDocument.Lookup("_fts").StringValueOK()
+			return kind == "text"
+		}
+	}
+
+	if len(elems) != len(i.keys) {
+		return false
+	}
+	for n, elem := range elems {
+		want, ok := i.keys[n].Value.(int)
+		if elem.Key() != i.keys[n].Key || !ok || direction(elem.Value()) != want {
+			return false
+		}
+	}
+	return true
+}
+
+// direction reads an index key's direction, which mongo may hand back as
+// any numeric type.
+func direction(v bson.RawValue) int {
+	if i, ok := v.Int32OK(); ok {
+		return int(i)
+	}
+	if i, ok := v.Int64OK(); ok {
+		return int(i)
+	}
+	if f, ok := v.DoubleOK(); ok {
+		return int(f)
+	}
+	return 0
+}
+
+// declaredIndexes are every index the service expects, by collection.
+// Indexes are matched by name, so changing one means renaming it too.
+var declaredIndexes = []struct {
+	collection string
+	indexes    []index
+}{
+	{"features", []index{
+		// a user's features, newest first, and their trash
+		{name: "userId_createdAt", keys: bson.D{{Key: "userId", Value: 1}, {Key: "createdAt", Value: -1}}},
+		{name: "board_createdAt", keys: bson.D{{Key: "board", Value: 1}, {Key: "createdAt", Value: -1}}},
+		{name: "createdAt", keys: bson.D{{Key: "createdAt", Value: -1}, {Key: "_id", Value: 1}}},
+		{name: "score_voteCount", keys: bson.D{{Key: "score", Value: -1}, {Key: "voteCount", Value: -1}, {Key: "_id", Value: 1}}},
+		// ?sort=hot and ?sort=rising
+		{name: "hotScore", keys: bson.D{{Key: "hotScore", Value: -1}, {Key: "_id", Value: 1}}},
+		{name: "risingScore", keys: bson.D{{Key: "risingScore", Value: -1}, {Key: "_id", Value: 1}}},
+		{name: "tags", keys: bson.D{{Key: "tags", Value: 1}}},
+		{name: "name_description_text", keys: bson.D{{Key: "name", Value: "text"}, {Key: "description", Value: "text"}}},
+		{name: "deletedAt", keys: bson.D{{Key: "deletedAt", Value: 1}}, sparse: true},
+	}},
+	{"votes", []index{
+		{name: "featureId_voterId_unique", keys: bson.D{{Key: "featureId", Value: 1}, {Key: "voterId", Value: 1}}, unique: true},
+		{name: "voterId_board", keys: bson.D{{Key: "voterId", Value: 1}, {Key: "board", Value: 1}}},
+	}},
+	{"audit", []index{
+		{name: "featureId_at", keys: bson.D{{Key: "featureId", Value: 1}, {Key: "at", Value: 1}}},
+		{name: "actor_at", keys: bson.D{{Key: "actor", Value: 1}, {Key: "at", Value: -1}}},
+		{name: "at", keys: bson.D{{Key: "at", Value: -1}}},
+	}},
+	{"outbox", []index{
+		{name: "pending", keys: bson.D{{Key: "dispatchedAt", Value: 1}, {Key: "deadAt", Value: 1}, {Key: "nextAttemptAt", Value: 1}}},
+		// delivered events are only kept around for a week of debugging
+		{name: "dispatchedAt_ttl", keys: bson.D{{Key: "dispatchedAt", Value: 1}}, ttl: ttl(7 * 24 * 60 * 60)},
+	}},
+	{"webhook_deliveries", []index{
+		// an event is only ever queued once per webhook, however many
+		// times the outbox delivers it
+		{name: "webhookId_eventId_unique", keys: bson.D{{Key: "webhookId", Value: 1}, {Key: "event._id", Value: 1}}, unique: true},
+		{name: "status_nextAttemptAt", keys: bson.D{{Key: "status", Value: 1}, {Key: "nextAttemptAt", Value: 1}}},
+		{name: "webhookId_createdAt", keys: bson.D{{Key: "webhookId", Value: 1}, {Key: "createdAt", Value: -1}}},
+	}},
+	{"idempotency_keys", []index{
+		{name: "expiresAt_ttl", keys: bson.D{{Key: "expiresAt", Value: 1}}, ttl: ttl(0)},
+	}},
+}
+
+// indexDrift compares the declared indexes of a collection with those it
+// has, returning the declared indexes that are missing, the names of
+// those defined differently and of those nobody declared.
+func indexDrift(declared []index, existing []*mongo.IndexSpecification) (missing []index, changed, unexpected []string) {
+	found := make(map[string]*mongo.IndexSpecification, len(existing))
+	for _, spec := range existing {
+		found[spec.Name] = spec
+	}
+
+	for _, i := range declared {
+		spec, ok := found[i.name]
+		switch {
+		case !ok:
+			missing = append(missing, i)
+		case !i.matches(spec):
+			changed = append(changed, i.name)
+		}
+		delete(found, i.name)
+	}
+
+	for _, spec := range existing {
+		if _, ok := found[spec.Name]; ok && spec.Name != "_id_" {
+			unexpected = append(unexpected, spec.Name)
+		}
+	}
+
+	return missing, changed, unexpected
+}
+
+// ensureIndexes creates any declared index that is missing, and logs the
+// indexes that have drifted from their declarations. Drifted indexes are
+// left alone, as rebuilding an index on a live collection is a decision
+// for an operator: dropping one lets it be recreated on the next start.
+func (mdb *MongoDatabase) ensureIndexes() error {
+	db := mdb.client.Database("pair-challenge")
+	ctx := context.Background()
+
+	for _, c := range declaredIndexes {
+		view := db.Collection(c.collection).Indexes()
+
+		existing, err := view.ListSpecifications(ctx)
+		if err != nil {
+			mdb.logger.Errorf("database.ensureIndexes: mongo.ListSpecifications >> %v", err)
+			return err
+		}
+
+		missing, changed, unexpected := indexDrift(c.indexes, existing)
+		for _, name := range changed {
+			mdb.logger.Errorf("database.ensureIndexes: index %s.%s differs from its declaration; drop it to have it rebuilt", c.collection, name)
+		}
+		if len(unexpected) > 0 {
+			mdb.logger.Infof("database.ensureIndexes: %s has undeclared indexes %s", c.collection, strings.Join(unexpected, ", "))
+		}
+		if len(missing) == 0 {
+			continue
+		}
+
+		models := make([]mongo.IndexModel, len(missing))
+		names := make([]string, len(missing))
+		for n, i := range missing {
+			models[n], names[n] = i.model(), i.name
+		}
+
+		// a fresh database has nothing to report
+		if len(existing) > 0 {
+			mdb.logger.Infof("database.ensureIndexes: creating missing indexes %s on %s", strings.Join(names, ", "), c.collection)
+		}
+		if _, err := view.CreateMany(ctx, models); err != nil {
+			mdb.logger.Errorf("database.ensureIndexes: mongo.CreateMany >> %v", err)
+			return err
+		}
+	}
+
+	return nil
+}
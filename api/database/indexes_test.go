@@ -0,0 +1,88 @@
+package database
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestIndexDrift(t *testing.T) {
+	spec := func(name string, keys interface{}, unique bool, ttl *int32) *mongo.IndexSpecification {
+		raw, err := bson.Marshal(keys)
+		assert.NoError(t, err)
+		return &mongo.IndexSpecification{Name: name, KeysDocument: raw, Unique: &unique, ExpireAfterSeconds: ttl}
+	}
+
+	declared := []index{
+		{name: "userId_createdAt", keys: bson.D{{Key: "userId", Value: 1}, {Key: "createdAt", Value: -1}}},
+		{name: "featureId_voterId_unique", keys: bson.D{{Key: "featureId", Value: 1}, {Key: "voterId", Value: 1}}, unique: true},
+		{name: "name_description_text", keys: bson.D{{Key: "name", Value: "text"}, {Key: "description", Value: "text"}}},
+		{name: "expiresAt_ttl", keys: bson.D{{Key: "expiresAt", Value: 1}}, ttl: ttl(0)},
+	}
+
+	t.Run("when every declared index exists as declared, there should be no drift", func(t *testing.T) {
+		missing, changed, unexpected := indexDrift(declared, []*mongo.IndexSpecification{
+			spec("_id_", bson.D{{Key: "_id", Value: int32(1)}}, false, nil),
+			// mongo may hand directions back as any numeric type
+			spec("userId_createdAt", bson.D{{Key: "userId", Value: int64(1)}, {Key: "createdAt", Value: -1.0}}, false, nil),
+			spec("featureId_voterId_unique", bson.D{{Key: "featureId", Value: 1}, {Key: "voterId", Value: 1}}, true, nil),
+			spec("name_description_text", bson.D{{Key: "_fts", Value: "text"}, {Key: "_ftsx", Value: 1}}, false, nil),
+			spec("expiresAt_ttl", bson.D{{Key: "expiresAt", Value: 1}}, false, ttl(0)),
+		})
+		assert.Empty(t, missing)
+		assert.Empty(t, changed)
+		assert.Empty(t, unexpected)
+	})
+
+	t.Run("when indexes are missing, we should return them to be created", func(t *testing.T) {
+		missing, changed, unexpected := indexDrift(declared, nil)
+		assert.Equal(t, declared, missing)
+		assert.Empty(t, changed)
+		assert.Empty(t, unexpected)
+	})
+
+	t.Run("when an index differs from its declaration, we should report it as changed", func(t *testing.T) {
+		_, changed, _ := indexDrift(declared, []*mongo.IndexSpecification{
+			spec("userId_createdAt", bson.D{{Key: "userId", Value: 1}, {Key: "createdAt", Value: 1}}, false, nil),
+			spec("featureId_voterId_unique", bson.D{{Key: "featureId", Value: 1}, {Key: "voterId", Value: 1}}, false, nil),
+			spec("name_description_text", bson.D{{Key: "name", Value: 1}}, false, nil),
+			spec("expiresAt_ttl", bson.D{{Key: "expiresAt", Value: 1}}, false, ttl(60)),
+		})
+		assert.Equal(t, []string{"userId_createdAt", "featureId_voterId_unique", "name_description_text", "expiresAt_ttl"}, changed)
+	})
+
+	t.Run("when an index isn't declared, we should report it as unexpected", func(t *testing.T) {
+		_, _, unexpected := indexDrift(declared, []*mongo.IndexSpecification{
+			spec("_id_", bson.D{{Key: "_id", Value: 1}}, false, nil),
+			spec("votes", bson.D{{Key: "votes", Value: 1}}, false, nil),
+		})
+		assert.Equal(t, []string{"votes"}, unexpected)
+	})
+}
+
+func TestDeclaredIndexes(t *testing.T) {
+	t.Run("when indexes are declared, their names should be unique per collection", func(t *testing.T) {
+		for _, c := range declaredIndexes {
+			names := map[string]bool{}
+			for _, i := range c.indexes {
+				assert.False(t, names[i.name], "%s.%s is declared twice", c.collection, i.name)
+				names[i.name] = true
+			}
+		}
+	})
+
+	t.Run("when features are sorted, every sort should have an index", func(t *testing.T) {
+		for sort, keys := range sortFields {
+			found := false
+			for _, c := range declaredIndexes {
+				for _, i := range c.indexes {
+					found = found || (c.collection == "features" && reflect.DeepEqual(i.keys, keys))
+				}
+			}
+			assert.True(t, found, "sort %q has no index on %v", sort, keys)
+		}
+	})
+}
@@ -0,0 +1,58 @@
+package database
+
+import (
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/react-pairing-challenge/ranking"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// ListOptions narrows and orders a list of features.
+type ListOptions struct {
+	Sort ranking.Sort
+	// Board limits the list to one board when set.
+	Board string
+	// Tags limits the list to features carrying any of the tags, or all of
+	// them when MatchAllTags is set.
+	Tags         []string
+	MatchAllTags bool
+	// Text is a free text search over feature names and descriptions.
+	Text string
+	// Status limits the list to features with this status when set.
+	Status domain.FeatureStatus
+}
+
+// live narrows filter to features that haven't been moved to the trash.
+func live(filter bson.M) bson.M {
+	filter["deletedAt"] = bson.M{"$exists": false}
+	return filter
+}
+
+// listFilter builds the mongo filter for opts on top of base.
+func listFilter(base bson.M, opts ListOptions) bson.M {
+	filter := live(bson.M{})
+	for k, v := range base {
+		filter[k] = v
+	}
+
+	if opts.Board != "" {
+		filter["board"] = opts.Board
+	}
+
+	if len(opts.Tags) > 0 {
+		op := "$in"
+		if opts.MatchAllTags {
+			op = "$all"
+		}
+		filter["tags"] = bson.M{op: opts.Tags}
+	}
+
+	if opts.Status != "" {
+		filter["status"] = opts.Status
+	}
+
+	if opts.Text != "" {
+		filter["$text"] = bson.M{"$search": opts.Text}
+	}
+
+	return filter
+}
@@ -0,0 +1,83 @@
+package database
+
+import (
+	"errors"
+
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var ErrSameFeature = errors.New("a feature can't be merged into itself")
+
+// MergeFeatures folds the duplicate feature sourceId into targetId on behalf
+// of actor. The source's votes move to the target, except where the voter
+// has already voted for the target, whose vote is kept; the target is then
+// recounted and the source moved to its owner's trash. Moved votes keep
+// their weight but take the target's board, so a merge across boards can
+// leave a voter over their budget there.
+func (mdb *MongoDatabase) MergeFeatures(targetId, sourceId uuid.UUID, actor string) (*domain.Feature, error) {
+	if targetId == sourceId {
+		return nil, ErrSameFeature
+	}
+
+	db := mdb.client.Database("pair-challenge")
+	votes := db.Collection("votes")
+
+	now := mdb.now()
+	var merged *domain.Feature
+	err := mdb.transact(func(ctx mongo.SessionContext) error {
+		target, err := mdb.findFeature(ctx, live(bson.M{"_id": targetId}))
+		if err != nil {
+			return err
+		}
+		if _, err := mdb.findFeature(ctx, live(bson.M{"_id": sourceId})); err != nil {
+			return err
+		}
+
+		targetVotes, err := mdb.getVotes(ctx, targetId)
+		if err != nil {
+			return err
+		}
+		voters := make([]uuid.UUID, len(targetVotes))
+		for i, v := range targetVotes {
+			voters[i] = v.VoterId
+		}
+
+		if len(voters) > 0 {
+			if _, err := votes.DeleteMany(ctx, bson.M{"featureId": sourceId, "voterId": bson.M{"$in": voters}}); err != nil {
+				mdb.logger.Errorf("database.MergeFeatures: mongo.DeleteMany >> %v", err)
+				return err
+			}
+		}
+
+		update := bson.M{"$set": bson.M{"featureId": targetId, "board": target.Board}}
+		if _, err := votes.UpdateMany(ctx, bson.M{"featureId": sourceId}, update); err != nil {
+			mdb.logger.Errorf("database.MergeFeatures: mongo.UpdateMany >> %v", err)
+			return err
+		}
+
+		before, after, err := mdb.recount(ctx, targetId, bson.M{"updatedAt": now})
+		if err != nil {
+			return err
+		}
+		if err := mdb.record(ctx, actor, domain.AuditUpdated, targetId, before, after); err != nil {
+			return err
+		}
+		merged = after
+
+		// the source is left with no votes, and can be restored empty if
+		// the merge was a mistake
+		sourceBefore, sourceAfter, err := mdb.recount(ctx, sourceId, bson.M{"deletedAt": now})
+		if err != nil {
+			return err
+		}
+		return mdb.record(ctx, actor, domain.AuditDeleted, sourceId, sourceBefore, sourceAfter)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return merged, nil
+}
@@ -0,0 +1,128 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// legacyVotes is the shape of a feature from before votes were stored as
+// records, when voters were kept as an array of ids on the feature itself.
+type legacyVotes struct {
+	Id          uuid.UUID   `bson:"_id"`
+	Board       string      `bson:"board"`
+	Votes       []uuid.UUID `bson:"votes"`
+	CreatedAt   time.Time   `bson:"createdAt"`
+	LastVotedAt *time.Time  `bson:"lastVotedAt"`
+}
+
+// MigrateVoteArrays moves any legacy votes array into the votes collection,
+// sets the feature's vote totals and removes the array. When a vote's real
+// cast time is unknown the feature's last voted, or created, time is used.
+// Re-running the migration is safe.
+func (mdb *MongoDatabase) MigrateVoteArrays() error {
+	db := mdb.client.Database("pair-challenge")
+	features := db.Collection("features")
+	votes := db.Collection("votes")
+	ctx := context.Background()
+
+	cur, err := features.Find(ctx, bson.M{"votes": bson.M{"$type": "array"}})
+	if err != nil {
+		mdb.logger.Errorf("database.MigrateVoteArrays: mongo.Find >> %v", err)
+		return err
+	}
+	defer cur.Close(ctx)
+
+	migrated := 0
+	for cur.Next(ctx) {
+		legacy := legacyVotes{}
+		if err := cur.Decode(&legacy); err != nil {
+			mdb.logger.Errorf("database.MigrateVoteArrays: mongo.Decode >> %v", err)
+			return err
+		}
+
+		castAt := legacy.CreatedAt
+		if legacy.LastVotedAt != nil {
+			castAt = *legacy.LastVotedAt
+		}
+		if castAt.IsZero() {
+			castAt = mdb.now()
+		}
+
+		board := legacy.Board
+		if board == "" {
+			board = domain.DefaultBoard
+		}
+
+		docs := make([]interface{}, 0, len(legacy.Votes))
+		for _, voter := range legacy.Votes {
+			docs = append(docs, &domain.Vote{
+				Id:        uuid.New(),
+				FeatureId: legacy.Id,
+				VoterId:   voter,
+				Board:     board,
+				CastAt:    castAt,
+				Weight:    1,
+				Source:    "migration",
+			})
+		}
+
+		if len(docs) > 0 {
+			_, err := votes.InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
+			if err != nil && !onlyDuplicateErrors(err) {
+				mdb.logger.Errorf("database.MigrateVoteArrays: mongo.InsertMany >> %v", err)
+				return err
+			}
+		}
+
+		count, err := votes.CountDocuments(ctx, bson.M{"featureId": legacy.Id})
+		if err != nil {
+			mdb.logger.Errorf("database.MigrateVoteArrays: mongo.CountDocuments >> %v", err)
+			return err
+		}
+
+		_, err = features.UpdateOne(ctx, bson.M{"_id": legacy.Id}, bson.M{
+			"$set":   bson.M{"voteCount": count, "upvotes": count, "downvotes": 0, "score": count},
+			"$unset": bson.M{"votes": ""},
+		})
+		if err != nil {
+			mdb.logger.Errorf("database.MigrateVoteArrays: mongo.UpdateOne >> %v", err)
+			return err
+		}
+		migrated++
+	}
+
+	if err := cur.Err(); err != nil {
+		mdb.logger.Errorf("database.MigrateVoteArrays: mongo.Cursor >> %v", err)
+		return err
+	}
+
+	if migrated > 0 {
+		mdb.logger.Infof("database.MigrateVoteArrays: migrated votes for %d features", migrated)
+	}
+
+	return nil
+}
+
+// onlyDuplicateErrors reports whether every write error in a bulk insert was
+// a duplicate key, i.e. the documents were already there.
+func onlyDuplicateErrors(err error) bool {
+	bwEx := mongo.BulkWriteException{}
+	if !errors.As(err, &bwEx) || bwEx.WriteConcernError != nil {
+		return false
+	}
+
+	for _, we := range bwEx.WriteErrors {
+		if we.Code != 11000 {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,23 @@
+package database
+
+import (
+	"context"
+
+	"github.com/music-tribe/react-pairing-challenge/migrate"
+)
+
+// Migrations lists the changes made to stored documents, in the order they
+// were introduced. A released migration is never edited or renumbered;
+// changes to it go in a new one. The first five are the backfills that
+// used to run on every start up, so databases that already ran them pass
+// through harmlessly; they can't be rolled back, as the data they replace
+// is gone.
+func (mdb *MongoDatabase) Migrations() []migrate.Migration {
+	return []migrate.Migration{
+		{Version: 1, Name: "backfill_timestamps", Up: func(context.Context) error { return mdb.BackfillTimestamps() }},
+		{Version: 2, Name: "vote_documents", Up: func(context.Context) error { return mdb.MigrateVoteArrays() }},
+		{Version: 3, Name: "backfill_boards", Up: func(context.Context) error { return mdb.BackfillBoards() }},
+		{Version: 4, Name: "backfill_scores", Up: func(context.Context) error { return mdb.BackfillScores() }},
+		{Version: 5, Name: "backfill_statuses", Up: func(context.Context) error { return mdb.BackfillStatuses() }},
+	}
+}
@@ -2,6 +2,7 @@ package database
 
 import (
 	"context"
+	"time"
 
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -10,6 +11,7 @@ import (
 type MongoDatabase struct {
 	client *mongo.Client
 	logger MongoDatabaseLogger
+	clock  Clock
 }
 
 type MongoDatabaseLogger interface {
@@ -17,7 +19,20 @@ type MongoDatabaseLogger interface {
 	Errorf(string, ...interface{})
 }
 
-func OpenMongoConnection(url string, logger MongoDatabaseLogger) (*MongoDatabase, error) {
+// Clock returns the current time. It is swapped out in tests so that
+// server managed timestamps are predictable.
+type Clock func() time.Time
+
+type MongoDatabaseOption func(*MongoDatabase)
+
+// WithClock overrides the clock used to stamp created, updated and voted times.
+func WithClock(clock Clock) MongoDatabaseOption {
+	return func(mdb *MongoDatabase) {
+		mdb.clock = clock
+	}
+}
+
+func OpenMongoConnection(url string, logger MongoDatabaseLogger, opts ...MongoDatabaseOption) (*MongoDatabase, error) {
 	cli, err := mongo.Connect(context.Background(), options.Client().ApplyURI(url))
 	if err != nil {
 		logger.Errorf("OpenMongoConnection: failed to open new mgo.Session >> %v", err)
@@ -31,13 +46,35 @@ func OpenMongoConnection(url string, logger MongoDatabaseLogger) (*MongoDatabase
 
 	logger.Infof("OpenMongoConnection: MongoDB Connected")
 
-	return &MongoDatabase{
+	mdb := &MongoDatabase{
 		client: cli,
 		logger: logger,
-	}, nil
+		clock:  time.Now,
+	}
+
+	for _, opt := range opts {
+		opt(mdb)
+	}
+
+	if err := mdb.ensureIndexes(); err != nil {
+		return nil, err
+	}
+
+	return mdb, nil
 }
 
 func (mdb *MongoDatabase) CloseMongoConnection() error {
 	mdb.logger.Infof("CloseMongoConnection: MongoDB disconnected")
 	return mdb.client.Disconnect(context.TODO())
 }
+
+// Close disconnects from mongo, as Store requires.
+func (mdb *MongoDatabase) Close() error {
+	return mdb.CloseMongoConnection()
+}
+
+// now returns the clock's time at the precision mongo stores it, so values
+// read back compare equal to the ones we wrote.
+func (mdb *MongoDatabase) now() time.Time {
+	return mdb.clock().UTC().Truncate(time.Millisecond)
+}
@@ -0,0 +1,130 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// OutboxEntry is a domain event waiting to be delivered to subscribers,
+// along with its delivery progress.
+type OutboxEntry struct {
+	domain.Event `bson:",inline"`
+	// Delivered names the subscribers that have already handled the event.
+	Delivered     []string   `bson:"delivered,omitempty"`
+	Attempts      int        `bson:"attempts"`
+	NextAttemptAt time.Time  `bson:"nextAttemptAt"`
+	LastError     string     `bson:"lastError,omitempty"`
+	DispatchedAt  *time.Time `bson:"dispatchedAt,omitempty"`
+	DeadAt        *time.Time `bson:"deadAt,omitempty"`
+}
+
+// dueOutbox matches the undelivered events due an attempt at now.
+func dueOutbox(now time.Time) bson.M {
+	return bson.M{
+		"dispatchedAt":  bson.M{"$exists": false},
+		"deadAt":        bson.M{"$exists": false},
+		"nextAttemptAt": bson.M{"$lte": now},
+	}
+}
+
+// PendingOutbox returns up to limit undelivered events that are due an
+// attempt at now, oldest first, without claiming them.
+func (mdb *MongoDatabase) PendingOutbox(now time.Time, limit int64) ([]*OutboxEntry, error) {
+	coll := mdb.client.Database("pair-challenge").Collection("outbox")
+
+	filter := dueOutbox(now)
+	opts := options.Find().SetSort(bson.D{{Key: "occurredAt", Value: 1}}).SetLimit(limit)
+
+	q, err := coll.Find(context.Background(), filter, opts)
+	if err != nil {
+		mdb.logger.Errorf("database.PendingOutbox: mongo.Find >> %v", err)
+		return nil, err
+	}
+
+	es := make([]*OutboxEntry, 0)
+	if err = q.All(context.Background(), &es); err != nil {
+		mdb.logger.Errorf("database.PendingOutbox: mongo.All >> %v", err)
+		return nil, err
+	}
+
+	return es, nil
+}
+
+// ClaimOutbox claims up to limit undelivered events that are due an attempt
+// at now, oldest first, by moving their next attempt to until. Until then no
+// other dispatcher can claim them; should the claiming dispatcher stop
+// before settling them, they fall due again and are retried.
+func (mdb *MongoDatabase) ClaimOutbox(now, until time.Time, limit int64) ([]*OutboxEntry, error) {
+	coll := mdb.client.Database("pair-challenge").Collection("outbox")
+
+	update := bson.M{"$set": bson.M{"nextAttemptAt": until}}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "occurredAt", Value: 1}, {Key: "_id", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	// each claim is a single atomic update, so two dispatchers can't both
+	// win the same event
+	es := make([]*OutboxEntry, 0)
+	for int64(len(es)) < limit {
+		e := new(OutboxEntry)
+		if err := coll.FindOneAndUpdate(context.Background(), dueOutbox(now), update, opts).Decode(e); err != nil {
+			if err == mongo.ErrNoDocuments {
+				break
+			}
+			mdb.logger.Errorf("database.ClaimOutbox: mongo.FindOneAndUpdate >> %v", err)
+			return nil, err
+		}
+		es = append(es, e)
+	}
+
+	return es, nil
+}
+
+// MarkDelivered records that subscriber has handled the event.
+func (mdb *MongoDatabase) MarkDelivered(eventId uuid.UUID, subscriber string) error {
+	return mdb.updateOutbox("MarkDelivered", eventId, bson.M{"$addToSet": bson.M{"delivered": subscriber}})
+}
+
+// MarkDispatched records that every subscriber has handled the event.
+func (mdb *MongoDatabase) MarkDispatched(eventId uuid.UUID) error {
+	return mdb.updateOutbox("MarkDispatched", eventId, bson.M{"$set": bson.M{"dispatchedAt": mdb.now()}})
+}
+
+// RetryOutbox counts a failed delivery attempt and schedules the next one.
+func (mdb *MongoDatabase) RetryOutbox(eventId uuid.UUID, next time.Time, lastError string) error {
+	return mdb.updateOutbox("RetryOutbox", eventId, bson.M{
+		"$inc": bson.M{"attempts": 1},
+		"$set": bson.M{"nextAttemptAt": next, "lastError": lastError},
+	})
+}
+
+// MarkDead gives up on delivering the event after too many failed attempts.
+func (mdb *MongoDatabase) MarkDead(eventId uuid.UUID, lastError string) error {
+	return mdb.updateOutbox("MarkDead", eventId, bson.M{
+		"$inc": bson.M{"attempts": 1},
+		"$set": bson.M{"deadAt": mdb.now(), "lastError": lastError},
+	})
+}
+
+func (mdb *MongoDatabase) updateOutbox(op string, eventId uuid.UUID, update bson.M) error {
+	coll := mdb.client.Database("pair-challenge").Collection("outbox")
+
+	res, err := coll.UpdateOne(context.Background(), bson.M{"_id": eventId}, update)
+	if err != nil {
+		mdb.logger.Errorf("database.%s: mongo.UpdateOne >> %v", op, err)
+		return err
+	}
+
+	if res.MatchedCount == 0 {
+		mdb.logger.Errorf("database.%s: mongo.UpdateOne >> %v", op, ErrNotFound)
+		return ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,42 @@
+package database
+
+import (
+	"github.com/music-tribe/react-pairing-challenge/ranking"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// sortFields maps a ranking sort onto the stored fields it orders by.
+var sortFields = map[ranking.Sort]bson.D{
+	ranking.SortHot:    {{Key: "hotScore", Value: -1}, {Key: "_id", Value: 1}},
+	ranking.SortTop:    {{Key: "score", Value: -1}, {Key: "voteCount", Value: -1}, {Key: "_id", Value: 1}},
+	ranking.SortNew:    {{Key: "createdAt", Value: -1}, {Key: "_id", Value: 1}},
+	ranking.SortRising: {{Key: "risingScore", Value: -1}, {Key: "_id", Value: 1}},
+}
+
+// sortFor returns the mongo sort document for s, defaulting to newest first.
+func sortFor(s ranking.Sort) bson.D {
+	if d, ok := sortFields[s]; ok {
+		return d
+	}
+	return sortFields[ranking.SortNew]
+}
+
+// logAddExpExpr is the aggregation expression equivalent of
+// ranking.LogAddExp(field, x), for use in pipeline updates.
+func logAddExpExpr(field string, x float64) bson.M {
+	cur := "$" + field
+	hi := bson.M{"$max": bson.A{cur, x}}
+	lo := bson.M{"$min": bson.A{cur, x}}
+
+	return bson.M{"$cond": bson.A{
+		bson.M{"$eq": bson.A{bson.M{"$type": cur}, "missing"}},
+		x,
+		bson.M{"$add": bson.A{hi, bson.M{"$ln": bson.M{"$add": bson.A{1, bson.M{"$exp": bson.M{"$subtract": bson.A{lo, hi}}}}}}}},
+	}}
+}
+
+// incExpr is the aggregation expression equivalent of $inc, for use in
+// pipeline updates.
+func incExpr(field string, by int64) bson.M {
+	return bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$" + field, 0}}, by}}
+}
@@ -0,0 +1,32 @@
+package database
+
+import (
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/uuid"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// RecountVotes resets a feature's vote totals and scores from its stored
+// votes, repairing counts that have drifted. The recount is only audited,
+// as actor, when it changed something; changed reports whether it did.
+func (mdb *MongoDatabase) RecountVotes(featureId uuid.UUID, actor string) (feature *domain.Feature, changed bool, err error) {
+	err = mdb.transact(func(ctx mongo.SessionContext) error {
+		before, after, err := mdb.recount(ctx, featureId, nil)
+		if err != nil {
+			return err
+		}
+
+		feature = after
+		changed = len(domain.DiffFeatures(before, after)) > 0
+		if !changed {
+			return nil
+		}
+
+		return mdb.record(ctx, actor, domain.AuditUpdated, featureId, before, after)
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	return feature, changed, nil
+}
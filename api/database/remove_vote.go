@@ -0,0 +1,94 @@
+package database
+
+import (
+	"context"
+
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/react-pairing-challenge/ranking"
+	"github.com/music-tribe/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// RemoveVote withdraws voterId's vote from the feature. A vote can't be
+// subtracted from a decayed score, so the feature's count and scores are
+// recomputed from the votes that remain. Votes on a feature in the trash
+// stay as they are, and ErrNotFound is returned for them.
+func (mdb *MongoDatabase) RemoveVote(featureId, voterId uuid.UUID) (*domain.Feature, error) {
+	db := mdb.client.Database("pair-challenge")
+
+	var after *domain.Feature
+	err := mdb.transact(func(ctx mongo.SessionContext) error {
+		if _, err := mdb.findFeature(ctx, live(bson.M{"_id": featureId})); err != nil {
+			return err
+		}
+
+		res, err := db.Collection("votes").DeleteOne(ctx, bson.M{"featureId": featureId, "voterId": voterId})
+		if err != nil {
+			mdb.logger.Errorf("database.RemoveVote: mongo.DeleteOne >> %v", err)
+			return err
+		}
+
+		if res.DeletedCount == 0 {
+			mdb.logger.Errorf("database.RemoveVote: mongo.DeleteOne >> %v", ErrNotFound)
+			return ErrNotFound
+		}
+
+		var before *domain.Feature
+		if before, after, err = mdb.recount(ctx, featureId, nil); err != nil {
+			return err
+		}
+
+		return mdb.record(ctx, voterId.String(), domain.AuditUnvoted, featureId, before, after)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return after, nil
+}
+
+// recount resets a feature's vote totals and scores from its stored votes,
+// setting any extra fields in the same write. It returns the feature as it
+// was before and after the recount; features in the trash are recounted too.
+func (mdb *MongoDatabase) recount(ctx context.Context, featureId uuid.UUID, extra bson.M) (*domain.Feature, *domain.Feature, error) {
+	coll := mdb.client.Database("pair-challenge").Collection("features")
+
+	feature, err := mdb.findFeature(ctx, bson.M{"_id": featureId})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	votes, err := mdb.getVotes(ctx, featureId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tally := ranking.Count(votes)
+	scores := ranking.Recompute(feature.CreatedAt, votes)
+	set := bson.M{
+		"voteCount":   tally.Votes,
+		"upvotes":     tally.Upvotes,
+		"downvotes":   tally.Downvotes,
+		"score":       tally.Score,
+		"hotScore":    scores.Hot,
+		"risingScore": scores.Rising,
+	}
+	for k, v := range extra {
+		set[k] = v
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	q := coll.FindOneAndUpdate(ctx, bson.M{"_id": featureId}, bson.M{"$set": set}, opts)
+	t := new(domain.Feature)
+	if err := q.Decode(t); err != nil {
+		mdb.logger.Errorf("database.recount: mongo.Decode >> %v", err)
+		if err == mongo.ErrNoDocuments {
+			return nil, nil, ErrNotFound
+		}
+		return nil, nil, err
+	}
+
+	return feature, t, nil
+}
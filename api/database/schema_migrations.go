@@ -0,0 +1,100 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// migrationLockId is the _id of the lock document, which shares the
+// schema_migrations collection with the records keyed by version.
+const migrationLockId = "lock"
+
+// AppliedMigrations returns the recorded migrations, oldest version first.
+func (mdb *MongoDatabase) AppliedMigrations(ctx context.Context) ([]*domain.SchemaMigration, error) {
+	coll := mdb.client.Database("pair-challenge").Collection("schema_migrations")
+
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}})
+	cur, err := coll.Find(ctx, bson.M{"_id": bson.M{"$type": "number"}}, opts)
+	if err != nil {
+		mdb.logger.Errorf("database.AppliedMigrations: mongo.Find >> %v", err)
+		return nil, err
+	}
+
+	applied := make([]*domain.SchemaMigration, 0)
+	if err := cur.All(ctx, &applied); err != nil {
+		mdb.logger.Errorf("database.AppliedMigrations: mongo.Cursor >> %v", err)
+		return nil, err
+	}
+
+	return applied, nil
+}
+
+// RecordMigration records m as applied.
+func (mdb *MongoDatabase) RecordMigration(ctx context.Context, m *domain.SchemaMigration) error {
+	coll := mdb.client.Database("pair-challenge").Collection("schema_migrations")
+
+	opts := options.Replace().SetUpsert(true)
+	if _, err := coll.ReplaceOne(ctx, bson.M{"_id": m.Version}, m, opts); err != nil {
+		mdb.logger.Errorf("database.RecordMigration: mongo.ReplaceOne >> %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// RemoveMigration forgets that the migration version was applied.
+func (mdb *MongoDatabase) RemoveMigration(ctx context.Context, version int) error {
+	coll := mdb.client.Database("pair-challenge").Collection("schema_migrations")
+
+	if _, err := coll.DeleteOne(ctx, bson.M{"_id": version}); err != nil {
+		mdb.logger.Errorf("database.RemoveMigration: mongo.DeleteOne >> %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// LockMigrations takes or extends the migration lock for owner until
+// expires. It reports false while another owner holds a lock that hasn't
+// expired by now.
+func (mdb *MongoDatabase) LockMigrations(ctx context.Context, owner string, now, expires time.Time) (bool, error) {
+	coll := mdb.client.Database("pair-challenge").Collection("schema_migrations")
+
+	filter := bson.M{"_id": migrationLockId, "$or": bson.A{
+		bson.M{"owner": owner},
+		bson.M{"expiresAt": bson.M{"$lte": now}},
+	}}
+	update := bson.M{"$set": bson.M{"owner": owner, "lockedAt": now, "expiresAt": expires}}
+
+	// when someone else holds the lock the filter misses and the upsert
+	// collides with their document
+	_, err := coll.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		wrEx := mongo.WriteException{}
+		if errors.As(err, &wrEx) && wrEx.HasErrorCode(11000) {
+			return false, nil
+		}
+		mdb.logger.Errorf("database.LockMigrations: mongo.UpdateOne >> %v", err)
+		return false, err
+	}
+
+	return true, nil
+}
+
+// UnlockMigrations releases owner's migration lock, if it still holds it.
+func (mdb *MongoDatabase) UnlockMigrations(ctx context.Context, owner string) error {
+	coll := mdb.client.Database("pair-challenge").Collection("schema_migrations")
+
+	if _, err := coll.DeleteOne(ctx, bson.M{"_id": migrationLockId, "owner": owner}); err != nil {
+		mdb.logger.Errorf("database.UnlockMigrations: mongo.DeleteOne >> %v", err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,30 @@
+package database
+
+import (
+	"context"
+
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Search lists features from every user matching opts.
+func (mdb *MongoDatabase) Search(opts ListOptions) ([]*domain.Feature, error) {
+	coll := mdb.client.Database("pair-challenge").Collection("features")
+
+	findOpts := options.Find().SetSort(sortFor(opts.Sort))
+	q, err := coll.Find(context.Background(), listFilter(bson.M{}, opts), findOpts)
+	if err != nil {
+		mdb.logger.Errorf("database.Search: mongo.Find >> %v", err)
+		return nil, err
+	}
+
+	ts := make([]*domain.Feature, 0)
+
+	if err = q.All(context.Background(), &ts); err != nil {
+		mdb.logger.Errorf("database.Search: mongo.All >> %v", err)
+		return nil, err
+	}
+
+	return ts, nil
+}
@@ -0,0 +1,39 @@
+package database
+
+import (
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// SetStatus moves a feature to a new status on behalf of actor.
+func (mdb *MongoDatabase) SetStatus(featureId uuid.UUID, status domain.FeatureStatus, actor string) (*domain.Feature, error) {
+	coll := mdb.client.Database("pair-challenge").Collection("features")
+
+	now := mdb.now()
+	update := bson.M{"$set": bson.M{"status": status, "updatedAt": now}}
+
+	var after domain.Feature
+	err := mdb.transact(func(ctx mongo.SessionContext) error {
+		before := new(domain.Feature)
+		if err := coll.FindOneAndUpdate(ctx, live(bson.M{"_id": featureId}), update).Decode(before); err != nil {
+			mdb.logger.Errorf("database.SetStatus: mongo.Decode >> %v", err)
+			if err == mongo.ErrNoDocuments {
+				return ErrNotFound
+			}
+			return err
+		}
+
+		after = *before
+		after.Status = status
+		after.UpdatedAt = now
+
+		return mdb.record(ctx, actor, domain.AuditStatusChanged, featureId, before, &after)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &after, nil
+}
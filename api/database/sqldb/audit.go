@@ -0,0 +1,98 @@
+package sqldb
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/music-tribe/react-pairing-challenge/database"
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/uuid"
+)
+
+// auditColumns leave out seq, which only orders events recorded in the
+// same millisecond by when they were inserted.
+const auditColumns = "id, feature_id, actor, action, changes, at"
+
+func scanAudit(row scanner) (*domain.AuditEvent, error) {
+	e := new(domain.AuditEvent)
+	var changes string
+	var at int64
+	if err := row.Scan(&e.Id, &e.FeatureId, &e.Actor, &e.Action, &changes, &at); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(changes), &e.Changes); err != nil {
+		return nil, err
+	}
+	e.At = fromMillis(at)
+	return e, nil
+}
+
+func (db *DB) findAudit(ctx context.Context, c conn, op string, query string, args ...interface{}) ([]*domain.AuditEvent, error) {
+	rows, err := c.query(ctx, query, args...)
+	if err != nil {
+		db.logger.Errorf("sqldb.%s: sql.Query >> %v", op, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	es := make([]*domain.AuditEvent, 0)
+	for rows.Next() {
+		e, err := scanAudit(rows)
+		if err != nil {
+			db.logger.Errorf("sqldb.%s: sql.Scan >> %v", op, err)
+			return nil, err
+		}
+		es = append(es, e)
+	}
+	if err := rows.Err(); err != nil {
+		db.logger.Errorf("sqldb.%s: sql.Rows >> %v", op, err)
+		return nil, err
+	}
+
+	return es, nil
+}
+
+// GetHistory returns every audit event for a feature, oldest first.
+func (db *DB) GetHistory(featureId uuid.UUID) ([]*domain.AuditEvent, error) {
+	return db.findAudit(context.Background(), db.pool(), "GetHistory",
+		"SELECT "+auditColumns+" FROM audit WHERE feature_id = ? ORDER BY at, seq", featureId)
+}
+
+// QueryAudit returns the audit events matching filter, newest first.
+func (db *DB) QueryAudit(filter database.AuditFilter) ([]*domain.AuditEvent, error) {
+	conds := []string{}
+	args := []interface{}{}
+	if filter.FeatureId != uuid.Nil {
+		conds = append(conds, "feature_id = ?")
+		args = append(args, filter.FeatureId)
+	}
+	if filter.Actor != "" {
+		conds = append(conds, "actor = ?")
+		args = append(args, filter.Actor)
+	}
+	if filter.Action != "" {
+		conds = append(conds, "action = ?")
+		args = append(args, filter.Action)
+	}
+	if !filter.Since.IsZero() {
+		conds = append(conds, "at >= ?")
+		args = append(args, millis(filter.Since))
+	}
+	if !filter.Until.IsZero() {
+		conds = append(conds, "at < ?")
+		args = append(args, millis(filter.Until))
+	}
+
+	query := "SELECT " + auditColumns + " FROM audit"
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+	query += " ORDER BY at DESC, seq DESC"
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
+	}
+
+	return db.findAudit(context.Background(), db.pool(), "QueryAudit", query, args...)
+}
@@ -0,0 +1,161 @@
+package sqldb
+
+import (
+	"context"
+
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/uuid"
+)
+
+// snapshotBatch is how many features or audit events a snapshot reads at
+// a time.
+const snapshotBatch = 500
+
+// Snapshot walks every feature, in the trash or not, with all its votes
+// oldest first, then every audit event oldest first, all as they stood at
+// the moment it started. It stops at the first error either callback
+// returns.
+func (db *DB) Snapshot(ctx context.Context, features func(feature *domain.Feature, votes []*domain.Vote) error, audit func(event *domain.AuditEvent) error) error {
+	return db.snapshot(ctx, func(c conn) error {
+		for offset := 0; ; offset += snapshotBatch {
+			fs, err := db.findFeatures(ctx, c, "Snapshot",
+				"SELECT "+featureColumns+" FROM features f ORDER BY f.id LIMIT ? OFFSET ?", snapshotBatch, offset)
+			if err != nil {
+				return err
+			}
+
+			votes, err := db.votesOf(ctx, c, fs)
+			if err != nil {
+				return err
+			}
+
+			for _, f := range fs {
+				vs := votes[f.Id]
+				if vs == nil {
+					vs = []*domain.Vote{}
+				}
+				if err := features(f, vs); err != nil {
+					return err
+				}
+			}
+
+			if len(fs) < snapshotBatch {
+				break
+			}
+		}
+
+		for offset := 0; ; offset += snapshotBatch {
+			es, err := db.findAudit(ctx, c, "Snapshot",
+				"SELECT "+auditColumns+" FROM audit ORDER BY at, seq LIMIT ? OFFSET ?", snapshotBatch, offset)
+			if err != nil {
+				return err
+			}
+
+			for _, e := range es {
+				if err := audit(e); err != nil {
+					return err
+				}
+			}
+
+			if len(es) < snapshotBatch {
+				return nil
+			}
+		}
+	})
+}
+
+// RestoreFeatures writes a batch of features and their votes in one
+// transaction, exactly as given: unlike ImportFeatures nothing is reset or
+// recorded, so the features come back as they were backed up. Features
+// whose id is already taken are skipped along with their votes, and their
+// ids returned.
+func (db *DB) RestoreFeatures(ctx context.Context, features []*domain.Feature, votes []*domain.Vote) ([]uuid.UUID, error) {
+	ids := make([]uuid.UUID, len(features))
+	for i, f := range features {
+		ids[i] = f.Id
+	}
+
+	var skipped []uuid.UUID
+	err := db.transact(ctx, func(tx conn) error {
+		skipped = nil
+
+		existing, err := db.existingFeatureIds(ctx, tx, ids)
+		if err != nil {
+			return err
+		}
+
+		for _, f := range features {
+			if existing[f.Id] {
+				skipped = append(skipped, f.Id)
+				continue
+			}
+			if err := db.insertFeature(ctx, tx, f); err != nil {
+				return err
+			}
+		}
+
+		for _, v := range votes {
+			if existing[v.FeatureId] {
+				continue
+			}
+			if err := db.insertVote(ctx, tx, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return skipped, nil
+}
+
+// RestoreAudit writes a batch of audit events exactly as given, skipping
+// any whose id is already taken, and returns how many it wrote.
+func (db *DB) RestoreAudit(ctx context.Context, events []*domain.AuditEvent) (int, error) {
+	ids := make([]uuid.UUID, len(events))
+	for i, e := range events {
+		ids[i] = e.Id
+	}
+
+	var written int
+	err := db.transact(ctx, func(tx conn) error {
+		written = 0
+
+		existing, err := db.existingIds(ctx, tx, "audit", ids)
+		if err != nil {
+			return err
+		}
+
+		for _, e := range events {
+			if existing[e.Id] {
+				continue
+			}
+			if err := db.insertAudit(ctx, tx, e); err != nil {
+				return err
+			}
+			written++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return written, nil
+}
+
+// ClearFeatures deletes every feature, vote and audit event, ahead of a
+// restore replacing them.
+func (db *DB) ClearFeatures(ctx context.Context) error {
+	return db.transact(ctx, func(tx conn) error {
+		for _, table := range []string{"votes", "feature_tags", "features", "audit"} {
+			if _, err := tx.exec(ctx, "DELETE FROM "+table); err != nil {
+				db.logger.Errorf("sqldb.ClearFeatures: sql.Exec %s >> %v", table, err)
+				return err
+			}
+		}
+		return nil
+	})
+}
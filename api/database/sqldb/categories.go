@@ -0,0 +1,200 @@
+package sqldb
+
+import (
+	"context"
+
+	"github.com/music-tribe/react-pairing-challenge/domain"
+)
+
+const categoryColumns = "slug, name, description, created_at, updated_at"
+
+func scanCategory(row scanner) (*domain.Category, error) {
+	c := new(domain.Category)
+	var createdAt, updatedAt int64
+	if err := row.Scan(&c.Slug, &c.Name, &c.Description, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+	c.CreatedAt = fromMillis(createdAt)
+	c.UpdatedAt = fromMillis(updatedAt)
+	return c, nil
+}
+
+func (db *DB) AddCategory(category *domain.Category) error {
+	now := db.now()
+	category.CreatedAt = now
+	category.UpdatedAt = now
+
+	if _, err := db.pool().exec(context.Background(), "INSERT INTO categories ("+categoryColumns+") VALUES (?, ?, ?, ?, ?)",
+		category.Slug, category.Name, category.Description, millis(now), millis(now)); err != nil {
+		return db.duplicate("AddCategory: sql.Exec", err)
+	}
+
+	return nil
+}
+
+// UpdateCategory saves a category's name and description, refreshing
+// category with the stored row on success.
+func (db *DB) UpdateCategory(category *domain.Category) error {
+	ctx := context.Background()
+
+	var t *domain.Category
+	err := db.transact(ctx, func(tx conn) error {
+		res, err := tx.exec(ctx, "UPDATE categories SET name = ?, description = ?, updated_at = ? WHERE slug = ?",
+			category.Name, category.Description, millis(db.now()), category.Slug)
+		if err != nil {
+			db.logger.Errorf("sqldb.UpdateCategory: sql.Exec >> %v", err)
+			return err
+		}
+		if err := db.affected("UpdateCategory", res); err != nil {
+			return err
+		}
+
+		t, err = scanCategory(tx.queryRow(ctx, "SELECT "+categoryColumns+" FROM categories WHERE slug = ?", category.Slug))
+		if err != nil {
+			return db.notFound("UpdateCategory: sql.Scan", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	*category = *t
+	return nil
+}
+
+// DeleteCategory removes a category from the taxonomy and untags every
+// feature that carried it, recording each untagging as an update by the
+// system.
+func (db *DB) DeleteCategory(slug string) error {
+	ctx := context.Background()
+
+	return db.transact(ctx, func(tx conn) error {
+		res, err := tx.exec(ctx, "DELETE FROM categories WHERE slug = ?", slug)
+		if err != nil {
+			db.logger.Errorf("sqldb.DeleteCategory: sql.Exec >> %v", err)
+			return err
+		}
+		if err := db.affected("DeleteCategory", res); err != nil {
+			return err
+		}
+
+		tagged, err := db.findFeatures(ctx, tx, "DeleteCategory",
+			"SELECT "+featureColumns+" FROM features f WHERE f.id IN (SELECT feature_id FROM feature_tags WHERE tag = ?)"+db.dialect.ForUpdate, slug)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.exec(ctx, "DELETE FROM feature_tags WHERE tag = ?", slug); err != nil {
+			db.logger.Errorf("sqldb.DeleteCategory: sql.Exec >> %v", err)
+			return err
+		}
+
+		for _, before := range tagged {
+			after := *before
+			after.Tags = domain.WithoutTag(before.Tags, slug)
+			if err := db.record(ctx, tx, domain.SystemActor, domain.AuditUpdated, before.Id, before, &after); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetCategories returns the whole taxonomy ordered by slug.
+func (db *DB) GetCategories() ([]*domain.Category, error) {
+	rows, err := db.pool().query(context.Background(), "SELECT "+categoryColumns+" FROM categories ORDER BY slug")
+	if err != nil {
+		db.logger.Errorf("sqldb.GetCategories: sql.Query >> %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	cs := make([]*domain.Category, 0)
+	for rows.Next() {
+		c, err := scanCategory(rows)
+		if err != nil {
+			db.logger.Errorf("sqldb.GetCategories: sql.Scan >> %v", err)
+			return nil, err
+		}
+		cs = append(cs, c)
+	}
+	if err := rows.Err(); err != nil {
+		db.logger.Errorf("sqldb.GetCategories: sql.Rows >> %v", err)
+		return nil, err
+	}
+
+	return cs, nil
+}
+
+// MissingCategories returns the slugs that aren't in the taxonomy.
+func (db *DB) MissingCategories(slugs []string) ([]string, error) {
+	missing := make([]string, 0)
+	if len(slugs) == 0 {
+		return missing, nil
+	}
+
+	args := make([]interface{}, len(slugs))
+	for i, slug := range slugs {
+		args[i] = slug
+	}
+
+	rows, err := db.pool().query(context.Background(), "SELECT slug FROM categories WHERE slug IN "+in(len(args)), args...)
+	if err != nil {
+		db.logger.Errorf("sqldb.MissingCategories: sql.Query >> %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	exists := map[string]bool{}
+	for rows.Next() {
+		var slug string
+		if err := rows.Scan(&slug); err != nil {
+			db.logger.Errorf("sqldb.MissingCategories: sql.Scan >> %v", err)
+			return nil, err
+		}
+		exists[slug] = true
+	}
+	if err := rows.Err(); err != nil {
+		db.logger.Errorf("sqldb.MissingCategories: sql.Rows >> %v", err)
+		return nil, err
+	}
+
+	for _, slug := range slugs {
+		if !exists[slug] {
+			missing = append(missing, slug)
+		}
+	}
+
+	return missing, nil
+}
+
+// TagCounts returns how many features carry each tag.
+func (db *DB) TagCounts() (map[string]int64, error) {
+	rows, err := db.pool().query(context.Background(), `SELECT t.tag, COUNT(*) FROM feature_tags t
+		JOIN features f ON f.id = t.feature_id
+		WHERE f.deleted_at IS NULL
+		GROUP BY t.tag`)
+	if err != nil {
+		db.logger.Errorf("sqldb.TagCounts: sql.Query >> %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := map[string]int64{}
+	for rows.Next() {
+		var tag string
+		var count int64
+		if err := rows.Scan(&tag, &count); err != nil {
+			db.logger.Errorf("sqldb.TagCounts: sql.Scan >> %v", err)
+			return nil, err
+		}
+		counts[tag] = count
+	}
+	if err := rows.Err(); err != nil {
+		db.logger.Errorf("sqldb.TagCounts: sql.Rows >> %v", err)
+		return nil, err
+	}
+
+	return counts, nil
+}
@@ -0,0 +1,324 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/music-tribe/react-pairing-challenge/database"
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/react-pairing-challenge/ranking"
+	"github.com/music-tribe/uuid"
+)
+
+// featureColumns are selected, in scanFeature's order, from features f.
+const featureColumns = `f.id, f.user_id, f.name, f.description, f.board, f.status,
+	f.vote_count, f.upvotes, f.downvotes, f.score, f.hot_score, f.rising_score,
+	f.created_at, f.updated_at, f.last_voted_at, f.deleted_at`
+
+// tagBatch is how many features' tags are loaded in one query.
+const tagBatch = 500
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanFeature reads a row of featureColumns. Tags are stored apart and
+// loaded by loadTags.
+func scanFeature(row scanner) (*domain.Feature, error) {
+	f := new(domain.Feature)
+	var createdAt, updatedAt int64
+	var lastVotedAt, deletedAt sql.NullInt64
+	err := row.Scan(&f.Id, &f.UserId, &f.Name, &f.Description, &f.Board, &f.Status,
+		&f.VoteCount, &f.Upvotes, &f.Downvotes, &f.Score, &f.HotScore, &f.RisingScore,
+		&createdAt, &updatedAt, &lastVotedAt, &deletedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	f.CreatedAt = fromMillis(createdAt)
+	f.UpdatedAt = fromMillis(updatedAt)
+	f.LastVotedAt = fromNullMillis(lastVotedAt)
+	f.DeletedAt = fromNullMillis(deletedAt)
+	return f, nil
+}
+
+// findFeature loads the single feature matching the where clause, locking
+// its row until the transaction ends when lock is set.
+func (db *DB) findFeature(ctx context.Context, c conn, op string, lock bool, where string, args ...interface{}) (*domain.Feature, error) {
+	query := "SELECT " + featureColumns + " FROM features f WHERE " + where
+	if lock {
+		query += db.dialect.ForUpdate
+	}
+
+	f, err := scanFeature(c.queryRow(ctx, query, args...))
+	if err != nil {
+		return nil, db.notFound(op+": sql.Scan", err)
+	}
+
+	if err := db.loadTags(ctx, c, []*domain.Feature{f}); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// findFeatures loads every feature query selects, with their tags.
+func (db *DB) findFeatures(ctx context.Context, c conn, op string, query string, args ...interface{}) ([]*domain.Feature, error) {
+	rows, err := c.query(ctx, query, args...)
+	if err != nil {
+		db.logger.Errorf("sqldb.%s: sql.Query >> %v", op, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	fs := make([]*domain.Feature, 0)
+	for rows.Next() {
+		f, err := scanFeature(rows)
+		if err != nil {
+			db.logger.Errorf("sqldb.%s: sql.Scan >> %v", op, err)
+			return nil, err
+		}
+		fs = append(fs, f)
+	}
+	if err := rows.Err(); err != nil {
+		db.logger.Errorf("sqldb.%s: sql.Rows >> %v", op, err)
+		return nil, err
+	}
+	rows.Close()
+
+	if err := db.loadTags(ctx, c, fs); err != nil {
+		return nil, err
+	}
+	return fs, nil
+}
+
+// loadTags fills in the tags of features, in the order they were given.
+func (db *DB) loadTags(ctx context.Context, c conn, features []*domain.Feature) error {
+	for start := 0; start < len(features); start += tagBatch {
+		end := start + tagBatch
+		if end > len(features) {
+			end = len(features)
+		}
+		batch := features[start:end]
+
+		byId := make(map[uuid.UUID]*domain.Feature, len(batch))
+		args := make([]interface{}, len(batch))
+		for i, f := range batch {
+			byId[f.Id] = f
+			args[i] = f.Id
+		}
+
+		rows, err := c.query(ctx, "SELECT feature_id, tag FROM feature_tags WHERE feature_id IN "+in(len(args))+" ORDER BY feature_id, position", args...)
+		if err != nil {
+			db.logger.Errorf("sqldb.loadTags: sql.Query >> %v", err)
+			return err
+		}
+
+		for rows.Next() {
+			var id uuid.UUID
+			var tag string
+			if err := rows.Scan(&id, &tag); err != nil {
+				rows.Close()
+				db.logger.Errorf("sqldb.loadTags: sql.Scan >> %v", err)
+				return err
+			}
+			if f := byId[id]; f != nil {
+				f.Tags = append(f.Tags, tag)
+			}
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			db.logger.Errorf("sqldb.loadTags: sql.Rows >> %v", err)
+			return err
+		}
+	}
+	return nil
+}
+
+// saveTags replaces the tags of the feature.
+func (db *DB) saveTags(ctx context.Context, c conn, featureId uuid.UUID, tags []string) error {
+	if _, err := c.exec(ctx, "DELETE FROM feature_tags WHERE feature_id = ?", featureId); err != nil {
+		db.logger.Errorf("sqldb.saveTags: sql.Exec >> %v", err)
+		return err
+	}
+
+	for i, tag := range tags {
+		if _, err := c.exec(ctx, "INSERT INTO feature_tags (feature_id, tag, position) VALUES (?, ?, ?)", featureId, tag, i); err != nil {
+			db.logger.Errorf("sqldb.saveTags: sql.Exec >> %v", err)
+			return err
+		}
+	}
+	return nil
+}
+
+// insertFeature stores a new feature with its tags. ErrDuplicate is
+// returned when the id is taken.
+func (db *DB) insertFeature(ctx context.Context, c conn, f *domain.Feature) error {
+	_, err := c.exec(ctx, `INSERT INTO features (id, user_id, name, description, board, status,
+			vote_count, upvotes, downvotes, score, hot_score, rising_score,
+			created_at, updated_at, last_voted_at, deleted_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		f.Id, f.UserId, f.Name, f.Description, f.Board, f.Status,
+		f.VoteCount, f.Upvotes, f.Downvotes, f.Score, f.HotScore, f.RisingScore,
+		millis(f.CreatedAt), millis(f.UpdatedAt), nullMillis(f.LastVotedAt), nullMillis(f.DeletedAt))
+	if err != nil {
+		return db.duplicate("insertFeature: sql.Exec", err)
+	}
+
+	return db.saveTags(ctx, c, f.Id, f.Tags)
+}
+
+// saveFeature writes every stored field of f but its tags, which only
+// change through saveTags.
+func (db *DB) saveFeature(ctx context.Context, c conn, f *domain.Feature) error {
+	res, err := c.exec(ctx, `UPDATE features SET name = ?, description = ?, board = ?, status = ?,
+			vote_count = ?, upvotes = ?, downvotes = ?, score = ?, hot_score = ?, rising_score = ?,
+			updated_at = ?, last_voted_at = ?, deleted_at = ?
+		WHERE id = ?`,
+		f.Name, f.Description, f.Board, f.Status,
+		f.VoteCount, f.Upvotes, f.Downvotes, f.Score, f.HotScore, f.RisingScore,
+		millis(f.UpdatedAt), nullMillis(f.LastVotedAt), nullMillis(f.DeletedAt), f.Id)
+	if err != nil {
+		db.logger.Errorf("sqldb.saveFeature: sql.Exec >> %v", err)
+		return err
+	}
+	return db.affected("saveFeature", res)
+}
+
+func (db *DB) Add(feature *domain.Feature) error {
+	now := db.now()
+	feature.CreatedAt = now
+	feature.UpdatedAt = now
+	feature.VoteCount = 0
+	feature.Upvotes = 0
+	feature.Downvotes = 0
+	feature.Score = 0
+	feature.LastVotedAt = nil
+	feature.DeletedAt = nil
+	feature.Status = domain.StatusOpen
+
+	scores := ranking.Initial(now)
+	feature.HotScore = scores.Hot
+	feature.RisingScore = scores.Rising
+
+	ctx := context.Background()
+	return db.transact(ctx, func(tx conn) error {
+		if err := db.insertFeature(ctx, tx, feature); err != nil {
+			return err
+		}
+
+		return db.record(ctx, tx, feature.UserId.String(), domain.AuditCreated, feature.Id, nil, feature)
+	})
+}
+
+func (db *DB) Get(userId, id uuid.UUID) (*domain.Feature, error) {
+	return db.findFeature(context.Background(), db.pool(), "Get", false,
+		"f.id = ? AND f.user_id = ? AND f.deleted_at IS NULL", id, userId)
+}
+
+func (db *DB) GetById(featureId uuid.UUID) (*domain.Feature, error) {
+	return db.findFeature(context.Background(), db.pool(), "GetById", false,
+		"f.id = ? AND f.deleted_at IS NULL", featureId)
+}
+
+func (db *DB) GetAll(userId uuid.UUID, opts database.ListOptions) ([]*domain.Feature, error) {
+	where, args := db.listWhere(opts, "f.user_id = ?", userId)
+	return db.findFeatures(context.Background(), db.pool(), "GetAll",
+		"SELECT "+featureColumns+" FROM features f WHERE "+where+" ORDER BY "+orderBy(opts.Sort), args...)
+}
+
+// Search lists features from every user matching opts.
+func (db *DB) Search(opts database.ListOptions) ([]*domain.Feature, error) {
+	where, args := db.listWhere(opts, "")
+	return db.findFeatures(context.Background(), db.pool(), "Search",
+		"SELECT "+featureColumns+" FROM features f WHERE "+where+" ORDER BY "+orderBy(opts.Sort), args...)
+}
+
+// Update saves the user editable fields of feature and stamps its UpdatedAt.
+// Server managed fields such as votes and CreatedAt are never overwritten;
+// feature is refreshed with the stored row on success.
+func (db *DB) Update(feature *domain.Feature) error {
+	ctx := context.Background()
+
+	var after domain.Feature
+	err := db.transact(ctx, func(tx conn) error {
+		before, err := db.findFeature(ctx, tx, "Update", true,
+			"f.id = ? AND f.user_id = ? AND f.deleted_at IS NULL", feature.Id, feature.UserId)
+		if err != nil {
+			return err
+		}
+
+		after = *before
+		after.Name = feature.Name
+		after.Description = feature.Description
+		after.Tags = feature.Tags
+		after.UpdatedAt = db.now()
+
+		if err := db.saveFeature(ctx, tx, &after); err != nil {
+			return err
+		}
+		if err := db.saveTags(ctx, tx, after.Id, after.Tags); err != nil {
+			return err
+		}
+
+		return db.record(ctx, tx, feature.UserId.String(), domain.AuditUpdated, feature.Id, before, &after)
+	})
+	if err != nil {
+		return err
+	}
+
+	*feature = after
+	return nil
+}
+
+// Delete moves a feature to its owner's trash. The feature and its votes are
+// kept until Restore brings it back or PurgeDeleted removes it for good.
+func (db *DB) Delete(userId, featureId uuid.UUID) error {
+	ctx := context.Background()
+
+	return db.transact(ctx, func(tx conn) error {
+		before, err := db.findFeature(ctx, tx, "Delete", true,
+			"f.id = ? AND f.user_id = ? AND f.deleted_at IS NULL", featureId, userId)
+		if err != nil {
+			return err
+		}
+
+		now := db.now()
+		after := *before
+		after.DeletedAt = &now
+
+		if err := db.saveFeature(ctx, tx, &after); err != nil {
+			return err
+		}
+
+		return db.record(ctx, tx, userId.String(), domain.AuditDeleted, featureId, before, &after)
+	})
+}
+
+// SetStatus moves a feature to a new status on behalf of actor.
+func (db *DB) SetStatus(featureId uuid.UUID, status domain.FeatureStatus, actor string) (*domain.Feature, error) {
+	ctx := context.Background()
+
+	var after domain.Feature
+	err := db.transact(ctx, func(tx conn) error {
+		before, err := db.findFeature(ctx, tx, "SetStatus", true, "f.id = ? AND f.deleted_at IS NULL", featureId)
+		if err != nil {
+			return err
+		}
+
+		after = *before
+		after.Status = status
+		after.UpdatedAt = db.now()
+
+		if err := db.saveFeature(ctx, tx, &after); err != nil {
+			return err
+		}
+
+		return db.record(ctx, tx, actor, domain.AuditStatusChanged, featureId, before, &after)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &after, nil
+}
@@ -0,0 +1,80 @@
+package sqldb
+
+import (
+	"context"
+
+	"github.com/music-tribe/react-pairing-challenge/domain"
+)
+
+// ReserveIdempotencyKey claims req.Key for a request about to be handled.
+// ErrDuplicate is returned while another request holds the key or its
+// response is still stored; an expired claim is taken over.
+func (db *DB) ReserveIdempotencyKey(req *domain.IdempotentRequest) error {
+	ctx := context.Background()
+
+	req.CreatedAt = db.now()
+
+	return db.transact(ctx, func(tx conn) error {
+		// expired keys are only cleared out by PurgeDeleted, so one may
+		// still be held by a request that has expired
+		if _, err := tx.exec(ctx, "DELETE FROM idempotency_keys WHERE key = ? AND expires_at <= ?", req.Key, millis(req.CreatedAt)); err != nil {
+			db.logger.Errorf("sqldb.ReserveIdempotencyKey: sql.Exec >> %v", err)
+			return err
+		}
+
+		_, err := tx.exec(ctx, `INSERT INTO idempotency_keys (key, fingerprint, status, content_type, body, created_at, expires_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			req.Key, req.Fingerprint, req.Status, req.ContentType, req.Body, millis(req.CreatedAt), millis(req.ExpiresAt))
+		if err != nil {
+			return db.duplicate("ReserveIdempotencyKey: sql.Exec", err)
+		}
+		return nil
+	})
+}
+
+// GetIdempotencyKey returns the request holding key.
+func (db *DB) GetIdempotencyKey(key string) (*domain.IdempotentRequest, error) {
+	req := domain.IdempotentRequest{}
+	var createdAt, expiresAt int64
+	err := db.pool().queryRow(context.Background(),
+		"SELECT key, fingerprint, status, content_type, body, created_at, expires_at FROM idempotency_keys WHERE key = ?", key).
+		Scan(&req.Key, &req.Fingerprint, &req.Status, &req.ContentType, &req.Body, &createdAt, &expiresAt)
+	if err != nil {
+		return nil, db.notFound("GetIdempotencyKey: sql.Scan", err)
+	}
+
+	req.CreatedAt = fromMillis(createdAt)
+	req.ExpiresAt = fromMillis(expiresAt)
+	return &req, nil
+}
+
+// CompleteIdempotencyKey stores the response to req, keeping it until
+// req.ExpiresAt. ErrNotFound is returned if req no longer holds its key.
+func (db *DB) CompleteIdempotencyKey(req *domain.IdempotentRequest) error {
+	res, err := db.pool().exec(context.Background(),
+		"UPDATE idempotency_keys SET status = ?, content_type = ?, body = ?, expires_at = ? WHERE key = ? AND created_at = ?",
+		req.Status, req.ContentType, req.Body, millis(req.ExpiresAt), req.Key, millis(req.CreatedAt))
+	if err != nil {
+		db.logger.Errorf("sqldb.CompleteIdempotencyKey: sql.Exec >> %v", err)
+		return err
+	}
+
+	if err := db.affected("CompleteIdempotencyKey", res); err != nil {
+		db.logger.Errorf("sqldb.CompleteIdempotencyKey: sql.Exec >> %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// ReleaseIdempotencyKey frees the key held by req after it failed, so the
+// request may be retried.
+func (db *DB) ReleaseIdempotencyKey(req *domain.IdempotentRequest) error {
+	if _, err := db.pool().exec(context.Background(),
+		"DELETE FROM idempotency_keys WHERE key = ? AND created_at = ? AND status = 0", req.Key, millis(req.CreatedAt)); err != nil {
+		db.logger.Errorf("sqldb.ReleaseIdempotencyKey: sql.Exec >> %v", err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,224 @@
+package sqldb
+
+import (
+	"context"
+
+	"github.com/music-tribe/react-pairing-challenge/database"
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/react-pairing-challenge/ranking"
+	"github.com/music-tribe/uuid"
+)
+
+// ImportFeatures adds a batch of features in one transaction, recording each
+// as created by actor. Features whose id is already taken, including by one
+// in the trash, are skipped and their ids returned.
+//
+// Unlike Add, an imported feature keeps the status and creation time it was
+// given, so requests moved from another tool don't all start over as new.
+func (db *DB) ImportFeatures(actor string, features []*domain.Feature) ([]uuid.UUID, error) {
+	ctx := context.Background()
+
+	now := db.now()
+	for _, feature := range features {
+		if feature.CreatedAt.IsZero() {
+			feature.CreatedAt = now
+		}
+		feature.UpdatedAt = now
+		feature.VoteCount = 0
+		feature.Upvotes = 0
+		feature.Downvotes = 0
+		feature.Score = 0
+		feature.LastVotedAt = nil
+		feature.DeletedAt = nil
+		if feature.Status == "" {
+			feature.Status = domain.StatusOpen
+		}
+
+		scores := ranking.Initial(feature.CreatedAt)
+		feature.HotScore = scores.Hot
+		feature.RisingScore = scores.Rising
+	}
+
+	var duplicates []uuid.UUID
+	err := db.transact(ctx, func(tx conn) error {
+		ids := make([]uuid.UUID, len(features))
+		for i, feature := range features {
+			ids[i] = feature.Id
+		}
+
+		existing, err := db.existingFeatureIds(ctx, tx, ids)
+		if err != nil {
+			return err
+		}
+
+		for _, feature := range features {
+			if existing[feature.Id] {
+				duplicates = append(duplicates, feature.Id)
+				continue
+			}
+
+			if err := db.insertFeature(ctx, tx, feature); err != nil {
+				return err
+			}
+			if err := db.record(ctx, tx, actor, domain.AuditCreated, feature.Id, nil, feature); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return duplicates, nil
+}
+
+// ExistingFeatureIds returns which of ids are already taken by a feature,
+// including one in the trash.
+func (db *DB) ExistingFeatureIds(ids []uuid.UUID) ([]uuid.UUID, error) {
+	existing, err := db.existingFeatureIds(context.Background(), db.pool(), ids)
+	if err != nil {
+		return nil, err
+	}
+
+	taken := make([]uuid.UUID, 0, len(existing))
+	for _, id := range ids {
+		if existing[id] {
+			taken = append(taken, id)
+		}
+	}
+
+	return taken, nil
+}
+
+func (db *DB) existingFeatureIds(ctx context.Context, c conn, ids []uuid.UUID) (map[uuid.UUID]bool, error) {
+	return db.existingIds(ctx, c, "features", ids)
+}
+
+// existingIds returns which of ids are already taken in table.
+func (db *DB) existingIds(ctx context.Context, c conn, table string, ids []uuid.UUID) (map[uuid.UUID]bool, error) {
+	existing := make(map[uuid.UUID]bool, len(ids))
+
+	for start := 0; start < len(ids); start += tagBatch {
+		end := start + tagBatch
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		args := make([]interface{}, 0, end-start)
+		for _, id := range ids[start:end] {
+			args = append(args, id)
+		}
+
+		rows, err := c.query(ctx, "SELECT id FROM "+table+" WHERE id IN "+in(len(args)), args...)
+		if err != nil {
+			db.logger.Errorf("sqldb.existingIds: sql.Query >> %v", err)
+			return nil, err
+		}
+
+		for rows.Next() {
+			var id uuid.UUID
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				db.logger.Errorf("sqldb.existingIds: sql.Scan >> %v", err)
+				return nil, err
+			}
+			existing[id] = true
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			db.logger.Errorf("sqldb.existingIds: sql.Rows >> %v", err)
+			return nil, err
+		}
+	}
+
+	return existing, nil
+}
+
+// MergeFeatures folds the duplicate feature sourceId into targetId on behalf
+// of actor. The source's votes move to the target, except where the voter
+// has already voted for the target, whose vote is kept; the target is then
+// recounted and the source moved to its owner's trash. Moved votes keep
+// their weight but take the target's board, so a merge across boards can
+// leave a voter over their budget there.
+func (db *DB) MergeFeatures(targetId, sourceId uuid.UUID, actor string) (*domain.Feature, error) {
+	if targetId == sourceId {
+		return nil, database.ErrSameFeature
+	}
+
+	ctx := context.Background()
+
+	now := db.now()
+	var merged *domain.Feature
+	err := db.transact(ctx, func(tx conn) error {
+		target, err := db.findFeature(ctx, tx, "MergeFeatures", true, "f.id = ? AND f.deleted_at IS NULL", targetId)
+		if err != nil {
+			return err
+		}
+		if _, err := db.findFeature(ctx, tx, "MergeFeatures", true, "f.id = ? AND f.deleted_at IS NULL", sourceId); err != nil {
+			return err
+		}
+
+		if _, err := tx.exec(ctx, `DELETE FROM votes WHERE feature_id = ?
+			AND voter_id IN (SELECT t.voter_id FROM votes t WHERE t.feature_id = ?)`, sourceId, targetId); err != nil {
+			db.logger.Errorf("sqldb.MergeFeatures: sql.Exec >> %v", err)
+			return err
+		}
+
+		if _, err := tx.exec(ctx, "UPDATE votes SET feature_id = ?, board = ? WHERE feature_id = ?", targetId, target.Board, sourceId); err != nil {
+			db.logger.Errorf("sqldb.MergeFeatures: sql.Exec >> %v", err)
+			return err
+		}
+
+		before, after, err := db.recount(ctx, tx, targetId, func(f *domain.Feature) { f.UpdatedAt = now })
+		if err != nil {
+			return err
+		}
+		if err := db.record(ctx, tx, actor, domain.AuditUpdated, targetId, before, after); err != nil {
+			return err
+		}
+		merged = after
+
+		// the source is left with no votes, and can be restored empty if
+		// the merge was a mistake
+		sourceBefore, sourceAfter, err := db.recount(ctx, tx, sourceId, func(f *domain.Feature) { f.DeletedAt = &now })
+		if err != nil {
+			return err
+		}
+		return db.record(ctx, tx, actor, domain.AuditDeleted, sourceId, sourceBefore, sourceAfter)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return merged, nil
+}
+
+// RecountVotes resets a feature's vote totals and scores from its stored
+// votes, repairing counts that have drifted. The recount is only audited,
+// as actor, when it changed something; changed reports whether it did.
+func (db *DB) RecountVotes(featureId uuid.UUID, actor string) (feature *domain.Feature, changed bool, err error) {
+	ctx := context.Background()
+
+	err = db.transact(ctx, func(tx conn) error {
+		before, after, err := db.recount(ctx, tx, featureId, nil)
+		if err != nil {
+			return err
+		}
+
+		feature = after
+		changed = len(domain.DiffFeatures(before, after)) > 0
+		if !changed {
+			return nil
+		}
+
+		return db.record(ctx, tx, actor, domain.AuditUpdated, featureId, before, after)
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	return feature, changed, nil
+}
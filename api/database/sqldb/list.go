@@ -0,0 +1,135 @@
+package sqldb
+
+import (
+	"context"
+	"strings"
+
+	"github.com/music-tribe/react-pairing-challenge/database"
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/react-pairing-challenge/ranking"
+	"github.com/music-tribe/uuid"
+)
+
+// eachFeatureBatch is how many features are fetched at a time.
+const eachFeatureBatch = 500
+
+// sortColumns maps a ranking sort onto the columns it orders by.
+var sortColumns = map[ranking.Sort]string{
+	ranking.SortHot:    "f.hot_score DESC, f.id",
+	ranking.SortTop:    "f.score DESC, f.vote_count DESC, f.id",
+	ranking.SortNew:    "f.created_at DESC, f.id",
+	ranking.SortRising: "f.rising_score DESC, f.id",
+}
+
+// orderBy returns the ORDER BY clause for s, defaulting to newest first.
+func orderBy(s ranking.Sort) string {
+	if o, ok := sortColumns[s]; ok {
+		return o
+	}
+	return sortColumns[ranking.SortNew]
+}
+
+// listWhere builds the condition on live features f for opts on top of
+// base, which may be empty, and its arguments.
+func (db *DB) listWhere(opts database.ListOptions, base string, baseArgs ...interface{}) (string, []interface{}) {
+	conds := []string{"f.deleted_at IS NULL"}
+	args := []interface{}{}
+	if base != "" {
+		conds = append(conds, base)
+		args = append(args, baseArgs...)
+	}
+
+	if opts.Board != "" {
+		conds = append(conds, "f.board = ?")
+		args = append(args, opts.Board)
+	}
+
+	if tags := distinct(opts.Tags); len(tags) > 0 {
+		for _, tag := range tags {
+			args = append(args, tag)
+		}
+		if opts.MatchAllTags {
+			conds = append(conds, "(SELECT COUNT(*) FROM feature_tags t WHERE t.feature_id = f.id AND t.tag IN "+in(len(tags))+") = ?")
+			args = append(args, len(tags))
+		} else {
+			conds = append(conds, "f.id IN (SELECT t.feature_id FROM feature_tags t WHERE t.tag IN "+in(len(tags))+")")
+		}
+	}
+
+	if opts.Status != "" {
+		conds = append(conds, "f.status = ?")
+		args = append(args, opts.Status)
+	}
+
+	// like mongo's $text, a feature matches any of the words
+	if words := strings.Fields(opts.Text); len(words) > 0 {
+		matches := make([]string, len(words))
+		for i, word := range words {
+			matches[i] = db.dialect.TextSearch
+			args = append(args, word)
+		}
+		conds = append(conds, "("+strings.Join(matches, " OR ")+")")
+	}
+
+	return strings.Join(conds, " AND "), args
+}
+
+// EachFeature calls fn for every feature from every user matching opts, one
+// at a time, so any number of features can be walked without holding them
+// all in memory. With withVotes fn also gets each feature's votes, oldest
+// first; otherwise votes is nil. It stops at the first error fn returns.
+//
+// Features are read a page at a time, so ones added or removed during the
+// walk may be missed or seen twice.
+func (db *DB) EachFeature(ctx context.Context, opts database.ListOptions, withVotes bool, fn func(feature *domain.Feature, votes []*domain.Vote) error) error {
+	where, args := db.listWhere(opts, "")
+	query := "SELECT " + featureColumns + " FROM features f WHERE " + where + " ORDER BY " + orderBy(opts.Sort) + " LIMIT ? OFFSET ?"
+
+	for offset := 0; ; offset += eachFeatureBatch {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		page, err := db.findFeatures(ctx, db.pool(), "EachFeature", query, append(args, eachFeatureBatch, offset)...)
+		if err != nil {
+			return err
+		}
+
+		var votes map[uuid.UUID][]*domain.Vote
+		if withVotes {
+			if votes, err = db.votesOf(ctx, db.pool(), page); err != nil {
+				return err
+			}
+		}
+
+		for _, f := range page {
+			var vs []*domain.Vote
+			if withVotes {
+				vs = votes[f.Id]
+				if vs == nil {
+					vs = []*domain.Vote{}
+				}
+			}
+			if err := fn(f, vs); err != nil {
+				return err
+			}
+		}
+
+		if len(page) < eachFeatureBatch {
+			return nil
+		}
+	}
+}
+
+// distinct drops repeated values, keeping the first of each.
+func distinct(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,135 @@
+package sqldb
+
+import (
+	"context"
+	"time"
+
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/react-pairing-challenge/migrate"
+)
+
+// Migrations lists the dialect's schema migrations. Each runs in its own
+// transaction. A released migration is never edited or renumbered; changes
+// to it go in a new one.
+func (db *DB) Migrations() []migrate.Migration {
+	ms := make([]migrate.Migration, len(db.dialect.Schema))
+	for i, s := range db.dialect.Schema {
+		ms[i] = migrate.Migration{
+			Version: s.Version,
+			Name:    s.Name,
+			Up:      db.runStatements(s.Up),
+		}
+		if s.Down != nil {
+			ms[i].Down = db.runStatements(s.Down)
+		}
+	}
+	return ms
+}
+
+func (db *DB) runStatements(stmts []string) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		return db.transact(ctx, func(tx conn) error {
+			for _, stmt := range stmts {
+				if _, err := tx.exec(ctx, stmt); err != nil {
+					db.logger.Errorf("sqldb.Migrations: sql.Exec >> %v", err)
+					return err
+				}
+			}
+			return nil
+		})
+	}
+}
+
+// AppliedMigrations returns the recorded migrations, oldest version first.
+func (db *DB) AppliedMigrations(ctx context.Context) ([]*domain.SchemaMigration, error) {
+	rows, err := db.pool().query(ctx, "SELECT version, name, applied_at, duration FROM schema_migrations ORDER BY version")
+	if err != nil {
+		db.logger.Errorf("sqldb.AppliedMigrations: sql.Query >> %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make([]*domain.SchemaMigration, 0)
+	for rows.Next() {
+		m := new(domain.SchemaMigration)
+		var appliedAt int64
+		if err := rows.Scan(&m.Version, &m.Name, &appliedAt, &m.Duration); err != nil {
+			db.logger.Errorf("sqldb.AppliedMigrations: sql.Scan >> %v", err)
+			return nil, err
+		}
+		m.AppliedAt = fromMillis(appliedAt)
+		applied = append(applied, m)
+	}
+	if err := rows.Err(); err != nil {
+		db.logger.Errorf("sqldb.AppliedMigrations: sql.Rows >> %v", err)
+		return nil, err
+	}
+
+	return applied, nil
+}
+
+// RecordMigration records m as applied.
+func (db *DB) RecordMigration(ctx context.Context, m *domain.SchemaMigration) error {
+	return db.transact(ctx, func(tx conn) error {
+		if _, err := tx.exec(ctx, "DELETE FROM schema_migrations WHERE version = ?", m.Version); err != nil {
+			db.logger.Errorf("sqldb.RecordMigration: sql.Exec >> %v", err)
+			return err
+		}
+
+		if _, err := tx.exec(ctx, "INSERT INTO schema_migrations (version, name, applied_at, duration) VALUES (?, ?, ?, ?)",
+			m.Version, m.Name, millis(m.AppliedAt), m.Duration); err != nil {
+			db.logger.Errorf("sqldb.RecordMigration: sql.Exec >> %v", err)
+			return err
+		}
+		return nil
+	})
+}
+
+// RemoveMigration forgets that the migration version was applied.
+func (db *DB) RemoveMigration(ctx context.Context, version int) error {
+	if _, err := db.pool().exec(ctx, "DELETE FROM schema_migrations WHERE version = ?", version); err != nil {
+		db.logger.Errorf("sqldb.RemoveMigration: sql.Exec >> %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// LockMigrations takes or extends the migration lock for owner until
+// expires. It reports false while another owner holds a lock that hasn't
+// expired by now.
+func (db *DB) LockMigrations(ctx context.Context, owner string, now, expires time.Time) (bool, error) {
+	res, err := db.pool().exec(ctx, "UPDATE schema_lock SET owner = ?, locked_at = ?, expires_at = ? WHERE id = 1 AND (owner = ? OR expires_at <= ?)",
+		owner, millis(now), millis(expires), owner, millis(now))
+	if err != nil {
+		db.logger.Errorf("sqldb.LockMigrations: sql.Exec >> %v", err)
+		return false, err
+	}
+	if n, err := res.RowsAffected(); err == nil && n > 0 {
+		return true, nil
+	}
+
+	// either nobody holds the lock, or someone else does and the insert
+	// collides with their row
+	_, err = db.pool().exec(ctx, "INSERT INTO schema_lock (id, owner, locked_at, expires_at) VALUES (1, ?, ?, ?)",
+		owner, millis(now), millis(expires))
+	if err != nil {
+		if db.dialect.IsDuplicate(err) {
+			return false, nil
+		}
+		db.logger.Errorf("sqldb.LockMigrations: sql.Exec >> %v", err)
+		return false, err
+	}
+
+	return true, nil
+}
+
+// UnlockMigrations releases owner's migration lock, if it still holds it.
+func (db *DB) UnlockMigrations(ctx context.Context, owner string) error {
+	if _, err := db.pool().exec(ctx, "DELETE FROM schema_lock WHERE id = 1 AND owner = ?", owner); err != nil {
+		db.logger.Errorf("sqldb.UnlockMigrations: sql.Exec >> %v", err)
+		return err
+	}
+
+	return nil
+}
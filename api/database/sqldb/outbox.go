@@ -0,0 +1,186 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/music-tribe/react-pairing-challenge/database"
+	"github.com/music-tribe/uuid"
+)
+
+const outboxColumns = "event, attempts, next_attempt_at, last_error, dispatched_at, dead_at"
+
+// dueOutbox selects the undelivered events due an attempt at its one
+// placeholder, oldest first.
+const dueOutbox = "SELECT " + outboxColumns + ` FROM outbox
+	WHERE dispatched_at IS NULL AND dead_at IS NULL AND next_attempt_at <= ?
+	ORDER BY occurred_at, seq LIMIT ?`
+
+// PendingOutbox returns up to limit undelivered events that are due an
+// attempt at now, oldest first, without claiming them.
+func (db *DB) PendingOutbox(now time.Time, limit int64) ([]*database.OutboxEntry, error) {
+	return db.findOutbox(context.Background(), db.pool(), "PendingOutbox", dueOutbox, millis(now), limit)
+}
+
+// ClaimOutbox claims up to limit undelivered events that are due an attempt
+// at now, oldest first, by moving their next attempt to until. Until then no
+// other dispatcher can claim them; should the claiming dispatcher stop
+// before settling them, they fall due again and are retried.
+func (db *DB) ClaimOutbox(now, until time.Time, limit int64) ([]*database.OutboxEntry, error) {
+	ctx := context.Background()
+
+	var es []*database.OutboxEntry
+	err := db.transact(ctx, func(tx conn) error {
+		var err error
+		es, err = db.findOutbox(ctx, tx, "ClaimOutbox", dueOutbox+db.dialect.SkipLocked, millis(now), limit)
+		if err != nil {
+			return err
+		}
+
+		for _, e := range es {
+			if _, err := tx.exec(ctx, "UPDATE outbox SET next_attempt_at = ? WHERE id = ?", millis(until), e.Id); err != nil {
+				db.logger.Errorf("sqldb.ClaimOutbox: sql.Exec >> %v", err)
+				return err
+			}
+			e.NextAttemptAt = until.UTC().Truncate(time.Millisecond)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return es, nil
+}
+
+// findOutbox loads every entry query selects, with the subscribers that
+// have handled each.
+func (db *DB) findOutbox(ctx context.Context, c conn, op string, query string, args ...interface{}) ([]*database.OutboxEntry, error) {
+	rows, err := c.query(ctx, query, args...)
+	if err != nil {
+		db.logger.Errorf("sqldb.%s: sql.Query >> %v", op, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	es := make([]*database.OutboxEntry, 0)
+	for rows.Next() {
+		e := new(database.OutboxEntry)
+		var event string
+		var nextAttemptAt int64
+		var dispatchedAt, deadAt sql.NullInt64
+		if err := rows.Scan(&event, &e.Attempts, &nextAttemptAt, &e.LastError, &dispatchedAt, &deadAt); err != nil {
+			db.logger.Errorf("sqldb.%s: sql.Scan >> %v", op, err)
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(event), &e.Event); err != nil {
+			db.logger.Errorf("sqldb.%s: json.Unmarshal >> %v", op, err)
+			return nil, err
+		}
+		e.NextAttemptAt = fromMillis(nextAttemptAt)
+		e.DispatchedAt = fromNullMillis(dispatchedAt)
+		e.DeadAt = fromNullMillis(deadAt)
+		es = append(es, e)
+	}
+	if err := rows.Err(); err != nil {
+		db.logger.Errorf("sqldb.%s: sql.Rows >> %v", op, err)
+		return nil, err
+	}
+	rows.Close()
+
+	if err := db.loadDelivered(ctx, c, es); err != nil {
+		return nil, err
+	}
+	return es, nil
+}
+
+// loadDelivered fills in the subscribers that have handled each entry.
+func (db *DB) loadDelivered(ctx context.Context, c conn, es []*database.OutboxEntry) error {
+	if len(es) == 0 {
+		return nil
+	}
+
+	byId := make(map[uuid.UUID]*database.OutboxEntry, len(es))
+	args := make([]interface{}, len(es))
+	for i, e := range es {
+		byId[e.Id] = e
+		args[i] = e.Id
+	}
+
+	rows, err := c.query(ctx, "SELECT event_id, subscriber FROM outbox_delivered WHERE event_id IN "+in(len(args)), args...)
+	if err != nil {
+		db.logger.Errorf("sqldb.loadDelivered: sql.Query >> %v", err)
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id uuid.UUID
+		var subscriber string
+		if err := rows.Scan(&id, &subscriber); err != nil {
+			db.logger.Errorf("sqldb.loadDelivered: sql.Scan >> %v", err)
+			return err
+		}
+		if e := byId[id]; e != nil {
+			e.Delivered = append(e.Delivered, subscriber)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		db.logger.Errorf("sqldb.loadDelivered: sql.Rows >> %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// MarkDelivered records that subscriber has handled the event.
+func (db *DB) MarkDelivered(eventId uuid.UUID, subscriber string) error {
+	ctx := context.Background()
+
+	var exists int
+	if err := db.pool().queryRow(ctx, "SELECT 1 FROM outbox WHERE id = ?", eventId).Scan(&exists); err != nil {
+		return db.notFound("MarkDelivered: sql.Scan", err)
+	}
+
+	// delivering twice is harmless, as with mongo's $addToSet
+	_, err := db.pool().exec(ctx, "INSERT INTO outbox_delivered (event_id, subscriber) VALUES (?, ?)", eventId, subscriber)
+	if err != nil && !db.dialect.IsDuplicate(err) {
+		db.logger.Errorf("sqldb.MarkDelivered: sql.Exec >> %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// MarkDispatched records that every subscriber has handled the event.
+func (db *DB) MarkDispatched(eventId uuid.UUID) error {
+	return db.updateOutbox("MarkDispatched", "dispatched_at = ?", millis(db.now()), eventId)
+}
+
+// RetryOutbox counts a failed delivery attempt and schedules the next one.
+func (db *DB) RetryOutbox(eventId uuid.UUID, next time.Time, lastError string) error {
+	return db.updateOutbox("RetryOutbox", "attempts = attempts + 1, next_attempt_at = ?, last_error = ?", millis(next), lastError, eventId)
+}
+
+// MarkDead gives up on delivering the event after too many failed attempts.
+func (db *DB) MarkDead(eventId uuid.UUID, lastError string) error {
+	return db.updateOutbox("MarkDead", "attempts = attempts + 1, dead_at = ?, last_error = ?", millis(db.now()), lastError, eventId)
+}
+
+// updateOutbox applies set to the event, whose id is the last of args.
+func (db *DB) updateOutbox(op string, set string, args ...interface{}) error {
+	res, err := db.pool().exec(context.Background(), "UPDATE outbox SET "+set+" WHERE id = ?", args...)
+	if err != nil {
+		db.logger.Errorf("sqldb.%s: sql.Exec >> %v", op, err)
+		return err
+	}
+
+	if err := db.affected(op, res); err != nil {
+		db.logger.Errorf("sqldb.%s: sql.Exec >> %v", op, err)
+		return err
+	}
+
+	return nil
+}
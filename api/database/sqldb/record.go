@@ -0,0 +1,76 @@
+package sqldb
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/uuid"
+)
+
+// record appends the audit event and the outbox event describing a change
+// from before to after. It must be called with the transaction of the
+// change itself.
+func (db *DB) record(ctx context.Context, tx conn, actor string, action domain.AuditAction, featureId uuid.UUID, before, after *domain.Feature) error {
+	now := db.now()
+	changes := domain.DiffFeatures(before, after)
+
+	audit := &domain.AuditEvent{
+		Id:        uuid.New(),
+		FeatureId: featureId,
+		Actor:     actor,
+		Action:    action,
+		Changes:   changes,
+		At:        now,
+	}
+	if err := db.insertAudit(ctx, tx, audit); err != nil {
+		return err
+	}
+
+	snapshot := after
+	if snapshot == nil {
+		snapshot = before
+	}
+	event := domain.Event{
+		Id:         uuid.New(),
+		Type:       action.EventType(),
+		FeatureId:  featureId,
+		Board:      snapshot.Board,
+		Actor:      actor,
+		Feature:    snapshot,
+		Changes:    changes,
+		OccurredAt: now,
+	}
+	b, err := json.Marshal(event)
+	if err != nil {
+		db.logger.Errorf("sqldb.record: json.Marshal >> %v", err)
+		return err
+	}
+
+	if _, err := tx.exec(ctx, "INSERT INTO outbox (id, event, occurred_at, next_attempt_at) VALUES (?, ?, ?, ?)",
+		event.Id, string(b), millis(now), millis(now)); err != nil {
+		db.logger.Errorf("sqldb.record: sql.Exec >> %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// insertAudit stores an audit event.
+func (db *DB) insertAudit(ctx context.Context, c conn, e *domain.AuditEvent) error {
+	changes := e.Changes
+	if changes == nil {
+		changes = []domain.FieldChange{}
+	}
+	b, err := json.Marshal(changes)
+	if err != nil {
+		db.logger.Errorf("sqldb.insertAudit: json.Marshal >> %v", err)
+		return err
+	}
+
+	if _, err := c.exec(ctx, "INSERT INTO audit (id, feature_id, actor, action, changes, at) VALUES (?, ?, ?, ?, ?, ?)",
+		e.Id, e.FeatureId, e.Actor, e.Action, string(b), millis(e.At)); err != nil {
+		return db.duplicate("insertAudit: sql.Exec", err)
+	}
+	return nil
+}
@@ -0,0 +1,294 @@
+// Package sqldb stores the service's data in a SQL database through
+// database/sql. The queries are shared by every supported database; a
+// Dialect supplies the driver, the schema and the few statements that
+// differ between them.
+//
+// Times are stored as milliseconds since the Unix epoch, the precision the
+// service works at, so they compare and sort the same way everywhere.
+// Structured values the service never queries inside, such as audit
+// changes and event snapshots, are stored as JSON.
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/music-tribe/react-pairing-challenge/database"
+)
+
+type Logger interface {
+	Infof(string, ...interface{})
+	Errorf(string, ...interface{})
+}
+
+// DB is a database.Store kept in a SQL database.
+type DB struct {
+	db      *sql.DB
+	dialect *Dialect
+	logger  Logger
+	clock   database.Clock
+	// pollEvery is how often WatchFeatures looks for new events.
+	pollEvery time.Duration
+}
+
+var _ database.Store = (*DB)(nil)
+
+type Option func(*DB)
+
+// WithClock overrides the clock used to stamp created, updated and voted
+// times.
+func WithClock(clock database.Clock) Option {
+	return func(db *DB) {
+		db.clock = clock
+	}
+}
+
+// WithPollInterval sets how often WatchFeatures looks for new events.
+func WithPollInterval(d time.Duration) Option {
+	return func(db *DB) {
+		db.pollEvery = d
+	}
+}
+
+// Open connects to the database at dsn. The schema itself is created by
+// the dialect's migrations, which the caller runs; only the tables the
+// migrations are recorded in are created here.
+func Open(dialect *Dialect, dsn string, logger Logger, opts ...Option) (*DB, error) {
+	conn, err := sql.Open(dialect.Driver, dialect.DSN(dsn))
+	if err != nil {
+		logger.Errorf("sqldb.Open: sql.Open >> %v", err)
+		return nil, err
+	}
+	if dialect.Configure != nil {
+		dialect.Configure(conn)
+	}
+
+	if err := conn.Ping(); err != nil {
+		logger.Errorf("sqldb.Open: failed on ping to %s >> %v", dialect.Name, err)
+		conn.Close()
+		return nil, err
+	}
+
+	db := &DB{
+		db:        conn,
+		dialect:   dialect,
+		logger:    logger,
+		clock:     time.Now,
+		pollEvery: time.Second,
+	}
+	for _, opt := range opts {
+		opt(db)
+	}
+
+	for _, stmt := range dialect.Bootstrap {
+		if _, err := conn.Exec(stmt); err != nil {
+			logger.Errorf("sqldb.Open: sql.Exec >> %v", err)
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	logger.Infof("sqldb.Open: %s connected", dialect.Name)
+	return db, nil
+}
+
+func (db *DB) Close() error {
+	db.logger.Infof("sqldb.Close: %s disconnected", db.dialect.Name)
+	return db.db.Close()
+}
+
+// now returns the clock's time at the precision it is stored at.
+func (db *DB) now() time.Time {
+	return db.clock().UTC().Truncate(time.Millisecond)
+}
+
+// querier is what both *sql.DB and *sql.Tx can run queries on.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// conn binds a querier to the dialect, rewriting the ? placeholders every
+// query is written with into the dialect's own.
+type conn struct {
+	q       querier
+	dialect *Dialect
+}
+
+func (c conn) exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return c.q.ExecContext(ctx, c.dialect.rebind(query), args...)
+}
+
+func (c conn) query(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return c.q.QueryContext(ctx, c.dialect.rebind(query), args...)
+}
+
+func (c conn) queryRow(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return c.q.QueryRowContext(ctx, c.dialect.rebind(query), args...)
+}
+
+// pool runs queries outside of any transaction.
+func (db *DB) pool() conn {
+	return conn{q: db.db, dialect: db.dialect}
+}
+
+// transact runs fn in a transaction, so that a change to a feature is
+// committed together with its audit and outbox events or not at all. Errors
+// returned by fn are passed back unchanged.
+func (db *DB) transact(ctx context.Context, fn func(tx conn) error) error {
+	tx, err := db.db.BeginTx(ctx, nil)
+	if err != nil {
+		db.logger.Errorf("sqldb.transact: sql.Begin >> %v", err)
+		return err
+	}
+
+	if err := fn(conn{q: tx, dialect: db.dialect}); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		db.logger.Errorf("sqldb.transact: sql.Commit >> %v", err)
+		return err
+	}
+	return nil
+}
+
+// snapshot runs fn in a read only transaction, so that everything it reads
+// is as of one moment however long it takes.
+func (db *DB) snapshot(ctx context.Context, fn func(c conn) error) error {
+	tx, err := db.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		db.logger.Errorf("sqldb.snapshot: sql.Begin >> %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	return fn(conn{q: tx, dialect: db.dialect})
+}
+
+// notFound maps a missing row onto database.ErrNotFound, logging anything
+// else as a failure of op.
+func (db *DB) notFound(op string, err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.ErrNotFound
+	}
+	db.logger.Errorf("sqldb.%s >> %v", op, err)
+	return err
+}
+
+// duplicate maps a unique constraint violation onto database.ErrDuplicate,
+// logging anything else as a failure of op.
+func (db *DB) duplicate(op string, err error) error {
+	if db.dialect.IsDuplicate(err) {
+		return database.ErrDuplicate
+	}
+	db.logger.Errorf("sqldb.%s >> %v", op, err)
+	return err
+}
+
+// affected reports database.ErrNotFound when res changed no rows.
+func (db *DB) affected(op string, res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		db.logger.Errorf("sqldb.%s: sql.RowsAffected >> %v", op, err)
+		return err
+	}
+	if n == 0 {
+		return database.ErrNotFound
+	}
+	return nil
+}
+
+// millis is how times are stored.
+func millis(t time.Time) int64 {
+	return t.UnixMilli()
+}
+
+// nullMillis stores an optional time, nil as NULL.
+func nullMillis(t *time.Time) sql.NullInt64 {
+	if t == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: t.UnixMilli(), Valid: true}
+}
+
+func fromMillis(ms int64) time.Time {
+	return time.UnixMilli(ms).UTC()
+}
+
+func fromNullMillis(ms sql.NullInt64) *time.Time {
+	if !ms.Valid {
+		return nil
+	}
+	t := fromMillis(ms.Int64)
+	return &t
+}
+
+// in returns the placeholders for an IN list of n values.
+func in(n int) string {
+	return "(" + strings.TrimSuffix(strings.Repeat("?, ", n), ", ") + ")"
+}
+
+// Dialect is what differs between the SQL databases sqldb supports.
+type Dialect struct {
+	// Name is shown in logs.
+	Name string
+	// Driver is the database/sql driver to open.
+	Driver string
+	// DSN turns the DB_URL the service was configured with into the
+	// driver's data source name.
+	DSN func(url string) string
+	// Configure tunes the connection pool after it is opened.
+	Configure func(db *sql.DB)
+	// Bootstrap creates the tables migrations are recorded in.
+	Bootstrap []string
+	// Schema is the ordered schema migrations.
+	Schema []SchemaMigration
+	// NumberedParams writes placeholders as $1, $2... rather than ?.
+	NumberedParams bool
+	// ForUpdate is appended to selects that should lock the rows they read
+	// until the transaction ends.
+	ForUpdate string
+	// SkipLocked is appended to selects that claim rows for the
+	// transaction, passing over those another transaction has claimed.
+	SkipLocked string
+	// TextSearch is the condition matching features f against one word of
+	// a search, given as its single placeholder; a search ORs its words.
+	TextSearch string
+	// IsDuplicate reports whether err is a unique constraint violation.
+	IsDuplicate func(err error) bool
+}
+
+// SchemaMigration is one step of a dialect's schema.
+type SchemaMigration struct {
+	Version int
+	Name    string
+	Up      []string
+	Down    []string
+}
+
+// rebind rewrites the ? placeholders in query into the dialect's own. None
+// of the queries contain a literal ?.
+func (d *Dialect) rebind(query string) string {
+	if !d.NumberedParams || !strings.Contains(query, "?") {
+		return query
+	}
+
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
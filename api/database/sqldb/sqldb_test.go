@@ -0,0 +1,26 @@
+package sqldb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDialect_Rebind(t *testing.T) {
+	t.Run("when the dialect numbers its parameters, every ? should be numbered in order", func(t *testing.T) {
+		got := Postgres.rebind("SELECT id FROM features WHERE board = ? AND status IN (?, ?)")
+		assert.Equal(t, "SELECT id FROM features WHERE board = $1 AND status IN ($2, $3)", got)
+	})
+
+	t.Run("when the dialect uses ?, the query should be left alone", func(t *testing.T) {
+		d := &Dialect{}
+		assert.Equal(t, "SELECT 1 WHERE ? = ?", d.rebind("SELECT 1 WHERE ? = ?"))
+	})
+}
+
+func TestIn(t *testing.T) {
+	t.Run("when an IN list is built, it should have one placeholder per value", func(t *testing.T) {
+		assert.Equal(t, "(?)", in(1))
+		assert.Equal(t, "(?, ?, ?)", in(3))
+	})
+}
@@ -0,0 +1,265 @@
+package sqldb
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// SQLite stores the service's data in a single SQLite file, for small
+// deployments and local development. DB_URL names the file, e.g.
+// sqlite:///var/lib/features/features.db, or sqlite://features.db relative
+// to the working directory.
+//
+// The file is opened in WAL mode, so reads carry on while a write is in
+// progress. Writers take the database lock as their transaction begins,
+// and wait up to busy_timeout for one another, rather than failing when
+// two of them meet mid-transaction as SQLite otherwise would.
+var SQLite = &Dialect{
+	Name:   "sqlite",
+	Driver: "sqlite",
+	DSN: func(url string) string {
+		path, query, _ := strings.Cut(strings.TrimPrefix(url, "sqlite://"), "?")
+		params := []string{
+			"_pragma=journal_mode(WAL)",
+			"_pragma=busy_timeout(10000)",
+			"_pragma=foreign_keys(1)",
+			"_pragma=synchronous(NORMAL)",
+			"_txlock=immediate",
+		}
+		if query != "" {
+			params = append(params, query)
+		}
+		return "file:" + path + "?" + strings.Join(params, "&")
+	},
+	Configure: func(db *sql.DB) {
+		db.SetMaxOpenConns(4)
+	},
+	Bootstrap: []string{
+		`CREATE TABLE IF NOT EXISTS schema_migrations (
+			version    INTEGER PRIMARY KEY,
+			name       TEXT NOT NULL,
+			applied_at INTEGER NOT NULL,
+			duration   INTEGER NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS schema_lock (
+			id         INTEGER PRIMARY KEY CHECK (id = 1),
+			owner      TEXT NOT NULL,
+			locked_at  INTEGER NOT NULL,
+			expires_at INTEGER NOT NULL
+		)`,
+	},
+	Schema: sqliteSchema,
+	// writers already hold the whole database from the start of their
+	// transaction
+	ForUpdate:  "",
+	SkipLocked: "",
+	TextSearch: `f.id IN (SELECT feature_id FROM features_fts WHERE features_fts MATCH '"' || replace(?, '"', '""') || '"')`,
+	IsDuplicate: func(err error) bool {
+		var sqliteErr *sqlite.Error
+		if !errors.As(err, &sqliteErr) {
+			return false
+		}
+		code := sqliteErr.Code()
+		return code == sqlite3.SQLITE_CONSTRAINT_UNIQUE || code == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY
+	},
+}
+
+var sqliteSchema = []SchemaMigration{
+	{
+		Version: 1,
+		Name:    "features_and_votes",
+		Up: []string{
+			`CREATE TABLE features (
+				id            TEXT PRIMARY KEY,
+				user_id       TEXT NOT NULL,
+				name          TEXT NOT NULL,
+				description   TEXT NOT NULL,
+				board         TEXT NOT NULL,
+				status        TEXT NOT NULL,
+				vote_count    INTEGER NOT NULL DEFAULT 0,
+				upvotes       INTEGER NOT NULL DEFAULT 0,
+				downvotes     INTEGER NOT NULL DEFAULT 0,
+				score         INTEGER NOT NULL DEFAULT 0,
+				hot_score     REAL NOT NULL,
+				rising_score  REAL NOT NULL,
+				created_at    INTEGER NOT NULL,
+				updated_at    INTEGER NOT NULL,
+				last_voted_at INTEGER,
+				deleted_at    INTEGER
+			)`,
+			`CREATE INDEX features_user_id_created_at ON features (user_id, created_at DESC)`,
+			`CREATE INDEX features_board_created_at ON features (board, created_at DESC)`,
+			`CREATE INDEX features_created_at ON features (created_at DESC, id)`,
+			`CREATE INDEX features_score_vote_count ON features (score DESC, vote_count DESC, id)`,
+			`CREATE INDEX features_hot_score ON features (hot_score DESC, id)`,
+			`CREATE INDEX features_rising_score ON features (rising_score DESC, id)`,
+			`CREATE INDEX features_deleted_at ON features (deleted_at) WHERE deleted_at IS NOT NULL`,
+			// the search index is kept in step with the features by
+			// triggers, and stems words as mongo's text index does
+			`CREATE VIRTUAL TABLE features_fts USING fts5 (feature_id UNINDEXED, name, description, tokenize = 'porter unicode61')`,
+			`CREATE TRIGGER features_fts_insert AFTER INSERT ON features BEGIN
+				INSERT INTO features_fts (feature_id, name, description) VALUES (new.id, new.name, new.description);
+			END`,
+			`CREATE TRIGGER features_fts_update AFTER UPDATE OF name, description ON features BEGIN
+				DELETE FROM features_fts WHERE feature_id = old.id;
+				INSERT INTO features_fts (feature_id, name, description) VALUES (new.id, new.name, new.description);
+			END`,
+			`CREATE TRIGGER features_fts_delete AFTER DELETE ON features BEGIN
+				DELETE FROM features_fts WHERE feature_id = old.id;
+			END`,
+			`CREATE TABLE feature_tags (
+				feature_id TEXT NOT NULL REFERENCES features (id) ON DELETE CASCADE,
+				tag        TEXT NOT NULL,
+				position   INTEGER NOT NULL,
+				PRIMARY KEY (feature_id, tag)
+			)`,
+			`CREATE INDEX feature_tags_tag ON feature_tags (tag)`,
+			`CREATE TABLE votes (
+				id         TEXT PRIMARY KEY,
+				feature_id TEXT NOT NULL REFERENCES features (id) ON DELETE CASCADE,
+				voter_id   TEXT NOT NULL,
+				board      TEXT NOT NULL,
+				cast_at    INTEGER NOT NULL,
+				direction  TEXT NOT NULL DEFAULT '',
+				weight     INTEGER NOT NULL DEFAULT 0,
+				source     TEXT NOT NULL DEFAULT '',
+				UNIQUE (feature_id, voter_id)
+			)`,
+			`CREATE INDEX votes_voter_id_board ON votes (voter_id, board)`,
+			`CREATE TABLE categories (
+				slug        TEXT PRIMARY KEY,
+				name        TEXT NOT NULL,
+				description TEXT NOT NULL DEFAULT '',
+				created_at  INTEGER NOT NULL,
+				updated_at  INTEGER NOT NULL
+			)`,
+		},
+		Down: []string{
+			`DROP TABLE categories`,
+			`DROP TABLE votes`,
+			`DROP TABLE feature_tags`,
+			`DROP TABLE features_fts`,
+			`DROP TABLE features`,
+		},
+	},
+	{
+		Version: 2,
+		Name:    "audit_and_outbox",
+		Up: []string{
+			`CREATE TABLE audit (
+				seq        INTEGER PRIMARY KEY AUTOINCREMENT,
+				id         TEXT NOT NULL UNIQUE,
+				feature_id TEXT NOT NULL,
+				actor      TEXT NOT NULL,
+				action     TEXT NOT NULL,
+				changes    TEXT NOT NULL,
+				at         INTEGER NOT NULL
+			)`,
+			`CREATE INDEX audit_feature_id_at ON audit (feature_id, at)`,
+			`CREATE INDEX audit_actor_at ON audit (actor, at DESC)`,
+			`CREATE INDEX audit_at ON audit (at DESC)`,
+			`CREATE TABLE outbox (
+				seq             INTEGER PRIMARY KEY AUTOINCREMENT,
+				id              TEXT NOT NULL UNIQUE,
+				event           TEXT NOT NULL,
+				occurred_at     INTEGER NOT NULL,
+				attempts        INTEGER NOT NULL DEFAULT 0,
+				next_attempt_at INTEGER NOT NULL,
+				last_error      TEXT NOT NULL DEFAULT '',
+				dispatched_at   INTEGER,
+				dead_at         INTEGER
+			)`,
+			`CREATE INDEX outbox_pending ON outbox (next_attempt_at) WHERE dispatched_at IS NULL AND dead_at IS NULL`,
+			`CREATE INDEX outbox_dispatched_at ON outbox (dispatched_at) WHERE dispatched_at IS NOT NULL`,
+			`CREATE TABLE outbox_delivered (
+				event_id   TEXT NOT NULL REFERENCES outbox (id) ON DELETE CASCADE,
+				subscriber TEXT NOT NULL,
+				PRIMARY KEY (event_id, subscriber)
+			)`,
+			`CREATE TABLE resume_tokens (
+				consumer   TEXT PRIMARY KEY,
+				seq        INTEGER NOT NULL,
+				updated_at INTEGER NOT NULL
+			)`,
+		},
+		Down: []string{
+			`DROP TABLE resume_tokens`,
+			`DROP TABLE outbox_delivered`,
+			`DROP TABLE outbox`,
+			`DROP TABLE audit`,
+		},
+	},
+	{
+		Version: 3,
+		Name:    "webhooks",
+		Up: []string{
+			`CREATE TABLE webhooks (
+				id          TEXT PRIMARY KEY,
+				url         TEXT NOT NULL,
+				secret      TEXT NOT NULL,
+				event_types TEXT NOT NULL,
+				active      INTEGER NOT NULL,
+				created_at  INTEGER NOT NULL,
+				updated_at  INTEGER NOT NULL
+			)`,
+			`CREATE TABLE webhook_deliveries (
+				id              TEXT PRIMARY KEY,
+				webhook_id      TEXT NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+				event_id        TEXT NOT NULL,
+				event           TEXT NOT NULL,
+				status          TEXT NOT NULL,
+				attempts        TEXT NOT NULL,
+				next_attempt_at INTEGER NOT NULL,
+				created_at      INTEGER NOT NULL,
+				completed_at    INTEGER,
+				UNIQUE (webhook_id, event_id)
+			)`,
+			`CREATE INDEX webhook_deliveries_status_next_attempt_at ON webhook_deliveries (status, next_attempt_at)`,
+			`CREATE INDEX webhook_deliveries_webhook_id_created_at ON webhook_deliveries (webhook_id, created_at DESC)`,
+		},
+		Down: []string{
+			`DROP TABLE webhook_deliveries`,
+			`DROP TABLE webhooks`,
+		},
+	},
+	{
+		Version: 4,
+		Name:    "idempotency_keys",
+		Up: []string{
+			`CREATE TABLE idempotency_keys (
+				key          TEXT PRIMARY KEY,
+				fingerprint  TEXT NOT NULL,
+				status       INTEGER NOT NULL,
+				content_type TEXT NOT NULL DEFAULT '',
+				body         BLOB,
+				created_at   INTEGER NOT NULL,
+				expires_at   INTEGER NOT NULL
+			)`,
+			`CREATE INDEX idempotency_keys_expires_at ON idempotency_keys (expires_at)`,
+		},
+		Down: []string{
+			`DROP TABLE idempotency_keys`,
+		},
+	},
+	{
+		Version: 5,
+		Name:    "vote_locks",
+		Up: []string{
+			// one row per voter and board, locked by each vote cast
+			// against a budget
+			`CREATE TABLE vote_locks (
+				voter_id  TEXT NOT NULL,
+				board     TEXT NOT NULL,
+				locked_at INTEGER NOT NULL,
+				PRIMARY KEY (voter_id, board)
+			)`,
+		},
+		Down: []string{
+			`DROP TABLE vote_locks`,
+		},
+	},
+}
@@ -0,0 +1,29 @@
+package sqldb
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/music-tribe/react-pairing-challenge/database"
+	"github.com/music-tribe/react-pairing-challenge/database/storetest"
+	"github.com/music-tribe/react-pairing-challenge/migrate"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSQLite(t *testing.T) {
+	url := "sqlite://" + filepath.Join(t.TempDir(), "features.db")
+
+	storetest.Run(t, func(t *testing.T, clock database.Clock) database.Store {
+		db, err := Open(SQLite, url, echo.New().Logger, WithClock(clock), WithPollInterval(50*time.Millisecond))
+		if !assert.NoError(t, err) {
+			t.FailNow()
+		}
+
+		_, err = migrate.New(migrate.Config{Store: db, Migrations: db.Migrations()}).Up(context.Background(), migrate.Options{})
+		assert.NoError(t, err)
+		return db
+	})
+}
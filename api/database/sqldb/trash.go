@@ -0,0 +1,103 @@
+package sqldb
+
+import (
+	"context"
+	"time"
+
+	"github.com/music-tribe/react-pairing-challenge/database"
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/uuid"
+)
+
+// dispatchedRetention is how long dispatched outbox events are kept, as
+// mongo's TTL index on them does.
+const dispatchedRetention = 7 * 24 * time.Hour
+
+// GetTrash lists a user's deleted features, most recently deleted first.
+func (db *DB) GetTrash(userId uuid.UUID) ([]*domain.Feature, error) {
+	return db.findFeatures(context.Background(), db.pool(), "GetTrash",
+		"SELECT "+featureColumns+" FROM features f WHERE f.user_id = ? AND f.deleted_at IS NOT NULL ORDER BY f.deleted_at DESC", userId)
+}
+
+// Restore takes a feature back out of its owner's trash.
+func (db *DB) Restore(userId, featureId uuid.UUID) (*domain.Feature, error) {
+	ctx := context.Background()
+
+	var after domain.Feature
+	err := db.transact(ctx, func(tx conn) error {
+		before, err := db.findFeature(ctx, tx, "Restore", true,
+			"f.id = ? AND f.user_id = ? AND f.deleted_at IS NOT NULL", featureId, userId)
+		if err != nil {
+			return err
+		}
+
+		after = *before
+		after.DeletedAt = nil
+
+		if err := db.saveFeature(ctx, tx, &after); err != nil {
+			return err
+		}
+
+		return db.record(ctx, tx, userId.String(), domain.AuditRestored, featureId, before, &after)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &after, nil
+}
+
+// PurgeDeleted permanently removes features, and their votes, that were
+// deleted before the given time. It returns how many features were removed.
+//
+// There are no TTL indexes outside mongo, so expired idempotency keys and
+// long dispatched outbox events are cleared out here too.
+func (db *DB) PurgeDeleted(before time.Time) (int64, error) {
+	ctx := context.Background()
+
+	expired, err := db.findFeatures(ctx, db.pool(), "PurgeDeleted",
+		"SELECT "+featureColumns+" FROM features f WHERE f.deleted_at < ?", millis(before))
+	if err != nil {
+		return 0, err
+	}
+
+	// each feature goes in its own transaction so one large purge doesn't
+	// hold a long running transaction open
+	var purged int64
+	for _, feature := range expired {
+		err := db.transact(ctx, func(tx conn) error {
+			// votes and tags go with the feature
+			res, err := tx.exec(ctx, "DELETE FROM features WHERE id = ? AND deleted_at = ?", feature.Id, nullMillis(feature.DeletedAt))
+			if err != nil {
+				db.logger.Errorf("sqldb.PurgeDeleted: sql.Exec >> %v", err)
+				return err
+			}
+
+			// restored since we looked
+			if err := db.affected("PurgeDeleted", res); err != nil {
+				return err
+			}
+
+			return db.record(ctx, tx, domain.SystemActor, domain.AuditPurged, feature.Id, feature, nil)
+		})
+		if err == database.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return purged, err
+		}
+		purged++
+	}
+
+	now := db.now()
+	if _, err := db.pool().exec(ctx, "DELETE FROM idempotency_keys WHERE expires_at <= ?", millis(now)); err != nil {
+		db.logger.Errorf("sqldb.PurgeDeleted: sql.Exec >> %v", err)
+		return purged, err
+	}
+	if _, err := db.pool().exec(ctx, "DELETE FROM outbox WHERE dispatched_at < ?", millis(now.Add(-dispatchedRetention))); err != nil {
+		db.logger.Errorf("sqldb.PurgeDeleted: sql.Exec >> %v", err)
+		return purged, err
+	}
+
+	return purged, nil
+}
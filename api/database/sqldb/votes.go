@@ -0,0 +1,309 @@
+package sqldb
+
+import (
+	"context"
+
+	"github.com/music-tribe/react-pairing-challenge/database"
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/react-pairing-challenge/ranking"
+	"github.com/music-tribe/uuid"
+)
+
+const voteColumns = "id, feature_id, voter_id, board, cast_at, direction, weight, source"
+
+// onLiveFeature keeps the votes on features that aren't in the trash.
+const onLiveFeature = "feature_id IN (SELECT id FROM features WHERE deleted_at IS NULL)"
+
+func scanVote(row scanner) (*domain.Vote, error) {
+	v := new(domain.Vote)
+	var castAt int64
+	if err := row.Scan(&v.Id, &v.FeatureId, &v.VoterId, &v.Board, &castAt, &v.Direction, &v.Weight, &v.Source); err != nil {
+		return nil, err
+	}
+	v.CastAt = fromMillis(castAt)
+	return v, nil
+}
+
+// findVotes loads every vote query selects.
+func (db *DB) findVotes(ctx context.Context, c conn, op string, query string, args ...interface{}) ([]*domain.Vote, error) {
+	rows, err := c.query(ctx, query, args...)
+	if err != nil {
+		db.logger.Errorf("sqldb.%s: sql.Query >> %v", op, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	vs := make([]*domain.Vote, 0)
+	for rows.Next() {
+		v, err := scanVote(rows)
+		if err != nil {
+			db.logger.Errorf("sqldb.%s: sql.Scan >> %v", op, err)
+			return nil, err
+		}
+		vs = append(vs, v)
+	}
+	if err := rows.Err(); err != nil {
+		db.logger.Errorf("sqldb.%s: sql.Rows >> %v", op, err)
+		return nil, err
+	}
+
+	return vs, nil
+}
+
+// insertVote stores a vote. ErrDuplicate is returned when the voter has
+// already voted for the feature.
+func (db *DB) insertVote(ctx context.Context, c conn, v *domain.Vote) error {
+	_, err := c.exec(ctx, "INSERT INTO votes ("+voteColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?)",
+		v.Id, v.FeatureId, v.VoterId, v.Board, millis(v.CastAt), v.Direction, v.Weight, v.Source)
+	if err != nil {
+		return db.duplicate("insertVote: sql.Exec", err)
+	}
+	return nil
+}
+
+// AddVote stores vote, stamping its CastAt and Board, and folds it into the
+// feature's totals, scores and LastVotedAt. ErrDuplicate is returned when the
+// voter has already voted for the feature, and ErrBudgetExhausted when the
+// vote would take them past budget on the feature's board.
+func (db *DB) AddVote(vote *domain.Vote, budget domain.VoteBudget) (*domain.Feature, error) {
+	ctx := context.Background()
+
+	var after domain.Feature
+	err := db.transact(ctx, func(tx conn) error {
+		// the feature's row stays locked until the vote is folded in, so
+		// concurrent votes can't overwrite each other's totals
+		before, err := db.findFeature(ctx, tx, "AddVote", true, "f.id = ? AND f.deleted_at IS NULL", vote.FeatureId)
+		if err != nil {
+			return err
+		}
+
+		vote.Board = before.Board
+		vote.CastAt = db.now()
+		if err := db.checkBudget(ctx, tx, vote, budget); err != nil {
+			return err
+		}
+		if err := db.insertVote(ctx, tx, vote); err != nil {
+			return err
+		}
+
+		after = *before
+		after.VoteCount++
+		after.Score += vote.Direction.Sign() * ranking.Weight(vote.Weight)
+		after.LastVotedAt = &vote.CastAt
+		if vote.Direction == domain.Downvote {
+			after.Downvotes++
+		} else {
+			after.Upvotes++
+			scores := ranking.Scores{Hot: after.HotScore, Rising: after.RisingScore}.AddVote(vote.CastAt, vote.Weight)
+			after.HotScore = scores.Hot
+			after.RisingScore = scores.Rising
+		}
+
+		if err := db.saveFeature(ctx, tx, &after); err != nil {
+			return err
+		}
+
+		return db.record(ctx, tx, vote.VoterId.String(), domain.AuditVoted, vote.FeatureId, before, &after)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &after, nil
+}
+
+// checkBudget returns database.ErrBudgetExhausted when vote would take its
+// voter past budget on its board. It first upserts the voter's row in
+// vote_locks, which holds off any other vote by them on the board until
+// this transaction ends, so the sum it takes can't miss one.
+func (db *DB) checkBudget(ctx context.Context, tx conn, vote *domain.Vote, budget domain.VoteBudget) error {
+	if budget.Unlimited() {
+		return nil
+	}
+
+	if _, err := tx.exec(ctx, `INSERT INTO vote_locks (voter_id, board, locked_at) VALUES (?, ?, ?)
+		ON CONFLICT (voter_id, board) DO UPDATE SET locked_at = excluded.locked_at`,
+		vote.VoterId, vote.Board, millis(vote.CastAt)); err != nil {
+		db.logger.Errorf("sqldb.AddVote: sql.Exec >> %v", err)
+		return err
+	}
+
+	votes, err := db.findVotes(ctx, tx, "AddVote",
+		"SELECT "+voteColumns+" FROM votes WHERE voter_id = ? AND board = ? AND "+onLiveFeature, vote.VoterId, vote.Board)
+	if err != nil {
+		return err
+	}
+
+	if budget.Spent(votes, vote.Board)+budget.Cost(vote.Weight) > budget.Points {
+		return database.ErrBudgetExhausted
+	}
+	return nil
+}
+
+// RemoveVote withdraws voterId's vote from the feature. A vote can't be
+// subtracted from a decayed score, so the feature's count and scores are
+// recomputed from the votes that remain. Votes on a feature in the trash
+// stay as they are, and ErrNotFound is returned for them.
+func (db *DB) RemoveVote(featureId, voterId uuid.UUID) (*domain.Feature, error) {
+	ctx := context.Background()
+
+	var after *domain.Feature
+	err := db.transact(ctx, func(tx conn) error {
+		if _, err := db.findFeature(ctx, tx, "RemoveVote", true, "f.id = ? AND f.deleted_at IS NULL", featureId); err != nil {
+			return err
+		}
+
+		res, err := tx.exec(ctx, "DELETE FROM votes WHERE feature_id = ? AND voter_id = ?", featureId, voterId)
+		if err != nil {
+			db.logger.Errorf("sqldb.RemoveVote: sql.Exec >> %v", err)
+			return err
+		}
+		if err := db.affected("RemoveVote", res); err != nil {
+			return err
+		}
+
+		var before *domain.Feature
+		if before, after, err = db.recount(ctx, tx, featureId, nil); err != nil {
+			return err
+		}
+
+		return db.record(ctx, tx, voterId.String(), domain.AuditUnvoted, featureId, before, after)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return after, nil
+}
+
+// ChangeVoteDirection switches voterId's existing vote for the feature
+// between up and down. The switch counts as a fresh vote, so its CastAt is
+// moved to now before the feature's totals and scores are recomputed.
+// Votes on a feature in the trash stay as they are, and ErrNotFound is
+// returned for them.
+func (db *DB) ChangeVoteDirection(featureId, voterId uuid.UUID, direction domain.VoteDirection) (*domain.Feature, error) {
+	ctx := context.Background()
+
+	now := db.now()
+	var after *domain.Feature
+	err := db.transact(ctx, func(tx conn) error {
+		if _, err := db.findFeature(ctx, tx, "ChangeVoteDirection", true, "f.id = ? AND f.deleted_at IS NULL", featureId); err != nil {
+			return err
+		}
+
+		res, err := tx.exec(ctx, "UPDATE votes SET direction = ?, cast_at = ? WHERE feature_id = ? AND voter_id = ?",
+			direction, millis(now), featureId, voterId)
+		if err != nil {
+			db.logger.Errorf("sqldb.ChangeVoteDirection: sql.Exec >> %v", err)
+			return err
+		}
+		if err := db.affected("ChangeVoteDirection", res); err != nil {
+			return err
+		}
+
+		var before *domain.Feature
+		setLastVoted := func(f *domain.Feature) { f.LastVotedAt = &now }
+		if before, after, err = db.recount(ctx, tx, featureId, setLastVoted); err != nil {
+			return err
+		}
+
+		return db.record(ctx, tx, voterId.String(), domain.AuditVoteChanged, featureId, before, after)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return after, nil
+}
+
+// recount resets a feature's vote totals and scores from its stored votes,
+// applying extra, when given, in the same write. It returns the feature as
+// it was before and after the recount; features in the trash are recounted
+// too.
+func (db *DB) recount(ctx context.Context, tx conn, featureId uuid.UUID, extra func(*domain.Feature)) (*domain.Feature, *domain.Feature, error) {
+	before, err := db.findFeature(ctx, tx, "recount", true, "f.id = ?", featureId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	votes, err := db.getVotes(ctx, tx, featureId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tally := ranking.Count(votes)
+	scores := ranking.Recompute(before.CreatedAt, votes)
+
+	after := *before
+	after.VoteCount = tally.Votes
+	after.Upvotes = tally.Upvotes
+	after.Downvotes = tally.Downvotes
+	after.Score = tally.Score
+	after.HotScore = scores.Hot
+	after.RisingScore = scores.Rising
+	if extra != nil {
+		extra(&after)
+	}
+
+	if err := db.saveFeature(ctx, tx, &after); err != nil {
+		return nil, nil, err
+	}
+
+	return before, &after, nil
+}
+
+// GetVote returns voterId's vote for the feature.
+func (db *DB) GetVote(featureId, voterId uuid.UUID) (*domain.Vote, error) {
+	row := db.pool().queryRow(context.Background(),
+		"SELECT "+voteColumns+" FROM votes WHERE feature_id = ? AND voter_id = ?", featureId, voterId)
+
+	v, err := scanVote(row)
+	if err != nil {
+		return nil, db.notFound("GetVote: sql.Scan", err)
+	}
+	return v, nil
+}
+
+// GetVotes returns every vote cast for the feature, oldest first.
+func (db *DB) GetVotes(featureId uuid.UUID) ([]*domain.Vote, error) {
+	return db.getVotes(context.Background(), db.pool(), featureId)
+}
+
+func (db *DB) getVotes(ctx context.Context, c conn, featureId uuid.UUID) ([]*domain.Vote, error) {
+	return db.findVotes(ctx, c, "GetVotes",
+		"SELECT "+voteColumns+" FROM votes WHERE feature_id = ? ORDER BY cast_at, id", featureId)
+}
+
+// GetVotesByVoter returns every active vote cast by voterId, across all
+// boards, oldest first. Votes on features in the trash are left out, so
+// they neither show nor count against the voter's budget until the feature
+// is restored.
+func (db *DB) GetVotesByVoter(voterId uuid.UUID) ([]*domain.Vote, error) {
+	return db.findVotes(context.Background(), db.pool(), "GetVotesByVoter",
+		"SELECT "+voteColumns+" FROM votes WHERE voter_id = ? AND "+onLiveFeature+" ORDER BY cast_at, id", voterId)
+}
+
+// votesOf loads the votes of every feature, oldest first, keyed by
+// feature.
+func (db *DB) votesOf(ctx context.Context, c conn, features []*domain.Feature) (map[uuid.UUID][]*domain.Vote, error) {
+	byFeature := make(map[uuid.UUID][]*domain.Vote, len(features))
+	if len(features) == 0 {
+		return byFeature, nil
+	}
+
+	args := make([]interface{}, len(features))
+	for i, f := range features {
+		args[i] = f.Id
+	}
+
+	votes, err := db.findVotes(ctx, c, "votesOf",
+		"SELECT "+voteColumns+" FROM votes WHERE feature_id IN "+in(len(args))+" ORDER BY cast_at, id", args...)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, v := range votes {
+		byFeature[v.FeatureId] = append(byFeature[v.FeatureId], v)
+	}
+	return byFeature, nil
+}
@@ -0,0 +1,143 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/music-tribe/react-pairing-challenge/domain"
+)
+
+// watchBatch is how many events WatchFeatures reads at a time.
+const watchBatch = 100
+
+// WatchFeatures follows the outbox, passing each event recorded from now
+// on to handle, until ctx is done or an error occurs. There are no change
+// streams outside mongo, so the outbox is polled every poll interval; the
+// events are those the outbox dispatcher delivers, rather than the coarser
+// changes mongo's stream reports. The last event seen is stored under
+// consumer after every event, so a restart carries on where the last run
+// stopped.
+//
+// Delivery is best effort, as it is with mongo: an event whose transaction
+// commits after a later one has been seen is missed.
+func (db *DB) WatchFeatures(ctx context.Context, consumer string, handle func(context.Context, *domain.Event) error) error {
+	last, err := db.resumeSeq(ctx, consumer)
+	if err != nil {
+		return err
+	}
+
+	for {
+		n := 0
+		err := db.eachEventAfter(ctx, last, func(seq int64, event *domain.Event) error {
+			n++
+			if err := handle(ctx, event); err != nil {
+				return err
+			}
+
+			last = seq
+			return db.saveResumeSeq(ctx, consumer, seq)
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+
+		if n == watchBatch {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(db.pollEvery):
+		}
+	}
+}
+
+// resumeSeq returns the last event consumer has seen, or the latest event
+// when it is watching for the first time.
+func (db *DB) resumeSeq(ctx context.Context, consumer string) (int64, error) {
+	var seq int64
+	err := db.pool().queryRow(ctx, "SELECT seq FROM resume_tokens WHERE consumer = ?", consumer).Scan(&seq)
+	if err == nil {
+		return seq, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		db.logger.Errorf("sqldb.WatchFeatures: sql.Scan >> %v", err)
+		return 0, err
+	}
+
+	if err := db.pool().queryRow(ctx, "SELECT COALESCE(MAX(seq), 0) FROM outbox").Scan(&seq); err != nil {
+		db.logger.Errorf("sqldb.WatchFeatures: sql.Scan >> %v", err)
+		return 0, err
+	}
+	return seq, nil
+}
+
+func (db *DB) saveResumeSeq(ctx context.Context, consumer string, seq int64) error {
+	now := millis(db.now())
+
+	res, err := db.pool().exec(ctx, "UPDATE resume_tokens SET seq = ?, updated_at = ? WHERE consumer = ?", seq, now, consumer)
+	if err != nil {
+		db.logger.Errorf("sqldb.WatchFeatures: sql.Exec >> %v", err)
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n > 0 {
+		return nil
+	}
+
+	if _, err := db.pool().exec(ctx, "INSERT INTO resume_tokens (consumer, seq, updated_at) VALUES (?, ?, ?)", consumer, seq, now); err != nil {
+		db.logger.Errorf("sqldb.WatchFeatures: sql.Exec >> %v", err)
+		return err
+	}
+	return nil
+}
+
+// eachEventAfter calls fn for up to watchBatch events recorded after seq,
+// in the order they were recorded.
+func (db *DB) eachEventAfter(ctx context.Context, seq int64, fn func(seq int64, event *domain.Event) error) error {
+	rows, err := db.pool().query(ctx, "SELECT seq, event FROM outbox WHERE seq > ? ORDER BY seq LIMIT ?", seq, watchBatch)
+	if err != nil {
+		db.logger.Errorf("sqldb.WatchFeatures: sql.Query >> %v", err)
+		return err
+	}
+	defer rows.Close()
+
+	type row struct {
+		seq   int64
+		event *domain.Event
+	}
+	batch := make([]row, 0, watchBatch)
+	for rows.Next() {
+		var r row
+		var event string
+		if err := rows.Scan(&r.seq, &event); err != nil {
+			db.logger.Errorf("sqldb.WatchFeatures: sql.Scan >> %v", err)
+			return err
+		}
+		r.event = new(domain.Event)
+		if err := json.Unmarshal([]byte(event), r.event); err != nil {
+			db.logger.Errorf("sqldb.WatchFeatures: json.Unmarshal >> %v", err)
+			return err
+		}
+		batch = append(batch, r)
+	}
+	if err := rows.Err(); err != nil {
+		db.logger.Errorf("sqldb.WatchFeatures: sql.Rows >> %v", err)
+		return err
+	}
+	// the rows are released before fn runs, as it writes
+	rows.Close()
+
+	for _, r := range batch {
+		if err := fn(r.seq, r.event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
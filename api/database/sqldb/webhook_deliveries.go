@@ -0,0 +1,164 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/uuid"
+)
+
+const deliveryColumns = "id, webhook_id, event, status, attempts, next_attempt_at, created_at, completed_at"
+
+func scanDelivery(row scanner) (*domain.WebhookDelivery, error) {
+	d := new(domain.WebhookDelivery)
+	var event, attempts string
+	var nextAttemptAt, createdAt int64
+	var completedAt sql.NullInt64
+	if err := row.Scan(&d.Id, &d.WebhookId, &event, &d.Status, &attempts, &nextAttemptAt, &createdAt, &completedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(event), &d.Event); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(attempts), &d.Attempts); err != nil {
+		return nil, err
+	}
+	d.NextAttemptAt = fromMillis(nextAttemptAt)
+	d.CreatedAt = fromMillis(createdAt)
+	d.CompletedAt = fromNullMillis(completedAt)
+	return d, nil
+}
+
+// AddWebhookDelivery stores a delivery. ErrDuplicate is returned when the
+// event has already been queued for the webhook.
+func (db *DB) AddWebhookDelivery(delivery *domain.WebhookDelivery) error {
+	delivery.CreatedAt = db.now()
+	if delivery.Attempts == nil {
+		delivery.Attempts = []domain.DeliveryAttempt{}
+	}
+
+	event, err := json.Marshal(delivery.Event)
+	if err != nil {
+		db.logger.Errorf("sqldb.AddWebhookDelivery: json.Marshal >> %v", err)
+		return err
+	}
+	attempts, err := json.Marshal(delivery.Attempts)
+	if err != nil {
+		db.logger.Errorf("sqldb.AddWebhookDelivery: json.Marshal >> %v", err)
+		return err
+	}
+
+	if _, err := db.pool().exec(context.Background(), "INSERT INTO webhook_deliveries (event_id, "+deliveryColumns+") VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+		delivery.Event.Id, delivery.Id, delivery.WebhookId, string(event), delivery.Status, string(attempts),
+		millis(delivery.NextAttemptAt), millis(delivery.CreatedAt), nullMillis(delivery.CompletedAt)); err != nil {
+		return db.duplicate("AddWebhookDelivery: sql.Exec", err)
+	}
+
+	return nil
+}
+
+// ClaimWebhookDeliveries claims up to limit pending deliveries that are due
+// an attempt at now, oldest first, by moving their next attempt to until.
+// Until then no other worker can claim them; should the claiming worker stop
+// before recording an attempt, they fall due again and are retried.
+func (db *DB) ClaimWebhookDeliveries(now, until time.Time, limit int64) ([]*domain.WebhookDelivery, error) {
+	ctx := context.Background()
+
+	var ds []*domain.WebhookDelivery
+	err := db.transact(ctx, func(tx conn) error {
+		var err error
+		ds, err = db.findDeliveries(ctx, tx, "ClaimWebhookDeliveries",
+			"SELECT "+deliveryColumns+" FROM webhook_deliveries WHERE status = ? AND next_attempt_at <= ? ORDER BY next_attempt_at, id LIMIT ?"+db.dialect.SkipLocked,
+			domain.DeliveryPending, millis(now), limit)
+		if err != nil {
+			return err
+		}
+
+		for _, d := range ds {
+			if _, err := tx.exec(ctx, "UPDATE webhook_deliveries SET next_attempt_at = ? WHERE id = ?", millis(until), d.Id); err != nil {
+				db.logger.Errorf("sqldb.ClaimWebhookDeliveries: sql.Exec >> %v", err)
+				return err
+			}
+			d.NextAttemptAt = until.UTC().Truncate(time.Millisecond)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ds, nil
+}
+
+// GetWebhookDeliveries returns a webhook's most recent deliveries, newest
+// first.
+func (db *DB) GetWebhookDeliveries(webhookId uuid.UUID, limit int64) ([]*domain.WebhookDelivery, error) {
+	return db.findDeliveries(context.Background(), db.pool(), "GetWebhookDeliveries",
+		"SELECT "+deliveryColumns+" FROM webhook_deliveries WHERE webhook_id = ? ORDER BY created_at DESC, id LIMIT ?",
+		webhookId, limit)
+}
+
+func (db *DB) findDeliveries(ctx context.Context, c conn, op string, query string, args ...interface{}) ([]*domain.WebhookDelivery, error) {
+	rows, err := c.query(ctx, query, args...)
+	if err != nil {
+		db.logger.Errorf("sqldb.%s: sql.Query >> %v", op, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	ds := make([]*domain.WebhookDelivery, 0)
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			db.logger.Errorf("sqldb.%s: sql.Scan >> %v", op, err)
+			return nil, err
+		}
+		ds = append(ds, d)
+	}
+	if err := rows.Err(); err != nil {
+		db.logger.Errorf("sqldb.%s: sql.Rows >> %v", op, err)
+		return nil, err
+	}
+
+	return ds, nil
+}
+
+// RecordWebhookAttempt appends attempt to a delivery's log and moves it to
+// status. Pending deliveries are retried at next; others are completed.
+func (db *DB) RecordWebhookAttempt(deliveryId uuid.UUID, attempt domain.DeliveryAttempt, status domain.DeliveryStatus, next time.Time) error {
+	ctx := context.Background()
+
+	return db.transact(ctx, func(tx conn) error {
+		var raw string
+		err := tx.queryRow(ctx, "SELECT attempts FROM webhook_deliveries WHERE id = ?"+db.dialect.ForUpdate, deliveryId).Scan(&raw)
+		if err != nil {
+			return db.notFound("RecordWebhookAttempt: sql.Scan", err)
+		}
+
+		attempts := []domain.DeliveryAttempt{}
+		if err := json.Unmarshal([]byte(raw), &attempts); err != nil {
+			db.logger.Errorf("sqldb.RecordWebhookAttempt: json.Unmarshal >> %v", err)
+			return err
+		}
+		b, err := json.Marshal(append(attempts, attempt))
+		if err != nil {
+			db.logger.Errorf("sqldb.RecordWebhookAttempt: json.Marshal >> %v", err)
+			return err
+		}
+
+		var completedAt *time.Time
+		if status != domain.DeliveryPending {
+			completedAt = &attempt.At
+		}
+
+		if _, err := tx.exec(ctx, "UPDATE webhook_deliveries SET attempts = ?, status = ?, next_attempt_at = ?, completed_at = ? WHERE id = ?",
+			string(b), status, millis(next), nullMillis(completedAt), deliveryId); err != nil {
+			db.logger.Errorf("sqldb.RecordWebhookAttempt: sql.Exec >> %v", err)
+			return err
+		}
+		return nil
+	})
+}
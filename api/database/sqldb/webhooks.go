@@ -0,0 +1,180 @@
+package sqldb
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/uuid"
+)
+
+const webhookColumns = "id, url, secret, event_types, active, created_at, updated_at"
+
+func scanWebhook(row scanner) (*domain.Webhook, error) {
+	w := new(domain.Webhook)
+	var eventTypes string
+	var createdAt, updatedAt int64
+	if err := row.Scan(&w.Id, &w.URL, &w.Secret, &eventTypes, &w.Active, &createdAt, &updatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(eventTypes), &w.EventTypes); err != nil {
+		return nil, err
+	}
+	w.CreatedAt = fromMillis(createdAt)
+	w.UpdatedAt = fromMillis(updatedAt)
+	return w, nil
+}
+
+// eventTypesJSON stores a webhook's event types, null for every event.
+func eventTypesJSON(types []domain.EventType) (string, error) {
+	if len(types) == 0 {
+		return "null", nil
+	}
+	b, err := json.Marshal(types)
+	return string(b), err
+}
+
+func (db *DB) AddWebhook(webhook *domain.Webhook) error {
+	now := db.now()
+	webhook.CreatedAt = now
+	webhook.UpdatedAt = now
+
+	eventTypes, err := eventTypesJSON(webhook.EventTypes)
+	if err != nil {
+		db.logger.Errorf("sqldb.AddWebhook: json.Marshal >> %v", err)
+		return err
+	}
+
+	if _, err := db.pool().exec(context.Background(), "INSERT INTO webhooks ("+webhookColumns+") VALUES (?, ?, ?, ?, ?, ?, ?)",
+		webhook.Id, webhook.URL, webhook.Secret, eventTypes, webhook.Active, millis(now), millis(now)); err != nil {
+		db.logger.Errorf("sqldb.AddWebhook: sql.Exec >> %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// GetWebhooks lists every webhook, oldest first.
+func (db *DB) GetWebhooks() ([]*domain.Webhook, error) {
+	return db.findWebhooks("GetWebhooks", false)
+}
+
+// ActiveWebhooks lists the active webhooks subscribed to events of type t.
+func (db *DB) ActiveWebhooks(t domain.EventType) ([]*domain.Webhook, error) {
+	ws, err := db.findWebhooks("ActiveWebhooks", true)
+	if err != nil {
+		return nil, err
+	}
+
+	// there are few webhooks, so their event types are matched here rather
+	// than in each dialect's JSON functions
+	wanted := make([]*domain.Webhook, 0, len(ws))
+	for _, w := range ws {
+		if w.Wants(t) {
+			wanted = append(wanted, w)
+		}
+	}
+	return wanted, nil
+}
+
+func (db *DB) findWebhooks(op string, activeOnly bool) ([]*domain.Webhook, error) {
+	query := "SELECT " + webhookColumns + " FROM webhooks"
+	args := []interface{}{}
+	if activeOnly {
+		query += " WHERE active = ?"
+		args = append(args, true)
+	}
+	query += " ORDER BY created_at, id"
+
+	rows, err := db.pool().query(context.Background(), query, args...)
+	if err != nil {
+		db.logger.Errorf("sqldb.%s: sql.Query >> %v", op, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	ws := make([]*domain.Webhook, 0)
+	for rows.Next() {
+		w, err := scanWebhook(rows)
+		if err != nil {
+			db.logger.Errorf("sqldb.%s: sql.Scan >> %v", op, err)
+			return nil, err
+		}
+		ws = append(ws, w)
+	}
+	if err := rows.Err(); err != nil {
+		db.logger.Errorf("sqldb.%s: sql.Rows >> %v", op, err)
+		return nil, err
+	}
+
+	return ws, nil
+}
+
+func (db *DB) GetWebhook(webhookId uuid.UUID) (*domain.Webhook, error) {
+	return db.getWebhook(context.Background(), db.pool(), webhookId)
+}
+
+func (db *DB) getWebhook(ctx context.Context, c conn, webhookId uuid.UUID) (*domain.Webhook, error) {
+	w, err := scanWebhook(c.queryRow(ctx, "SELECT "+webhookColumns+" FROM webhooks WHERE id = ?", webhookId))
+	if err != nil {
+		return nil, db.notFound("GetWebhook: sql.Scan", err)
+	}
+	return w, nil
+}
+
+// UpdateWebhook saves a webhook's URL, event types and active flag, and its
+// secret when one is given. webhook is refreshed with the stored row.
+func (db *DB) UpdateWebhook(webhook *domain.Webhook) error {
+	ctx := context.Background()
+
+	eventTypes, err := eventTypesJSON(webhook.EventTypes)
+	if err != nil {
+		db.logger.Errorf("sqldb.UpdateWebhook: json.Marshal >> %v", err)
+		return err
+	}
+
+	set := "url = ?, event_types = ?, active = ?, updated_at = ?"
+	args := []interface{}{webhook.URL, eventTypes, webhook.Active, millis(db.now())}
+	if webhook.Secret != "" {
+		set += ", secret = ?"
+		args = append(args, webhook.Secret)
+	}
+	args = append(args, webhook.Id)
+
+	var t *domain.Webhook
+	err = db.transact(ctx, func(tx conn) error {
+		res, err := tx.exec(ctx, "UPDATE webhooks SET "+set+" WHERE id = ?", args...)
+		if err != nil {
+			db.logger.Errorf("sqldb.UpdateWebhook: sql.Exec >> %v", err)
+			return err
+		}
+		if err := db.affected("UpdateWebhook", res); err != nil {
+			return err
+		}
+
+		t, err = db.getWebhook(ctx, tx, webhook.Id)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	*webhook = *t
+	return nil
+}
+
+// DeleteWebhook removes a webhook along with its delivery log.
+func (db *DB) DeleteWebhook(webhookId uuid.UUID) error {
+	res, err := db.pool().exec(context.Background(), "DELETE FROM webhooks WHERE id = ?", webhookId)
+	if err != nil {
+		db.logger.Errorf("sqldb.DeleteWebhook: sql.Exec >> %v", err)
+		return err
+	}
+
+	if err := db.affected("DeleteWebhook", res); err != nil {
+		db.logger.Errorf("sqldb.DeleteWebhook: sql.Exec >> %v", err)
+		return err
+	}
+
+	return nil
+}
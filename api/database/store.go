@@ -0,0 +1,93 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/react-pairing-challenge/migrate"
+	"github.com/music-tribe/uuid"
+)
+
+// Store is everything the service needs from a storage backend. MongoDatabase
+// is the reference implementation; others must behave the same way, down to
+// returning ErrNotFound and ErrDuplicate where it does, which the shared
+// tests in storetest check.
+type Store interface {
+	Add(feature *domain.Feature) error
+	Get(userId, id uuid.UUID) (*domain.Feature, error)
+	GetById(featureId uuid.UUID) (*domain.Feature, error)
+	GetAll(userId uuid.UUID, opts ListOptions) ([]*domain.Feature, error)
+	Search(opts ListOptions) ([]*domain.Feature, error)
+	EachFeature(ctx context.Context, opts ListOptions, withVotes bool, fn func(feature *domain.Feature, votes []*domain.Vote) error) error
+	Update(feature *domain.Feature) error
+	Delete(userId, featureId uuid.UUID) error
+	SetStatus(featureId uuid.UUID, status domain.FeatureStatus, actor string) (*domain.Feature, error)
+	ImportFeatures(actor string, features []*domain.Feature) ([]uuid.UUID, error)
+	ExistingFeatureIds(ids []uuid.UUID) ([]uuid.UUID, error)
+	MergeFeatures(targetId, sourceId uuid.UUID, actor string) (*domain.Feature, error)
+	RecountVotes(featureId uuid.UUID, actor string) (*domain.Feature, bool, error)
+
+	GetTrash(userId uuid.UUID) ([]*domain.Feature, error)
+	Restore(userId, featureId uuid.UUID) (*domain.Feature, error)
+	PurgeDeleted(before time.Time) (int64, error)
+
+	AddVote(vote *domain.Vote, budget domain.VoteBudget) (*domain.Feature, error)
+	RemoveVote(featureId, voterId uuid.UUID) (*domain.Feature, error)
+	ChangeVoteDirection(featureId, voterId uuid.UUID, direction domain.VoteDirection) (*domain.Feature, error)
+	GetVote(featureId, voterId uuid.UUID) (*domain.Vote, error)
+	GetVotes(featureId uuid.UUID) ([]*domain.Vote, error)
+	GetVotesByVoter(voterId uuid.UUID) ([]*domain.Vote, error)
+
+	AddCategory(category *domain.Category) error
+	UpdateCategory(category *domain.Category) error
+	DeleteCategory(slug string) error
+	GetCategories() ([]*domain.Category, error)
+	MissingCategories(slugs []string) ([]string, error)
+	TagCounts() (map[string]int64, error)
+
+	GetHistory(featureId uuid.UUID) ([]*domain.AuditEvent, error)
+	QueryAudit(filter AuditFilter) ([]*domain.AuditEvent, error)
+
+	Snapshot(ctx context.Context, features func(feature *domain.Feature, votes []*domain.Vote) error, audit func(event *domain.AuditEvent) error) error
+	RestoreFeatures(ctx context.Context, features []*domain.Feature, votes []*domain.Vote) ([]uuid.UUID, error)
+	RestoreAudit(ctx context.Context, events []*domain.AuditEvent) (int, error)
+	ClearFeatures(ctx context.Context) error
+
+	PendingOutbox(now time.Time, limit int64) ([]*OutboxEntry, error)
+	ClaimOutbox(now, until time.Time, limit int64) ([]*OutboxEntry, error)
+	MarkDelivered(eventId uuid.UUID, subscriber string) error
+	MarkDispatched(eventId uuid.UUID) error
+	RetryOutbox(eventId uuid.UUID, next time.Time, lastError string) error
+	MarkDead(eventId uuid.UUID, lastError string) error
+	WatchFeatures(ctx context.Context, consumer string, handle func(context.Context, *domain.Event) error) error
+
+	AddWebhook(webhook *domain.Webhook) error
+	GetWebhooks() ([]*domain.Webhook, error)
+	ActiveWebhooks(t domain.EventType) ([]*domain.Webhook, error)
+	GetWebhook(webhookId uuid.UUID) (*domain.Webhook, error)
+	UpdateWebhook(webhook *domain.Webhook) error
+	DeleteWebhook(webhookId uuid.UUID) error
+	AddWebhookDelivery(delivery *domain.WebhookDelivery) error
+	ClaimWebhookDeliveries(now, until time.Time, limit int64) ([]*domain.WebhookDelivery, error)
+	GetWebhookDeliveries(webhookId uuid.UUID, limit int64) ([]*domain.WebhookDelivery, error)
+	RecordWebhookAttempt(deliveryId uuid.UUID, attempt domain.DeliveryAttempt, status domain.DeliveryStatus, next time.Time) error
+
+	ReserveIdempotencyKey(req *domain.IdempotentRequest) error
+	GetIdempotencyKey(key string) (*domain.IdempotentRequest, error)
+	CompleteIdempotencyKey(req *domain.IdempotentRequest) error
+	ReleaseIdempotencyKey(req *domain.IdempotentRequest) error
+
+	// Migrations lists the backend's schema migrations, which the
+	// migration methods below record.
+	Migrations() []migrate.Migration
+	AppliedMigrations(ctx context.Context) ([]*domain.SchemaMigration, error)
+	RecordMigration(ctx context.Context, m *domain.SchemaMigration) error
+	RemoveMigration(ctx context.Context, version int) error
+	LockMigrations(ctx context.Context, owner string, now, expires time.Time) (bool, error)
+	UnlockMigrations(ctx context.Context, owner string) error
+
+	Close() error
+}
+
+var _ Store = (*MongoDatabase)(nil)
@@ -0,0 +1,1119 @@
+// Package storetest checks that a storage backend behaves the way the rest
+// of the service expects of a database.Store. Each backend runs the same
+// suite from its own tests, against a live database.
+package storetest
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/music-tribe/react-pairing-challenge/database"
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/react-pairing-challenge/ranking"
+	"github.com/music-tribe/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// Open connects to the backend under test with the given clock. The
+// store is closed when the test ends.
+type Open func(t *testing.T, clock database.Clock) database.Store
+
+// Run checks the backend opened by open. The database may be shared with
+// other data, so each group of checks works on features of its own board.
+func Run(t *testing.T, open Open) {
+	s := &suite{open: open}
+	run := uuid.New().String()[:8]
+
+	for _, group := range []struct {
+		name  string
+		check func(t *testing.T)
+	}{
+		{"Features", s.features},
+		{"Lists", s.lists},
+		{"Votes", s.votes},
+		{"Trash", s.trash},
+		{"Categories", s.categories},
+		{"Audit", s.audit},
+		{"Outbox", s.outbox},
+		{"Watch", s.watch},
+		{"Webhooks", s.webhooks},
+		{"Idempotency", s.idempotency},
+		{"Import", s.imports},
+		{"Merge", s.merge},
+		{"Backup", s.backup},
+		{"Migrations", s.migrations},
+	} {
+		s.board = "storetest-" + run + "-" + strings.ToLower(group.name)
+		t.Run(group.name, group.check)
+	}
+}
+
+type suite struct {
+	open Open
+	// board is the current group's
+	board string
+}
+
+func (s *suite) store(t *testing.T) database.Store {
+	return s.at(t, time.Now)
+}
+
+func (s *suite) at(t *testing.T, clock database.Clock) database.Store {
+	db := s.open(t, clock)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// feature adds a new feature to the suite's board.
+func (s *suite) feature(t *testing.T, db database.Store, name string, tags ...string) *domain.Feature {
+	f := &domain.Feature{
+		Id:          uuid.New(),
+		UserId:      uuid.New(),
+		Name:        name,
+		Description: "added by storetest",
+		Board:       s.board,
+		Tags:        tags,
+	}
+	assert.NoError(t, db.Add(f))
+	return f
+}
+
+func ids(features []*domain.Feature) []uuid.UUID {
+	out := make([]uuid.UUID, len(features))
+	for i, f := range features {
+		out[i] = f.Id
+	}
+	return out
+}
+
+func (s *suite) features(t *testing.T) {
+	db := s.store(t)
+
+	t.Run("when a feature is added, we should be able to get it back", func(t *testing.T) {
+		f := s.feature(t, db, "dark mode", "ui", "mobile-app")
+
+		got, err := db.Get(f.UserId, f.Id)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, f.Name, got.Name)
+		assert.Equal(t, s.board, got.Board)
+		assert.Equal(t, []string{"ui", "mobile-app"}, got.Tags)
+		assert.Equal(t, domain.StatusOpen, got.Status)
+		assert.Equal(t, f.CreatedAt.UnixMilli(), got.CreatedAt.UnixMilli())
+		assert.InDelta(t, f.HotScore, got.HotScore, 1e-9)
+		assert.Nil(t, got.LastVotedAt)
+
+		byId, err := db.GetById(f.Id)
+		assert.NoError(t, err)
+		assert.Equal(t, f.UserId, byId.UserId)
+	})
+
+	t.Run("when a feature is added twice, we should get ErrDuplicate", func(t *testing.T) {
+		f := s.feature(t, db, "twice")
+		assert.ErrorIs(t, db.Add(f), database.ErrDuplicate)
+	})
+
+	t.Run("when a feature belongs to someone else, we should get ErrNotFound", func(t *testing.T) {
+		f := s.feature(t, db, "not yours")
+
+		_, err := db.Get(uuid.New(), f.Id)
+		assert.ErrorIs(t, err, database.ErrNotFound)
+		_, err = db.GetById(uuid.New())
+		assert.ErrorIs(t, err, database.ErrNotFound)
+	})
+
+	t.Run("when a feature is updated, only its editable fields should change", func(t *testing.T) {
+		f := s.feature(t, db, "before", "ui")
+
+		edit := &domain.Feature{Id: f.Id, UserId: f.UserId, Name: "after", Description: "edited", Tags: []string{"audio"}, VoteCount: 99}
+		if !assert.NoError(t, db.Update(edit)) {
+			return
+		}
+		assert.Equal(t, "after", edit.Name)
+		assert.Equal(t, int64(0), edit.VoteCount)
+		assert.Equal(t, s.board, edit.Board)
+
+		got, err := db.GetById(f.Id)
+		assert.NoError(t, err)
+		assert.Equal(t, "edited", got.Description)
+		assert.Equal(t, []string{"audio"}, got.Tags)
+	})
+
+	t.Run("when a missing feature is updated, we should get ErrNotFound", func(t *testing.T) {
+		err := db.Update(&domain.Feature{Id: uuid.New(), UserId: uuid.New(), Name: "x", Description: "y"})
+		assert.ErrorIs(t, err, database.ErrNotFound)
+	})
+
+	t.Run("when a feature's status is set, it should be stored", func(t *testing.T) {
+		f := s.feature(t, db, "planned")
+
+		got, err := db.SetStatus(f.Id, domain.StatusPlanned, "admin")
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, domain.StatusPlanned, got.Status)
+
+		stored, err := db.GetById(f.Id)
+		assert.NoError(t, err)
+		assert.Equal(t, domain.StatusPlanned, stored.Status)
+
+		_, err = db.SetStatus(uuid.New(), domain.StatusDone, "admin")
+		assert.ErrorIs(t, err, database.ErrNotFound)
+	})
+}
+
+func (s *suite) lists(t *testing.T) {
+	db := s.store(t)
+	word := "zq" + uuid.New().String()[:8]
+
+	// a little apart, so newest first is a stable order
+	older := s.feature(t, db, "older "+word, "ui")
+	time.Sleep(5 * time.Millisecond)
+	newer := s.feature(t, db, "newer", "ui", "audio")
+	time.Sleep(5 * time.Millisecond)
+	voted := s.feature(t, db, "voted", "audio")
+	for i := 0; i < 2; i++ {
+		_, err := db.AddVote(&domain.Vote{Id: uuid.New(), FeatureId: voted.Id, VoterId: uuid.New()}, domain.VoteBudget{})
+		assert.NoError(t, err)
+	}
+	_, err := db.SetStatus(newer.Id, domain.StatusDone, "admin")
+	assert.NoError(t, err)
+
+	board := database.ListOptions{Board: s.board}
+
+	t.Run("when features are listed by owner, we should only get theirs", func(t *testing.T) {
+		got, err := db.GetAll(older.UserId, database.ListOptions{})
+		assert.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{older.Id}, ids(got))
+	})
+
+	t.Run("when features are sorted by top, the most voted should come first", func(t *testing.T) {
+		opts := board
+		opts.Sort = ranking.SortTop
+		got, err := db.Search(opts)
+		assert.NoError(t, err)
+		if assert.Len(t, got, 3) {
+			assert.Equal(t, voted.Id, got[0].Id)
+		}
+	})
+
+	t.Run("when features are sorted by new, the newest should come first", func(t *testing.T) {
+		got, err := db.Search(board)
+		assert.NoError(t, err)
+		if assert.Len(t, got, 3) {
+			assert.Equal(t, older.Id, got[2].Id)
+		}
+	})
+
+	t.Run("when features are filtered by tags, we should get any or all of them", func(t *testing.T) {
+		opts := board
+		opts.Tags = []string{"ui", "audio"}
+		got, err := db.Search(opts)
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []uuid.UUID{older.Id, newer.Id, voted.Id}, ids(got))
+
+		opts.MatchAllTags = true
+		got, err = db.Search(opts)
+		assert.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{newer.Id}, ids(got))
+	})
+
+	t.Run("when features are filtered by status, we should only get that status", func(t *testing.T) {
+		opts := board
+		opts.Status = domain.StatusDone
+		got, err := db.Search(opts)
+		assert.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{newer.Id}, ids(got))
+	})
+
+	t.Run("when features are searched for a word, we should get the ones using it", func(t *testing.T) {
+		got, err := db.Search(database.ListOptions{Text: word})
+		assert.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{older.Id}, ids(got))
+	})
+
+	t.Run("when every feature is walked with its votes, we should see them all", func(t *testing.T) {
+		votes := map[uuid.UUID]int{}
+		err := db.EachFeature(context.Background(), board, true, func(f *domain.Feature, vs []*domain.Vote) error {
+			votes[f.Id] = len(vs)
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, map[uuid.UUID]int{older.Id: 0, newer.Id: 0, voted.Id: 2}, votes)
+	})
+
+	t.Run("when features are tagged, we should be able to count the tags", func(t *testing.T) {
+		tag := "t" + uuid.New().String()[:8]
+		s.feature(t, db, "tagged", tag)
+		s.feature(t, db, "tagged too", tag)
+
+		counts, err := db.TagCounts()
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), counts[tag])
+	})
+}
+
+func (s *suite) votes(t *testing.T) {
+	db := s.store(t)
+
+	t.Run("when votes are cast, the feature's totals should follow", func(t *testing.T) {
+		f := s.feature(t, db, "votes")
+		voter := uuid.New()
+
+		got, err := db.AddVote(&domain.Vote{Id: uuid.New(), FeatureId: f.Id, VoterId: voter, Weight: 3}, domain.VoteBudget{})
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, int64(1), got.VoteCount)
+		assert.Equal(t, int64(3), got.Score)
+		assert.Greater(t, got.HotScore, f.HotScore)
+		assert.NotNil(t, got.LastVotedAt)
+
+		got, err = db.AddVote(&domain.Vote{Id: uuid.New(), FeatureId: f.Id, VoterId: uuid.New(), Direction: domain.Downvote}, domain.VoteBudget{})
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), got.VoteCount)
+		assert.Equal(t, int64(1), got.Upvotes)
+		assert.Equal(t, int64(1), got.Downvotes)
+		assert.Equal(t, int64(2), got.Score)
+
+		vote, err := db.GetVote(f.Id, voter)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, vote.Weight)
+		assert.Equal(t, s.board, vote.Board)
+
+		votes, err := db.GetVotes(f.Id)
+		assert.NoError(t, err)
+		assert.Len(t, votes, 2)
+
+		byVoter, err := db.GetVotesByVoter(voter)
+		assert.NoError(t, err)
+		assert.Len(t, byVoter, 1)
+	})
+
+	t.Run("when a voter votes twice, we should get ErrDuplicate", func(t *testing.T) {
+		f := s.feature(t, db, "twice")
+		voter := uuid.New()
+
+		_, err := db.AddVote(&domain.Vote{Id: uuid.New(), FeatureId: f.Id, VoterId: voter}, domain.VoteBudget{})
+		assert.NoError(t, err)
+		_, err = db.AddVote(&domain.Vote{Id: uuid.New(), FeatureId: f.Id, VoterId: voter}, domain.VoteBudget{})
+		assert.ErrorIs(t, err, database.ErrDuplicate)
+
+		got, err := db.GetById(f.Id)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), got.VoteCount)
+	})
+
+	t.Run("when a missing feature is voted for, we should get ErrNotFound", func(t *testing.T) {
+		_, err := db.AddVote(&domain.Vote{Id: uuid.New(), FeatureId: uuid.New(), VoterId: uuid.New()}, domain.VoteBudget{})
+		assert.ErrorIs(t, err, database.ErrNotFound)
+	})
+
+	t.Run("when votes are cast at once, none should be lost", func(t *testing.T) {
+		f := s.feature(t, db, "busy")
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := db.AddVote(&domain.Vote{Id: uuid.New(), FeatureId: f.Id, VoterId: uuid.New()}, domain.VoteBudget{})
+				assert.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+
+		got, err := db.GetById(f.Id)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(10), got.VoteCount)
+	})
+
+	t.Run("when a vote would go over budget, we should get ErrBudgetExhausted", func(t *testing.T) {
+		budget := domain.VoteBudget{Points: 5, Quadratic: true}
+		voter := uuid.New()
+
+		_, err := db.AddVote(&domain.Vote{Id: uuid.New(), FeatureId: s.feature(t, db, "pricey").Id, VoterId: voter, Weight: 2}, budget)
+		assert.NoError(t, err)
+
+		over := s.feature(t, db, "over budget")
+		_, err = db.AddVote(&domain.Vote{Id: uuid.New(), FeatureId: over.Id, VoterId: voter, Weight: 2}, budget)
+		assert.ErrorIs(t, err, database.ErrBudgetExhausted)
+		_, err = db.GetVote(over.Id, voter)
+		assert.ErrorIs(t, err, database.ErrNotFound)
+
+		_, err = db.AddVote(&domain.Vote{Id: uuid.New(), FeatureId: over.Id, VoterId: voter, Weight: 1}, budget)
+		assert.NoError(t, err)
+	})
+
+	t.Run("when a voted feature is in the trash, its vote should neither show nor count against the budget", func(t *testing.T) {
+		budget := domain.VoteBudget{Points: 1}
+		voter := uuid.New()
+		binned := s.feature(t, db, "binned vote")
+
+		_, err := db.AddVote(&domain.Vote{Id: uuid.New(), FeatureId: binned.Id, VoterId: voter}, budget)
+		assert.NoError(t, err)
+		assert.NoError(t, db.Delete(binned.UserId, binned.Id))
+
+		votes, err := db.GetVotesByVoter(voter)
+		assert.NoError(t, err)
+		assert.Empty(t, votes)
+
+		kept := s.feature(t, db, "budget freed")
+		_, err = db.AddVote(&domain.Vote{Id: uuid.New(), FeatureId: kept.Id, VoterId: voter}, budget)
+		assert.NoError(t, err)
+
+		_, err = db.Restore(binned.UserId, binned.Id)
+		assert.NoError(t, err)
+		votes, err = db.GetVotesByVoter(voter)
+		assert.NoError(t, err)
+		assert.Len(t, votes, 2)
+	})
+
+	t.Run("when a voter casts votes at once, the budget should still hold", func(t *testing.T) {
+		budget := domain.VoteBudget{Points: 3}
+		voter := uuid.New()
+
+		var (
+			wg       sync.WaitGroup
+			mu       sync.Mutex
+			accepted int
+		)
+		for i := 0; i < 10; i++ {
+			f := s.feature(t, db, "rush")
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := db.AddVote(&domain.Vote{Id: uuid.New(), FeatureId: f.Id, VoterId: voter}, budget)
+				if err == nil {
+					mu.Lock()
+					accepted++
+					mu.Unlock()
+					return
+				}
+				assert.ErrorIs(t, err, database.ErrBudgetExhausted)
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, 3, accepted)
+		votes, err := db.GetVotesByVoter(voter)
+		assert.NoError(t, err)
+		assert.Len(t, votes, 3)
+	})
+
+	t.Run("when a vote changes direction, the totals should be recounted", func(t *testing.T) {
+		f := s.feature(t, db, "flip")
+		voter := uuid.New()
+		_, err := db.AddVote(&domain.Vote{Id: uuid.New(), FeatureId: f.Id, VoterId: voter}, domain.VoteBudget{})
+		assert.NoError(t, err)
+
+		got, err := db.ChangeVoteDirection(f.Id, voter, domain.Downvote)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, int64(-1), got.Score)
+		assert.Equal(t, int64(1), got.Downvotes)
+
+		_, err = db.ChangeVoteDirection(f.Id, uuid.New(), domain.Upvote)
+		assert.ErrorIs(t, err, database.ErrNotFound)
+	})
+
+	t.Run("when a vote on a feature in the trash changes direction, we should get ErrNotFound", func(t *testing.T) {
+		f := s.feature(t, db, "binned flip")
+		voter := uuid.New()
+		_, err := db.AddVote(&domain.Vote{Id: uuid.New(), FeatureId: f.Id, VoterId: voter}, domain.VoteBudget{})
+		assert.NoError(t, err)
+		assert.NoError(t, db.Delete(f.UserId, f.Id))
+
+		_, err = db.ChangeVoteDirection(f.Id, voter, domain.Downvote)
+		assert.ErrorIs(t, err, database.ErrNotFound)
+
+		restored, err := db.Restore(f.UserId, f.Id)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), restored.Score)
+		assert.Equal(t, int64(0), restored.Downvotes)
+	})
+
+	t.Run("when a vote is removed, the totals should be recounted", func(t *testing.T) {
+		f := s.feature(t, db, "unvote")
+		voter := uuid.New()
+		_, err := db.AddVote(&domain.Vote{Id: uuid.New(), FeatureId: f.Id, VoterId: voter}, domain.VoteBudget{})
+		assert.NoError(t, err)
+
+		got, err := db.RemoveVote(f.Id, voter)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, int64(0), got.VoteCount)
+		assert.InDelta(t, f.HotScore, got.HotScore, 1e-9)
+
+		_, err = db.RemoveVote(f.Id, voter)
+		assert.ErrorIs(t, err, database.ErrNotFound)
+		_, err = db.GetVote(f.Id, voter)
+		assert.ErrorIs(t, err, database.ErrNotFound)
+	})
+
+	t.Run("when a vote is removed from a feature in the trash, we should get ErrNotFound", func(t *testing.T) {
+		f := s.feature(t, db, "binned unvote")
+		voter := uuid.New()
+		_, err := db.AddVote(&domain.Vote{Id: uuid.New(), FeatureId: f.Id, VoterId: voter}, domain.VoteBudget{})
+		assert.NoError(t, err)
+		assert.NoError(t, db.Delete(f.UserId, f.Id))
+
+		_, err = db.RemoveVote(f.Id, voter)
+		assert.ErrorIs(t, err, database.ErrNotFound)
+
+		restored, err := db.Restore(f.UserId, f.Id)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), restored.VoteCount)
+		_, err = db.GetVote(f.Id, voter)
+		assert.NoError(t, err)
+	})
+
+	t.Run("when the totals are already right, a recount should change nothing", func(t *testing.T) {
+		f := s.feature(t, db, "recount")
+		_, err := db.AddVote(&domain.Vote{Id: uuid.New(), FeatureId: f.Id, VoterId: uuid.New()}, domain.VoteBudget{})
+		assert.NoError(t, err)
+
+		got, changed, err := db.RecountVotes(f.Id, "admin")
+		assert.NoError(t, err)
+		assert.False(t, changed)
+		assert.Equal(t, int64(1), got.VoteCount)
+	})
+}
+
+func (s *suite) trash(t *testing.T) {
+	db := s.store(t)
+
+	t.Run("when a feature is deleted, it should move to the trash until restored", func(t *testing.T) {
+		f := s.feature(t, db, "binned")
+		if !assert.NoError(t, db.Delete(f.UserId, f.Id)) {
+			return
+		}
+
+		_, err := db.GetById(f.Id)
+		assert.ErrorIs(t, err, database.ErrNotFound)
+		assert.ErrorIs(t, db.Delete(f.UserId, f.Id), database.ErrNotFound)
+
+		trash, err := db.GetTrash(f.UserId)
+		assert.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{f.Id}, ids(trash))
+
+		restored, err := db.Restore(f.UserId, f.Id)
+		assert.NoError(t, err)
+		assert.Nil(t, restored.DeletedAt)
+
+		_, err = db.Restore(f.UserId, f.Id)
+		assert.ErrorIs(t, err, database.ErrNotFound)
+	})
+
+	t.Run("when the trash is purged, old features should go with their votes", func(t *testing.T) {
+		f := s.feature(t, db, "purged")
+		voter := uuid.New()
+		_, err := db.AddVote(&domain.Vote{Id: uuid.New(), FeatureId: f.Id, VoterId: voter}, domain.VoteBudget{})
+		assert.NoError(t, err)
+		assert.NoError(t, db.Delete(f.UserId, f.Id))
+
+		purged, err := db.PurgeDeleted(time.Now().Add(time.Minute))
+		assert.NoError(t, err)
+		assert.GreaterOrEqual(t, purged, int64(1))
+
+		trash, err := db.GetTrash(f.UserId)
+		assert.NoError(t, err)
+		assert.Empty(t, trash)
+		_, err = db.GetVote(f.Id, voter)
+		assert.ErrorIs(t, err, database.ErrNotFound)
+	})
+}
+
+func (s *suite) categories(t *testing.T) {
+	db := s.store(t)
+	slug := "c" + uuid.New().String()[:8]
+
+	t.Run("when a category is added, it should be in the taxonomy", func(t *testing.T) {
+		c := &domain.Category{Slug: slug, Name: "Storetest"}
+		assert.NoError(t, db.AddCategory(c))
+		assert.ErrorIs(t, db.AddCategory(&domain.Category{Slug: slug, Name: "Again"}), database.ErrDuplicate)
+
+		cs, err := db.GetCategories()
+		assert.NoError(t, err)
+		found := false
+		for _, c := range cs {
+			found = found || c.Slug == slug
+		}
+		assert.True(t, found)
+
+		missing, err := db.MissingCategories([]string{slug, "no-such-" + slug})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"no-such-" + slug}, missing)
+	})
+
+	t.Run("when a category is updated, it should be refreshed", func(t *testing.T) {
+		c := &domain.Category{Slug: slug, Name: "Renamed", Description: "described"}
+		assert.NoError(t, db.UpdateCategory(c))
+		assert.Equal(t, "described", c.Description)
+		assert.False(t, c.CreatedAt.IsZero())
+
+		assert.ErrorIs(t, db.UpdateCategory(&domain.Category{Slug: "no-such-" + slug}), database.ErrNotFound)
+	})
+
+	t.Run("when a category is deleted, features should lose its tag", func(t *testing.T) {
+		f := s.feature(t, db, "tagged", slug, "ui")
+
+		assert.NoError(t, db.DeleteCategory(slug))
+		assert.ErrorIs(t, db.DeleteCategory(slug), database.ErrNotFound)
+
+		got, err := db.GetById(f.Id)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"ui"}, got.Tags)
+
+		events, err := db.QueryAudit(database.AuditFilter{FeatureId: f.Id, Actor: domain.SystemActor})
+		assert.NoError(t, err)
+		if assert.Len(t, events, 1) {
+			assert.Equal(t, domain.AuditUpdated, events[0].Action)
+			if assert.Len(t, events[0].Changes, 1) {
+				assert.Equal(t, "tags", events[0].Changes[0].Field)
+			}
+		}
+	})
+}
+
+func (s *suite) audit(t *testing.T) {
+	db := s.store(t)
+
+	t.Run("when a feature changes, its history should record each change", func(t *testing.T) {
+		f := s.feature(t, db, "audited")
+		_, err := db.SetStatus(f.Id, domain.StatusPlanned, "pm")
+		assert.NoError(t, err)
+
+		history, err := db.GetHistory(f.Id)
+		assert.NoError(t, err)
+		if !assert.Len(t, history, 2) {
+			return
+		}
+		assert.Equal(t, domain.AuditCreated, history[0].Action)
+		assert.Equal(t, domain.AuditStatusChanged, history[1].Action)
+		assert.Equal(t, "pm", history[1].Actor)
+		if assert.Len(t, history[1].Changes, 1) {
+			assert.Equal(t, "status", history[1].Changes[0].Field)
+		}
+
+		events, err := db.QueryAudit(database.AuditFilter{FeatureId: f.Id, Actor: "pm"})
+		assert.NoError(t, err)
+		assert.Len(t, events, 1)
+
+		events, err = db.QueryAudit(database.AuditFilter{FeatureId: f.Id, Limit: 1})
+		assert.NoError(t, err)
+		if assert.Len(t, events, 1) {
+			assert.Equal(t, domain.AuditStatusChanged, events[0].Action)
+		}
+	})
+}
+
+func (s *suite) outbox(t *testing.T) {
+	db := s.store(t)
+
+	f := s.feature(t, db, "published")
+	pending, err := db.PendingOutbox(time.Now().Add(time.Minute), 100000)
+	assert.NoError(t, err)
+
+	var entry *database.OutboxEntry
+	for _, e := range pending {
+		if e.FeatureId == f.Id {
+			entry = e
+		}
+	}
+	if !assert.NotNil(t, entry, "the feature's event should be pending") {
+		return
+	}
+
+	t.Run("when a change is made, its event should be pending delivery", func(t *testing.T) {
+		assert.Equal(t, domain.FeatureCreated, entry.Type)
+		assert.Equal(t, s.board, entry.Board)
+		if assert.NotNil(t, entry.Feature) {
+			assert.Equal(t, f.Name, entry.Feature.Name)
+		}
+	})
+
+	t.Run("when an event is retried, delivered and dispatched, it should stop being pending", func(t *testing.T) {
+		assert.NoError(t, db.RetryOutbox(entry.Id, time.Now().Add(-time.Second), "boom"))
+		assert.NoError(t, db.MarkDelivered(entry.Id, "webhooks"))
+		assert.NoError(t, db.MarkDelivered(entry.Id, "webhooks"))
+
+		e := s.pendingEvent(t, db, entry.Id)
+		if assert.NotNil(t, e) {
+			assert.Equal(t, 1, e.Attempts)
+			assert.Equal(t, "boom", e.LastError)
+			assert.Equal(t, []string{"webhooks"}, e.Delivered)
+		}
+
+		assert.NoError(t, db.MarkDispatched(entry.Id))
+		assert.Nil(t, s.pendingEvent(t, db, entry.Id))
+	})
+
+	t.Run("when an event is claimed, it should be held from other claims until its lease ends", func(t *testing.T) {
+		g := s.feature(t, db, "claimed")
+
+		// claimed returns whether a claim at now takes g's event
+		claimed := func(now time.Time) bool {
+			es, err := db.ClaimOutbox(now, now.Add(time.Minute), 100000)
+			assert.NoError(t, err)
+			for _, e := range es {
+				if e.FeatureId == g.Id {
+					return true
+				}
+			}
+			return false
+		}
+		now := time.Now().Add(time.Second)
+		assert.True(t, claimed(now))
+		assert.False(t, claimed(now))
+		assert.True(t, claimed(now.Add(2*time.Minute)))
+	})
+
+	t.Run("when a missing event is updated, we should get ErrNotFound", func(t *testing.T) {
+		assert.ErrorIs(t, db.MarkDispatched(uuid.New()), database.ErrNotFound)
+		assert.ErrorIs(t, db.MarkDead(uuid.New(), "boom"), database.ErrNotFound)
+		assert.ErrorIs(t, db.MarkDelivered(uuid.New(), "webhooks"), database.ErrNotFound)
+	})
+}
+
+func (s *suite) pendingEvent(t *testing.T, db database.Store, id uuid.UUID) *database.OutboxEntry {
+	pending, err := db.PendingOutbox(time.Now().Add(time.Minute), 100000)
+	assert.NoError(t, err)
+	for _, e := range pending {
+		if e.Id == id {
+			return e
+		}
+	}
+	return nil
+}
+
+func (s *suite) watch(t *testing.T) {
+	db := s.store(t)
+
+	t.Run("when a feature is added while watching, we should be told", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+		defer cancel()
+
+		var mu sync.Mutex
+		added := map[uuid.UUID]bool{}
+		seen := make(chan struct{})
+		var once sync.Once
+
+		done := make(chan error, 1)
+		go func() {
+			done <- db.WatchFeatures(ctx, "storetest-"+uuid.New().String(), func(_ context.Context, e *domain.Event) error {
+				mu.Lock()
+				defer mu.Unlock()
+				if e.Type == domain.FeatureCreated && added[e.FeatureId] {
+					once.Do(func() { close(seen) })
+				}
+				return nil
+			})
+		}()
+
+		// the watch may not have started yet, so keep adding until one is
+		// seen
+		for {
+			f := &domain.Feature{Id: uuid.New(), UserId: uuid.New(), Name: "watched", Description: "d", Board: s.board}
+			mu.Lock()
+			added[f.Id] = true
+			mu.Unlock()
+			assert.NoError(t, db.Add(f))
+
+			select {
+			case <-seen:
+				cancel()
+				<-done
+				return
+			case <-ctx.Done():
+				t.Error("no event was seen")
+				return
+			case <-time.After(500 * time.Millisecond):
+			}
+		}
+	})
+}
+
+func (s *suite) webhooks(t *testing.T) {
+	db := s.store(t)
+
+	all := &domain.Webhook{Id: uuid.New(), URL: "https://example.com/all", Secret: "s", Active: true}
+	votes := &domain.Webhook{Id: uuid.New(), URL: "https://example.com/votes", Secret: "s", Active: true, EventTypes: []domain.EventType{domain.VoteCast}}
+	off := &domain.Webhook{Id: uuid.New(), URL: "https://example.com/off", Secret: "s"}
+	for _, w := range []*domain.Webhook{all, votes, off} {
+		assert.NoError(t, db.AddWebhook(w))
+	}
+	t.Cleanup(func() {
+		for _, w := range []*domain.Webhook{all, votes, off} {
+			_ = db.DeleteWebhook(w.Id)
+		}
+	})
+
+	t.Run("when webhooks are looked up by event, only active subscribers should be found", func(t *testing.T) {
+		mine := func(ws []*domain.Webhook) []uuid.UUID {
+			out := []uuid.UUID{}
+			for _, w := range ws {
+				if w.Id == all.Id || w.Id == votes.Id || w.Id == off.Id {
+					out = append(out, w.Id)
+				}
+			}
+			return out
+		}
+
+		ws, err := db.ActiveWebhooks(domain.FeatureCreated)
+		assert.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{all.Id}, mine(ws))
+
+		ws, err = db.ActiveWebhooks(domain.VoteCast)
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []uuid.UUID{all.Id, votes.Id}, mine(ws))
+
+		ws, err = db.GetWebhooks()
+		assert.NoError(t, err)
+		assert.ElementsMatch(t, []uuid.UUID{all.Id, votes.Id, off.Id}, mine(ws))
+	})
+
+	t.Run("when a webhook is updated without a secret, it should keep its secret", func(t *testing.T) {
+		w := &domain.Webhook{Id: votes.Id, URL: "https://example.com/v2", Active: false}
+		assert.NoError(t, db.UpdateWebhook(w))
+		assert.Equal(t, "s", w.Secret)
+		assert.Empty(t, w.EventTypes)
+
+		got, err := db.GetWebhook(votes.Id)
+		assert.NoError(t, err)
+		assert.Equal(t, "https://example.com/v2", got.URL)
+
+		assert.ErrorIs(t, db.UpdateWebhook(&domain.Webhook{Id: uuid.New()}), database.ErrNotFound)
+	})
+
+	t.Run("when a delivery is attempted, its log should grow until it completes", func(t *testing.T) {
+		event := domain.Event{Id: uuid.New(), Type: domain.FeatureCreated, FeatureId: uuid.New(), Board: s.board}
+		d := &domain.WebhookDelivery{Id: uuid.New(), WebhookId: all.Id, Event: event, Status: domain.DeliveryPending, NextAttemptAt: time.Now().Add(-time.Second)}
+		assert.NoError(t, db.AddWebhookDelivery(d))
+		again := *d
+		again.Id = uuid.New()
+		assert.ErrorIs(t, db.AddWebhookDelivery(&again), database.ErrDuplicate)
+
+		// claimed returns whether a claim at now takes d
+		claimed := func(now time.Time) bool {
+			ds, err := db.ClaimWebhookDeliveries(now, now.Add(time.Minute), 100000)
+			assert.NoError(t, err)
+			for _, c := range ds {
+				if c.Id == d.Id {
+					return true
+				}
+			}
+			return false
+		}
+		assert.True(t, claimed(time.Now()))
+		assert.False(t, claimed(time.Now()), "a claimed delivery should be held until its lease ends")
+		assert.True(t, claimed(time.Now().Add(2*time.Minute)), "a delivery whose lease ended should be claimed again")
+
+		failed := domain.DeliveryAttempt{At: time.Now().UTC().Truncate(time.Millisecond), StatusCode: 500}
+		assert.NoError(t, db.RecordWebhookAttempt(d.Id, failed, domain.DeliveryPending, time.Now().Add(time.Minute)))
+		ok := domain.DeliveryAttempt{At: time.Now().UTC().Truncate(time.Millisecond), StatusCode: 200}
+		assert.NoError(t, db.RecordWebhookAttempt(d.Id, ok, domain.DeliverySucceeded, time.Now()))
+
+		ds, err := db.GetWebhookDeliveries(all.Id, 10)
+		assert.NoError(t, err)
+		if assert.Len(t, ds, 1) {
+			assert.Equal(t, domain.DeliverySucceeded, ds[0].Status)
+			assert.Len(t, ds[0].Attempts, 2)
+			assert.NotNil(t, ds[0].CompletedAt)
+			assert.Equal(t, event.Id, ds[0].Event.Id)
+		}
+
+		assert.ErrorIs(t, db.RecordWebhookAttempt(uuid.New(), ok, domain.DeliverySucceeded, time.Now()), database.ErrNotFound)
+	})
+
+	t.Run("when a webhook is deleted, its deliveries should go too", func(t *testing.T) {
+		assert.NoError(t, db.DeleteWebhook(all.Id))
+		assert.ErrorIs(t, db.DeleteWebhook(all.Id), database.ErrNotFound)
+
+		_, err := db.GetWebhook(all.Id)
+		assert.ErrorIs(t, err, database.ErrNotFound)
+		ds, err := db.GetWebhookDeliveries(all.Id, 10)
+		assert.NoError(t, err)
+		assert.Empty(t, ds)
+	})
+}
+
+func (s *suite) idempotency(t *testing.T) {
+	now := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	db := s.at(t, func() time.Time { return now })
+
+	key := "POST /api/x " + uuid.New().String()
+	req := domain.IdempotentRequest{Key: key, Fingerprint: "abc", ExpiresAt: now.Add(time.Minute)}
+
+	t.Run("when a key is reserved twice, the second reservation should be refused", func(t *testing.T) {
+		assert.NoError(t, db.ReserveIdempotencyKey(&req))
+
+		again := domain.IdempotentRequest{Key: key, Fingerprint: "abc", ExpiresAt: now.Add(time.Minute)}
+		assert.ErrorIs(t, db.ReserveIdempotencyKey(&again), database.ErrDuplicate)
+	})
+
+	t.Run("when a request completes, its response should be stored", func(t *testing.T) {
+		req.Status = 201
+		req.ContentType = "application/json"
+		req.Body = []byte(`{"id":"1"}`)
+		req.ExpiresAt = now.Add(time.Hour)
+		assert.NoError(t, db.CompleteIdempotencyKey(&req))
+
+		got, err := db.GetIdempotencyKey(key)
+		assert.NoError(t, err)
+		assert.True(t, got.Completed())
+		assert.Equal(t, req.Body, got.Body)
+		assert.Equal(t, "abc", got.Fingerprint)
+	})
+
+	t.Run("when a completed key is released, it should be kept", func(t *testing.T) {
+		assert.NoError(t, db.ReleaseIdempotencyKey(&req))
+
+		_, err := db.GetIdempotencyKey(key)
+		assert.NoError(t, err)
+	})
+
+	t.Run("when a key has expired, it should be taken over", func(t *testing.T) {
+		later := s.at(t, func() time.Time { return now.Add(2 * time.Hour) })
+
+		fresh := domain.IdempotentRequest{Key: key, Fingerprint: "def", ExpiresAt: now.Add(3 * time.Hour)}
+		assert.NoError(t, later.ReserveIdempotencyKey(&fresh))
+		assert.NoError(t, later.ReleaseIdempotencyKey(&fresh))
+
+		_, err := db.GetIdempotencyKey(key)
+		assert.ErrorIs(t, err, database.ErrNotFound)
+	})
+}
+
+func (s *suite) imports(t *testing.T) {
+	db := s.store(t)
+
+	t.Run("when features are imported, taken ids should be skipped", func(t *testing.T) {
+		taken := s.feature(t, db, "taken")
+		createdAt := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+		fresh := &domain.Feature{Id: uuid.New(), UserId: uuid.New(), Name: "imported", Description: "d", Board: s.board,
+			Tags: []string{"ui"}, Status: domain.StatusDone, CreatedAt: createdAt}
+
+		skipped, err := db.ImportFeatures("importer", []*domain.Feature{
+			{Id: taken.Id, UserId: uuid.New(), Name: "clash", Description: "d", Board: s.board},
+			fresh,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{taken.Id}, skipped)
+
+		got, err := db.GetById(fresh.Id)
+		if assert.NoError(t, err) {
+			assert.Equal(t, domain.StatusDone, got.Status)
+			assert.Equal(t, createdAt.UnixMilli(), got.CreatedAt.UnixMilli())
+			assert.Equal(t, []string{"ui"}, got.Tags)
+		}
+
+		existing, err := db.ExistingFeatureIds([]uuid.UUID{uuid.New(), fresh.Id, taken.Id})
+		assert.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{fresh.Id, taken.Id}, existing)
+
+		history, err := db.GetHistory(fresh.Id)
+		assert.NoError(t, err)
+		if assert.Len(t, history, 1) {
+			assert.Equal(t, "importer", history[0].Actor)
+		}
+	})
+}
+
+func (s *suite) merge(t *testing.T) {
+	db := s.store(t)
+
+	target := s.feature(t, db, "dark mode")
+	source := s.feature(t, db, "dark theme")
+	both, other := uuid.New(), uuid.New()
+	for _, v := range []*domain.Vote{
+		{Id: uuid.New(), FeatureId: target.Id, VoterId: both},
+		{Id: uuid.New(), FeatureId: source.Id, VoterId: both},
+		{Id: uuid.New(), FeatureId: source.Id, VoterId: other},
+	} {
+		_, err := db.AddVote(v, domain.VoteBudget{})
+		assert.NoError(t, err)
+	}
+
+	t.Run("when a feature is merged into itself, we should get ErrSameFeature", func(t *testing.T) {
+		_, err := db.MergeFeatures(target.Id, target.Id, "admin")
+		assert.ErrorIs(t, err, database.ErrSameFeature)
+	})
+
+	t.Run("when a duplicate is merged, its votes should move without double counting", func(t *testing.T) {
+		merged, err := db.MergeFeatures(target.Id, source.Id, "admin")
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.Equal(t, int64(2), merged.VoteCount)
+
+		votes, err := db.GetVotes(target.Id)
+		assert.NoError(t, err)
+		assert.Len(t, votes, 2)
+
+		_, err = db.GetById(source.Id)
+		assert.ErrorIs(t, err, database.ErrNotFound)
+		trash, err := db.GetTrash(source.UserId)
+		assert.NoError(t, err)
+		if assert.Len(t, trash, 1) {
+			assert.Equal(t, int64(0), trash[0].VoteCount)
+		}
+	})
+}
+
+func (s *suite) backup(t *testing.T) {
+	db := s.store(t)
+
+	voted := s.feature(t, db, "voted", "ui")
+	_, err := db.AddVote(&domain.Vote{Id: uuid.New(), FeatureId: voted.Id, VoterId: uuid.New()}, domain.VoteBudget{})
+	assert.NoError(t, err)
+	trashed := s.feature(t, db, "trashed")
+	assert.NoError(t, db.Delete(trashed.UserId, trashed.Id))
+
+	t.Run("when a snapshot is taken, it should include trashed features, votes and audit", func(t *testing.T) {
+		features := map[uuid.UUID]*domain.Feature{}
+		votes := map[uuid.UUID][]*domain.Vote{}
+		audited := map[uuid.UUID]int{}
+		err := db.Snapshot(context.Background(), func(f *domain.Feature, vs []*domain.Vote) error {
+			if f.Board == s.board {
+				features[f.Id] = f
+				votes[f.Id] = vs
+			}
+			return nil
+		}, func(e *domain.AuditEvent) error {
+			audited[e.FeatureId]++
+			return nil
+		})
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		assert.Len(t, features, 2)
+		if assert.Contains(t, features, voted.Id) {
+			assert.Equal(t, int64(1), features[voted.Id].VoteCount)
+			assert.Equal(t, []string{"ui"}, features[voted.Id].Tags)
+			assert.Len(t, votes[voted.Id], 1)
+		}
+		if assert.Contains(t, features, trashed.Id) {
+			assert.NotNil(t, features[trashed.Id].DeletedAt)
+			assert.Empty(t, votes[trashed.Id])
+		}
+		assert.Equal(t, 2, audited[trashed.Id])
+	})
+
+	t.Run("when features are restored, they should come back as given and taken ids should be skipped", func(t *testing.T) {
+		castAt := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+		restored := &domain.Feature{Id: uuid.New(), UserId: uuid.New(), Name: "restored", Description: "d", Board: s.board,
+			Tags: []string{"ui"}, Status: domain.StatusPlanned, VoteCount: 1, Upvotes: 1, Score: 1, HotScore: 12.5,
+			CreatedAt: castAt.Add(-time.Hour), UpdatedAt: castAt, LastVotedAt: &castAt}
+		vote := &domain.Vote{Id: uuid.New(), FeatureId: restored.Id, VoterId: uuid.New(), Board: s.board, CastAt: castAt,
+			Direction: domain.Upvote, Weight: 1}
+		clash := &domain.Vote{Id: uuid.New(), FeatureId: voted.Id, VoterId: uuid.New(), Board: s.board, CastAt: castAt}
+
+		skipped, err := db.RestoreFeatures(context.Background(),
+			[]*domain.Feature{{Id: voted.Id, UserId: uuid.New(), Name: "clash", Description: "d", Board: s.board}, restored},
+			[]*domain.Vote{vote, clash})
+		assert.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{voted.Id}, skipped)
+
+		got, err := db.GetById(restored.Id)
+		if assert.NoError(t, err) {
+			assert.Equal(t, domain.StatusPlanned, got.Status)
+			assert.Equal(t, int64(1), got.VoteCount)
+			assert.Equal(t, 12.5, got.HotScore)
+			assert.Equal(t, castAt.UnixMilli(), got.LastVotedAt.UnixMilli())
+		}
+
+		vs, err := db.GetVotes(restored.Id)
+		assert.NoError(t, err)
+		if assert.Len(t, vs, 1) {
+			assert.Equal(t, vote.Id, vs[0].Id)
+		}
+
+		vs, err = db.GetVotes(voted.Id)
+		assert.NoError(t, err)
+		assert.Len(t, vs, 1)
+
+		history, err := db.GetHistory(restored.Id)
+		assert.NoError(t, err)
+		assert.Empty(t, history)
+	})
+
+	t.Run("when audit events are restored twice, the second time should write nothing", func(t *testing.T) {
+		featureId := uuid.New()
+		events := []*domain.AuditEvent{
+			{Id: uuid.New(), FeatureId: featureId, Actor: "admin", Action: domain.AuditCreated, At: time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)},
+			{Id: uuid.New(), FeatureId: featureId, Actor: "admin", Action: domain.AuditDeleted, At: time.Date(2024, 3, 2, 9, 30, 0, 0, time.UTC)},
+		}
+
+		n, err := db.RestoreAudit(context.Background(), events)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, n)
+
+		n, err = db.RestoreAudit(context.Background(), events)
+		assert.NoError(t, err)
+		assert.Equal(t, 0, n)
+
+		history, err := db.GetHistory(featureId)
+		assert.NoError(t, err)
+		assert.Len(t, history, 2)
+	})
+}
+
+func (s *suite) migrations(t *testing.T) {
+	db := s.store(t)
+	ctx := context.Background()
+
+	t.Run("when the lock is held, another owner should be refused until it is released", func(t *testing.T) {
+		now := time.Now().UTC().Truncate(time.Millisecond)
+		first, second := "storetest-"+uuid.New().String(), "storetest-"+uuid.New().String()
+
+		ok, err := db.LockMigrations(ctx, first, now, now.Add(time.Minute))
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		ok, err = db.LockMigrations(ctx, first, now, now.Add(2*time.Minute))
+		assert.NoError(t, err)
+		assert.True(t, ok, "the owner should be able to extend its lock")
+
+		ok, err = db.LockMigrations(ctx, second, now, now.Add(time.Minute))
+		assert.NoError(t, err)
+		assert.False(t, ok)
+
+		assert.NoError(t, db.UnlockMigrations(ctx, first))
+		ok, err = db.LockMigrations(ctx, second, now, now.Add(time.Minute))
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.NoError(t, db.UnlockMigrations(ctx, second))
+	})
+
+	t.Run("when a migration is recorded, it should be listed until removed", func(t *testing.T) {
+		version := 100000 + int(time.Now().UnixNano()%100000)
+		m := &domain.SchemaMigration{Version: version, Name: "storetest", AppliedAt: time.Now().UTC().Truncate(time.Millisecond), Duration: 1500 * time.Millisecond}
+		assert.NoError(t, db.RecordMigration(ctx, m))
+
+		find := func() *domain.SchemaMigration {
+			applied, err := db.AppliedMigrations(ctx)
+			assert.NoError(t, err)
+			for _, a := range applied {
+				if a.Version == version {
+					return a
+				}
+			}
+			return nil
+		}
+
+		if got := find(); assert.NotNil(t, got) {
+			assert.Equal(t, "storetest", got.Name)
+			assert.Equal(t, m.Duration, got.Duration)
+		}
+
+		assert.NoError(t, db.RemoveMigration(ctx, version))
+		assert.Nil(t, find())
+	})
+}
@@ -0,0 +1,90 @@
+package database
+
+import (
+	"context"
+
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// transact runs fn in a mongo transaction, so that a change to a feature is
+// committed together with its audit and outbox events or not at all. Errors
+// returned by fn are passed back unchanged.
+func (mdb *MongoDatabase) transact(fn func(ctx mongo.SessionContext) error) error {
+	sess, err := mdb.client.StartSession()
+	if err != nil {
+		mdb.logger.Errorf("database.transact: mongo.StartSession >> %v", err)
+		return err
+	}
+	defer sess.EndSession(context.Background())
+
+	_, err = sess.WithTransaction(context.Background(), func(ctx mongo.SessionContext) (interface{}, error) {
+		return nil, fn(ctx)
+	})
+	return err
+}
+
+// record appends the audit event and the outbox event describing a change
+// from before to after. It must be called with the transaction context of
+// the change itself.
+func (mdb *MongoDatabase) record(ctx context.Context, actor string, action domain.AuditAction, featureId uuid.UUID, before, after *domain.Feature) error {
+	db := mdb.client.Database("pair-challenge")
+
+	now := mdb.now()
+	changes := domain.DiffFeatures(before, after)
+
+	audit := &domain.AuditEvent{
+		Id:        uuid.New(),
+		FeatureId: featureId,
+		Actor:     actor,
+		Action:    action,
+		Changes:   changes,
+		At:        now,
+	}
+	if _, err := db.Collection("audit").InsertOne(ctx, audit); err != nil {
+		mdb.logger.Errorf("database.record: mongo.InsertOne >> %v", err)
+		return err
+	}
+
+	snapshot := after
+	if snapshot == nil {
+		snapshot = before
+	}
+	entry := &OutboxEntry{
+		Event: domain.Event{
+			Id:         uuid.New(),
+			Type:       action.EventType(),
+			FeatureId:  featureId,
+			Board:      snapshot.Board,
+			Actor:      actor,
+			Feature:    snapshot,
+			Changes:    changes,
+			OccurredAt: now,
+		},
+		NextAttemptAt: now,
+	}
+	if _, err := db.Collection("outbox").InsertOne(ctx, entry); err != nil {
+		mdb.logger.Errorf("database.record: mongo.InsertOne >> %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// findFeature loads the single feature matching filter.
+func (mdb *MongoDatabase) findFeature(ctx context.Context, filter bson.M) (*domain.Feature, error) {
+	coll := mdb.client.Database("pair-challenge").Collection("features")
+
+	t := new(domain.Feature)
+	if err := coll.FindOne(ctx, filter).Decode(t); err != nil {
+		mdb.logger.Errorf("database.findFeature: mongo.Decode >> %v", err)
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return t, nil
+}
@@ -0,0 +1,114 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GetTrash lists a user's deleted features, most recently deleted first.
+func (mdb *MongoDatabase) GetTrash(userId uuid.UUID) ([]*domain.Feature, error) {
+	coll := mdb.client.Database("pair-challenge").Collection("features")
+
+	opts := options.Find().SetSort(bson.D{{Key: "deletedAt", Value: -1}})
+	q, err := coll.Find(context.Background(), bson.M{"userId": userId, "deletedAt": bson.M{"$exists": true}}, opts)
+	if err != nil {
+		mdb.logger.Errorf("database.GetTrash: mongo.Find >> %v", err)
+		return nil, err
+	}
+
+	ts := make([]*domain.Feature, 0)
+	if err = q.All(context.Background(), &ts); err != nil {
+		mdb.logger.Errorf("database.GetTrash: mongo.All >> %v", err)
+		return nil, err
+	}
+
+	return ts, nil
+}
+
+// Restore takes a feature back out of its owner's trash.
+func (mdb *MongoDatabase) Restore(userId, featureId uuid.UUID) (*domain.Feature, error) {
+	coll := mdb.client.Database("pair-challenge").Collection("features")
+
+	filter := bson.M{"_id": featureId, "userId": userId, "deletedAt": bson.M{"$exists": true}}
+	update := bson.M{"$unset": bson.M{"deletedAt": ""}}
+
+	var after domain.Feature
+	err := mdb.transact(func(ctx mongo.SessionContext) error {
+		before := new(domain.Feature)
+		if err := coll.FindOneAndUpdate(ctx, filter, update).Decode(before); err != nil {
+			mdb.logger.Errorf("database.Restore: mongo.Decode >> %v", err)
+			if err == mongo.ErrNoDocuments {
+				return ErrNotFound
+			}
+			return err
+		}
+
+		after = *before
+		after.DeletedAt = nil
+
+		return mdb.record(ctx, userId.String(), domain.AuditRestored, featureId, before, &after)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &after, nil
+}
+
+// PurgeDeleted permanently removes features, and their votes, that were
+// deleted before the given time. It returns how many features were removed.
+func (mdb *MongoDatabase) PurgeDeleted(before time.Time) (int64, error) {
+	db := mdb.client.Database("pair-challenge")
+
+	q, err := db.Collection("features").Find(context.Background(), bson.M{"deletedAt": bson.M{"$lt": before}})
+	if err != nil {
+		mdb.logger.Errorf("database.PurgeDeleted: mongo.Find >> %v", err)
+		return 0, err
+	}
+
+	expired := make([]*domain.Feature, 0)
+	if err = q.All(context.Background(), &expired); err != nil {
+		mdb.logger.Errorf("database.PurgeDeleted: mongo.All >> %v", err)
+		return 0, err
+	}
+
+	// each feature goes in its own transaction so one large purge doesn't
+	// hold a long running transaction open
+	var purged int64
+	for _, feature := range expired {
+		err := mdb.transact(func(ctx mongo.SessionContext) error {
+			if _, err := db.Collection("votes").DeleteMany(ctx, bson.M{"featureId": feature.Id}); err != nil {
+				mdb.logger.Errorf("database.PurgeDeleted: mongo.DeleteMany >> %v", err)
+				return err
+			}
+
+			res, err := db.Collection("features").DeleteOne(ctx, bson.M{"_id": feature.Id, "deletedAt": feature.DeletedAt})
+			if err != nil {
+				mdb.logger.Errorf("database.PurgeDeleted: mongo.DeleteOne >> %v", err)
+				return err
+			}
+
+			// restored since we looked
+			if res.DeletedCount == 0 {
+				return ErrNotFound
+			}
+
+			return mdb.record(ctx, domain.SystemActor, domain.AuditPurged, feature.Id, feature, nil)
+		})
+		if err == ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return purged, err
+		}
+		purged++
+	}
+
+	return purged, nil
+}
@@ -1,25 +1,49 @@
 package database
 
 import (
-	"context"
-
 	"github.com/music-tribe/react-pairing-challenge/domain"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
+// Update saves the user editable fields of feature and stamps its UpdatedAt.
+// Server managed fields such as votes and CreatedAt are never overwritten;
+// feature is refreshed with the stored document on success.
 func (mdb *MongoDatabase) Update(feature *domain.Feature) error {
 	coll := mdb.client.Database("pair-challenge").Collection("features")
 
-	q := coll.FindOneAndReplace(context.Background(), bson.M{"_id": feature.Id, "userId": feature.UserId}, feature)
+	now := mdb.now()
+	update := bson.M{"$set": bson.M{
+		"name":        feature.Name,
+		"description": feature.Description,
+		"tags":        feature.Tags,
+		"updatedAt":   now,
+	}}
 
-	if err := q.Err(); err != nil {
-		mdb.logger.Errorf("database.Update: mongo.Err >> %v", err)
-		if err == mongo.ErrNoDocuments {
-			return ErrNotFound
+	var after domain.Feature
+	err := mdb.transact(func(ctx mongo.SessionContext) error {
+		q := coll.FindOneAndUpdate(ctx, live(bson.M{"_id": feature.Id, "userId": feature.UserId}), update)
+		before := new(domain.Feature)
+		if err := q.Decode(before); err != nil {
+			mdb.logger.Errorf("database.Update: mongo.Decode >> %v", err)
+			if err == mongo.ErrNoDocuments {
+				return ErrNotFound
+			}
+			return err
 		}
+
+		after = *before
+		after.Name = feature.Name
+		after.Description = feature.Description
+		after.Tags = feature.Tags
+		after.UpdatedAt = now
+
+		return mdb.record(ctx, feature.UserId.String(), domain.AuditUpdated, feature.Id, before, &after)
+	})
+	if err != nil {
 		return err
 	}
 
+	*feature = after
 	return nil
 }
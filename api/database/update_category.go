@@ -0,0 +1,36 @@
+package database
+
+import (
+	"context"
+
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// UpdateCategory saves a category's name and description, refreshing
+// category with the stored document on success.
+func (mdb *MongoDatabase) UpdateCategory(category *domain.Category) error {
+	coll := mdb.client.Database("pair-challenge").Collection("categories")
+
+	update := bson.M{"$set": bson.M{
+		"name":        category.Name,
+		"description": category.Description,
+		"updatedAt":   mdb.now(),
+	}}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	q := coll.FindOneAndUpdate(context.Background(), bson.M{"_id": category.Slug}, update, opts)
+	t := new(domain.Category)
+	if err := q.Decode(t); err != nil {
+		mdb.logger.Errorf("database.UpdateCategory: mongo.Decode >> %v", err)
+		if err == mongo.ErrNoDocuments {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	*category = *t
+	return nil
+}
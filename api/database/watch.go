@@ -0,0 +1,179 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// changeNamespace derives stable event ids from change-stream resume
+// tokens, so a change replayed after a restart keeps its id.
+var changeNamespace = uuid.MustParse("3f0f8c52-4f6e-4d0e-9d8e-5b7a1c2e9f41")
+
+// Server errors meaning a stored resume token can no longer be used.
+const (
+	codeInvalidResumeToken      = 260
+	codeChangeStreamHistoryLost = 286
+)
+
+// countFields are the feature fields a vote changes.
+var countFields = []string{"voteCount", "upvotes", "downvotes", "score"}
+
+// editFields are the feature fields an edit changes.
+var editFields = []string{"name", "description", "board", "tags"}
+
+// featureChange is a change-stream document for the features collection.
+type featureChange struct {
+	Token         bson.Raw            `bson:"_id"`
+	OperationType string              `bson:"operationType"`
+	ClusterTime   primitive.Timestamp `bson:"clusterTime"`
+	DocumentKey   struct {
+		Id uuid.UUID `bson:"_id"`
+	} `bson:"documentKey"`
+	FullDocument      *domain.Feature `bson:"fullDocument"`
+	UpdateDescription struct {
+		UpdatedFields bson.M   `bson:"updatedFields"`
+		RemovedFields []string `bson:"removedFields"`
+	} `bson:"updateDescription"`
+}
+
+// event translates the change into a domain event, or nil when it is of
+// no interest, such as a rescoring job touching only the rankings.
+func (c *featureChange) event() *domain.Event {
+	var t domain.EventType
+	switch c.OperationType {
+	case "insert":
+		t = domain.FeatureCreated
+	case "delete":
+		t = domain.FeaturePurged
+	case "update", "replace":
+		t = c.updateType()
+	}
+	if t == "" {
+		return nil
+	}
+
+	event := &domain.Event{
+		Id:         uuid.NewSHA1(changeNamespace, c.Token),
+		Type:       t,
+		FeatureId:  c.DocumentKey.Id,
+		Feature:    c.FullDocument,
+		OccurredAt: time.Unix(int64(c.ClusterTime.T), 0).UTC(),
+	}
+	if c.FullDocument != nil {
+		event.Board = c.FullDocument.Board
+	}
+
+	return event
+}
+
+func (c *featureChange) updateType() domain.EventType {
+	if c.OperationType == "replace" {
+		return domain.FeatureUpdated
+	}
+
+	updated := func(fields ...string) bool {
+		for _, f := range fields {
+			if _, ok := c.UpdateDescription.UpdatedFields[f]; ok {
+				return true
+			}
+		}
+		return false
+	}
+	removed := func(field string) bool {
+		for _, f := range c.UpdateDescription.RemovedFields {
+			if f == field {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch {
+	case updated("deletedAt"):
+		return domain.FeatureDeleted
+	case removed("deletedAt"):
+		return domain.FeatureRestored
+	case updated("status"):
+		return domain.FeatureStatusChanged
+	case updated(countFields...):
+		return domain.FeatureVotesChanged
+	case updated(editFields...) || removed("tags"):
+		return domain.FeatureUpdated
+	}
+	return ""
+}
+
+// WatchFeatures follows the change stream of the features collection,
+// passing each interesting change to handle as a domain event, until ctx is
+// done or an error occurs. The resume token is stored under consumer after
+// every change, so a restart carries on where the last run stopped. If the
+// stored token has fallen off the oplog, watching starts afresh.
+func (mdb *MongoDatabase) WatchFeatures(ctx context.Context, consumer string, handle func(context.Context, *domain.Event) error) error {
+	db := mdb.client.Database("pair-challenge")
+	tokens := db.Collection("resume_tokens")
+
+	saved := struct {
+		Token bson.Raw `bson:"token"`
+	}{}
+	err := tokens.FindOne(ctx, bson.M{"_id": consumer}).Decode(&saved)
+	if err != nil && err != mongo.ErrNoDocuments {
+		mdb.logger.Errorf("database.WatchFeatures: mongo.Decode >> %v", err)
+		return err
+	}
+
+	opts := options.ChangeStream().SetFullDocument(options.UpdateLookup)
+	if saved.Token != nil {
+		opts.SetResumeAfter(saved.Token)
+	}
+
+	stream, err := db.Collection("features").Watch(ctx, mongo.Pipeline{}, opts)
+	if saved.Token != nil && isLostToken(err) {
+		mdb.logger.Errorf("database.WatchFeatures: resume token for %q is no longer valid, changes may have been missed >> %v", consumer, err)
+		stream, err = db.Collection("features").Watch(ctx, mongo.Pipeline{}, opts.SetResumeAfter(nil))
+	}
+	if err != nil {
+		mdb.logger.Errorf("database.WatchFeatures: mongo.Watch >> %v", err)
+		return err
+	}
+	defer stream.Close(context.Background())
+
+	for stream.Next(ctx) {
+		change := featureChange{}
+		if err := stream.Decode(&change); err != nil {
+			mdb.logger.Errorf("database.WatchFeatures: mongo.Decode >> %v", err)
+			return err
+		}
+
+		if event := change.event(); event != nil {
+			if err := handle(ctx, event); err != nil {
+				return err
+			}
+		}
+
+		update := bson.M{"$set": bson.M{"token": stream.ResumeToken(), "updatedAt": mdb.now()}}
+		if _, err := tokens.UpdateOne(ctx, bson.M{"_id": consumer}, update, options.Update().SetUpsert(true)); err != nil {
+			mdb.logger.Errorf("database.WatchFeatures: mongo.UpdateOne >> %v", err)
+			return err
+		}
+	}
+
+	if err := stream.Err(); err != nil && ctx.Err() == nil {
+		mdb.logger.Errorf("database.WatchFeatures: mongo.Next >> %v", err)
+		return err
+	}
+
+	return ctx.Err()
+}
+
+func isLostToken(err error) bool {
+	var se mongo.ServerError
+	return errors.As(err, &se) && (se.HasErrorCode(codeInvalidResumeToken) || se.HasErrorCode(codeChangeStreamHistoryLost))
+}
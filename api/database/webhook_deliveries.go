@@ -0,0 +1,122 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// AddWebhookDelivery stores a delivery. ErrDuplicate is returned when the
+// event has already been queued for the webhook.
+func (mdb *MongoDatabase) AddWebhookDelivery(delivery *domain.WebhookDelivery) error {
+	coll := mdb.client.Database("pair-challenge").Collection("webhook_deliveries")
+
+	delivery.CreatedAt = mdb.now()
+	if delivery.Attempts == nil {
+		delivery.Attempts = []domain.DeliveryAttempt{}
+	}
+
+	if _, err := coll.InsertOne(context.Background(), delivery); err != nil {
+		mdb.logger.Errorf("database.AddWebhookDelivery: mongo.InsertOne >> %v", err)
+		wrEx := mongo.WriteException{}
+		if errors.As(err, &wrEx) {
+			if wrEx.HasErrorCode(11000) {
+				return ErrDuplicate
+			}
+		}
+		return err
+	}
+
+	return nil
+}
+
+// ClaimWebhookDeliveries claims up to limit pending deliveries that are due
+// an attempt at now, oldest first, by moving their next attempt to until.
+// Until then no other worker can claim them; should the claiming worker stop
+// before recording an attempt, they fall due again and are retried.
+func (mdb *MongoDatabase) ClaimWebhookDeliveries(now, until time.Time, limit int64) ([]*domain.WebhookDelivery, error) {
+	coll := mdb.client.Database("pair-challenge").Collection("webhook_deliveries")
+
+	filter := bson.M{"status": domain.DeliveryPending, "nextAttemptAt": bson.M{"$lte": now}}
+	update := bson.M{"$set": bson.M{"nextAttemptAt": until}}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.D{{Key: "nextAttemptAt", Value: 1}, {Key: "_id", Value: 1}}).
+		SetReturnDocument(options.After)
+
+	// each claim is a single atomic update, so two workers can't both win
+	// the same delivery
+	ds := make([]*domain.WebhookDelivery, 0)
+	for int64(len(ds)) < limit {
+		d := new(domain.WebhookDelivery)
+		if err := coll.FindOneAndUpdate(context.Background(), filter, update, opts).Decode(d); err != nil {
+			if err == mongo.ErrNoDocuments {
+				break
+			}
+			mdb.logger.Errorf("database.ClaimWebhookDeliveries: mongo.FindOneAndUpdate >> %v", err)
+			return nil, err
+		}
+		ds = append(ds, d)
+	}
+
+	return ds, nil
+}
+
+// GetWebhookDeliveries returns a webhook's most recent deliveries, newest
+// first.
+func (mdb *MongoDatabase) GetWebhookDeliveries(webhookId uuid.UUID, limit int64) ([]*domain.WebhookDelivery, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}}).SetLimit(limit)
+
+	return mdb.findDeliveries("GetWebhookDeliveries", bson.M{"webhookId": webhookId}, opts)
+}
+
+func (mdb *MongoDatabase) findDeliveries(op string, filter bson.M, opts *options.FindOptions) ([]*domain.WebhookDelivery, error) {
+	coll := mdb.client.Database("pair-challenge").Collection("webhook_deliveries")
+
+	q, err := coll.Find(context.Background(), filter, opts)
+	if err != nil {
+		mdb.logger.Errorf("database.%s: mongo.Find >> %v", op, err)
+		return nil, err
+	}
+
+	ds := make([]*domain.WebhookDelivery, 0)
+	if err = q.All(context.Background(), &ds); err != nil {
+		mdb.logger.Errorf("database.%s: mongo.All >> %v", op, err)
+		return nil, err
+	}
+
+	return ds, nil
+}
+
+// RecordWebhookAttempt appends attempt to a delivery's log and moves it to
+// status. Pending deliveries are retried at next; others are completed.
+func (mdb *MongoDatabase) RecordWebhookAttempt(deliveryId uuid.UUID, attempt domain.DeliveryAttempt, status domain.DeliveryStatus, next time.Time) error {
+	coll := mdb.client.Database("pair-challenge").Collection("webhook_deliveries")
+
+	set := bson.M{"status": status, "nextAttemptAt": next}
+	if status != domain.DeliveryPending {
+		set["completedAt"] = attempt.At
+	}
+	update := bson.M{
+		"$push": bson.M{"attempts": attempt},
+		"$set":  set,
+	}
+
+	res, err := coll.UpdateOne(context.Background(), bson.M{"_id": deliveryId}, update)
+	if err != nil {
+		mdb.logger.Errorf("database.RecordWebhookAttempt: mongo.UpdateOne >> %v", err)
+		return err
+	}
+
+	if res.MatchedCount == 0 {
+		mdb.logger.Errorf("database.RecordWebhookAttempt: mongo.UpdateOne >> %v", ErrNotFound)
+		return ErrNotFound
+	}
+
+	return nil
+}
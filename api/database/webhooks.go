@@ -0,0 +1,133 @@
+package database
+
+import (
+	"context"
+
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func (mdb *MongoDatabase) AddWebhook(webhook *domain.Webhook) error {
+	coll := mdb.client.Database("pair-challenge").Collection("webhooks")
+
+	now := mdb.now()
+	webhook.CreatedAt = now
+	webhook.UpdatedAt = now
+
+	if _, err := coll.InsertOne(context.Background(), webhook); err != nil {
+		mdb.logger.Errorf("database.AddWebhook: mongo.InsertOne >> %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// GetWebhooks lists every webhook, oldest first.
+func (mdb *MongoDatabase) GetWebhooks() ([]*domain.Webhook, error) {
+	return mdb.findWebhooks("GetWebhooks", bson.M{})
+}
+
+// ActiveWebhooks lists the active webhooks subscribed to events of type t.
+func (mdb *MongoDatabase) ActiveWebhooks(t domain.EventType) ([]*domain.Webhook, error) {
+	return mdb.findWebhooks("ActiveWebhooks", bson.M{
+		"active": true,
+		"$or": bson.A{
+			bson.M{"eventTypes": bson.M{"$exists": false}},
+			bson.M{"eventTypes": t},
+		},
+	})
+}
+
+func (mdb *MongoDatabase) findWebhooks(op string, filter bson.M) ([]*domain.Webhook, error) {
+	coll := mdb.client.Database("pair-challenge").Collection("webhooks")
+
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}})
+	q, err := coll.Find(context.Background(), filter, opts)
+	if err != nil {
+		mdb.logger.Errorf("database.%s: mongo.Find >> %v", op, err)
+		return nil, err
+	}
+
+	ws := make([]*domain.Webhook, 0)
+	if err = q.All(context.Background(), &ws); err != nil {
+		mdb.logger.Errorf("database.%s: mongo.All >> %v", op, err)
+		return nil, err
+	}
+
+	return ws, nil
+}
+
+func (mdb *MongoDatabase) GetWebhook(webhookId uuid.UUID) (*domain.Webhook, error) {
+	coll := mdb.client.Database("pair-challenge").Collection("webhooks")
+
+	t := new(domain.Webhook)
+	if err := coll.FindOne(context.Background(), bson.M{"_id": webhookId}).Decode(t); err != nil {
+		mdb.logger.Errorf("database.GetWebhook: mongo.Decode >> %v", err)
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// UpdateWebhook saves a webhook's URL, event types and active flag, and its
+// secret when one is given. webhook is refreshed with the stored document.
+func (mdb *MongoDatabase) UpdateWebhook(webhook *domain.Webhook) error {
+	coll := mdb.client.Database("pair-challenge").Collection("webhooks")
+
+	set := bson.M{
+		"url":       webhook.URL,
+		"active":    webhook.Active,
+		"updatedAt": mdb.now(),
+	}
+	update := bson.M{"$set": set}
+	if len(webhook.EventTypes) > 0 {
+		set["eventTypes"] = webhook.EventTypes
+	} else {
+		update["$unset"] = bson.M{"eventTypes": ""}
+	}
+	if webhook.Secret != "" {
+		set["secret"] = webhook.Secret
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	t := new(domain.Webhook)
+	if err := coll.FindOneAndUpdate(context.Background(), bson.M{"_id": webhook.Id}, update, opts).Decode(t); err != nil {
+		mdb.logger.Errorf("database.UpdateWebhook: mongo.Decode >> %v", err)
+		if err == mongo.ErrNoDocuments {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	*webhook = *t
+	return nil
+}
+
+// DeleteWebhook removes a webhook along with its delivery log.
+func (mdb *MongoDatabase) DeleteWebhook(webhookId uuid.UUID) error {
+	db := mdb.client.Database("pair-challenge")
+
+	res, err := db.Collection("webhooks").DeleteOne(context.Background(), bson.M{"_id": webhookId})
+	if err != nil {
+		mdb.logger.Errorf("database.DeleteWebhook: mongo.DeleteOne >> %v", err)
+		return err
+	}
+
+	if res.DeletedCount == 0 {
+		mdb.logger.Errorf("database.DeleteWebhook: mongo.DeleteOne >> %v", ErrNotFound)
+		return ErrNotFound
+	}
+
+	if _, err := db.Collection("webhook_deliveries").DeleteMany(context.Background(), bson.M{"webhookId": webhookId}); err != nil {
+		mdb.logger.Errorf("database.DeleteWebhook: mongo.DeleteMany >> %v", err)
+		return err
+	}
+
+	return nil
+}
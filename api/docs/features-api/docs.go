@@ -18,51 +18,83 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api/vote/{featureId}": {
-            "put": {
-                "description": "Enables the user to place one vote against another users feature request.",
-                "consumes": [
-                    "application/json"
+        "/api/admin/audit": {
+            "get": {
+                "security": [
+                    {
+                        "AdminKey": []
+                    }
                 ],
+                "description": "Query changes to every feature, newest first. Requires the admin API key.",
                 "produces": [
-                    "text/plain"
+                    "application/json"
                 ],
-                "summary": "Enables the user to vote for a new feature request.",
+                "summary": "Query the audit log.",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Feature ID",
+                        "description": "Only changes to this feature",
                         "name": "featureId",
-                        "in": "path",
-                        "required": true
+                        "in": "query"
                     },
                     {
-                        "description": "Upvote Request Body",
-                        "name": "upvoteRequest",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/upvote.UpvoteRequest"
-                        }
+                        "type": "string",
+                        "description": "Only changes made by this actor",
+                        "name": "actor",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "created",
+                            "updated",
+                            "voted",
+                            "vote_changed",
+                            "unvoted",
+                            "status_changed",
+                            "deleted",
+                            "restored",
+                            "purged"
+                        ],
+                        "type": "string",
+                        "description": "Only this kind of change",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only changes at or after this RFC 3339 time",
+                        "name": "since",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only changes before this RFC 3339 time",
+                        "name": "until",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Most events to return, defaults to 100",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/upvote.UpvoteResponse"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.AuditEvent"
+                            }
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {}
                     },
-                    "404": {
-                        "description": "Not Found",
-                        "schema": {}
-                    },
-                    "409": {
-                        "description": "Conflict",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {}
                     },
                     "500": {
@@ -72,41 +104,79 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/{userId}": {
+        "/api/admin/cache": {
             "get": {
-                "description": "Get a all features releted to this userId.",
+                "security": [
+                    {
+                        "AdminKey": []
+                    }
+                ],
+                "description": "Get the size and hit, miss and eviction counts of the in-memory feature cache of the replica that answers. Requires the admin API key.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get the feature cache's statistics.",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/cache.Stats"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/api/admin/categories": {
+            "post": {
+                "security": [
+                    {
+                        "AdminKey": []
+                    }
+                ],
+                "description": "Add a category to the taxonomy so features can be tagged with its slug. Requires the admin API key.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "text/plain"
+                    "application/json"
                 ],
-                "summary": "Get all of a users features.",
+                "summary": "Add a feature category.",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "User UUID",
-                        "name": "userId",
-                        "in": "path",
-                        "required": true
+                        "description": "Category",
+                        "name": "category",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreateCategory"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/domain.Feature"
-                            }
+                            "$ref": "#/definitions/domain.Category"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {}
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {}
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
                         "schema": {}
                     },
                     "500": {
@@ -114,31 +184,38 @@ const docTemplate = `{
                         "schema": {}
                     }
                 }
-            },
+            }
+        },
+        "/api/admin/categories/{slug}": {
             "put": {
-                "description": "Get a all features releted to this userId.",
+                "security": [
+                    {
+                        "AdminKey": []
+                    }
+                ],
+                "description": "Update the name and description of a category. Slugs can't be changed. Requires the admin API key.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "text/plain"
+                    "application/json"
                 ],
-                "summary": "Get all of a users features.",
+                "summary": "Update a feature category.",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User UUID",
-                        "name": "userId",
+                        "description": "Category slug",
+                        "name": "slug",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Feature",
-                        "name": "feature",
+                        "description": "Category",
+                        "name": "category",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/domain.Feature"
+                            "$ref": "#/definitions/dto.UpdateCategory"
                         }
                     }
                 ],
@@ -146,63 +223,72 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.Feature"
+                            "$ref": "#/definitions/domain.Category"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {}
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {}
                     },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {}
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {}
                     }
                 }
             },
-            "post": {
-                "description": "Add a new feature for this user id.",
-                "consumes": [
-                    "application/json"
+            "delete": {
+                "security": [
+                    {
+                        "AdminKey": []
+                    }
                 ],
+                "description": "Delete a category from the taxonomy and remove its tag from every feature. Requires the admin API key.",
                 "produces": [
-                    "application/json"
+                    "text/plain"
                 ],
-                "summary": "Add a new feature for this user.",
+                "summary": "Delete a feature category.",
                 "parameters": [
-                    {
-                        "description": "Feature",
-                        "name": "feature",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/add.AddRequest"
-                        }
-                    },
                     {
                         "type": "string",
-                        "description": "User UUID",
-                        "name": "userId",
+                        "description": "Category slug",
+                        "name": "slug",
                         "in": "path",
                         "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "DELETED",
                         "schema": {
-                            "$ref": "#/definitions/add.AddResponse"
+                            "type": "string"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {}
                     },
-                    "409": {
-                        "description": "Conflict",
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
                         "schema": {}
                     },
                     "500": {
@@ -212,30 +298,37 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/{userId}/{featureId}": {
-            "get": {
-                "description": "Get a feature with matching feature and user id.",
+        "/api/admin/features/{featureId}/status": {
+            "put": {
+                "security": [
+                    {
+                        "AdminKey": []
+                    }
+                ],
+                "description": "Move a feature along the product process. Requires the admin API key.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "text/plain"
+                    "application/json"
                 ],
-                "summary": "Get a users feature.",
+                "summary": "Change the status of a feature.",
                 "parameters": [
-                    {
-                        "type": "string",
-                        "description": "User UUID",
-                        "name": "userId",
-                        "in": "path",
-                        "required": true
-                    },
                     {
                         "type": "string",
                         "description": "Feature UUID",
                         "name": "featureId",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Status",
+                        "name": "status",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/status.StatusRequest"
+                        }
                     }
                 ],
                 "responses": {
@@ -249,6 +342,10 @@ const docTemplate = `{
                         "description": "Bad Request",
                         "schema": {}
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
                     "404": {
                         "description": "Not Found",
                         "schema": {}
@@ -258,45 +355,72 @@ const docTemplate = `{
                         "schema": {}
                     }
                 }
-            },
-            "delete": {
-                "description": "Delete one of this users features.",
+            }
+        },
+        "/api/admin/import": {
+            "post": {
+                "security": [
+                    {
+                        "AdminKey": []
+                    }
+                ],
+                "description": "Add features from a CSV or JSON Lines file, sent as the request body or as the \"file\" field of a form. Rows are validated like new features; bad rows are reported and skipped, and features that already exist are skipped as duplicates. Rows without an id get one derived from their owner, board and name, so importing a file twice is safe. Requires the admin API key.",
                 "consumes": [
-                    "application/json"
+                    "text/csv",
+                    "application/x-ndjson",
+                    "multipart/form-data"
                 ],
                 "produces": [
-                    "text/plain"
+                    "application/json"
                 ],
-                "summary": "Delete a users feature.",
+                "summary": "Import features in bulk.",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User UUID",
-                        "name": "userId",
-                        "in": "path",
-                        "required": true
+                        "description": "csv, json, jsonl or ndjson; taken from the content type when absent",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Read a field from a differently named column, as field=column",
+                        "name": "map",
+                        "in": "query"
                     },
                     {
                         "type": "string",
-                        "description": "Feature UUID",
-                        "name": "featureId",
-                        "in": "path",
-                        "required": true
+                        "description": "Owner of the rows that don't name one",
+                        "name": "userId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Check the file without importing anything",
+                        "name": "dryRun",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "DELETED",
+                        "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/importer.Report"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {}
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
                         "schema": {}
                     },
                     "500": {
@@ -306,21 +430,1250 @@ const docTemplate = `{
                 }
             }
         },
-        "/status": {
+        "/api/admin/webhooks": {
             "get": {
-                "description": "get the status of server.",
-                "consumes": [
-                    "*/*"
+                "security": [
+                    {
+                        "AdminKey": []
+                    }
                 ],
+                "description": "List every registered webhook. Secrets are never included. Requires the admin API key.",
                 "produces": [
-                    "text/plain"
+                    "application/json"
                 ],
-                "summary": "Show if the server is alive.",
+                "summary": "List the webhooks.",
                 "responses": {
                     "200": {
-                        "description": "I'm Alive!!!",
+                        "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Webhook"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "AdminKey": []
+                    }
+                ],
+                "description": "Register a URL to be sent signed events. The signing secret is returned once, in this response. Requires the admin API key.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Register a webhook.",
+                "parameters": [
+                    {
+                        "description": "Webhook",
+                        "name": "webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreateWebhook"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/webhooks.CreatedResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/api/admin/webhooks/{webhookId}": {
+            "get": {
+                "security": [
+                    {
+                        "AdminKey": []
+                    }
+                ],
+                "description": "Get a single webhook. Its secret is never included. Requires the admin API key.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get a webhook.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook UUID",
+                        "name": "webhookId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Webhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "AdminKey": []
+                    }
+                ],
+                "description": "Change a webhook's URL, event types or active flag. Giving a secret rotates it. Requires the admin API key.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Update a webhook.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook UUID",
+                        "name": "webhookId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Webhook",
+                        "name": "webhook",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UpdateWebhook"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Webhook"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "AdminKey": []
+                    }
+                ],
+                "description": "Delete a webhook and its delivery log. Queued deliveries are dropped. Requires the admin API key.",
+                "produces": [
+                    "text/plain"
+                ],
+                "summary": "Delete a webhook.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook UUID",
+                        "name": "webhookId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "DELETED",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/api/admin/webhooks/{webhookId}/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "AdminKey": []
+                    }
+                ],
+                "description": "Get a webhook's most recent deliveries, newest first, with the response code of every attempt. Requires the admin API key.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get a webhook's delivery log.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook UUID",
+                        "name": "webhookId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Most deliveries to return, defaults to 50",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.WebhookDelivery"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/api/admin/webhooks/{webhookId}/test": {
+            "post": {
+                "security": [
+                    {
+                        "AdminKey": []
+                    }
+                ],
+                "description": "Send a signed webhook.test event straight away, even to an inactive webhook, and return the delivery with the receiver's response. Test deliveries are not retried. Requires the admin API key.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Send a test event to a webhook.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Webhook UUID",
+                        "name": "webhookId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.WebhookDelivery"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/api/categories": {
+            "get": {
+                "description": "List every category in the taxonomy with the number of features tagged with it.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "List the feature categories.",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/categories.CategoryResponse"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/api/features": {
+            "get": {
+                "description": "Search features across all users by text, board and tags.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Search every users features.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Free text to match against names and descriptions",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only features on this board",
+                        "name": "board",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Only features with these tags",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "any",
+                            "all"
+                        ],
+                        "type": "string",
+                        "description": "Match any or all of the tags, defaults to any",
+                        "name": "match",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "hot",
+                            "top",
+                            "new",
+                            "rising"
+                        ],
+                        "type": "string",
+                        "description": "Sort order, defaults to new",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Feature"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/api/features/export": {
+            "get": {
+                "description": "Stream every feature matching the filters as CSV or JSON Lines, for analysis in a spreadsheet. Rows are written as they are read, so exports of any size start straight away. Lists such as tags and voters are separated by semicolons in CSV.",
+                "produces": [
+                    "text/csv",
+                    "application/x-ndjson"
+                ],
+                "summary": "Export features as CSV or JSON Lines.",
+                "parameters": [
+                    {
+                        "enum": [
+                            "csv",
+                            "jsonl",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "description": "Output format",
+                        "name": "format",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "id,name,voteCount,voters",
+                        "description": "Comma separated columns to export, defaults to every feature field",
+                        "name": "columns",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Free text to match against names and descriptions",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only features on this board",
+                        "name": "board",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Only features with these tags",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "any",
+                            "all"
+                        ],
+                        "type": "string",
+                        "description": "Match any or all of the tags, defaults to any",
+                        "name": "match",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "open",
+                            "planned",
+                            "in-progress",
+                            "done",
+                            "declined"
+                        ],
+                        "type": "string",
+                        "description": "Only features with this status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "hot",
+                            "top",
+                            "new",
+                            "rising"
+                        ],
+                        "type": "string",
+                        "description": "Sort order, defaults to new",
+                        "name": "sort",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The exported features",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/api/features/stream": {
+            "get": {
+                "description": "Server-Sent Events stream of new, edited, deleted and restored features, status changes and vote counts. Each event's data is a live.Message and its id can be sent back as Last-Event-ID to resume. A reset event means the missed changes are gone and the client should refetch. A heartbeat event is sent when the stream is otherwise idle.",
+                "produces": [
+                    "text/event-stream"
+                ],
+                "summary": "Stream live changes to features.",
+                "parameters": [
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Only changes to these features",
+                        "name": "featureId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only changes on this board",
+                        "name": "board",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Resume after this event",
+                        "name": "Last-Event-ID",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Resume after this event, for clients that can't set headers",
+                        "name": "lastEventId",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/live.Message"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/api/features/{featureId}/history": {
+            "get": {
+                "description": "Get every recorded change to a feature, oldest first, including who made it.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get the change history of a feature.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Feature UUID",
+                        "name": "featureId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.AuditEvent"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/api/users/{userId}/votes": {
+            "get": {
+                "description": "List a user's active votes grouped by board, with the budget spent and remaining on each.\nVotes on features in the trash are left out until the feature is restored.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Show where a user's votes are spent.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User UUID",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/uservotes.UserVotesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/api/vote/{featureId}": {
+            "put": {
+                "description": "Enables the user to place one vote against another users feature request.\nOn boards with downvotes enabled the vote may be cast down, and an existing vote can be switched by voting again in the other direction.\nWhen vote budgets are enabled each vote costs points from the user's budget for the feature's board.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "text/plain"
+                ],
+                "summary": "Enables the user to vote for a new feature request.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Feature ID",
+                        "name": "featureId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Upvote Request Body",
+                        "name": "upvoteRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/upvote.UpvoteRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bearer token for the user id, required when USER_TOKEN_SECRET is set",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Replays the first response to retries sent with the same key",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/upvote.UpvoteResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {}
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {}
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "delete": {
+                "description": "Removes the user's vote from a feature request, returning its cost to their budget.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Withdraws the user's vote for a feature request.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Feature ID",
+                        "name": "featureId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User UUID",
+                        "name": "userId",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bearer token for the user id, required when USER_TOKEN_SECRET is set",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Replays the first response to retries sent with the same key",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/upvote.UpvoteResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {}
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {}
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/api/ws": {
+            "get": {
+                "description": "Upgrades to a WebSocket bound to the user id it was opened with; votes sent over it are always cast as that user.\nWhen USER_TOKEN_SECRET is set the user's token must be sent, as the token parameter or a bearer token, as it must for PUT /api/vote/{featureId}. Browsers may only connect from origins allowed by CORS_ALLOW_ORIGINS.\nClients send JSON frames of type subscribe/unsubscribe (with boards and featureIds), vote (featureId, direction, weight) and ping.\nThe server replies with ack, voted, pong or error frames carrying the request's id, and pushes an event frame for every change to a subscribed board or feature.\nVotes follow the same rules, and share the same rate limits, as PUT /api/vote/{featureId}; a vote over the limit gets an error frame with code 429. A client that falls behind is sent a lagged frame and disconnected.",
+                "summary": "Watch and vote on boards over a WebSocket.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID the connection acts as",
+                        "name": "userId",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Token for the user id, required when USER_TOKEN_SECRET is set",
+                        "name": "token",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "101": {
+                        "description": "Switching Protocols",
+                        "schema": {
+                            "$ref": "#/definitions/socket.ServerMessage"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {}
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/api/{userId}": {
+            "get": {
+                "description": "Get a all features releted to this userId.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "text/plain"
+                ],
+                "summary": "Get all of a users features.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User UUID",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "hot",
+                            "top",
+                            "new",
+                            "rising"
+                        ],
+                        "type": "string",
+                        "description": "Sort order, defaults to new",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Only features with these tags",
+                        "name": "tag",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "any",
+                            "all"
+                        ],
+                        "type": "string",
+                        "description": "Match any or all of the tags, defaults to any",
+                        "name": "match",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Feature"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "put": {
+                "description": "Update the name, description and tags of one of this users features. Tags are kept when left out, and cleared by an empty list.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "text/plain"
+                ],
+                "summary": "Update a users feature.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User UUID",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Feature",
+                        "name": "feature",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UpdateFeature"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Feature"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "post": {
+                "description": "Add a new feature for this user id.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Add a new feature for this user.",
+                "parameters": [
+                    {
+                        "description": "Feature",
+                        "name": "feature",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreateFeature"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "User UUID",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Replays the first response to retries sent with the same key",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/add.AddResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {}
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {}
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/api/{userId}/trash": {
+            "get": {
+                "description": "Get the features this user has deleted that can still be restored, most recently deleted first.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Get a users deleted features.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User UUID",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Feature"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/api/{userId}/{featureId}": {
+            "get": {
+                "description": "Get a feature with matching feature and user id.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "text/plain"
+                ],
+                "summary": "Get a users feature.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User UUID",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Feature UUID",
+                        "name": "featureId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Feature"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            },
+            "delete": {
+                "description": "Move one of this users features to the trash, where it can be restored until it is purged.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "text/plain"
+                ],
+                "summary": "Delete a users feature.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User UUID",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Feature UUID",
+                        "name": "featureId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "DELETED",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/api/{userId}/{featureId}/restore": {
+            "post": {
+                "description": "Take one of this users features back out of the trash, votes included.",
+                "produces": [
+                    "application/json"
+                ],
+                "summary": "Restore a users deleted feature.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User UUID",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Feature UUID",
+                        "name": "featureId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Feature"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {}
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {}
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {}
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {}
+                    }
+                }
+            }
+        },
+        "/status": {
+            "get": {
+                "description": "get the status of server.",
+                "consumes": [
+                    "*/*"
+                ],
+                "produces": [
+                    "text/plain"
+                ],
+                "summary": "Show if the server is alive.",
+                "responses": {
+                    "200": {
+                        "description": "I'm Alive!!!",
+                        "schema": {
+                            "type": "string"
                         }
                     }
                 }
@@ -328,14 +1681,509 @@ const docTemplate = `{
         }
     },
     "definitions": {
-        "add.AddRequest": {
+        "add.AddResponse": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                }
+            }
+        },
+        "cache.Stats": {
+            "type": "object",
+            "properties": {
+                "capacity": {
+                    "type": "integer",
+                    "example": 10000
+                },
+                "enabled": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "evictions": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "hits": {
+                    "type": "integer",
+                    "example": 48211
+                },
+                "loads": {
+                    "type": "integer",
+                    "example": 1990
+                },
+                "misses": {
+                    "type": "integer",
+                    "example": 2034
+                },
+                "size": {
+                    "type": "integer",
+                    "example": 812
+                }
+            }
+        },
+        "categories.CategoryResponse": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string",
+                    "example": "2024-03-01T09:30:00Z"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Requests for the iOS and Android apps"
+                },
+                "featureCount": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Mobile App"
+                },
+                "slug": {
+                    "type": "string",
+                    "example": "mobile-app"
+                },
+                "updatedAt": {
+                    "type": "string",
+                    "example": "2024-03-02T14:05:00Z"
+                }
+            }
+        },
+        "domain.AuditAction": {
+            "type": "string",
+            "enum": [
+                "created",
+                "updated",
+                "voted",
+                "vote_changed",
+                "unvoted",
+                "status_changed",
+                "deleted",
+                "restored",
+                "purged"
+            ],
+            "x-enum-varnames": [
+                "AuditCreated",
+                "AuditUpdated",
+                "AuditVoted",
+                "AuditVoteChanged",
+                "AuditUnvoted",
+                "AuditStatusChanged",
+                "AuditDeleted",
+                "AuditRestored",
+                "AuditPurged"
+            ]
+        },
+        "domain.AuditEvent": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.AuditAction"
+                        }
+                    ],
+                    "example": "updated"
+                },
+                "actor": {
+                    "type": "string",
+                    "example": "effe01ec-7f09-4a1c-9453-794212a8ac26"
+                },
+                "at": {
+                    "type": "string",
+                    "example": "2024-03-02T14:05:00Z"
+                },
+                "changes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.FieldChange"
+                    }
+                },
+                "featureId": {
+                    "type": "string",
+                    "example": "f6e7f8c4-3af6-4028-ac7c-30c9d79a3fa7"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "8d0a4f8e-6b7c-4f51-9a3e-1f2b3c4d5e6f"
+                }
+            }
+        },
+        "domain.Category": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string",
+                    "example": "2024-03-01T09:30:00Z"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Requests for the iOS and Android apps"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Mobile App"
+                },
+                "slug": {
+                    "type": "string",
+                    "example": "mobile-app"
+                },
+                "updatedAt": {
+                    "type": "string",
+                    "example": "2024-03-02T14:05:00Z"
+                }
+            }
+        },
+        "domain.DeliveryAttempt": {
+            "type": "object",
+            "properties": {
+                "at": {
+                    "type": "string",
+                    "example": "2024-03-03T17:45:01Z"
+                },
+                "durationMs": {
+                    "type": "integer",
+                    "example": 84
+                },
+                "error": {
+                    "type": "string",
+                    "example": "context deadline exceeded"
+                },
+                "statusCode": {
+                    "description": "StatusCode is the receiver's response code, zero when no response\nwas received.",
+                    "type": "integer",
+                    "example": 200
+                }
+            }
+        },
+        "domain.DeliveryStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "succeeded",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "DeliveryPending",
+                "DeliverySucceeded",
+                "DeliveryFailed"
+            ]
+        },
+        "domain.Event": {
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "type": "string",
+                    "example": "155dccaa-0299-4018-ab6b-90b9ee448943"
+                },
+                "board": {
+                    "type": "string",
+                    "example": "mixer"
+                },
+                "changes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.FieldChange"
+                    }
+                },
+                "feature": {
+                    "$ref": "#/definitions/domain.Feature"
+                },
+                "featureId": {
+                    "type": "string",
+                    "example": "f6e7f8c4-3af6-4028-ac7c-30c9d79a3fa7"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "0b7c5d1e-2f3a-4b5c-8d9e-0f1a2b3c4d5e"
+                },
+                "occurredAt": {
+                    "type": "string",
+                    "example": "2024-03-03T17:45:00Z"
+                },
+                "type": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.EventType"
+                        }
+                    ],
+                    "example": "vote.cast"
+                }
+            }
+        },
+        "domain.EventType": {
+            "type": "string",
+            "enum": [
+                "feature.created",
+                "feature.updated",
+                "feature.status_changed",
+                "feature.deleted",
+                "feature.restored",
+                "feature.purged",
+                "vote.cast",
+                "vote.changed",
+                "vote.removed",
+                "feature.votes_changed",
+                "webhook.test"
+            ],
+            "x-enum-varnames": [
+                "FeatureCreated",
+                "FeatureUpdated",
+                "FeatureStatusChanged",
+                "FeatureDeleted",
+                "FeatureRestored",
+                "FeaturePurged",
+                "VoteCast",
+                "VoteChanged",
+                "VoteRemoved",
+                "FeatureVotesChanged",
+                "WebhookTest"
+            ]
+        },
+        "domain.Feature": {
+            "type": "object",
+            "required": [
+                "description",
+                "name",
+                "userId"
+            ],
+            "properties": {
+                "board": {
+                    "type": "string",
+                    "example": "mixer"
+                },
+                "createdAt": {
+                    "type": "string",
+                    "example": "2024-03-01T09:30:00Z"
+                },
+                "deletedAt": {
+                    "description": "DeletedAt is set while the feature sits in its owner's trash.",
+                    "type": "string",
+                    "example": "2024-03-04T08:15:00Z"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "Could we have this new feature please?"
+                },
+                "downvotes": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "hotScore": {
+                    "type": "number",
+                    "example": 512.37
+                },
+                "id": {
+                    "type": "string",
+                    "example": "f6e7f8c4-3af6-4028-ac7c-30c9d79a3fa7"
+                },
+                "lastVotedAt": {
+                    "type": "string",
+                    "example": "2024-03-03T17:45:00Z"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "My New Feature Request"
+                },
+                "risingScore": {
+                    "type": "number",
+                    "example": 6148.02
+                },
+                "score": {
+                    "type": "integer",
+                    "example": 44
+                },
+                "status": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.FeatureStatus"
+                        }
+                    ],
+                    "example": "open"
+                },
+                "tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "mixer",
+                        "mobile-app"
+                    ]
+                },
+                "updatedAt": {
+                    "type": "string",
+                    "example": "2024-03-02T14:05:00Z"
+                },
+                "upvotes": {
+                    "type": "integer",
+                    "example": 45
+                },
+                "userId": {
+                    "type": "string",
+                    "example": "effe01ec-7f09-4a1c-9453-794212a8ac26"
+                },
+                "voteCount": {
+                    "type": "integer",
+                    "example": 42
+                }
+            }
+        },
+        "domain.FeatureStatus": {
+            "type": "string",
+            "enum": [
+                "open",
+                "planned",
+                "in-progress",
+                "done",
+                "declined"
+            ],
+            "x-enum-varnames": [
+                "StatusOpen",
+                "StatusPlanned",
+                "StatusInProgress",
+                "StatusDone",
+                "StatusDeclined"
+            ]
+        },
+        "domain.FieldChange": {
+            "type": "object",
+            "properties": {
+                "after": {},
+                "before": {},
+                "field": {
+                    "type": "string",
+                    "example": "description"
+                }
+            }
+        },
+        "domain.VoteDirection": {
+            "type": "string",
+            "enum": [
+                "up",
+                "down"
+            ],
+            "x-enum-varnames": [
+                "Upvote",
+                "Downvote"
+            ]
+        },
+        "domain.Webhook": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "createdAt": {
+                    "type": "string",
+                    "example": "2024-03-01T09:30:00Z"
+                },
+                "eventTypes": {
+                    "description": "EventTypes limits the webhook to these events, every event when empty.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.EventType"
+                    },
+                    "example": [
+                        "feature.created",
+                        "vote.cast"
+                    ]
+                },
+                "id": {
+                    "type": "string",
+                    "example": "5a1c9e3b-7d2f-4e6a-8b0c-1d2e3f4a5b6c"
+                },
+                "updatedAt": {
+                    "type": "string",
+                    "example": "2024-03-02T14:05:00Z"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://hooks.example.com/features"
+                }
+            }
+        },
+        "domain.WebhookDelivery": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.DeliveryAttempt"
+                    }
+                },
+                "completedAt": {
+                    "type": "string",
+                    "example": "2024-03-03T17:45:01Z"
+                },
+                "createdAt": {
+                    "type": "string",
+                    "example": "2024-03-03T17:45:00Z"
+                },
+                "event": {
+                    "$ref": "#/definitions/domain.Event"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "9e8d7c6b-5a4f-4e3d-a2c1-b0a9f8e7d6c5"
+                },
+                "nextAttemptAt": {
+                    "type": "string",
+                    "example": "2024-03-03T17:45:00Z"
+                },
+                "status": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.DeliveryStatus"
+                        }
+                    ],
+                    "example": "succeeded"
+                },
+                "webhookId": {
+                    "type": "string",
+                    "example": "5a1c9e3b-7d2f-4e6a-8b0c-1d2e3f4a5b6c"
+                }
+            }
+        },
+        "dto.CreateCategory": {
+            "type": "object",
+            "required": [
+                "name",
+                "slug"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "maxLength": 1024,
+                    "example": "Requests for the iOS and Android apps"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 128,
+                    "example": "Mobile App"
+                },
+                "slug": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": "mobile-app"
+                }
+            }
+        },
+        "dto.CreateFeature": {
             "type": "object",
             "required": [
                 "description",
-                "name",
-                "userId"
+                "name"
             ],
             "properties": {
+                "board": {
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": "mixer"
+                },
                 "description": {
                     "type": "string",
                     "example": "Could we have this new feature please?"
@@ -344,36 +2192,78 @@ const docTemplate = `{
                     "type": "string",
                     "example": "My New Feature Request"
                 },
-                "userId": {
-                    "type": "string",
-                    "example": "202c25c4-b2ce-4514-9045-890a1aa896ea"
-                },
-                "votes": {
+                "tags": {
                     "type": "array",
+                    "maxItems": 10,
                     "items": {
                         "type": "string"
                     },
                     "example": [
-                        "['155dccaa-0299-4018-ab6b-90b9ee448943'",
-                        "'ef2a27c4-b03d-4190-86f2-b1dc2538243e']"
+                        "mixer",
+                        "mobile-app"
                     ]
                 }
             }
         },
-        "add.AddResponse": {
+        "dto.CreateWebhook": {
             "type": "object",
+            "required": [
+                "url"
+            ],
             "properties": {
-                "id": {
-                    "type": "string"
+                "active": {
+                    "description": "Active defaults to true.",
+                    "type": "boolean",
+                    "example": true
+                },
+                "eventTypes": {
+                    "type": "array",
+                    "maxItems": 16,
+                    "items": {
+                        "$ref": "#/definitions/domain.EventType"
+                    },
+                    "example": [
+                        "feature.created",
+                        "vote.cast"
+                    ]
+                },
+                "secret": {
+                    "type": "string",
+                    "maxLength": 256,
+                    "minLength": 16,
+                    "example": "9f86d081884c7d659a2feaa0c55ad015"
+                },
+                "url": {
+                    "type": "string",
+                    "maxLength": 2048,
+                    "example": "https://hooks.example.com/features"
                 }
             }
         },
-        "domain.Feature": {
+        "dto.UpdateCategory": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "description": {
+                    "type": "string",
+                    "maxLength": 1024,
+                    "example": "Requests for the iOS and Android apps"
+                },
+                "name": {
+                    "type": "string",
+                    "maxLength": 128,
+                    "example": "Mobile App"
+                }
+            }
+        },
+        "dto.UpdateFeature": {
             "type": "object",
             "required": [
                 "description",
-                "name",
-                "userId"
+                "id",
+                "name"
             ],
             "properties": {
                 "description": {
@@ -388,19 +2278,244 @@ const docTemplate = `{
                     "type": "string",
                     "example": "My New Feature Request"
                 },
-                "userId": {
+                "tags": {
+                    "type": "array",
+                    "maxItems": 10,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "mixer",
+                        "mobile-app"
+                    ]
+                }
+            }
+        },
+        "dto.UpdateWebhook": {
+            "type": "object",
+            "required": [
+                "url"
+            ],
+            "properties": {
+                "active": {
+                    "description": "Active defaults to true.",
+                    "type": "boolean",
+                    "example": true
+                },
+                "eventTypes": {
+                    "type": "array",
+                    "maxItems": 16,
+                    "items": {
+                        "$ref": "#/definitions/domain.EventType"
+                    },
+                    "example": [
+                        "feature.created",
+                        "vote.cast"
+                    ]
+                },
+                "secret": {
                     "type": "string",
-                    "example": "effe01ec-7f09-4a1c-9453-794212a8ac26"
+                    "maxLength": 256,
+                    "minLength": 16,
+                    "example": "9f86d081884c7d659a2feaa0c55ad015"
                 },
-                "votes": {
+                "url": {
+                    "type": "string",
+                    "maxLength": 2048,
+                    "example": "https://hooks.example.com/features"
+                }
+            }
+        },
+        "importer.Report": {
+            "type": "object",
+            "properties": {
+                "dryRun": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "duplicates": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "errors": {
+                    "description": "Errors lists the rows that failed or were skipped as duplicates.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/importer.RowError"
+                    }
+                },
+                "failed": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "imported": {
+                    "type": "integer",
+                    "example": 115
+                },
+                "rows": {
+                    "type": "integer",
+                    "example": 120
+                },
+                "truncated": {
+                    "description": "Truncated is set when there were more than MaxReportedErrors.",
+                    "type": "boolean",
+                    "example": false
+                }
+            }
+        },
+        "importer.RowError": {
+            "type": "object",
+            "properties": {
+                "duplicate": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "error": {
+                    "type": "string",
+                    "example": "Key: 'ImportFeature.Name' Error:Field validation for 'Name' failed on the 'required' tag"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "f6e7f8c4-3af6-4028-ac7c-30c9d79a3fa7"
+                },
+                "row": {
+                    "description": "Row counts from 1, not including a CSV header.",
+                    "type": "integer",
+                    "example": 7
+                }
+            }
+        },
+        "live.Message": {
+            "type": "object",
+            "properties": {
+                "board": {
+                    "type": "string",
+                    "example": "mixer"
+                },
+                "description": {
+                    "type": "string",
+                    "example": "A darker theme for late sessions"
+                },
+                "downvotes": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "feature": {
+                    "description": "Feature is only set for feature.created messages.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.Feature"
+                        }
+                    ]
+                },
+                "featureId": {
+                    "type": "string",
+                    "example": "202c25c4-b2ce-4514-9045-890a1aa896ea"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "7c9e6679-7425-40de-944b-e07fc1f90ae7"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Dark mode"
+                },
+                "occurredAt": {
+                    "type": "string",
+                    "example": "2024-03-03T17:45:00Z"
+                },
+                "score": {
+                    "type": "integer",
+                    "example": 44
+                },
+                "status": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.FeatureStatus"
+                        }
+                    ],
+                    "example": "open"
+                },
+                "tags": {
                     "type": "array",
                     "items": {
                         "type": "string"
                     },
                     "example": [
-                        "['155dccaa-0299-4018-ab6b-90b9ee448943'",
-                        "'ef2a27c4-b03d-4190-86f2-b1dc2538243e']"
+                        "ui",
+                        "mixer"
                     ]
+                },
+                "type": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.EventType"
+                        }
+                    ],
+                    "example": "vote.cast"
+                },
+                "upvotes": {
+                    "type": "integer",
+                    "example": 45
+                },
+                "voteCount": {
+                    "type": "integer",
+                    "example": 42
+                }
+            }
+        },
+        "socket.ServerMessage": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "description": "Code is the HTTP status the same failure would get over REST.",
+                    "type": "integer",
+                    "example": 409
+                },
+                "error": {
+                    "type": "string",
+                    "example": "you've already voted for this feature request"
+                },
+                "event": {
+                    "$ref": "#/definitions/live.Message"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "1"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "event"
+                },
+                "userId": {
+                    "type": "string",
+                    "example": "202c25c4-b2ce-4514-9045-890a1aa896ea"
+                },
+                "vote": {
+                    "$ref": "#/definitions/upvote.UpvoteResponse"
+                }
+            }
+        },
+        "status.StatusRequest": {
+            "type": "object",
+            "required": [
+                "status"
+            ],
+            "properties": {
+                "status": {
+                    "enum": [
+                        "open",
+                        "planned",
+                        "in-progress",
+                        "done",
+                        "declined"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.FeatureStatus"
+                        }
+                    ],
+                    "example": "planned"
                 }
             }
         },
@@ -410,24 +2525,185 @@ const docTemplate = `{
                 "userId"
             ],
             "properties": {
+                "direction": {
+                    "enum": [
+                        "up",
+                        "down"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.VoteDirection"
+                        }
+                    ],
+                    "example": "up"
+                },
+                "source": {
+                    "type": "string",
+                    "maxLength": 32,
+                    "example": "web"
+                },
                 "userId": {
                     "type": "string",
                     "example": "202c25c4-b2ce-4514-9045-890a1aa896ea"
+                },
+                "weight": {
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 1
                 }
             }
         },
         "upvote.UpvoteResponse": {
             "type": "object",
             "properties": {
+                "direction": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.VoteDirection"
+                        }
+                    ],
+                    "example": "up"
+                },
+                "downvotes": {
+                    "type": "integer",
+                    "example": 2
+                },
                 "featureId": {
                     "type": "string",
                     "example": "b1f01569-ecff-4c60-a716-435b2e51f1ff"
                 },
+                "remainingBudget": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "score": {
+                    "type": "integer",
+                    "example": 45928
+                },
+                "upvotes": {
+                    "type": "integer",
+                    "example": 45930
+                },
                 "voteCount": {
                     "type": "integer",
                     "example": 45928
                 }
             }
+        },
+        "uservotes.BoardVotes": {
+            "type": "object",
+            "properties": {
+                "board": {
+                    "type": "string",
+                    "example": "mixer"
+                },
+                "budget": {
+                    "type": "integer",
+                    "example": 10
+                },
+                "remaining": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "spent": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "votes": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/uservotes.VoteSummary"
+                    }
+                }
+            }
+        },
+        "uservotes.UserVotesResponse": {
+            "type": "object",
+            "properties": {
+                "boards": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/uservotes.BoardVotes"
+                    }
+                },
+                "unlimited": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "userId": {
+                    "type": "string",
+                    "example": "ef2a27c4-b03d-4190-86f2-b1dc2538243e"
+                }
+            }
+        },
+        "uservotes.VoteSummary": {
+            "type": "object",
+            "properties": {
+                "castAt": {
+                    "type": "string",
+                    "example": "2024-03-03T17:45:00Z"
+                },
+                "cost": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "featureId": {
+                    "type": "string",
+                    "example": "b1f01569-ecff-4c60-a716-435b2e51f1ff"
+                },
+                "weight": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "webhooks.CreatedResponse": {
+            "type": "object",
+            "properties": {
+                "active": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "createdAt": {
+                    "type": "string",
+                    "example": "2024-03-01T09:30:00Z"
+                },
+                "eventTypes": {
+                    "description": "EventTypes limits the webhook to these events, every event when empty.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.EventType"
+                    },
+                    "example": [
+                        "feature.created",
+                        "vote.cast"
+                    ]
+                },
+                "id": {
+                    "type": "string",
+                    "example": "5a1c9e3b-7d2f-4e6a-8b0c-1d2e3f4a5b6c"
+                },
+                "secret": {
+                    "type": "string",
+                    "example": "9f86d081884c7d659a2feaa0c55ad015"
+                },
+                "updatedAt": {
+                    "type": "string",
+                    "example": "2024-03-02T14:05:00Z"
+                },
+                "url": {
+                    "type": "string",
+                    "example": "https://hooks.example.com/features"
+                }
+            }
+        }
+    },
+    "securityDefinitions": {
+        "AdminKey": {
+            "description": "Bearer token matching ADMIN_API_KEY, e.g. \"Bearer s3cret\".",
+            "type": "apiKey",
+            "name": "Authorization",
+            "in": "header"
         }
     }
 }`
@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/music-tribe/uuid"
+)
+
+type AuditAction string
+
+const (
+	AuditCreated       AuditAction = "created"
+	AuditUpdated       AuditAction = "updated"
+	AuditVoted         AuditAction = "voted"
+	AuditVoteChanged   AuditAction = "vote_changed"
+	AuditUnvoted       AuditAction = "unvoted"
+	AuditStatusChanged AuditAction = "status_changed"
+	AuditDeleted       AuditAction = "deleted"
+	AuditRestored      AuditAction = "restored"
+	AuditPurged        AuditAction = "purged"
+)
+
+// SystemActor is recorded as the actor of changes the service makes on its
+// own, such as purging the trash.
+const SystemActor = "system"
+
+// AuditEvent is an immutable record of one change to a feature.
+type AuditEvent struct {
+	Id        uuid.UUID     `json:"id" bson:"_id" example:"8d0a4f8e-6b7c-4f51-9a3e-1f2b3c4d5e6f"`
+	FeatureId uuid.UUID     `json:"featureId" bson:"featureId" example:"f6e7f8c4-3af6-4028-ac7c-30c9d79a3fa7"`
+	Actor     string        `json:"actor" bson:"actor" example:"effe01ec-7f09-4a1c-9453-794212a8ac26"`
+	Action    AuditAction   `json:"action" bson:"action" example:"updated"`
+	Changes   []FieldChange `json:"changes" bson:"changes"`
+	At        time.Time     `json:"at" bson:"at" example:"2024-03-02T14:05:00Z"`
+}
+
+// FieldChange is the before and after value of one changed feature field.
+type FieldChange struct {
+	Field  string      `json:"field" bson:"field" example:"description"`
+	Before interface{} `json:"before" bson:"before"`
+	After  interface{} `json:"after" bson:"after"`
+}
+
+// DiffFeatures lists the audited fields that differ between before and
+// after. A nil before or after stands for a feature that doesn't exist.
+func DiffFeatures(before, after *Feature) []FieldChange {
+	if before == nil {
+		before = &Feature{}
+	}
+	if after == nil {
+		after = &Feature{}
+	}
+
+	fields := []FieldChange{
+		{"name", before.Name, after.Name},
+		{"description", before.Description, after.Description},
+		{"board", before.Board, after.Board},
+		{"tags", tagsValue(before.Tags), tagsValue(after.Tags)},
+		{"status", before.Status, after.Status},
+		{"voteCount", before.VoteCount, after.VoteCount},
+		{"upvotes", before.Upvotes, after.Upvotes},
+		{"downvotes", before.Downvotes, after.Downvotes},
+		{"score", before.Score, after.Score},
+		{"deletedAt", timeValue(before.DeletedAt), timeValue(after.DeletedAt)},
+	}
+
+	changes := make([]FieldChange, 0, len(fields))
+	for _, f := range fields {
+		if !reflect.DeepEqual(f.Before, f.After) {
+			changes = append(changes, f)
+		}
+	}
+	return changes
+}
+
+// tagsValue treats no tags and an empty tag list as the same thing.
+func tagsValue(tags []string) interface{} {
+	if len(tags) == 0 {
+		return nil
+	}
+	return tags
+}
+
+func timeValue(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return *t
+}
@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffFeatures(t *testing.T) {
+	t.Run("when a feature is created, every set field should be a change from nothing", func(t *testing.T) {
+		changes := DiffFeatures(nil, &Feature{Name: "dark mode", Description: "please", Board: "mixer", Status: StatusOpen})
+		assert.Equal(t, []FieldChange{
+			{"name", "", "dark mode"},
+			{"description", "", "please"},
+			{"board", "", "mixer"},
+			{"status", FeatureStatus(""), StatusOpen},
+		}, changes)
+	})
+
+	t.Run("when only the description changes, only it should be listed", func(t *testing.T) {
+		before := &Feature{Name: "dark mode", Description: "please", Tags: []string{}}
+		after := &Feature{Name: "dark mode", Description: "pretty please", UpdatedAt: time.Now()}
+		assert.Equal(t, []FieldChange{{"description", "please", "pretty please"}}, DiffFeatures(before, after))
+	})
+
+	t.Run("when a feature is deleted, the tombstone should be listed", func(t *testing.T) {
+		at := time.Date(2024, 3, 4, 8, 15, 0, 0, time.UTC)
+		changes := DiffFeatures(&Feature{}, &Feature{DeletedAt: &at})
+		assert.Equal(t, []FieldChange{{"deletedAt", nil, at}}, changes)
+	})
+
+	t.Run("when a vote is cast, the totals should be listed", func(t *testing.T) {
+		changes := DiffFeatures(&Feature{VoteCount: 1, Upvotes: 1, Score: 1}, &Feature{VoteCount: 2, Upvotes: 2, Score: 2, HotScore: 3})
+		assert.Equal(t, []FieldChange{
+			{"voteCount", int64(1), int64(2)},
+			{"upvotes", int64(1), int64(2)},
+			{"score", int64(1), int64(2)},
+		}, changes)
+	})
+}
+
+func TestFeatureStatus_Valid(t *testing.T) {
+	assert.True(t, StatusInProgress.Valid())
+	assert.False(t, FeatureStatus("shipped").Valid())
+	assert.False(t, FeatureStatus("").Valid())
+}
+
+func TestAuditAction_EventType(t *testing.T) {
+	assert.Equal(t, VoteCast, AuditVoted.EventType())
+	assert.Equal(t, FeaturePurged, AuditPurged.EventType())
+
+	for _, event := range auditEventTypes {
+		assert.Contains(t, EventTypes, event)
+	}
+}
@@ -0,0 +1,53 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// Category is an entry in the managed tag taxonomy. Features may only be
+// tagged with the slugs of existing categories.
+type Category struct {
+	Slug        string    `json:"slug" bson:"_id" example:"mobile-app"`
+	Name        string    `json:"name" bson:"name" example:"Mobile App"`
+	Description string    `json:"description,omitempty" bson:"description,omitempty" example:"Requests for the iOS and Android apps"`
+	CreatedAt   time.Time `json:"createdAt" bson:"createdAt" example:"2024-03-01T09:30:00Z"`
+	UpdatedAt   time.Time `json:"updatedAt" bson:"updatedAt" example:"2024-03-02T14:05:00Z"`
+}
+
+// NormalizeTag turns user input such as " Mobile App" into the slug form
+// tags are stored in, "mobile-app".
+func NormalizeTag(tag string) string {
+	return strings.Join(strings.Fields(strings.ToLower(tag)), "-")
+}
+
+// NormalizeTags normalizes every tag, dropping blanks and duplicates while
+// keeping the original order.
+func NormalizeTags(tags []string) []string {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	seen := map[string]bool{}
+	out := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = NormalizeTag(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		out = append(out, tag)
+	}
+	return out
+}
+
+// WithoutTag returns a copy of tags with tag left out.
+func WithoutTag(tags []string, tag string) []string {
+	out := make([]string, 0, len(tags))
+	for _, t := range tags {
+		if t != tag {
+			out = append(out, t)
+		}
+	}
+	return out
+}
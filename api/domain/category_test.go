@@ -0,0 +1,17 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	t.Run("tags should be lower cased, hyphenated and de-duplicated", func(t *testing.T) {
+		assert.Equal(t, []string{"mobile-app", "mixer"}, NormalizeTags([]string{" Mobile  App", "mixer", "", "MIXER", "mobile-app"}))
+	})
+
+	t.Run("no tags should stay nil", func(t *testing.T) {
+		assert.Nil(t, NormalizeTags(nil))
+	})
+}
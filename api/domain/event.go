@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/music-tribe/uuid"
+)
+
+type EventType string
+
+const (
+	FeatureCreated       EventType = "feature.created"
+	FeatureUpdated       EventType = "feature.updated"
+	FeatureStatusChanged EventType = "feature.status_changed"
+	FeatureDeleted       EventType = "feature.deleted"
+	FeatureRestored      EventType = "feature.restored"
+	FeaturePurged        EventType = "feature.purged"
+	VoteCast             EventType = "vote.cast"
+	VoteChanged          EventType = "vote.changed"
+	VoteRemoved          EventType = "vote.removed"
+	// FeatureVotesChanged is only published by the change-stream watcher,
+	// which sees a feature's counts change but not the vote that did it.
+	FeatureVotesChanged EventType = "feature.votes_changed"
+)
+
+// EventTypes lists every event type recorded in the outbox, in the order
+// they are documented.
+var EventTypes = []EventType{
+	FeatureCreated,
+	FeatureUpdated,
+	FeatureStatusChanged,
+	FeatureDeleted,
+	FeatureRestored,
+	FeaturePurged,
+	VoteCast,
+	VoteChanged,
+	VoteRemoved,
+}
+
+var auditEventTypes = map[AuditAction]EventType{
+	AuditCreated:       FeatureCreated,
+	AuditUpdated:       FeatureUpdated,
+	AuditStatusChanged: FeatureStatusChanged,
+	AuditDeleted:       FeatureDeleted,
+	AuditRestored:      FeatureRestored,
+	AuditPurged:        FeaturePurged,
+	AuditVoted:         VoteCast,
+	AuditVoteChanged:   VoteChanged,
+	AuditUnvoted:       VoteRemoved,
+}
+
+// EventType is the domain event published when a change of this kind is
+// made to a feature.
+func (a AuditAction) EventType() EventType {
+	return auditEventTypes[a]
+}
+
+// Event tells the rest of the system that a feature changed. Feature is the
+// feature after the change, or as it was last seen when it has been purged.
+type Event struct {
+	Id         uuid.UUID     `json:"id" bson:"_id" example:"0b7c5d1e-2f3a-4b5c-8d9e-0f1a2b3c4d5e"`
+	Type       EventType     `json:"type" bson:"type" example:"vote.cast"`
+	FeatureId  uuid.UUID     `json:"featureId" bson:"featureId" example:"f6e7f8c4-3af6-4028-ac7c-30c9d79a3fa7"`
+	Board      string        `json:"board" bson:"board" example:"mixer"`
+	Actor      string        `json:"actor" bson:"actor" example:"155dccaa-0299-4018-ab6b-90b9ee448943"`
+	Feature    *Feature      `json:"feature" bson:"feature"`
+	Changes    []FieldChange `json:"changes" bson:"changes"`
+	OccurredAt time.Time     `json:"occurredAt" bson:"occurredAt" example:"2024-03-03T17:45:00Z"`
+}
@@ -1,11 +1,31 @@
 package domain
 
-import "github.com/music-tribe/uuid"
+import (
+	"time"
+
+	"github.com/music-tribe/uuid"
+)
+
+// DefaultBoard is the board a feature lands on when none is given.
+const DefaultBoard = "default"
 
 type Feature struct {
-	Id          uuid.UUID   `json:"id" bson:"_id" example:"f6e7f8c4-3af6-4028-ac7c-30c9d79a3fa7"`
-	UserId      uuid.UUID   `json:"userId" param:"userId" bson:"userId" validate:"required" example:"effe01ec-7f09-4a1c-9453-794212a8ac26"`
-	Name        string      `json:"name" validate:"required" example:"My New Feature Request"`
-	Description string      `json:"description" validate:"required" example:"Could we have this new feature please?"`
-	Votes       []uuid.UUID `json:"votes" example:"['155dccaa-0299-4018-ab6b-90b9ee448943','ef2a27c4-b03d-4190-86f2-b1dc2538243e']"`
+	Id          uuid.UUID     `json:"id" bson:"_id" example:"f6e7f8c4-3af6-4028-ac7c-30c9d79a3fa7"`
+	UserId      uuid.UUID     `json:"userId" param:"userId" bson:"userId" validate:"required" example:"effe01ec-7f09-4a1c-9453-794212a8ac26"`
+	Name        string        `json:"name" validate:"required" example:"My New Feature Request"`
+	Description string        `json:"description" validate:"required" example:"Could we have this new feature please?"`
+	Board       string        `json:"board" bson:"board" example:"mixer"`
+	Tags        []string      `json:"tags" bson:"tags" example:"mixer,mobile-app"`
+	Status      FeatureStatus `json:"status" bson:"status" example:"open"`
+	VoteCount   int64         `json:"voteCount" bson:"voteCount" example:"42"`
+	Upvotes     int64         `json:"upvotes" bson:"upvotes" example:"45"`
+	Downvotes   int64         `json:"downvotes" bson:"downvotes" example:"3"`
+	Score       int64         `json:"score" bson:"score" example:"44"`
+	HotScore    float64       `json:"hotScore" bson:"hotScore" example:"512.37"`
+	RisingScore float64       `json:"risingScore" bson:"risingScore" example:"6148.02"`
+	CreatedAt   time.Time     `json:"createdAt" bson:"createdAt" example:"2024-03-01T09:30:00Z"`
+	UpdatedAt   time.Time     `json:"updatedAt" bson:"updatedAt" example:"2024-03-02T14:05:00Z"`
+	LastVotedAt *time.Time    `json:"lastVotedAt,omitempty" bson:"lastVotedAt,omitempty" example:"2024-03-03T17:45:00Z"`
+	// DeletedAt is set while the feature sits in its owner's trash.
+	DeletedAt *time.Time `json:"deletedAt,omitempty" bson:"deletedAt,omitempty" example:"2024-03-04T08:15:00Z"`
 }
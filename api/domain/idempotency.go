@@ -0,0 +1,26 @@
+package domain
+
+import "time"
+
+// IdempotentRequest remembers a request made with an Idempotency-Key, so
+// retries of it get the first response instead of being run again.
+type IdempotentRequest struct {
+	// Key scopes the client's key to the route it was sent to.
+	Key string `bson:"_id"`
+	// Fingerprint hashes the request, so a key reused for a different
+	// request can be told apart from a retry.
+	Fingerprint string `bson:"fingerprint"`
+	// Status is zero while the first request is still being handled.
+	Status      int       `bson:"status"`
+	ContentType string    `bson:"contentType,omitempty"`
+	Body        []byte    `bson:"body,omitempty"`
+	CreatedAt   time.Time `bson:"createdAt"`
+	// ExpiresAt is when the key may be used afresh. While the request is
+	// being handled it bounds how long a crashed replica holds the key.
+	ExpiresAt time.Time `bson:"expiresAt"`
+}
+
+// Completed reports whether the first request's response has been stored.
+func (r *IdempotentRequest) Completed() bool {
+	return r.Status != 0
+}
@@ -0,0 +1,13 @@
+package domain
+
+import "time"
+
+// SchemaMigration records a migration that has been applied to the
+// database.
+type SchemaMigration struct {
+	Version   int       `json:"version" bson:"_id"`
+	Name      string    `json:"name" bson:"name"`
+	AppliedAt time.Time `json:"appliedAt" bson:"appliedAt"`
+	// Duration is how long the migration took to run.
+	Duration time.Duration `json:"duration" bson:"duration"`
+}
@@ -0,0 +1,21 @@
+package domain
+
+// FeatureStatus tracks where a feature request is in the product process.
+type FeatureStatus string
+
+const (
+	StatusOpen       FeatureStatus = "open"
+	StatusPlanned    FeatureStatus = "planned"
+	StatusInProgress FeatureStatus = "in-progress"
+	StatusDone       FeatureStatus = "done"
+	StatusDeclined   FeatureStatus = "declined"
+)
+
+// Valid reports whether s is one of the known statuses.
+func (s FeatureStatus) Valid() bool {
+	switch s {
+	case StatusOpen, StatusPlanned, StatusInProgress, StatusDone, StatusDeclined:
+		return true
+	}
+	return false
+}
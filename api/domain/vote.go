@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/music-tribe/uuid"
+)
+
+type VoteDirection string
+
+const (
+	Upvote   VoteDirection = "up"
+	Downvote VoteDirection = "down"
+)
+
+// Sign is +1 for an upvote and -1 for a downvote. Votes stored before
+// downvotes existed have no direction and count as upvotes.
+func (d VoteDirection) Sign() int64 {
+	if d == Downvote {
+		return -1
+	}
+	return 1
+}
+
+// Vote is a single user's vote for a feature request. A voter may only hold
+// one vote per feature.
+type Vote struct {
+	Id        uuid.UUID     `json:"id" bson:"_id" example:"3c1f0d57-8f7a-4d3e-9b1a-2d5c6e7f8a9b"`
+	FeatureId uuid.UUID     `json:"featureId" bson:"featureId" example:"f6e7f8c4-3af6-4028-ac7c-30c9d79a3fa7"`
+	VoterId   uuid.UUID     `json:"voterId" bson:"voterId" example:"155dccaa-0299-4018-ab6b-90b9ee448943"`
+	Board     string        `json:"board" bson:"board" example:"mixer"`
+	CastAt    time.Time     `json:"castAt" bson:"castAt" example:"2024-03-03T17:45:00Z"`
+	Direction VoteDirection `json:"direction,omitempty" bson:"direction,omitempty" example:"up"`
+	Weight    int           `json:"weight,omitempty" bson:"weight,omitempty" example:"1"`
+	Source    string        `json:"source,omitempty" bson:"source,omitempty" example:"web"`
+}
+
+// VoteBudget caps the points a voter may have spent on one board at a time.
+// The zero value is unlimited.
+type VoteBudget struct {
+	Points int
+	// Quadratic makes a vote of weight w cost w*w points.
+	Quadratic bool
+}
+
+// Unlimited reports whether votes are cast without a budget.
+func (b VoteBudget) Unlimited() bool {
+	return b.Points <= 0
+}
+
+// Cost is the number of points a vote of the given weight uses, whichever
+// direction it is cast in.
+func (b VoteBudget) Cost(weight int) int {
+	if weight < 1 {
+		weight = 1
+	}
+	if b.Quadratic {
+		return weight * weight
+	}
+	return weight
+}
+
+// Spent totals the cost of the votes placed on board.
+func (b VoteBudget) Spent(votes []*Vote, board string) int {
+	spent := 0
+	for _, vote := range votes {
+		if vote.Board == board {
+			spent += b.Cost(vote.Weight)
+		}
+	}
+	return spent
+}
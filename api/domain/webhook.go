@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/music-tribe/uuid"
+)
+
+// WebhookTest is the type of the sample event sent to check a webhook is
+// set up correctly. It is never recorded in the outbox.
+const WebhookTest EventType = "webhook.test"
+
+// Webhook is a subscription to have events POSTed to an external URL.
+type Webhook struct {
+	Id  uuid.UUID `json:"id" bson:"_id" example:"5a1c9e3b-7d2f-4e6a-8b0c-1d2e3f4a5b6c"`
+	URL string    `json:"url" bson:"url" example:"https://hooks.example.com/features"`
+	// Secret signs every payload sent to the webhook. It is only ever shown
+	// when the webhook is created.
+	Secret string `json:"-" bson:"secret"`
+	// EventTypes limits the webhook to these events, every event when empty.
+	EventTypes []EventType `json:"eventTypes" bson:"eventTypes,omitempty" example:"feature.created,vote.cast"`
+	Active     bool        `json:"active" bson:"active" example:"true"`
+	CreatedAt  time.Time   `json:"createdAt" bson:"createdAt" example:"2024-03-01T09:30:00Z"`
+	UpdatedAt  time.Time   `json:"updatedAt" bson:"updatedAt" example:"2024-03-02T14:05:00Z"`
+}
+
+// Wants reports whether the webhook subscribes to events of type t.
+func (w *Webhook) Wants(t EventType) bool {
+	if len(w.EventTypes) == 0 {
+		return true
+	}
+	for _, want := range w.EventTypes {
+		if want == t {
+			return true
+		}
+	}
+	return false
+}
+
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliverySucceeded DeliveryStatus = "succeeded"
+	DeliveryFailed    DeliveryStatus = "failed"
+)
+
+// WebhookDelivery is one event sent, or still to be sent, to one webhook,
+// with a log of every attempt made.
+type WebhookDelivery struct {
+	Id            uuid.UUID         `json:"id" bson:"_id" example:"9e8d7c6b-5a4f-4e3d-a2c1-b0a9f8e7d6c5"`
+	WebhookId     uuid.UUID         `json:"webhookId" bson:"webhookId" example:"5a1c9e3b-7d2f-4e6a-8b0c-1d2e3f4a5b6c"`
+	Event         Event             `json:"event" bson:"event"`
+	Status        DeliveryStatus    `json:"status" bson:"status" example:"succeeded"`
+	Attempts      []DeliveryAttempt `json:"attempts" bson:"attempts"`
+	NextAttemptAt time.Time         `json:"nextAttemptAt" bson:"nextAttemptAt" example:"2024-03-03T17:45:00Z"`
+	CreatedAt     time.Time         `json:"createdAt" bson:"createdAt" example:"2024-03-03T17:45:00Z"`
+	CompletedAt   *time.Time        `json:"completedAt,omitempty" bson:"completedAt,omitempty" example:"2024-03-03T17:45:01Z"`
+}
+
+// DeliveryAttempt records the outcome of one POST to a webhook.
+type DeliveryAttempt struct {
+	At time.Time `json:"at" bson:"at" example:"2024-03-03T17:45:01Z"`
+	// StatusCode is the receiver's response code, zero when no response
+	// was received.
+	StatusCode int    `json:"statusCode" bson:"statusCode" example:"200"`
+	Error      string `json:"error,omitempty" bson:"error,omitempty" example:"context deadline exceeded"`
+	DurationMs int64  `json:"durationMs" bson:"durationMs" example:"84"`
+}
+
+// Succeeded reports whether the receiver accepted the delivery.
+func (a DeliveryAttempt) Succeeded() bool {
+	return a.Error == "" && a.StatusCode >= 200 && a.StatusCode < 300
+}
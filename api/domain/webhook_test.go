@@ -0,0 +1,20 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhook_Wants(t *testing.T) {
+	assert.True(t, (&Webhook{}).Wants(VoteCast))
+	assert.True(t, (&Webhook{EventTypes: []EventType{FeatureCreated, VoteCast}}).Wants(VoteCast))
+	assert.False(t, (&Webhook{EventTypes: []EventType{FeatureCreated}}).Wants(VoteCast))
+}
+
+func TestDeliveryAttempt_Succeeded(t *testing.T) {
+	assert.True(t, DeliveryAttempt{StatusCode: 204}.Succeeded())
+	assert.False(t, DeliveryAttempt{StatusCode: 500}.Succeeded())
+	assert.False(t, DeliveryAttempt{StatusCode: 301}.Succeeded())
+	assert.False(t, DeliveryAttempt{Error: "connection refused"}.Succeeded())
+}
@@ -0,0 +1,38 @@
+package dto
+
+import (
+	"github.com/music-tribe/react-pairing-challenge/domain"
+)
+
+// CreateCategory is the payload for adding a category to the taxonomy.
+type CreateCategory struct {
+	Slug        string `json:"slug" validate:"required,max=64" example:"mobile-app"`
+	Name        string `json:"name" validate:"required,max=128" example:"Mobile App"`
+	Description string `json:"description,omitempty" validate:"max=1024" example:"Requests for the iOS and Android apps"`
+}
+
+// UpdateCategory is the payload for renaming or redescribing a category.
+// Slugs are referenced by features so they can't be changed.
+type UpdateCategory struct {
+	Slug        string `json:"-" param:"slug" validate:"required,max=64"`
+	Name        string `json:"name" validate:"required,max=128" example:"Mobile App"`
+	Description string `json:"description,omitempty" validate:"max=1024" example:"Requests for the iOS and Android apps"`
+}
+
+// NewCategory maps a create request onto a new domain.Category.
+func NewCategory(req *CreateCategory) *domain.Category {
+	return &domain.Category{
+		Slug:        domain.NormalizeTag(req.Slug),
+		Name:        req.Name,
+		Description: req.Description,
+	}
+}
+
+// UpdatedCategory maps an update request onto the domain.Category to save.
+func UpdatedCategory(req *UpdateCategory) *domain.Category {
+	return &domain.Category{
+		Slug:        domain.NormalizeTag(req.Slug),
+		Name:        req.Name,
+		Description: req.Description,
+	}
+}
@@ -0,0 +1,56 @@
+package dto
+
+import (
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/uuid"
+)
+
+// CreateFeature is the client supplied payload for a new feature request.
+// Server owned fields (id, votes, owner) are deliberately absent so they
+// can't be bound from the request body.
+type CreateFeature struct {
+	UserId      uuid.UUID `json:"-" param:"userId" validate:"required"`
+	Name        string    `json:"name" validate:"required" example:"My New Feature Request"`
+	Description string    `json:"description" validate:"required" example:"Could we have this new feature please?"`
+	Board       string    `json:"board,omitempty" validate:"omitempty,max=64" example:"mixer"`
+	Tags        []string  `json:"tags,omitempty" validate:"max=10,dive,max=64" example:"mixer,mobile-app"`
+}
+
+// UpdateFeature is the client supplied payload for editing a feature request.
+// The id only selects the feature to edit, the owner always comes from the path.
+// Tags are only replaced when sent, so clients that predate them keep the
+// stored tags; an empty list clears them.
+type UpdateFeature struct {
+	Id          uuid.UUID `json:"id" validate:"required" example:"f6e7f8c4-3af6-4028-ac7c-30c9d79a3fa7"`
+	UserId      uuid.UUID `json:"-" param:"userId" validate:"required"`
+	Name        string    `json:"name" validate:"required" example:"My New Feature Request"`
+	Description string    `json:"description" validate:"required" example:"Could we have this new feature please?"`
+	Tags        *[]string `json:"tags,omitempty" validate:"omitempty,max=10,dive,max=64" example:"mixer,mobile-app"`
+}
+
+// NewFeature maps a create request onto a new domain.Feature with the given id.
+func NewFeature(id uuid.UUID, req *CreateFeature) *domain.Feature {
+	board := req.Board
+	if board == "" {
+		board = domain.DefaultBoard
+	}
+
+	return &domain.Feature{
+		Id:          id,
+		UserId:      req.UserId,
+		Name:        req.Name,
+		Description: req.Description,
+		Board:       board,
+		Tags:        domain.NormalizeTags(req.Tags),
+	}
+}
+
+// ApplyUpdate copies the client editable fields of req onto feature, leaving
+// everything the server owns untouched.
+func ApplyUpdate(feature *domain.Feature, req *UpdateFeature) {
+	feature.Name = req.Name
+	feature.Description = req.Description
+	if req.Tags != nil {
+		feature.Tags = domain.NormalizeTags(*req.Tags)
+	}
+}
@@ -0,0 +1,116 @@
+package dto
+
+import (
+	"testing"
+	"time"
+
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewFeature(t *testing.T) {
+	t.Run("when we map a create request, the id should come from the server", func(t *testing.T) {
+		id := uuid.New()
+		req := &CreateFeature{
+			UserId:      uuid.New(),
+			Name:        "hello",
+			Description: "do something",
+		}
+
+		feature := NewFeature(id, req)
+		assert.Equal(t, &domain.Feature{
+			Id:          id,
+			UserId:      req.UserId,
+			Name:        "hello",
+			Description: "do something",
+			Board:       domain.DefaultBoard,
+		}, feature)
+	})
+
+	t.Run("when a board is given, the feature should be placed on it", func(t *testing.T) {
+		req := &CreateFeature{
+			UserId:      uuid.New(),
+			Name:        "hello",
+			Description: "do something",
+			Board:       "mixer",
+			Tags:        []string{"Mobile App", "mixer", "mobile-app"},
+		}
+
+		feature := NewFeature(uuid.New(), req)
+		assert.Equal(t, "mixer", feature.Board)
+		assert.Equal(t, []string{"mobile-app", "mixer"}, feature.Tags)
+	})
+}
+
+func TestApplyUpdate(t *testing.T) {
+	t.Run("when we apply an update, server owned fields should be left alone", func(t *testing.T) {
+		id := uuid.New()
+		userId := uuid.New()
+		feature := &domain.Feature{
+			Id:          id,
+			UserId:      userId,
+			Name:        "old name",
+			Description: "old description",
+			VoteCount:   3,
+		}
+
+		ApplyUpdate(feature, &UpdateFeature{
+			Id:          uuid.New(),
+			UserId:      uuid.New(),
+			Name:        "hello",
+			Description: "do something",
+		})
+		assert.Equal(t, &domain.Feature{
+			Id:          id,
+			UserId:      userId,
+			Name:        "hello",
+			Description: "do something",
+			VoteCount:   3,
+		}, feature)
+	})
+
+	t.Run("when the tags are left out they should be kept, and otherwise replaced", func(t *testing.T) {
+		feature := &domain.Feature{Tags: []string{"mixer"}}
+
+		ApplyUpdate(feature, &UpdateFeature{Name: "hello"})
+		assert.Equal(t, []string{"mixer"}, feature.Tags)
+
+		ApplyUpdate(feature, &UpdateFeature{Name: "hello", Tags: &[]string{" Cloud "}})
+		assert.Equal(t, []string{"cloud"}, feature.Tags)
+
+		ApplyUpdate(feature, &UpdateFeature{Name: "hello", Tags: &[]string{}})
+		assert.Empty(t, feature.Tags)
+	})
+}
+
+func TestNewImportedFeature(t *testing.T) {
+	userId := "202c25c4-b2ce-4514-9045-890a1aa896ea"
+
+	t.Run("when a row has no id, the same row should always get the same id", func(t *testing.T) {
+		a := NewImportedFeature(&ImportFeature{UserId: userId, Name: "Dark mode", Description: "please"})
+		b := NewImportedFeature(&ImportFeature{UserId: userId, Name: " dark mode ", Description: "again"})
+		c := NewImportedFeature(&ImportFeature{UserId: userId, Name: "Dark mode", Description: "please", Board: "mixer"})
+
+		assert.Equal(t, a.Id, b.Id)
+		assert.NotEqual(t, a.Id, c.Id)
+		assert.Equal(t, domain.DefaultBoard, a.Board)
+	})
+
+	t.Run("when a row brings its id, status and creation time, they should be kept", func(t *testing.T) {
+		feature := NewImportedFeature(&ImportFeature{
+			Id:          "f6e7f8c4-3af6-4028-ac7c-30c9d79a3fa7",
+			UserId:      userId,
+			Name:        "Dark mode",
+			Description: "please",
+			Tags:        []string{"Mobile App"},
+			Status:      "planned",
+			CreatedAt:   "2023-11-02T10:00:00+01:00",
+		})
+
+		assert.Equal(t, uuid.MustParse("f6e7f8c4-3af6-4028-ac7c-30c9d79a3fa7"), feature.Id)
+		assert.Equal(t, domain.StatusPlanned, feature.Status)
+		assert.Equal(t, time.Date(2023, 11, 2, 9, 0, 0, 0, time.UTC), feature.CreatedAt)
+		assert.Equal(t, []string{"mobile-app"}, feature.Tags)
+	})
+}
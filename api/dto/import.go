@@ -0,0 +1,63 @@
+package dto
+
+import (
+	"strings"
+	"time"
+
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/uuid"
+)
+
+// importNamespace seeds the ids of imported features that don't bring their
+// own, so importing the same file twice doesn't add everything twice.
+var importNamespace = uuid.MustParse("3f0c2a9e-6b1d-4c8e-9a57-0e4d2b7f1c63")
+
+// ImportFeature is one feature read from an import file. Everything is kept
+// as text until it has been validated, so a bad row can be reported rather
+// than failing the whole file.
+type ImportFeature struct {
+	Id          string   `json:"id" validate:"omitempty,uuid"`
+	UserId      string   `json:"userId" validate:"required,uuid"`
+	Name        string   `json:"name" validate:"required"`
+	Description string   `json:"description" validate:"required"`
+	Board       string   `json:"board" validate:"omitempty,max=64"`
+	Tags        []string `json:"tags" validate:"max=10,dive,max=64"`
+	Status      string   `json:"status" validate:"omitempty,oneof=open planned in-progress done declined"`
+	CreatedAt   string   `json:"createdAt" validate:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
+}
+
+// NewImportedFeature maps a validated import row onto a domain.Feature. Rows
+// without an id get one derived from their owner, board and name.
+func NewImportedFeature(req *ImportFeature) *domain.Feature {
+	board := req.Board
+	if board == "" {
+		board = domain.DefaultBoard
+	}
+
+	userId := uuid.MustParse(req.UserId)
+
+	var id uuid.UUID
+	if req.Id != "" {
+		id = uuid.MustParse(req.Id)
+	} else {
+		key := userId.String() + "\n" + board + "\n" + strings.ToLower(strings.TrimSpace(req.Name))
+		id = uuid.NewSHA1(importNamespace, []byte(key))
+	}
+
+	var createdAt time.Time
+	if req.CreatedAt != "" {
+		createdAt, _ = time.Parse(time.RFC3339, req.CreatedAt)
+		createdAt = createdAt.UTC()
+	}
+
+	return &domain.Feature{
+		Id:          id,
+		UserId:      userId,
+		Name:        req.Name,
+		Description: req.Description,
+		Board:       board,
+		Tags:        domain.NormalizeTags(req.Tags),
+		Status:      domain.FeatureStatus(req.Status),
+		CreatedAt:   createdAt,
+	}
+}
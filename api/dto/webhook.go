@@ -0,0 +1,50 @@
+package dto
+
+import (
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/uuid"
+)
+
+// CreateWebhook is the payload for registering a webhook. A secret is
+// generated when none is given.
+type CreateWebhook struct {
+	URL        string             `json:"url" validate:"required,http_url,max=2048" example:"https://hooks.example.com/features"`
+	Secret     string             `json:"secret,omitempty" validate:"omitempty,min=16,max=256" example:"9f86d081884c7d659a2feaa0c55ad015"`
+	EventTypes []domain.EventType `json:"eventTypes,omitempty" validate:"max=16,dive,oneof=feature.created feature.updated feature.status_changed feature.deleted feature.restored feature.purged vote.cast vote.changed vote.removed" example:"feature.created,vote.cast"`
+	// Active defaults to true.
+	Active *bool `json:"active,omitempty" example:"true"`
+}
+
+// UpdateWebhook is the payload for changing a webhook. The secret is only
+// rotated when a new one is given.
+type UpdateWebhook struct {
+	Id         uuid.UUID          `json:"-" param:"webhookId" validate:"required"`
+	URL        string             `json:"url" validate:"required,http_url,max=2048" example:"https://hooks.example.com/features"`
+	Secret     string             `json:"secret,omitempty" validate:"omitempty,min=16,max=256" example:"9f86d081884c7d659a2feaa0c55ad015"`
+	EventTypes []domain.EventType `json:"eventTypes,omitempty" validate:"max=16,dive,oneof=feature.created feature.updated feature.status_changed feature.deleted feature.restored feature.purged vote.cast vote.changed vote.removed" example:"feature.created,vote.cast"`
+	// Active defaults to true.
+	Active *bool `json:"active,omitempty" example:"true"`
+}
+
+// NewWebhook maps a create request onto a new domain.Webhook. The caller
+// fills in the secret when the request doesn't carry one.
+func NewWebhook(req *CreateWebhook) *domain.Webhook {
+	return &domain.Webhook{
+		Id:         uuid.New(),
+		URL:        req.URL,
+		Secret:     req.Secret,
+		EventTypes: req.EventTypes,
+		Active:     req.Active == nil || *req.Active,
+	}
+}
+
+// UpdatedWebhook maps an update request onto the domain.Webhook to save.
+func UpdatedWebhook(req *UpdateWebhook) *domain.Webhook {
+	return &domain.Webhook{
+		Id:         req.Id,
+		URL:        req.URL,
+		Secret:     req.Secret,
+		EventTypes: req.EventTypes,
+		Active:     req.Active == nil || *req.Active,
+	}
+}
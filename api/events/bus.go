@@ -0,0 +1,55 @@
+// Package events delivers the domain events recorded in the outbox to the
+// in-process subscribers interested in them.
+package events
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/music-tribe/react-pairing-challenge/domain"
+)
+
+// Handler reacts to a single event. Events are delivered at least once, so
+// handlers must cope with seeing the same event again after a retry.
+type Handler func(ctx context.Context, event *domain.Event) error
+
+type subscriber struct {
+	name    string
+	handler Handler
+}
+
+// Bus holds the named subscribers events are delivered to.
+type Bus struct {
+	mu          sync.RWMutex
+	subscribers []subscriber
+}
+
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// Subscribe registers handler under name. The name is recorded against each
+// event it handles, so it must be unique and stable across restarts.
+func (b *Bus) Subscribe(name string, handler Handler) {
+	if handler == nil {
+		panic("events.Subscribe: handler has nil value")
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, s := range b.subscribers {
+		if s.name == name {
+			panic(fmt.Sprintf("events.Subscribe: %q is already subscribed", name))
+		}
+	}
+	b.subscribers = append(b.subscribers, subscriber{name: name, handler: handler})
+}
+
+func (b *Bus) snapshot() []subscriber {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return append([]subscriber(nil), b.subscribers...)
+}
@@ -0,0 +1,38 @@
+package events
+
+import (
+	"context"
+	"testing"
+
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBus(t *testing.T) {
+	noop := func(context.Context, *domain.Event) error { return nil }
+
+	t.Run("when a handler has a nil value, we should panic", func(t *testing.T) {
+		assert.Panics(t, func() {
+			NewBus().Subscribe("nil", nil)
+		})
+	})
+
+	t.Run("when a name is subscribed twice, we should panic", func(t *testing.T) {
+		bus := NewBus()
+		bus.Subscribe("webhooks", noop)
+
+		assert.Panics(t, func() {
+			bus.Subscribe("webhooks", noop)
+		})
+	})
+
+	t.Run("when subscribers are added, they should be kept in order", func(t *testing.T) {
+		bus := NewBus()
+		bus.Subscribe("first", noop)
+		bus.Subscribe("second", noop)
+
+		subs := bus.snapshot()
+		assert.Equal(t, "first", subs[0].name)
+		assert.Equal(t, "second", subs[1].name)
+	})
+}
@@ -0,0 +1,185 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/music-tribe/react-pairing-challenge/database"
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/uuid"
+)
+
+//go:generate mockgen -destination=./mocks/dispatcher.go -package=eventsmocks -source=dispatcher.go
+type OutboxDatabase interface {
+	ClaimOutbox(now, until time.Time, limit int64) ([]*database.OutboxEntry, error)
+	MarkDelivered(eventId uuid.UUID, subscriber string) error
+	MarkDispatched(eventId uuid.UUID) error
+	RetryOutbox(eventId uuid.UUID, next time.Time, lastError string) error
+	MarkDead(eventId uuid.UUID, lastError string) error
+}
+
+const (
+	DefaultInterval    = time.Second
+	DefaultBatchSize   = 100
+	DefaultMaxAttempts = 10
+	// DefaultBaseDelay is the wait before the first retry; each further
+	// retry waits twice as long, up to DefaultMaxDelay.
+	DefaultBaseDelay = time.Second
+	DefaultMaxDelay  = 10 * time.Minute
+	// DefaultLease is how long a dispatcher holds an event it has claimed
+	// before another may take it over, so it must outlast the subscribers.
+	DefaultLease = time.Minute
+)
+
+// Dispatcher polls the outbox and hands each event to every subscriber
+// that hasn't handled it yet, retrying failures with exponential backoff.
+// Every replica may run one: each event is claimed by a single dispatcher
+// before it is handed out, though events then no longer reach subscribers
+// strictly in the order they occurred.
+type Dispatcher struct {
+	db          OutboxDatabase
+	bus         *Bus
+	logger      echo.Logger
+	interval    time.Duration
+	batchSize   int64
+	maxAttempts int
+	baseDelay   time.Duration
+	maxDelay    time.Duration
+	lease       time.Duration
+	now         func() time.Time
+}
+
+func NewDispatcher(db OutboxDatabase, bus *Bus, logger echo.Logger) *Dispatcher {
+	if db == nil {
+		panic("events.NewDispatcher: db has nil value")
+	}
+	if bus == nil {
+		panic("events.NewDispatcher: bus has nil value")
+	}
+
+	return &Dispatcher{
+		db:          db,
+		bus:         bus,
+		logger:      logger,
+		interval:    DefaultInterval,
+		batchSize:   DefaultBatchSize,
+		maxAttempts: DefaultMaxAttempts,
+		baseDelay:   DefaultBaseDelay,
+		maxDelay:    DefaultMaxDelay,
+		lease:       DefaultLease,
+		now:         time.Now,
+	}
+}
+
+// Run dispatches pending events every interval until ctx is done.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := d.DispatchOnce(ctx); err != nil {
+			d.logger.Errorf("events.Run: DispatchOnce >> %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DispatchOnce delivers up to a batch of due events, returning how many
+// were fully dispatched. Events are claimed one at a time, so each lease
+// only has to cover a single event.
+func (d *Dispatcher) DispatchOnce(ctx context.Context) (int, error) {
+	dispatched := 0
+	for i := int64(0); i < d.batchSize; i++ {
+		if ctx.Err() != nil {
+			return dispatched, ctx.Err()
+		}
+
+		now := d.now().UTC()
+		claimed, err := d.db.ClaimOutbox(now, now.Add(d.lease), 1)
+		if err != nil {
+			return dispatched, err
+		}
+		if len(claimed) == 0 {
+			break
+		}
+
+		ok, err := d.dispatch(ctx, claimed[0])
+		if err != nil {
+			return dispatched, err
+		}
+		if ok {
+			dispatched++
+		}
+	}
+
+	return dispatched, nil
+}
+
+// dispatch delivers entry to its outstanding subscribers. Delivery failures
+// are scheduled for retry; only storage errors are returned.
+func (d *Dispatcher) dispatch(ctx context.Context, entry *database.OutboxEntry) (bool, error) {
+	delivered := map[string]bool{}
+	for _, name := range entry.Delivered {
+		delivered[name] = true
+	}
+
+	var failures []string
+	for _, s := range d.bus.snapshot() {
+		if delivered[s.name] {
+			continue
+		}
+
+		if err := deliver(ctx, s.handler, &entry.Event); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", s.name, err))
+			continue
+		}
+
+		if err := d.db.MarkDelivered(entry.Id, s.name); err != nil {
+			return false, err
+		}
+	}
+
+	if len(failures) == 0 {
+		return true, d.db.MarkDispatched(entry.Id)
+	}
+
+	lastError := strings.Join(failures, "; ")
+	if entry.Attempts+1 >= d.maxAttempts {
+		d.logger.Errorf("events.dispatch: giving up on %s event %s >> %s", entry.Type, entry.Id, lastError)
+		return false, d.db.MarkDead(entry.Id, lastError)
+	}
+
+	return false, d.db.RetryOutbox(entry.Id, d.now().UTC().Add(d.backoff(entry.Attempts)), lastError)
+}
+
+// backoff is the wait after the given number of earlier failed attempts.
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.baseDelay
+	for i := 0; i < attempts && delay < d.maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > d.maxDelay {
+		delay = d.maxDelay
+	}
+	return delay
+}
+
+// deliver calls handler, turning a panic into an error so one misbehaving
+// subscriber can't take the dispatcher down.
+func deliver(ctx context.Context, handler Handler, event *domain.Event) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	return handler(ctx, event)
+}
@@ -0,0 +1,233 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/music-tribe/react-pairing-challenge/database"
+	"github.com/music-tribe/react-pairing-challenge/database/sqldb"
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	eventsmocks "github.com/music-tribe/react-pairing-challenge/events/mocks"
+	"github.com/music-tribe/react-pairing-challenge/migrate"
+	"github.com/music-tribe/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDispatcher(t *testing.T) {
+	logger := echo.New().Logger
+	now := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	ctx := context.Background()
+
+	newDispatcher := func(db OutboxDatabase, bus *Bus) *Dispatcher {
+		d := NewDispatcher(db, bus, logger)
+		d.now = func() time.Time { return now }
+		return d
+	}
+
+	// queue has db hand out entries one claim at a time, then none
+	queue := func(db *eventsmocks.MockOutboxDatabase, entries ...*database.OutboxEntry) {
+		for _, e := range entries {
+			db.EXPECT().ClaimOutbox(now, now.Add(DefaultLease), int64(1)).Return([]*database.OutboxEntry{e}, nil)
+		}
+		db.EXPECT().ClaimOutbox(now, now.Add(DefaultLease), int64(1)).Return([]*database.OutboxEntry{}, nil)
+	}
+
+	newEntry := func() *database.OutboxEntry {
+		return &database.OutboxEntry{Event: domain.Event{Id: uuid.New(), Type: domain.VoteCast}}
+	}
+
+	t.Run("when the db or bus has a nil value, we should panic", func(t *testing.T) {
+		assert.Panics(t, func() {
+			NewDispatcher(nil, NewBus(), logger)
+		})
+		assert.Panics(t, func() {
+			NewDispatcher(eventsmocks.NewMockOutboxDatabase(gomock.NewController(t)), nil, logger)
+		})
+	})
+
+	t.Run("when every subscriber handles an event, it should be marked dispatched", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := eventsmocks.NewMockOutboxDatabase(ctrl)
+
+		entry := newEntry()
+		var got []*domain.Event
+		bus := NewBus()
+		bus.Subscribe("a", func(_ context.Context, e *domain.Event) error {
+			got = append(got, e)
+			return nil
+		})
+		bus.Subscribe("b", func(_ context.Context, e *domain.Event) error {
+			got = append(got, e)
+			return nil
+		})
+
+		queue(db, entry)
+		db.EXPECT().MarkDelivered(entry.Id, "a").Return(nil)
+		db.EXPECT().MarkDelivered(entry.Id, "b").Return(nil)
+		db.EXPECT().MarkDispatched(entry.Id).Return(nil)
+
+		n, err := newDispatcher(db, bus).DispatchOnce(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, n)
+		assert.Equal(t, []*domain.Event{&entry.Event, &entry.Event}, got)
+	})
+
+	t.Run("when a subscriber has already handled an event, it shouldn't see it again", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := eventsmocks.NewMockOutboxDatabase(ctrl)
+
+		entry := newEntry()
+		entry.Delivered = []string{"a"}
+		bus := NewBus()
+		bus.Subscribe("a", func(context.Context, *domain.Event) error {
+			t.Fatal("a should not be called again")
+			return nil
+		})
+		bus.Subscribe("b", func(context.Context, *domain.Event) error { return nil })
+
+		queue(db, entry)
+		db.EXPECT().MarkDelivered(entry.Id, "b").Return(nil)
+		db.EXPECT().MarkDispatched(entry.Id).Return(nil)
+
+		_, err := newDispatcher(db, bus).DispatchOnce(ctx)
+		assert.NoError(t, err)
+	})
+
+	t.Run("when a subscriber fails, the event should be retried with backoff", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := eventsmocks.NewMockOutboxDatabase(ctrl)
+
+		entry := newEntry()
+		entry.Attempts = 3
+		bus := NewBus()
+		bus.Subscribe("ok", func(context.Context, *domain.Event) error { return nil })
+		bus.Subscribe("flaky", func(context.Context, *domain.Event) error { return errors.New("connection refused") })
+
+		queue(db, entry)
+		db.EXPECT().MarkDelivered(entry.Id, "ok").Return(nil)
+		db.EXPECT().RetryOutbox(entry.Id, now.Add(8*time.Second), "flaky: connection refused").Return(nil)
+
+		n, err := newDispatcher(db, bus).DispatchOnce(ctx)
+		assert.NoError(t, err)
+		assert.Zero(t, n)
+	})
+
+	t.Run("when a subscriber panics, it should be treated as a failure", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := eventsmocks.NewMockOutboxDatabase(ctrl)
+
+		entry := newEntry()
+		bus := NewBus()
+		bus.Subscribe("broken", func(context.Context, *domain.Event) error { panic("boom") })
+
+		queue(db, entry)
+		db.EXPECT().RetryOutbox(entry.Id, now.Add(DefaultBaseDelay), "broken: panic: boom").Return(nil)
+
+		_, err := newDispatcher(db, bus).DispatchOnce(ctx)
+		assert.NoError(t, err)
+	})
+
+	t.Run("when an event runs out of attempts, it should be marked dead", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := eventsmocks.NewMockOutboxDatabase(ctrl)
+
+		entry := newEntry()
+		entry.Attempts = DefaultMaxAttempts - 1
+		bus := NewBus()
+		bus.Subscribe("down", func(context.Context, *domain.Event) error { return errors.New("503") })
+
+		queue(db, entry)
+		db.EXPECT().MarkDead(entry.Id, "down: 503").Return(nil)
+
+		_, err := newDispatcher(db, bus).DispatchOnce(ctx)
+		assert.NoError(t, err)
+	})
+
+	t.Run("when the outbox can't be read, we should get the error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := eventsmocks.NewMockOutboxDatabase(ctrl)
+
+		db.EXPECT().ClaimOutbox(now, now.Add(DefaultLease), int64(1)).Return(nil, errors.New("some error"))
+
+		_, err := newDispatcher(db, NewBus()).DispatchOnce(ctx)
+		assert.ErrorContains(t, err, "some error")
+	})
+}
+
+func TestDispatcher_backoff(t *testing.T) {
+	d := NewDispatcher(eventsmocks.NewMockOutboxDatabase(gomock.NewController(t)), NewBus(), echo.New().Logger)
+
+	assert.Equal(t, time.Second, d.backoff(0))
+	assert.Equal(t, 2*time.Second, d.backoff(1))
+	assert.Equal(t, 64*time.Second, d.backoff(6))
+	assert.Equal(t, DefaultMaxDelay, d.backoff(20))
+	assert.Equal(t, DefaultMaxDelay, d.backoff(1000))
+}
+
+func TestDispatcher_SharedStore(t *testing.T) {
+	ctx := context.Background()
+	logger := echo.New().Logger
+
+	db, err := sqldb.Open(sqldb.SQLite, "sqlite://"+filepath.Join(t.TempDir(), "features.db"), logger)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer db.Close()
+	_, err = migrate.New(migrate.Config{Store: db, Migrations: db.Migrations()}).Up(ctx, migrate.Options{})
+	assert.NoError(t, err)
+
+	for i := 0; i < 30; i++ {
+		assert.NoError(t, db.Add(&domain.Feature{Id: uuid.New(), UserId: uuid.New(), Name: "shared", Description: "d"}))
+	}
+
+	var (
+		mu      sync.Mutex
+		handled = map[uuid.UUID]int{}
+	)
+	bus := NewBus()
+	bus.Subscribe("counter", func(_ context.Context, e *domain.Event) error {
+		mu.Lock()
+		handled[e.Id]++
+		mu.Unlock()
+		return nil
+	})
+
+	t.Run("when two dispatchers share a store, each event should be handled once", func(t *testing.T) {
+		var wg sync.WaitGroup
+		dispatched := make([]int, 2)
+		for i := range dispatched {
+			i := i
+			d := NewDispatcher(db, bus, logger)
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				n, err := d.DispatchOnce(ctx)
+				assert.NoError(t, err)
+				dispatched[i] = n
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, 30, dispatched[0]+dispatched[1])
+		assert.Len(t, handled, 30)
+		for id, n := range handled {
+			assert.Equal(t, 1, n, "event %s", id)
+		}
+
+		pending, err := db.PendingOutbox(time.Now().Add(time.Hour), 100)
+		assert.NoError(t, err)
+		assert.Empty(t, pending)
+	})
+}
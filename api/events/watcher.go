@@ -0,0 +1,92 @@
+package events
+
+import (
+	"context"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/music-tribe/react-pairing-challenge/domain"
+)
+
+//go:generate mockgen -destination=./mocks/watcher.go -package=eventsmocks -source=watcher.go
+type ChangeStream interface {
+	WatchFeatures(ctx context.Context, consumer string, handle func(context.Context, *domain.Event) error) error
+}
+
+const (
+	// DefaultWatchRetry is the wait before reopening a change stream that
+	// failed; it doubles with each consecutive failure up to
+	// DefaultWatchMaxRetry.
+	DefaultWatchRetry    = time.Second
+	DefaultWatchMaxRetry = time.Minute
+)
+
+// Watcher feeds the subscribers of a bus from the database's change
+// stream, so they see writes made by every replica. Unlike the outbox,
+// delivery is best effort: a subscriber that fails misses that change.
+type Watcher struct {
+	db       ChangeStream
+	bus      *Bus
+	consumer string
+	logger   echo.Logger
+	retry    time.Duration
+	maxRetry time.Duration
+}
+
+// NewWatcher returns a Watcher storing its resume token under consumer,
+// which must be unique to this replica.
+func NewWatcher(db ChangeStream, bus *Bus, consumer string, logger echo.Logger) *Watcher {
+	if db == nil {
+		panic("events.NewWatcher: db has nil value")
+	}
+	if bus == nil {
+		panic("events.NewWatcher: bus has nil value")
+	}
+
+	return &Watcher{
+		db:       db,
+		bus:      bus,
+		consumer: consumer,
+		logger:   logger,
+		retry:    DefaultWatchRetry,
+		maxRetry: DefaultWatchMaxRetry,
+	}
+}
+
+// Run watches until ctx is done, reopening the stream after failures.
+func (w *Watcher) Run(ctx context.Context) {
+	delay := w.retry
+	for {
+		started := time.Now()
+		err := w.db.WatchFeatures(ctx, w.consumer, w.handle)
+		if ctx.Err() != nil {
+			return
+		}
+
+		w.logger.Errorf("events.Run: WatchFeatures >> %v", err)
+		if time.Since(started) > w.maxRetry {
+			// the stream was healthy for a while, so start backing off again
+			delay = w.retry
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+
+		if delay *= 2; delay > w.maxRetry {
+			delay = w.maxRetry
+		}
+	}
+}
+
+// handle passes event to every subscriber, logging their failures.
+func (w *Watcher) handle(ctx context.Context, event *domain.Event) error {
+	for _, s := range w.bus.snapshot() {
+		if err := deliver(ctx, s.handler, event); err != nil {
+			w.logger.Errorf("events.handle: %s failed on %s event %s >> %v", s.name, event.Type, event.Id, err)
+		}
+	}
+	return nil
+}
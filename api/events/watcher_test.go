@@ -0,0 +1,79 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	eventsmocks "github.com/music-tribe/react-pairing-challenge/events/mocks"
+	"github.com/music-tribe/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatcher(t *testing.T) {
+	logger := echo.New().Logger
+
+	t.Run("when the db or bus has a nil value, we should panic", func(t *testing.T) {
+		assert.Panics(t, func() {
+			NewWatcher(nil, NewBus(), "api-1", logger)
+		})
+		assert.Panics(t, func() {
+			NewWatcher(eventsmocks.NewMockChangeStream(gomock.NewController(t)), nil, "api-1", logger)
+		})
+	})
+
+	t.Run("when a change is seen, every subscriber should get it even if one fails", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := eventsmocks.NewMockChangeStream(ctrl)
+
+		event := &domain.Event{Id: uuid.New(), Type: domain.FeatureVotesChanged}
+		var got []string
+		bus := NewBus()
+		bus.Subscribe("a", func(context.Context, *domain.Event) error {
+			got = append(got, "a")
+			return errors.New("boom")
+		})
+		bus.Subscribe("b", func(_ context.Context, e *domain.Event) error {
+			assert.Equal(t, event, e)
+			got = append(got, "b")
+			return nil
+		})
+
+		ctx, cancel := context.WithCancel(context.Background())
+		db.EXPECT().WatchFeatures(gomock.Any(), "api-1", gomock.Any()).
+			DoAndReturn(func(ctx context.Context, _ string, handle func(context.Context, *domain.Event) error) error {
+				assert.NoError(t, handle(ctx, event))
+				cancel()
+				return ctx.Err()
+			})
+
+		NewWatcher(db, bus, "api-1", logger).Run(ctx)
+		assert.Equal(t, []string{"a", "b"}, got)
+	})
+
+	t.Run("when the stream fails, it should be reopened", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := eventsmocks.NewMockChangeStream(ctrl)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		gomock.InOrder(
+			db.EXPECT().WatchFeatures(gomock.Any(), "api-1", gomock.Any()).Return(errors.New("connection reset")),
+			db.EXPECT().WatchFeatures(gomock.Any(), "api-1", gomock.Any()).Return(errors.New("connection reset")),
+			db.EXPECT().WatchFeatures(gomock.Any(), "api-1", gomock.Any()).
+				DoAndReturn(func(context.Context, string, func(context.Context, *domain.Event) error) error {
+					cancel()
+					return context.Canceled
+				}),
+		)
+
+		w := NewWatcher(db, NewBus(), "api-1", logger)
+		w.retry = time.Millisecond
+		w.Run(ctx)
+	})
+}
@@ -0,0 +1,157 @@
+package exporter
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/music-tribe/react-pairing-challenge/domain"
+)
+
+// Column is one field of an exported feature. csv renders it as text; json
+// renders it as the value for a JSON Lines object.
+type Column struct {
+	Name string
+	// votes is set for columns that need the feature's votes.
+	votes bool
+	csv   func(f *domain.Feature, votes []*domain.Vote) string
+	json  func(f *domain.Feature, votes []*domain.Vote) interface{}
+}
+
+// Columns lists every column that can be exported, in the default order.
+// The feature columns are named like the import fields, so an export can be
+// imported again.
+var Columns = []Column{
+	text("id", func(f *domain.Feature) string { return f.Id.String() }),
+	text("userId", func(f *domain.Feature) string { return f.UserId.String() }),
+	text("name", func(f *domain.Feature) string { return f.Name }),
+	text("description", func(f *domain.Feature) string { return f.Description }),
+	text("board", func(f *domain.Feature) string { return f.Board }),
+	list("tags", func(f *domain.Feature, _ []*domain.Vote) []string { return f.Tags }),
+	text("status", func(f *domain.Feature) string { return string(f.Status) }),
+	number("voteCount", func(f *domain.Feature) int64 { return f.VoteCount }),
+	number("upvotes", func(f *domain.Feature) int64 { return f.Upvotes }),
+	number("downvotes", func(f *domain.Feature) int64 { return f.Downvotes }),
+	number("score", func(f *domain.Feature) int64 { return f.Score }),
+	timestamp("createdAt", func(f *domain.Feature) *time.Time { return &f.CreatedAt }),
+	timestamp("updatedAt", func(f *domain.Feature) *time.Time { return &f.UpdatedAt }),
+	timestamp("lastVotedAt", func(f *domain.Feature) *time.Time { return f.LastVotedAt }),
+	voters("voters", ""),
+	voters("upvoters", domain.Upvote),
+	voters("downvoters", domain.Downvote),
+}
+
+// DefaultColumns are exported when no columns are asked for. The voter
+// lists are left out as they make exports much bigger and slower.
+const DefaultColumns = "id,userId,name,description,board,tags,status,voteCount,upvotes,downvotes,score,createdAt,updatedAt,lastVotedAt"
+
+// ParseColumns reads a comma separated list of column names, returning the
+// default columns for an empty list.
+func ParseColumns(s string) ([]Column, error) {
+	if strings.TrimSpace(s) == "" {
+		s = DefaultColumns
+	}
+
+	var cols []Column
+	seen := map[string]bool{}
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		col, ok := columnNamed(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown column %q", name)
+		}
+		seen[name] = true
+		cols = append(cols, col)
+	}
+	return cols, nil
+}
+
+// NeedVotes reports whether any of cols lists voters, so the features'
+// votes have to be read too.
+func NeedVotes(cols []Column) bool {
+	for _, col := range cols {
+		if col.votes {
+			return true
+		}
+	}
+	return false
+}
+
+func columnNamed(name string) (Column, bool) {
+	for _, c := range Columns {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return Column{}, false
+}
+
+func text(name string, get func(*domain.Feature) string) Column {
+	return Column{
+		Name: name,
+		csv:  func(f *domain.Feature, _ []*domain.Vote) string { return get(f) },
+		json: func(f *domain.Feature, _ []*domain.Vote) interface{} { return get(f) },
+	}
+}
+
+func number(name string, get func(*domain.Feature) int64) Column {
+	return Column{
+		Name: name,
+		csv:  func(f *domain.Feature, _ []*domain.Vote) string { return fmt.Sprint(get(f)) },
+		json: func(f *domain.Feature, _ []*domain.Vote) interface{} { return get(f) },
+	}
+}
+
+func timestamp(name string, get func(*domain.Feature) *time.Time) Column {
+	format := func(f *domain.Feature) string {
+		if t := get(f); t != nil && !t.IsZero() {
+			return t.UTC().Format(time.RFC3339)
+		}
+		return ""
+	}
+	return Column{
+		Name: name,
+		csv:  func(f *domain.Feature, _ []*domain.Vote) string { return format(f) },
+		json: func(f *domain.Feature, _ []*domain.Vote) interface{} {
+			if s := format(f); s != "" {
+				return s
+			}
+			return nil
+		},
+	}
+}
+
+// list renders a list as one CSV cell separated by semicolons, or a JSON
+// array.
+func list(name string, get func(*domain.Feature, []*domain.Vote) []string) Column {
+	return Column{
+		Name: name,
+		csv:  func(f *domain.Feature, votes []*domain.Vote) string { return strings.Join(get(f, votes), ";") },
+		json: func(f *domain.Feature, votes []*domain.Vote) interface{} {
+			if l := get(f, votes); l != nil {
+				return l
+			}
+			return []string{}
+		},
+	}
+}
+
+// voters lists the ids of the users who voted in direction, or in either
+// direction when it is empty.
+func voters(name string, direction domain.VoteDirection) Column {
+	col := list(name, func(_ *domain.Feature, votes []*domain.Vote) []string {
+		ids := make([]string, 0, len(votes))
+		for _, v := range votes {
+			if direction != "" && v.Direction.Sign() != direction.Sign() {
+				continue
+			}
+			ids = append(ids, v.VoterId.String())
+		}
+		return ids
+	})
+	col.votes = true
+	return col
+}
@@ -0,0 +1,60 @@
+package exporter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseColumns(t *testing.T) {
+	t.Run("when no columns are given, we should get the defaults", func(t *testing.T) {
+		cols, err := ParseColumns(" ")
+		assert.NoError(t, err)
+		assert.Equal(t, DefaultColumns, names(cols))
+		assert.False(t, NeedVotes(cols))
+	})
+
+	t.Run("when columns are repeated, they should only be exported once", func(t *testing.T) {
+		cols, err := ParseColumns("name, voters,name,")
+		assert.NoError(t, err)
+		assert.Equal(t, "name,voters", names(cols))
+		assert.True(t, NeedVotes(cols))
+	})
+
+	t.Run("when a column is unknown, we should get an error", func(t *testing.T) {
+		_, err := ParseColumns("name,secret")
+		assert.EqualError(t, err, `unknown column "secret"`)
+	})
+}
+
+func TestWriter(t *testing.T) {
+	cols, _ := ParseColumns("name,tags")
+	feature := &domain.Feature{Name: "Dark mode", Tags: []string{"mixer", "mobile-app"}}
+
+	for _, tt := range []struct {
+		format Format
+		want   string
+	}{
+		{CSV, "name,tags\nDark mode,mixer;mobile-app\n"},
+		{JSONL, `{"name":"Dark mode","tags":["mixer","mobile-app"]}` + "\n"},
+	} {
+		t.Run("when we write "+string(tt.format)+", the columns should keep their order", func(t *testing.T) {
+			var b strings.Builder
+			w := NewWriter(tt.format, &b, cols)
+			assert.NoError(t, w.Header())
+			assert.NoError(t, w.Row(feature, nil))
+			assert.NoError(t, w.Flush())
+			assert.Equal(t, tt.want, b.String())
+		})
+	}
+}
+
+func names(cols []Column) string {
+	ns := make([]string, len(cols))
+	for i, c := range cols {
+		ns[i] = c.Name
+	}
+	return strings.Join(ns, ",")
+}
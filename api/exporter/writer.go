@@ -0,0 +1,120 @@
+// Package exporter writes features out as CSV or JSON Lines, for analysis
+// in spreadsheets or moving to another tool.
+package exporter
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/music-tribe/react-pairing-challenge/domain"
+)
+
+type Format string
+
+const (
+	CSV   Format = "csv"
+	JSONL Format = "jsonl"
+)
+
+// ParseFormat accepts the names clients use for the supported formats.
+func ParseFormat(s string) (Format, error) {
+	switch s {
+	case "csv":
+		return CSV, nil
+	case "jsonl", "ndjson":
+		return JSONL, nil
+	}
+	return "", fmt.Errorf("unsupported format %q, want csv or jsonl", s)
+}
+
+// ContentType is the media type of a file in format f.
+func (f Format) ContentType() string {
+	if f == CSV {
+		return "text/csv; charset=utf-8"
+	}
+	return "application/x-ndjson"
+}
+
+// Writer writes features one row at a time. Rows are buffered until Flush.
+type Writer interface {
+	// Header starts the file; it must be called once, before any row.
+	Header() error
+	Row(f *domain.Feature, votes []*domain.Vote) error
+	Flush() error
+}
+
+// NewWriter returns a Writer of cols in format f.
+func NewWriter(f Format, w io.Writer, cols []Column) Writer {
+	if f == CSV {
+		return &csvWriter{w: csv.NewWriter(w), cols: cols, rec: make([]string, len(cols))}
+	}
+
+	keys := make([][]byte, len(cols))
+	for i, col := range cols {
+		keys[i], _ = json.Marshal(col.Name)
+	}
+	return &jsonWriter{w: bufio.NewWriter(w), cols: cols, keys: keys}
+}
+
+type csvWriter struct {
+	w    *csv.Writer
+	cols []Column
+	rec  []string
+}
+
+func (w *csvWriter) Header() error {
+	for i, col := range w.cols {
+		w.rec[i] = col.Name
+	}
+	return w.w.Write(w.rec)
+}
+
+func (w *csvWriter) Row(f *domain.Feature, votes []*domain.Vote) error {
+	for i, col := range w.cols {
+		w.rec[i] = col.csv(f, votes)
+	}
+	return w.w.Write(w.rec)
+}
+
+func (w *csvWriter) Flush() error {
+	w.w.Flush()
+	return w.w.Error()
+}
+
+type jsonWriter struct {
+	w    *bufio.Writer
+	cols []Column
+	// keys holds each column's name already encoded
+	keys [][]byte
+}
+
+func (w *jsonWriter) Header() error {
+	return nil
+}
+
+// Row writes the columns in order, which a map would not keep.
+func (w *jsonWriter) Row(f *domain.Feature, votes []*domain.Vote) error {
+	w.w.WriteByte('{')
+	for i, col := range w.cols {
+		if i > 0 {
+			w.w.WriteByte(',')
+		}
+		v, err := json.Marshal(col.json(f, votes))
+		if err != nil {
+			return err
+		}
+		w.w.Write(w.keys[i])
+		w.w.WriteByte(':')
+		w.w.Write(v)
+	}
+	w.w.WriteByte('}')
+	_, err := w.w.WriteString("\n")
+	return err
+}
+
+func (w *jsonWriter) Flush() error {
+	return w.w.Flush()
+}
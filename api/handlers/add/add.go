@@ -7,22 +7,17 @@ import (
 	"github.com/labstack/echo/v4"
 	"github.com/music-tribe/react-pairing-challenge/database"
 	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/react-pairing-challenge/dto"
+	"github.com/music-tribe/react-pairing-challenge/handlers/categories"
 	"github.com/music-tribe/uuid"
 )
 
 //go:generate mockgen -destination=./mocks/add.go -package=addmocks -source=add.go
 type AddDatabase interface {
+	categories.TagDatabase
 	Add(feature *domain.Feature) error
 }
 
-type AddRequest struct {
-	Id          uuid.UUID   `json:"-" bson:"_id"`
-	UserId      uuid.UUID   `json:"userId" param:"userId" bson:"userId" validate:"required" example:"202c25c4-b2ce-4514-9045-890a1aa896ea"`
-	Name        string      `json:"name" validate:"required" example:"My New Feature Request"`
-	Description string      `json:"description" validate:"required" example:"Could we have this new feature please?"`
-	Votes       []uuid.UUID `json:"votes" example:"['155dccaa-0299-4018-ab6b-90b9ee448943','ef2a27c4-b03d-4190-86f2-b1dc2538243e']"`
-}
-
 type AddResponse struct {
 	Id uuid.UUID `json:"id"`
 }
@@ -32,12 +27,15 @@ type AddResponse struct {
 // @Description Add a new feature for this user id.
 // @Accept application/json
 // @Produce application/json
-// @Param feature body AddRequest true "Feature"
+// @Param feature body dto.CreateFeature true "Feature"
 // @Param userId path string true "User UUID"
+// @Param Idempotency-Key header string false "Replays the first response to retries sent with the same key"
 // @Router /api/{userId} [post]
 // @Success 200 {object} AddResponse
 // @failure 400 {object} error
 // @failure 409 {object} error
+// @failure 422 {object} error
+// @failure 429 {object} error
 // @failure 500 {object} error
 func Add(db AddDatabase) func(echo.Context) error {
 	if db == nil {
@@ -45,21 +43,23 @@ func Add(db AddDatabase) func(echo.Context) error {
 	}
 
 	return func(c echo.Context) error {
-		feature := domain.Feature{}
+		req := dto.CreateFeature{}
 
-		if err := c.Bind(&feature); err != nil {
+		if err := c.Bind(&req); err != nil {
 			return echo.NewHTTPError(http.StatusBadRequest, err)
 		}
 
-		if feature.Id == uuid.Nil {
-			feature.Id = uuid.New()
+		if err := validator.New().Struct(&req); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err)
 		}
 
-		if err := validator.New().Struct(&feature); err != nil {
-			return echo.NewHTTPError(http.StatusBadRequest, err)
+		feature := dto.NewFeature(uuid.New(), &req)
+
+		if err := categories.CheckTags(db, feature.Tags); err != nil {
+			return err
 		}
 
-		if err := db.Add(&feature); err != nil {
+		if err := db.Add(feature); err != nil {
 			if err == database.ErrDuplicate {
 				return echo.NewHTTPError(http.StatusConflict, err)
 			}
@@ -13,6 +13,7 @@ import (
 	"github.com/music-tribe/react-pairing-challenge/database"
 	"github.com/music-tribe/react-pairing-challenge/domain"
 	addmocks "github.com/music-tribe/react-pairing-challenge/handlers/add/mocks"
+	"github.com/music-tribe/react-pairing-challenge/handlers/categories"
 	"github.com/music-tribe/uuid"
 	"github.com/stretchr/testify/assert"
 )
@@ -85,9 +86,8 @@ func TestAdd(t *testing.T) {
 		defer ctrl.Finish()
 		db := addmocks.NewMockAddDatabase(ctrl)
 
-		id := uuid.New()
 		userId := uuid.New()
-		byt := []byte(`{"name":"hello","description":"do something", "id":"` + id.String() + `"}`)
+		byt := []byte(`{"name":"hello","description":"do something"}`)
 		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(byt))
 		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 		rec := httptest.NewRecorder()
@@ -95,12 +95,7 @@ func TestAdd(t *testing.T) {
 		ctx.SetParamNames("userId")
 		ctx.SetParamValues(userId.String())
 
-		db.EXPECT().Add(&domain.Feature{
-			Id:          id,
-			UserId:      userId,
-			Name:        "hello",
-			Description: "do something",
-		}).Return(database.ErrDuplicate)
+		db.EXPECT().Add(gomock.Any()).Return(database.ErrDuplicate)
 
 		err := Add(db)(ctx)
 		assert.ErrorContains(t, err, database.ErrDuplicate.Error())
@@ -112,9 +107,8 @@ func TestAdd(t *testing.T) {
 		defer ctrl.Finish()
 		db := addmocks.NewMockAddDatabase(ctrl)
 
-		id := uuid.New()
 		userId := uuid.New()
-		byt := []byte(`{"name":"hello","description":"do something", "id":"` + id.String() + `"}`)
+		byt := []byte(`{"name":"hello","description":"do something"}`)
 		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(byt))
 		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 		rec := httptest.NewRecorder()
@@ -122,12 +116,7 @@ func TestAdd(t *testing.T) {
 		ctx.SetParamNames("userId")
 		ctx.SetParamValues(userId.String())
 
-		db.EXPECT().Add(&domain.Feature{
-			Id:          id,
-			UserId:      userId,
-			Name:        "hello",
-			Description: "do something",
-		}).Return(errors.New("some error"))
+		db.EXPECT().Add(gomock.Any()).Return(errors.New("some error"))
 
 		err := Add(db)(ctx)
 		assert.ErrorContains(t, err, "some error")
@@ -138,10 +127,9 @@ func TestAdd(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 		db := addmocks.NewMockAddDatabase(ctrl)
-		id := uuid.New()
 		userId := uuid.New()
 
-		byt := []byte(`{"name":"hello","description":"do something", "id":"` + id.String() + `"}`)
+		byt := []byte(`{"name":"hello","description":"do something"}`)
 		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(byt))
 		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 		rec := httptest.NewRecorder()
@@ -149,31 +137,35 @@ func TestAdd(t *testing.T) {
 		ctx.SetParamNames("userId")
 		ctx.SetParamValues(userId.String())
 
-		db.EXPECT().Add(&domain.Feature{
-			Id:          id,
-			UserId:      userId,
-			Name:        "hello",
-			Description: "do something",
-		}).Return(nil)
+		var added *domain.Feature
+		db.EXPECT().Add(gomock.Any()).DoAndReturn(func(feature *domain.Feature) error {
+			added = feature
+			return nil
+		})
 
 		err := Add(db)(ctx)
 		assert.NoError(t, err)
 		assert.Equal(t, http.StatusCreated, getStatusCode(rec, err))
+		assert.NotEqual(t, uuid.Nil, added.Id)
+		assert.Equal(t, userId, added.UserId)
+		assert.Equal(t, "hello", added.Name)
+		assert.Equal(t, "do something", added.Description)
 
 		ar := new(AddResponse)
 		err = json.Unmarshal(rec.Body.Bytes(), ar)
 		assert.NoError(t, err)
-		assert.Equal(t, id, ar.Id)
+		assert.Equal(t, added.Id, ar.Id)
 	})
 
-	t.Run("when the votes are added on creation, we should see them bound to the Feature object", func(t *testing.T) {
+	t.Run("when the client sends server owned fields, they should be ignored", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 		db := addmocks.NewMockAddDatabase(ctrl)
 		id := uuid.New()
 		userId := uuid.New()
+		otherUserId := uuid.New()
 
-		byt := []byte(`{"name":"hello","description":"do something", "votes":["aa9f9cfd-efb4-4931-82a0-59e66140a365", "197f2c81-c786-4c00-b4d0-13d9a1c02a3e"], "id":"` + id.String() + `"}`)
+		byt := []byte(`{"name":"hello","description":"do something", "voteCount":1000, "id":"` + id.String() + `", "userId":"` + otherUserId.String() + `"}`)
 		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(byt))
 		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 		rec := httptest.NewRecorder()
@@ -181,27 +173,65 @@ func TestAdd(t *testing.T) {
 		ctx.SetParamNames("userId")
 		ctx.SetParamValues(userId.String())
 
-		vote1, _ := uuid.Parse("aa9f9cfd-efb4-4931-82a0-59e66140a365")
-		vote2, _ := uuid.Parse("197f2c81-c786-4c00-b4d0-13d9a1c02a3e")
-
-		db.EXPECT().Add(&domain.Feature{
-			Id:          id,
-			UserId:      userId,
-			Name:        "hello",
-			Description: "do something",
-			Votes: []uuid.UUID{
-				vote1, vote2,
-			},
-		}).Return(nil)
+		var added *domain.Feature
+		db.EXPECT().Add(gomock.Any()).DoAndReturn(func(feature *domain.Feature) error {
+			added = feature
+			return nil
+		})
 
 		err := Add(db)(ctx)
 		assert.NoError(t, err)
 		assert.Equal(t, http.StatusCreated, getStatusCode(rec, err))
+		assert.NotEqual(t, id, added.Id)
+		assert.Equal(t, userId, added.UserId)
+		assert.Zero(t, added.VoteCount)
+	})
 
-		ar := new(AddResponse)
-		err = json.Unmarshal(rec.Body.Bytes(), ar)
+	t.Run("when the tags aren't existing categories we should return a 400 error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := addmocks.NewMockAddDatabase(ctrl)
+
+		byt := []byte(`{"name":"hello","description":"do something","tags":["Mixer","toaster"]}`)
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(byt))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetParamNames("userId")
+		ctx.SetParamValues(uuid.New().String())
+
+		db.EXPECT().MissingCategories([]string{"mixer", "toaster"}).Return([]string{"toaster"}, nil)
+
+		err := Add(db)(ctx)
+		assert.ErrorContains(t, err, categories.ErrUnknownTags.Error())
+		assert.ErrorContains(t, err, "toaster")
+		assert.Equal(t, http.StatusBadRequest, getStatusCode(rec, err))
+	})
+
+	t.Run("when the tags are existing categories, they should be stored normalized", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := addmocks.NewMockAddDatabase(ctrl)
+
+		byt := []byte(`{"name":"hello","description":"do something","tags":["Mixer","mobile app","mixer"]}`)
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(byt))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetParamNames("userId")
+		ctx.SetParamValues(uuid.New().String())
+
+		var added *domain.Feature
+		db.EXPECT().MissingCategories([]string{"mixer", "mobile-app"}).Return([]string{}, nil)
+		db.EXPECT().Add(gomock.Any()).DoAndReturn(func(feature *domain.Feature) error {
+			added = feature
+			return nil
+		})
+
+		err := Add(db)(ctx)
 		assert.NoError(t, err)
-		assert.Equal(t, id, ar.Id)
+		assert.Equal(t, http.StatusCreated, getStatusCode(rec, err))
+		assert.Equal(t, []string{"mixer", "mobile-app"}, added.Tags)
 	})
 }
 
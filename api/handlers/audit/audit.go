@@ -0,0 +1,82 @@
+package audit
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/music-tribe/react-pairing-challenge/database"
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/uuid"
+)
+
+// DefaultLimit is how many events are returned when the client doesn't ask
+// for a number.
+const DefaultLimit = 100
+
+//go:generate mockgen -destination=./mocks/audit.go -package=auditmocks -source=audit.go
+type AuditDatabase interface {
+	QueryAudit(filter database.AuditFilter) ([]*domain.AuditEvent, error)
+}
+
+type AuditRequest struct {
+	FeatureId uuid.UUID          `query:"featureId" example:"202c25c4-b2ce-4514-9045-890a1aa896ea"`
+	Actor     string             `query:"actor" validate:"max=64" example:"effe01ec-7f09-4a1c-9453-794212a8ac26"`
+	Action    domain.AuditAction `query:"action" validate:"omitempty,oneof=created updated voted vote_changed unvoted status_changed deleted restored purged" example:"updated"`
+	Since     time.Time          `query:"since" example:"2024-03-01T00:00:00Z"`
+	Until     time.Time          `query:"until" example:"2024-04-01T00:00:00Z"`
+	Limit     int64              `query:"limit" validate:"min=0,max=1000" example:"100"`
+}
+
+// Audit godoc
+// @Summary Query the audit log.
+// @Description Query changes to every feature, newest first. Requires the admin API key.
+// @Produce application/json
+// @Security AdminKey
+// @Param featureId query string false "Only changes to this feature"
+// @Param actor query string false "Only changes made by this actor"
+// @Param action query string false "Only this kind of change" Enums(created, updated, voted, vote_changed, unvoted, status_changed, deleted, restored, purged)
+// @Param since query string false "Only changes at or after this RFC 3339 time"
+// @Param until query string false "Only changes before this RFC 3339 time"
+// @Param limit query int false "Most events to return, defaults to 100"
+// @Router /api/admin/audit [get]
+// @Success 200 {object} []domain.AuditEvent
+// @failure 400 {object} error
+// @failure 401 {object} error
+// @failure 500 {object} error
+func Audit(db AuditDatabase) func(echo.Context) error {
+	if db == nil {
+		panic("audit.Audit: db has nil value")
+	}
+
+	return func(c echo.Context) error {
+		req := AuditRequest{}
+
+		if err := c.Bind(&req); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err)
+		}
+
+		if err := validator.New().Struct(&req); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err)
+		}
+
+		if req.Limit == 0 {
+			req.Limit = DefaultLimit
+		}
+
+		events, err := db.QueryAudit(database.AuditFilter{
+			FeatureId: req.FeatureId,
+			Actor:     req.Actor,
+			Action:    req.Action,
+			Since:     req.Since,
+			Until:     req.Until,
+			Limit:     req.Limit,
+		})
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err)
+		}
+
+		return c.JSON(http.StatusOK, events)
+	}
+}
@@ -0,0 +1,124 @@
+package audit
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/music-tribe/react-pairing-challenge/database"
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	auditmocks "github.com/music-tribe/react-pairing-challenge/handlers/audit/mocks"
+	"github.com/music-tribe/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAudit(t *testing.T) {
+	e := echo.New()
+
+	t.Run("when the db has a nil value, we should panic", func(t *testing.T) {
+		assert.Panics(t, func() {
+			Audit(nil)
+		})
+	})
+
+	t.Run("when the action is unknown we should return a 400 error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := auditmocks.NewMockAuditDatabase(ctrl)
+
+		req := httptest.NewRequest(http.MethodGet, "/?action=renamed", nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+
+		err := Audit(db)(ctx)
+		assert.ErrorContains(t, err, "Error:Field validation for 'Action' failed on the 'oneof' tag")
+		assert.Equal(t, http.StatusBadRequest, getStatusCode(rec, err))
+	})
+
+	t.Run("when a time can't be parsed we should return a 400 error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := auditmocks.NewMockAuditDatabase(ctrl)
+
+		req := httptest.NewRequest(http.MethodGet, "/?since=yesterday", nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+
+		err := Audit(db)(ctx)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, getStatusCode(rec, err))
+	})
+
+	t.Run("when no filters are given, the default limit should be used", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := auditmocks.NewMockAuditDatabase(ctrl)
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+
+		db.EXPECT().QueryAudit(database.AuditFilter{Limit: DefaultLimit}).Return([]*domain.AuditEvent{}, nil)
+
+		err := Audit(db)(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, getStatusCode(rec, err))
+	})
+
+	t.Run("when filters are given, they should be passed to the db", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := auditmocks.NewMockAuditDatabase(ctrl)
+
+		featureId := uuid.New()
+		req := httptest.NewRequest(http.MethodGet, "/?featureId="+featureId.String()+"&actor=admin&action=status_changed&since=2024-03-01T00:00:00Z&until=2024-04-01T00:00:00Z&limit=5", nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+
+		db.EXPECT().QueryAudit(database.AuditFilter{
+			FeatureId: featureId,
+			Actor:     "admin",
+			Action:    domain.AuditStatusChanged,
+			Since:     time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			Until:     time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+			Limit:     5,
+		}).Return([]*domain.AuditEvent{}, nil)
+
+		err := Audit(db)(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, getStatusCode(rec, err))
+	})
+
+	t.Run("when we get an unknown error from the db we should return a 500 error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := auditmocks.NewMockAuditDatabase(ctrl)
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+
+		db.EXPECT().QueryAudit(gomock.Any()).Return(nil, errors.New("some error"))
+
+		err := Audit(db)(ctx)
+		assert.ErrorContains(t, err, "some error")
+		assert.Equal(t, http.StatusInternalServerError, getStatusCode(rec, err))
+	})
+}
+
+func getStatusCode(rec *httptest.ResponseRecorder, err error) int {
+	if err == nil {
+		return rec.Code
+	}
+
+	hterr := &echo.HTTPError{}
+	if errors.As(err, &hterr) {
+		return hterr.Code
+	}
+
+	return 500
+}
@@ -0,0 +1,31 @@
+package cachestats
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/music-tribe/react-pairing-challenge/cache"
+)
+
+//go:generate mockgen -destination=./mocks/cachestats.go -package=cachestatsmocks -source=cachestats.go
+type StatsSource interface {
+	CacheStats() cache.Stats
+}
+
+// CacheStats godoc
+// @Summary Get the feature cache's statistics.
+// @Description Get the size and hit, miss and eviction counts of the in-memory feature cache of the replica that answers. Requires the admin API key.
+// @Produce application/json
+// @Security AdminKey
+// @Router /api/admin/cache [get]
+// @Success 200 {object} cache.Stats
+// @failure 401 {object} error
+func CacheStats(src StatsSource) func(echo.Context) error {
+	if src == nil {
+		panic("cachestats.CacheStats: src has nil value")
+	}
+
+	return func(c echo.Context) error {
+		return c.JSON(http.StatusOK, src.CacheStats())
+	}
+}
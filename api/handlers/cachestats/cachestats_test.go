@@ -0,0 +1,40 @@
+package cachestats
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/music-tribe/react-pairing-challenge/cache"
+	cachestatsmocks "github.com/music-tribe/react-pairing-challenge/handlers/cachestats/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheStats(t *testing.T) {
+	e := echo.New()
+
+	t.Run("when the source has a nil value, we should panic", func(t *testing.T) {
+		assert.Panics(t, func() {
+			CacheStats(nil)
+		})
+	})
+
+	t.Run("when the stats are requested, they should be returned", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		src := cachestatsmocks.NewMockStatsSource(ctrl)
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+
+		src.EXPECT().CacheStats().Return(cache.Stats{Enabled: true, Hits: 9, Misses: 1})
+
+		err := CacheStats(src)(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"hits":9`)
+	})
+}
@@ -0,0 +1,59 @@
+package categories
+
+import (
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/music-tribe/react-pairing-challenge/database"
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/react-pairing-challenge/dto"
+)
+
+//go:generate mockgen -destination=./mocks/add.go -package=categoriesmocks -source=add.go
+type AddDatabase interface {
+	AddCategory(category *domain.Category) error
+}
+
+// Add godoc
+// @Summary Add a feature category.
+// @Description Add a category to the taxonomy so features can be tagged with its slug. Requires the admin API key.
+// @Accept application/json
+// @Produce application/json
+// @Security AdminKey
+// @Param category body dto.CreateCategory true "Category"
+// @Router /api/admin/categories [post]
+// @Success 201 {object} domain.Category
+// @failure 400 {object} error
+// @failure 401 {object} error
+// @failure 409 {object} error
+// @failure 429 {object} error
+// @failure 500 {object} error
+func Add(db AddDatabase) func(echo.Context) error {
+	if db == nil {
+		panic("categories.Add: db has nil value")
+	}
+
+	return func(c echo.Context) error {
+		req := dto.CreateCategory{}
+
+		if err := c.Bind(&req); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err)
+		}
+
+		if err := validator.New().Struct(&req); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err)
+		}
+
+		category := dto.NewCategory(&req)
+
+		if err := db.AddCategory(category); err != nil {
+			if err == database.ErrDuplicate {
+				return echo.NewHTTPError(http.StatusConflict, err)
+			}
+			return echo.NewHTTPError(http.StatusInternalServerError, err)
+		}
+
+		return c.JSON(http.StatusCreated, category)
+	}
+}
@@ -0,0 +1,81 @@
+package categories
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/music-tribe/react-pairing-challenge/database"
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	categoriesmocks "github.com/music-tribe/react-pairing-challenge/handlers/categories/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdd(t *testing.T) {
+	e := echo.New()
+
+	t.Run("when the db has a nil value, we should panic", func(t *testing.T) {
+		assert.Panics(t, func() {
+			Add(nil)
+		})
+	})
+
+	t.Run("when the name is missing we should return a 400 error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := categoriesmocks.NewMockAddDatabase(ctrl)
+
+		byt := []byte(`{"slug":"mixer"}`)
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(byt))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+
+		err := Add(db)(ctx)
+		assert.ErrorContains(t, err, "Error:Field validation for 'Name' failed on the 'required' tag")
+		assert.Equal(t, http.StatusBadRequest, getStatusCode(rec, err))
+	})
+
+	t.Run("when the category already exists we should return a 409 error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := categoriesmocks.NewMockAddDatabase(ctrl)
+
+		byt := []byte(`{"slug":"mixer","name":"Mixer"}`)
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(byt))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+
+		db.EXPECT().AddCategory(gomock.Any()).Return(database.ErrDuplicate)
+
+		err := Add(db)(ctx)
+		assert.ErrorContains(t, err, database.ErrDuplicate.Error())
+		assert.Equal(t, http.StatusConflict, getStatusCode(rec, err))
+	})
+
+	t.Run("when the request is healthy, the slug should be normalized and a 201 returned", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := categoriesmocks.NewMockAddDatabase(ctrl)
+
+		byt := []byte(`{"slug":"Mobile App","name":"Mobile App","description":"iOS and Android"}`)
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(byt))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+
+		db.EXPECT().AddCategory(&domain.Category{
+			Slug:        "mobile-app",
+			Name:        "Mobile App",
+			Description: "iOS and Android",
+		}).Return(nil)
+
+		err := Add(db)(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, getStatusCode(rec, err))
+	})
+}
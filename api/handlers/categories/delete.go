@@ -0,0 +1,59 @@
+package categories
+
+import (
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/music-tribe/react-pairing-challenge/database"
+	"github.com/music-tribe/react-pairing-challenge/domain"
+)
+
+//go:generate mockgen -destination=./mocks/delete.go -package=categoriesmocks -source=delete.go
+type DeleteDatabase interface {
+	DeleteCategory(slug string) error
+}
+
+type DeleteRequest struct {
+	Slug string `param:"slug" validate:"required,max=64" example:"mobile-app"`
+}
+
+// Delete godoc
+// @Summary Delete a feature category.
+// @Description Delete a category from the taxonomy and remove its tag from every feature. Requires the admin API key.
+// @Produce text/plain
+// @Security AdminKey
+// @Param slug path string true "Category slug"
+// @Router /api/admin/categories/{slug} [delete]
+// @Success 200 {string} string "DELETED"
+// @failure 400 {object} error
+// @failure 401 {object} error
+// @failure 404 {object} error
+// @failure 429 {object} error
+// @failure 500 {object} error
+func Delete(db DeleteDatabase) func(echo.Context) error {
+	if db == nil {
+		panic("categories.Delete: db has nil value")
+	}
+
+	return func(c echo.Context) error {
+		req := DeleteRequest{}
+
+		if err := c.Bind(&req); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err)
+		}
+
+		if err := validator.New().Struct(&req); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err)
+		}
+
+		if err := db.DeleteCategory(domain.NormalizeTag(req.Slug)); err != nil {
+			if err == database.ErrNotFound {
+				return echo.NewHTTPError(http.StatusNotFound, err)
+			}
+			return echo.NewHTTPError(http.StatusInternalServerError, err)
+		}
+
+		return c.String(http.StatusOK, "DELETED")
+	}
+}
@@ -0,0 +1,76 @@
+package categories
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/music-tribe/react-pairing-challenge/database"
+	categoriesmocks "github.com/music-tribe/react-pairing-challenge/handlers/categories/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDelete(t *testing.T) {
+	e := echo.New()
+
+	t.Run("when the db has a nil value, we should panic", func(t *testing.T) {
+		assert.Panics(t, func() {
+			Delete(nil)
+		})
+	})
+
+	t.Run("when the slug is missing we should return a 400 error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := categoriesmocks.NewMockDeleteDatabase(ctrl)
+
+		req := httptest.NewRequest(http.MethodDelete, "/", nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetParamNames("slug")
+		ctx.SetParamValues("")
+
+		err := Delete(db)(ctx)
+		assert.ErrorContains(t, err, "Error:Field validation for 'Slug' failed on the 'required' tag")
+		assert.Equal(t, http.StatusBadRequest, getStatusCode(rec, err))
+	})
+
+	t.Run("when the category can't be found we should return a 404 error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := categoriesmocks.NewMockDeleteDatabase(ctrl)
+
+		req := httptest.NewRequest(http.MethodDelete, "/", nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetParamNames("slug")
+		ctx.SetParamValues("mixer")
+
+		db.EXPECT().DeleteCategory("mixer").Return(database.ErrNotFound)
+
+		err := Delete(db)(ctx)
+		assert.ErrorContains(t, err, database.ErrNotFound.Error())
+		assert.Equal(t, http.StatusNotFound, getStatusCode(rec, err))
+	})
+
+	t.Run("when the request is healthy, we should return a 200 response", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := categoriesmocks.NewMockDeleteDatabase(ctrl)
+
+		req := httptest.NewRequest(http.MethodDelete, "/", nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetParamNames("slug")
+		ctx.SetParamValues("mixer")
+
+		db.EXPECT().DeleteCategory("mixer").Return(nil)
+
+		err := Delete(db)(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, getStatusCode(rec, err))
+		assert.Equal(t, "DELETED", rec.Body.String())
+	})
+}
@@ -0,0 +1,54 @@
+package categories
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/music-tribe/react-pairing-challenge/domain"
+)
+
+//go:generate mockgen -destination=./mocks/list.go -package=categoriesmocks -source=list.go
+type ListDatabase interface {
+	GetCategories() ([]*domain.Category, error)
+	TagCounts() (map[string]int64, error)
+}
+
+type CategoryResponse struct {
+	domain.Category
+	FeatureCount int64 `json:"featureCount" example:"12"`
+}
+
+// List godoc
+// @Summary List the feature categories.
+// @Description List every category in the taxonomy with the number of features tagged with it.
+// @Produce application/json
+// @Router /api/categories [get]
+// @Success 200 {object} []CategoryResponse
+// @failure 500 {object} error
+func List(db ListDatabase) func(echo.Context) error {
+	if db == nil {
+		panic("categories.List: db has nil value")
+	}
+
+	return func(c echo.Context) error {
+		cs, err := db.GetCategories()
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err)
+		}
+
+		counts, err := db.TagCounts()
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err)
+		}
+
+		res := make([]CategoryResponse, 0, len(cs))
+		for _, category := range cs {
+			res = append(res, CategoryResponse{
+				Category:     *category,
+				FeatureCount: counts[category.Slug],
+			})
+		}
+
+		return c.JSON(http.StatusOK, res)
+	}
+}
@@ -0,0 +1,69 @@
+package categories
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	categoriesmocks "github.com/music-tribe/react-pairing-challenge/handlers/categories/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestList(t *testing.T) {
+	e := echo.New()
+
+	t.Run("when the db has a nil value, we should panic", func(t *testing.T) {
+		assert.Panics(t, func() {
+			List(nil)
+		})
+	})
+
+	t.Run("when the categories can't be read we should return a 500 error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := categoriesmocks.NewMockListDatabase(ctrl)
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+
+		db.EXPECT().GetCategories().Return(nil, errors.New("some error"))
+
+		err := List(db)(ctx)
+		assert.ErrorContains(t, err, "some error")
+		assert.Equal(t, http.StatusInternalServerError, getStatusCode(rec, err))
+	})
+
+	t.Run("when the request is healthy, each category should carry its feature count", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := categoriesmocks.NewMockListDatabase(ctrl)
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+
+		db.EXPECT().GetCategories().Return([]*domain.Category{
+			{Slug: "cloud", Name: "Cloud"},
+			{Slug: "mixer", Name: "Mixer"},
+		}, nil)
+		db.EXPECT().TagCounts().Return(map[string]int64{"mixer": 3}, nil)
+
+		err := List(db)(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, getStatusCode(rec, err))
+
+		res := []CategoryResponse{}
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+		assert.Len(t, res, 2)
+		assert.Equal(t, "cloud", res[0].Slug)
+		assert.Zero(t, res[0].FeatureCount)
+		assert.Equal(t, "mixer", res[1].Slug)
+		assert.Equal(t, int64(3), res[1].FeatureCount)
+	})
+}
@@ -0,0 +1,37 @@
+package categories
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+var ErrUnknownTags = errors.New("tags must be existing categories")
+
+//go:generate mockgen -destination=./mocks/tags.go -package=categoriesmocks -source=tags.go
+type TagDatabase interface {
+	MissingCategories(slugs []string) ([]string, error)
+}
+
+// CheckTags makes sure every tag names a category in the taxonomy, returning
+// an echo.HTTPError ready to hand back to the client when it doesn't.
+func CheckTags(db TagDatabase, tags []string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	missing, err := db.MissingCategories(tags)
+	if err != nil {
+		return echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	if len(missing) > 0 {
+		return echo.NewHTTPError(http.StatusBadRequest,
+			fmt.Errorf("%w: unknown %s", ErrUnknownTags, strings.Join(missing, ", ")))
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package categories
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	categoriesmocks "github.com/music-tribe/react-pairing-challenge/handlers/categories/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckTags(t *testing.T) {
+	t.Run("when there are no tags, we shouldn't touch the db", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := categoriesmocks.NewMockTagDatabase(ctrl)
+
+		assert.NoError(t, CheckTags(db, nil))
+	})
+
+	t.Run("when every tag exists we should return no error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := categoriesmocks.NewMockTagDatabase(ctrl)
+
+		db.EXPECT().MissingCategories([]string{"mixer"}).Return([]string{}, nil)
+
+		assert.NoError(t, CheckTags(db, []string{"mixer"}))
+	})
+
+	t.Run("when tags are missing we should return a 400 error naming them", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := categoriesmocks.NewMockTagDatabase(ctrl)
+
+		db.EXPECT().MissingCategories([]string{"mixer", "toaster", "kettle"}).Return([]string{"toaster", "kettle"}, nil)
+
+		err := CheckTags(db, []string{"mixer", "toaster", "kettle"})
+		assert.ErrorContains(t, err, ErrUnknownTags.Error()+": unknown toaster, kettle")
+		assert.Equal(t, http.StatusBadRequest, getStatusCode(httptest.NewRecorder(), err))
+	})
+
+	t.Run("when the db fails we should return a 500 error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := categoriesmocks.NewMockTagDatabase(ctrl)
+
+		db.EXPECT().MissingCategories([]string{"mixer"}).Return(nil, errors.New("some error"))
+
+		err := CheckTags(db, []string{"mixer"})
+		assert.ErrorContains(t, err, "some error")
+		assert.Equal(t, http.StatusInternalServerError, getStatusCode(httptest.NewRecorder(), err))
+	})
+}
+
+func getStatusCode(rec *httptest.ResponseRecorder, err error) int {
+	if err == nil {
+		return rec.Code
+	}
+
+	hterr := &echo.HTTPError{}
+	if errors.As(err, &hterr) {
+		return hterr.Code
+	}
+
+	return 500
+}
@@ -0,0 +1,60 @@
+package categories
+
+import (
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/music-tribe/react-pairing-challenge/database"
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/react-pairing-challenge/dto"
+)
+
+//go:generate mockgen -destination=./mocks/update.go -package=categoriesmocks -source=update.go
+type UpdateDatabase interface {
+	UpdateCategory(category *domain.Category) error
+}
+
+// Update godoc
+// @Summary Update a feature category.
+// @Description Update the name and description of a category. Slugs can't be changed. Requires the admin API key.
+// @Accept application/json
+// @Produce application/json
+// @Security AdminKey
+// @Param slug path string true "Category slug"
+// @Param category body dto.UpdateCategory true "Category"
+// @Router /api/admin/categories/{slug} [put]
+// @Success 200 {object} domain.Category
+// @failure 400 {object} error
+// @failure 401 {object} error
+// @failure 404 {object} error
+// @failure 429 {object} error
+// @failure 500 {object} error
+func Update(db UpdateDatabase) func(echo.Context) error {
+	if db == nil {
+		panic("categories.Update: db has nil value")
+	}
+
+	return func(c echo.Context) error {
+		req := dto.UpdateCategory{}
+
+		if err := c.Bind(&req); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err)
+		}
+
+		if err := validator.New().Struct(&req); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err)
+		}
+
+		category := dto.UpdatedCategory(&req)
+
+		if err := db.UpdateCategory(category); err != nil {
+			if err == database.ErrNotFound {
+				return echo.NewHTTPError(http.StatusNotFound, err)
+			}
+			return echo.NewHTTPError(http.StatusInternalServerError, err)
+		}
+
+		return c.JSON(http.StatusOK, category)
+	}
+}
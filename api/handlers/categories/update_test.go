@@ -0,0 +1,90 @@
+package categories
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/music-tribe/react-pairing-challenge/database"
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	categoriesmocks "github.com/music-tribe/react-pairing-challenge/handlers/categories/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdate(t *testing.T) {
+	e := echo.New()
+
+	t.Run("when the db has a nil value, we should panic", func(t *testing.T) {
+		assert.Panics(t, func() {
+			Update(nil)
+		})
+	})
+
+	t.Run("when the category can't be found we should return a 404 error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := categoriesmocks.NewMockUpdateDatabase(ctrl)
+
+		byt := []byte(`{"name":"Mixer"}`)
+		req := httptest.NewRequest(http.MethodPut, "/", bytes.NewReader(byt))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetParamNames("slug")
+		ctx.SetParamValues("mixer")
+
+		db.EXPECT().UpdateCategory(gomock.Any()).Return(database.ErrNotFound)
+
+		err := Update(db)(ctx)
+		assert.ErrorContains(t, err, database.ErrNotFound.Error())
+		assert.Equal(t, http.StatusNotFound, getStatusCode(rec, err))
+	})
+
+	t.Run("when we get an unknown error from the db we should return a 500 error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := categoriesmocks.NewMockUpdateDatabase(ctrl)
+
+		byt := []byte(`{"name":"Mixer"}`)
+		req := httptest.NewRequest(http.MethodPut, "/", bytes.NewReader(byt))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetParamNames("slug")
+		ctx.SetParamValues("mixer")
+
+		db.EXPECT().UpdateCategory(gomock.Any()).Return(errors.New("some error"))
+
+		err := Update(db)(ctx)
+		assert.ErrorContains(t, err, "some error")
+		assert.Equal(t, http.StatusInternalServerError, getStatusCode(rec, err))
+	})
+
+	t.Run("when the request is healthy, the slug should come from the path", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := categoriesmocks.NewMockUpdateDatabase(ctrl)
+
+		byt := []byte(`{"slug":"other","name":"Mixers","description":"Desks and racks"}`)
+		req := httptest.NewRequest(http.MethodPut, "/", bytes.NewReader(byt))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetParamNames("slug")
+		ctx.SetParamValues("mixer")
+
+		db.EXPECT().UpdateCategory(&domain.Category{
+			Slug:        "mixer",
+			Name:        "Mixers",
+			Description: "Desks and racks",
+		}).Return(nil)
+
+		err := Update(db)(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, getStatusCode(rec, err))
+	})
+}
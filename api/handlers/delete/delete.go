@@ -21,7 +21,7 @@ type DeleteRequest struct {
 
 // Delete godoc
 // @Summary Delete a users feature.
-// @Description Delete one of this users features.
+// @Description Move one of this users features to the trash, where it can be restored until it is purged.
 // @Accept application/json
 // @Produce text/plain
 // @Param userId path string true "User UUID"
@@ -30,6 +30,7 @@ type DeleteRequest struct {
 // @Success 200 {string} string "DELETED"
 // @failure 400 {object} error
 // @failure 404 {object} error
+// @failure 429 {object} error
 // @failure 500 {object} error
 func Delete(db DeleteDatabase) func(echo.Context) error {
 	if db == nil {
@@ -0,0 +1,133 @@
+package export
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/music-tribe/react-pairing-challenge/database"
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/react-pairing-challenge/exporter"
+	"github.com/music-tribe/react-pairing-challenge/ranking"
+)
+
+// flushEvery is how many rows are written between flushes to the client.
+const flushEvery = 100
+
+//go:generate mockgen -destination=./mocks/export.go -package=exportmocks -source=export.go
+type ExportDatabase interface {
+	EachFeature(ctx context.Context, opts database.ListOptions, withVotes bool, fn func(feature *domain.Feature, votes []*domain.Vote) error) error
+}
+
+type ExportRequest struct {
+	Format  string       `query:"format" validate:"required,oneof=csv jsonl ndjson" example:"csv"`
+	Columns string       `query:"columns" validate:"max=512" example:"id,name,voteCount,voters"`
+	Query   string       `query:"q" validate:"max=256" example:"dark mode"`
+	Board   string       `query:"board" validate:"max=64" example:"mixer"`
+	Tags    []string     `query:"tag" validate:"max=10,dive,max=64" example:"mixer"`
+	Match   string       `query:"match" validate:"omitempty,oneof=any all" example:"any"`
+	Status  string       `query:"status" validate:"omitempty,oneof=open planned in-progress done declined" example:"planned"`
+	Sort    ranking.Sort `query:"sort" validate:"omitempty,oneof=hot top new rising" example:"new"`
+}
+
+// Export godoc
+// @Summary Export features as CSV or JSON Lines.
+// @Description Stream every feature matching the filters as CSV or JSON Lines, for analysis in a spreadsheet. Rows are written as they are read, so exports of any size start straight away. Lists such as tags and voters are separated by semicolons in CSV.
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Param format query string true "Output format" Enums(csv, jsonl, ndjson)
+// @Param columns query string false "Comma separated columns to export, defaults to every feature field" example(id,name,voteCount,voters)
+// @Param q query string false "Free text to match against names and descriptions"
+// @Param board query string false "Only features on this board"
+// @Param tag query []string false "Only features with these tags" collectionFormat(multi)
+// @Param match query string false "Match any or all of the tags, defaults to any" Enums(any, all)
+// @Param status query string false "Only features with this status" Enums(open, planned, in-progress, done, declined)
+// @Param sort query string false "Sort order, defaults to new" Enums(hot, top, new, rising)
+// @Router /api/features/export [get]
+// @Success 200 {string} string "The exported features"
+// @failure 400 {object} error
+// @failure 500 {object} error
+func Export(db ExportDatabase) func(echo.Context) error {
+	if db == nil {
+		panic("export.Export: db has nil value")
+	}
+
+	return func(c echo.Context) error {
+		req := ExportRequest{}
+
+		if err := c.Bind(&req); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err)
+		}
+
+		if err := validator.New().Struct(&req); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err)
+		}
+
+		cols, err := exporter.ParseColumns(req.Columns)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err)
+		}
+
+		format, err := exporter.ParseFormat(req.Format)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err)
+		}
+
+		res := c.Response()
+		w := exporter.NewWriter(format, res, cols)
+		res.Header().Set(echo.HeaderContentType, format.ContentType())
+		filename := fmt.Sprintf("features-%s.%s", time.Now().UTC().Format("20060102-150405"), format)
+		res.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+
+		rows := 0
+		err = db.EachFeature(c.Request().Context(), database.ListOptions{
+			Sort:         req.Sort,
+			Board:        req.Board,
+			Tags:         domain.NormalizeTags(req.Tags),
+			MatchAllTags: req.Match == "all",
+			Text:         req.Query,
+			Status:       domain.FeatureStatus(req.Status),
+		}, exporter.NeedVotes(cols), func(f *domain.Feature, votes []*domain.Vote) error {
+			if !res.Committed {
+				res.WriteHeader(http.StatusOK)
+				if err := w.Header(); err != nil {
+					return err
+				}
+			}
+
+			if err := w.Row(f, votes); err != nil {
+				return err
+			}
+
+			if rows++; rows%flushEvery == 0 {
+				if err := w.Flush(); err != nil {
+					return err
+				}
+				res.Flush()
+			}
+			return nil
+		})
+		if err != nil {
+			// once rows have gone out the status can't change; the client
+			// sees the export cut short
+			if !res.Committed {
+				res.Header().Del(echo.HeaderContentDisposition)
+			}
+			return echo.NewHTTPError(http.StatusInternalServerError, err)
+		}
+
+		if !res.Committed {
+			res.WriteHeader(http.StatusOK)
+			if err := w.Header(); err != nil {
+				return echo.NewHTTPError(http.StatusInternalServerError, err)
+			}
+		}
+		if err := w.Flush(); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err)
+		}
+		return nil
+	}
+}
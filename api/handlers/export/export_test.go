@@ -0,0 +1,209 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/music-tribe/react-pairing-challenge/database"
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/react-pairing-challenge/exporter"
+	exportmocks "github.com/music-tribe/react-pairing-challenge/handlers/export/mocks"
+	"github.com/music-tribe/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExport(t *testing.T) {
+	e := echo.New()
+
+	createdAt := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	feature := &domain.Feature{
+		Id:          uuid.MustParse("f6e7f8c4-3af6-4028-ac7c-30c9d79a3fa7"),
+		UserId:      uuid.MustParse("effe01ec-7f09-4a1c-9453-794212a8ac26"),
+		Name:        "Dark mode",
+		Description: "Easier on the eyes, \"please\"",
+		Board:       "mixer",
+		Tags:        []string{"mixer", "mobile-app"},
+		Status:      domain.StatusPlanned,
+		VoteCount:   2,
+		Upvotes:     2,
+		Downvotes:   1,
+		Score:       1,
+		CreatedAt:   createdAt,
+		UpdatedAt:   createdAt,
+	}
+	up := &domain.Vote{VoterId: uuid.MustParse("155dccaa-0299-4018-ab6b-90b9ee448943"), Direction: domain.Upvote}
+	legacy := &domain.Vote{VoterId: uuid.MustParse("202c25c4-b2ce-4514-9045-890a1aa896ea")}
+	down := &domain.Vote{VoterId: uuid.MustParse("3c1f0d57-8f7a-4d3e-9b1a-2d5c6e7f8a9b"), Direction: domain.Downvote}
+
+	// each hands the features to the handler the way the database does
+	each := func(features ...*domain.Feature) func(context.Context, database.ListOptions, bool, func(*domain.Feature, []*domain.Vote) error) error {
+		return func(_ context.Context, _ database.ListOptions, withVotes bool, fn func(*domain.Feature, []*domain.Vote) error) error {
+			for _, f := range features {
+				var votes []*domain.Vote
+				if withVotes {
+					votes = []*domain.Vote{up, legacy, down}
+				}
+				if err := fn(f, votes); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+
+	t.Run("when the db has a nil value, we should panic", func(t *testing.T) {
+		assert.Panics(t, func() {
+			Export(nil)
+		})
+	})
+
+	t.Run("when the format is missing, we should return a 400 error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := exportmocks.NewMockExportDatabase(ctrl)
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+
+		err := Export(db)(ctx)
+		assert.ErrorContains(t, err, "Error:Field validation for 'Format' failed on the 'required' tag")
+		assert.Equal(t, http.StatusBadRequest, getStatusCode(rec, err))
+	})
+
+	t.Run("when a column is unknown, we should return a 400 error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := exportmocks.NewMockExportDatabase(ctrl)
+
+		req := httptest.NewRequest(http.MethodGet, "/?format=csv&columns=id,secret", nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+
+		err := Export(db)(ctx)
+		assert.ErrorContains(t, err, `unknown column "secret"`)
+		assert.Equal(t, http.StatusBadRequest, getStatusCode(rec, err))
+	})
+
+	t.Run("when we export CSV, we should get the default columns with the filters passed on", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := exportmocks.NewMockExportDatabase(ctrl)
+
+		req := httptest.NewRequest(http.MethodGet, "/?format=csv&board=mixer&tag=Mobile+App&status=planned&sort=top", nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+
+		opts := database.ListOptions{Sort: "top", Board: "mixer", Tags: []string{"mobile-app"}, Status: domain.StatusPlanned}
+		db.EXPECT().EachFeature(gomock.Any(), opts, false, gomock.Any()).DoAndReturn(each(feature))
+
+		err := Export(db)(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "text/csv; charset=utf-8", rec.Header().Get(echo.HeaderContentType))
+		assert.Contains(t, rec.Header().Get(echo.HeaderContentDisposition), `attachment; filename="features-`)
+		assert.Equal(t,
+			exporter.DefaultColumns+"\n"+
+				`f6e7f8c4-3af6-4028-ac7c-30c9d79a3fa7,effe01ec-7f09-4a1c-9453-794212a8ac26,Dark mode,"Easier on the eyes, ""please""",mixer,mixer;mobile-app,planned,2,2,1,1,2024-03-01T09:30:00Z,2024-03-01T09:30:00Z,`+"\n",
+			rec.Body.String())
+	})
+
+	t.Run("when voter columns are asked for, the votes should be fetched and listed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := exportmocks.NewMockExportDatabase(ctrl)
+
+		req := httptest.NewRequest(http.MethodGet, "/?format=jsonl&columns=id,voteCount,upvoters,downvoters,lastVotedAt", nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+
+		db.EXPECT().EachFeature(gomock.Any(), gomock.Any(), true, gomock.Any()).DoAndReturn(each(feature))
+
+		err := Export(db)(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, "application/x-ndjson", rec.Header().Get(echo.HeaderContentType))
+		assert.Equal(t,
+			`{"id":"f6e7f8c4-3af6-4028-ac7c-30c9d79a3fa7","voteCount":2,`+
+				`"upvoters":["155dccaa-0299-4018-ab6b-90b9ee448943","202c25c4-b2ce-4514-9045-890a1aa896ea"],`+
+				`"downvoters":["3c1f0d57-8f7a-4d3e-9b1a-2d5c6e7f8a9b"],"lastVotedAt":null}`+"\n",
+			rec.Body.String())
+	})
+
+	t.Run("when there are many features, they should all be streamed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := exportmocks.NewMockExportDatabase(ctrl)
+
+		features := make([]*domain.Feature, 2*flushEvery+1)
+		for i := range features {
+			features[i] = &domain.Feature{Id: uuid.New(), Name: fmt.Sprint(i)}
+		}
+
+		req := httptest.NewRequest(http.MethodGet, "/?format=ndjson&columns=name", nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+
+		db.EXPECT().EachFeature(gomock.Any(), gomock.Any(), false, gomock.Any()).DoAndReturn(each(features...))
+
+		err := Export(db)(ctx)
+		assert.NoError(t, err)
+		lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+		assert.Len(t, lines, len(features))
+		assert.Equal(t, `{"name":"200"}`, lines[200])
+		assert.True(t, rec.Flushed)
+	})
+
+	t.Run("when nothing matches, a CSV export should still have its header", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := exportmocks.NewMockExportDatabase(ctrl)
+
+		req := httptest.NewRequest(http.MethodGet, "/?format=csv&columns=id,name", nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+
+		db.EXPECT().EachFeature(gomock.Any(), gomock.Any(), false, gomock.Any()).DoAndReturn(each())
+
+		err := Export(db)(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, "id,name\n", rec.Body.String())
+	})
+
+	t.Run("when the db fails before anything is written, we should return a 500 error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := exportmocks.NewMockExportDatabase(ctrl)
+
+		req := httptest.NewRequest(http.MethodGet, "/?format=csv", nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+
+		db.EXPECT().EachFeature(gomock.Any(), gomock.Any(), false, gomock.Any()).Return(errors.New("db down"))
+
+		err := Export(db)(ctx)
+		assert.ErrorContains(t, err, "db down")
+		assert.Equal(t, http.StatusInternalServerError, getStatusCode(rec, err))
+		assert.False(t, ctx.Response().Committed)
+	})
+}
+
+func getStatusCode(rec *httptest.ResponseRecorder, err error) int {
+	if err == nil {
+		return rec.Code
+	}
+
+	hterr := &echo.HTTPError{}
+	if errors.As(err, &hterr) {
+		return hterr.Code
+	}
+
+	return 500
+}
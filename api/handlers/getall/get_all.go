@@ -7,16 +7,20 @@ import (
 	"github.com/labstack/echo/v4"
 	"github.com/music-tribe/react-pairing-challenge/database"
 	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/react-pairing-challenge/ranking"
 	"github.com/music-tribe/uuid"
 )
 
 //go:generate mockgen -destination=./mocks/get_all.go -package=getallmocks -source=get_all.go
 type GetAllDatabase interface {
-	GetAll(userId uuid.UUID) ([]*domain.Feature, error)
+	GetAll(userId uuid.UUID, opts database.ListOptions) ([]*domain.Feature, error)
 }
 
 type GetAllRequest struct {
-	UserId uuid.UUID `param:"userId" validate:"required" example:"ef2a27c4-b03d-4190-86f2-b1dc2538243e"`
+	UserId uuid.UUID    `param:"userId" validate:"required" example:"ef2a27c4-b03d-4190-86f2-b1dc2538243e"`
+	Sort   ranking.Sort `query:"sort" validate:"omitempty,oneof=hot top new rising" example:"hot"`
+	Tags   []string     `query:"tag" validate:"max=10,dive,max=64" example:"mixer"`
+	Match  string       `query:"match" validate:"omitempty,oneof=any all" example:"any"`
 }
 
 // GetAll godoc
@@ -25,6 +29,9 @@ type GetAllRequest struct {
 // @Accept application/json
 // @Produce text/plain
 // @Param userId path string true "User UUID"
+// @Param sort query string false "Sort order, defaults to new" Enums(hot, top, new, rising)
+// @Param tag query []string false "Only features with these tags" collectionFormat(multi)
+// @Param match query string false "Match any or all of the tags, defaults to any" Enums(any, all)
 // @Router /api/{userId} [get]
 // @Success 200 {object} []domain.Feature
 // @failure 400 {object} error
@@ -46,7 +53,11 @@ func GetAll(db GetAllDatabase) func(echo.Context) error {
 			return echo.NewHTTPError(http.StatusBadRequest, err)
 		}
 
-		features, err := db.GetAll(req.UserId)
+		features, err := db.GetAll(req.UserId, database.ListOptions{
+			Sort:         req.Sort,
+			Tags:         domain.NormalizeTags(req.Tags),
+			MatchAllTags: req.Match == "all",
+		})
 		if err != nil {
 			if err == database.ErrNotFound {
 				return echo.NewHTTPError(http.StatusNotFound, err)
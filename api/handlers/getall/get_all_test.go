@@ -12,6 +12,7 @@ import (
 	"github.com/music-tribe/react-pairing-challenge/database"
 	"github.com/music-tribe/react-pairing-challenge/domain"
 	getallmocks "github.com/music-tribe/react-pairing-challenge/handlers/getall/mocks"
+	"github.com/music-tribe/react-pairing-challenge/ranking"
 	"github.com/music-tribe/uuid"
 	"github.com/stretchr/testify/assert"
 )
@@ -69,7 +70,7 @@ func TestGetAll(t *testing.T) {
 		ctx.SetParamNames("userId")
 		ctx.SetParamValues(userId.String())
 
-		db.EXPECT().GetAll(userId).Return([]*domain.Feature{}, database.ErrNotFound)
+		db.EXPECT().GetAll(userId, database.ListOptions{}).Return([]*domain.Feature{}, database.ErrNotFound)
 
 		err := GetAll(db)(ctx)
 		assert.ErrorContains(t, err, database.ErrNotFound.Error())
@@ -88,7 +89,7 @@ func TestGetAll(t *testing.T) {
 		ctx.SetParamNames("userId")
 		ctx.SetParamValues(userId.String())
 
-		db.EXPECT().GetAll(userId).Return([]*domain.Feature{}, errors.New("some error"))
+		db.EXPECT().GetAll(userId, database.ListOptions{}).Return([]*domain.Feature{}, errors.New("some error"))
 
 		err := GetAll(db)(ctx)
 		assert.ErrorContains(t, err, "some error")
@@ -128,7 +129,7 @@ func TestGetAll(t *testing.T) {
 			},
 		}
 
-		db.EXPECT().GetAll(userId).Return(expectfeatures, nil)
+		db.EXPECT().GetAll(userId, database.ListOptions{}).Return(expectfeatures, nil)
 
 		err := GetAll(db)(ctx)
 		assert.NoError(t, err)
@@ -139,6 +140,80 @@ func TestGetAll(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, expectfeatures, actualfeatures)
 	})
+
+	t.Run("when the sort order is unknown we should return a 400 error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := getallmocks.NewMockGetAllDatabase(ctrl)
+
+		userId := uuid.New()
+		req := httptest.NewRequest(http.MethodGet, "/?sort=random", nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetParamNames("userId")
+		ctx.SetParamValues(userId.String())
+
+		err := GetAll(db)(ctx)
+		assert.ErrorContains(t, err, "Error:Field validation for 'Sort' failed on the 'oneof' tag")
+		assert.Equal(t, http.StatusBadRequest, getAllStatusCode(rec, err))
+	})
+
+	t.Run("when a sort order is given, it should be passed to the db", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := getallmocks.NewMockGetAllDatabase(ctrl)
+
+		userId := uuid.New()
+		req := httptest.NewRequest(http.MethodGet, "/?sort=hot", nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetParamNames("userId")
+		ctx.SetParamValues(userId.String())
+
+		db.EXPECT().GetAll(userId, database.ListOptions{Sort: ranking.SortHot}).Return([]*domain.Feature{}, nil)
+
+		err := GetAll(db)(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, getAllStatusCode(rec, err))
+	})
+
+	t.Run("when tags are given, they should be normalized and passed to the db", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := getallmocks.NewMockGetAllDatabase(ctrl)
+
+		userId := uuid.New()
+		req := httptest.NewRequest(http.MethodGet, "/?tag=Mixer&tag=mobile%20app&match=all", nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetParamNames("userId")
+		ctx.SetParamValues(userId.String())
+
+		db.EXPECT().GetAll(userId, database.ListOptions{
+			Tags:         []string{"mixer", "mobile-app"},
+			MatchAllTags: true,
+		}).Return([]*domain.Feature{}, nil)
+
+		err := GetAll(db)(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, getAllStatusCode(rec, err))
+	})
+
+	t.Run("when the tag match mode is unknown we should return a 400 error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := getallmocks.NewMockGetAllDatabase(ctrl)
+
+		req := httptest.NewRequest(http.MethodGet, "/?tag=mixer&match=some", nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetParamNames("userId")
+		ctx.SetParamValues(uuid.New().String())
+
+		err := GetAll(db)(ctx)
+		assert.ErrorContains(t, err, "Error:Field validation for 'Match' failed on the 'oneof' tag")
+		assert.Equal(t, http.StatusBadRequest, getAllStatusCode(rec, err))
+	})
 }
 
 func getAllStatusCode(rec *httptest.ResponseRecorder, err error) int {
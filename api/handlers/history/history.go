@@ -0,0 +1,53 @@
+package history
+
+import (
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/uuid"
+)
+
+//go:generate mockgen -destination=./mocks/history.go -package=historymocks -source=history.go
+type HistoryDatabase interface {
+	GetHistory(featureId uuid.UUID) ([]*domain.AuditEvent, error)
+}
+
+type HistoryRequest struct {
+	FeatureId uuid.UUID `param:"featureId" validate:"required" example:"202c25c4-b2ce-4514-9045-890a1aa896ea"`
+}
+
+// History godoc
+// @Summary Get the change history of a feature.
+// @Description Get every recorded change to a feature, oldest first, including who made it.
+// @Produce application/json
+// @Param featureId path string true "Feature UUID"
+// @Router /api/features/{featureId}/history [get]
+// @Success 200 {object} []domain.AuditEvent
+// @failure 400 {object} error
+// @failure 500 {object} error
+func History(db HistoryDatabase) func(echo.Context) error {
+	if db == nil {
+		panic("history.History: db has nil value")
+	}
+
+	return func(c echo.Context) error {
+		req := HistoryRequest{}
+
+		if err := c.Bind(&req); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err)
+		}
+
+		if err := validator.New().Struct(&req); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err)
+		}
+
+		events, err := db.GetHistory(req.FeatureId)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err)
+		}
+
+		return c.JSON(http.StatusOK, events)
+	}
+}
@@ -0,0 +1,104 @@
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	historymocks "github.com/music-tribe/react-pairing-challenge/handlers/history/mocks"
+	"github.com/music-tribe/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistory(t *testing.T) {
+	e := echo.New()
+
+	t.Run("when the db has a nil value, we should panic", func(t *testing.T) {
+		assert.Panics(t, func() {
+			History(nil)
+		})
+	})
+
+	t.Run("when the featureId has a nil value we should return a 400 error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := historymocks.NewMockHistoryDatabase(ctrl)
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetParamNames("featureId")
+		ctx.SetParamValues(uuid.Nil.String())
+
+		err := History(db)(ctx)
+		assert.ErrorContains(t, err, "Error:Field validation for 'FeatureId' failed on the 'required' tag")
+		assert.Equal(t, http.StatusBadRequest, getStatusCode(rec, err))
+	})
+
+	t.Run("when we get an unknown error from the db we should return a 500 error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := historymocks.NewMockHistoryDatabase(ctrl)
+
+		featureId := uuid.New()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetParamNames("featureId")
+		ctx.SetParamValues(featureId.String())
+
+		db.EXPECT().GetHistory(featureId).Return(nil, errors.New("some error"))
+
+		err := History(db)(ctx)
+		assert.ErrorContains(t, err, "some error")
+		assert.Equal(t, http.StatusInternalServerError, getStatusCode(rec, err))
+	})
+
+	t.Run("when the request is healthy, we should get the feature's events", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := historymocks.NewMockHistoryDatabase(ctrl)
+
+		featureId := uuid.New()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetParamNames("featureId")
+		ctx.SetParamValues(featureId.String())
+
+		db.EXPECT().GetHistory(featureId).Return([]*domain.AuditEvent{{
+			Id:        uuid.New(),
+			FeatureId: featureId,
+			Actor:     "someone",
+			Action:    domain.AuditUpdated,
+			Changes:   []domain.FieldChange{{Field: "name", Before: "old", After: "new"}},
+		}}, nil)
+
+		err := History(db)(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, getStatusCode(rec, err))
+
+		events := []*domain.AuditEvent{}
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &events))
+		assert.Equal(t, "someone", events[0].Actor)
+		assert.Equal(t, "new", events[0].Changes[0].After)
+	})
+}
+
+func getStatusCode(rec *httptest.ResponseRecorder, err error) int {
+	if err == nil {
+		return rec.Code
+	}
+
+	hterr := &echo.HTTPError{}
+	if errors.As(err, &hterr) {
+		return hterr.Code
+	}
+
+	return 500
+}
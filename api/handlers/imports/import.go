@@ -0,0 +1,143 @@
+package imports
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/music-tribe/react-pairing-challenge/importer"
+)
+
+// MaxImportBytes bounds the size of an uploaded file.
+const MaxImportBytes = 32 << 20
+
+//go:generate mockgen -destination=./mocks/import.go -package=importsmocks -source=import.go
+type ImportDatabase interface {
+	importer.Database
+}
+
+type ImportRequest struct {
+	Format string `query:"format" validate:"omitempty,oneof=csv json jsonl ndjson"`
+	// Map renames columns, as field=column.
+	Map    []string `query:"map" validate:"max=8"`
+	UserId string   `query:"userId" validate:"omitempty,uuid"`
+	DryRun bool     `query:"dryRun"`
+}
+
+// Import godoc
+// @Summary Import features in bulk.
+// @Description Add features from a CSV or JSON Lines file, sent as the request body or as the "file" field of a form. Rows are validated like new features; bad rows are reported and skipped, and features that already exist are skipped as duplicates. Rows without an id get one derived from their owner, board and name, so importing a file twice is safe. Requires the admin API key.
+// @Accept text/csv
+// @Accept application/x-ndjson
+// @Accept multipart/form-data
+// @Produce application/json
+// @Security AdminKey
+// @Param format query string false "csv, json, jsonl or ndjson; taken from the content type when absent"
+// @Param map query []string false "Read a field from a differently named column, as field=column" collectionFormat(multi)
+// @Param userId query string false "Owner of the rows that don't name one"
+// @Param dryRun query bool false "Check the file without importing anything"
+// @Router /api/admin/import [post]
+// @Success 200 {object} importer.Report
+// @failure 400 {object} error
+// @failure 401 {object} error
+// @failure 413 {object} error
+// @failure 500 {object} error
+func Import(db ImportDatabase) func(echo.Context) error {
+	if db == nil {
+		panic("imports.Import: db has nil value")
+	}
+
+	return func(c echo.Context) error {
+		req := ImportRequest{}
+
+		if err := (&echo.DefaultBinder{}).BindQueryParams(c, &req); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err)
+		}
+
+		if err := validator.New().Struct(&req); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err)
+		}
+
+		mapping, err := importer.ParseMapping(req.Map)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err)
+		}
+
+		c.Request().Body = http.MaxBytesReader(c.Response(), c.Request().Body, MaxImportBytes)
+
+		body, contentType, err := file(c)
+		if err != nil {
+			return err
+		}
+		defer body.Close()
+
+		format := req.Format
+		if format == "" {
+			format = formatOf(contentType)
+		}
+		f, err := importer.ParseFormat(format)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err)
+		}
+
+		report, err := importer.Import(db, body, importer.Options{
+			Format:  f,
+			Mapping: mapping,
+			UserId:  req.UserId,
+			Actor:   "admin",
+			DryRun:  req.DryRun,
+		})
+		if err != nil {
+			if report != nil && report.Imported > 0 && !req.DryRun {
+				err = fmt.Errorf("%w (%d features were imported before it stopped)", err, report.Imported)
+			}
+
+			maxErr := &http.MaxBytesError{}
+			switch {
+			case errors.As(err, &maxErr):
+				return echo.NewHTTPError(http.StatusRequestEntityTooLarge, err)
+			case errors.Is(err, importer.ErrBadFile):
+				return echo.NewHTTPError(http.StatusBadRequest, err)
+			}
+			return echo.NewHTTPError(http.StatusInternalServerError, err)
+		}
+
+		return c.JSON(http.StatusOK, report)
+	}
+}
+
+// file returns the uploaded file and its content type, from a form or the
+// body itself.
+func file(c echo.Context) (io.ReadCloser, string, error) {
+	contentType := c.Request().Header.Get(echo.HeaderContentType)
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	if mediaType != echo.MIMEMultipartForm {
+		return c.Request().Body, mediaType, nil
+	}
+
+	fh, err := c.FormFile("file")
+	if err != nil {
+		return nil, "", echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+	f, err := fh.Open()
+	if err != nil {
+		return nil, "", echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+	mediaType, _, _ = mime.ParseMediaType(fh.Header.Get(echo.HeaderContentType))
+	return f, mediaType, nil
+}
+
+func formatOf(mediaType string) string {
+	switch mediaType {
+	case "text/csv", "application/csv":
+		return "csv"
+	case echo.MIMEApplicationJSON, "application/x-ndjson", "application/jsonl", "application/x-jsonlines":
+		return "json"
+	}
+	return mediaType
+}
@@ -0,0 +1,152 @@
+package imports
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	importsmocks "github.com/music-tribe/react-pairing-challenge/handlers/imports/mocks"
+	"github.com/music-tribe/react-pairing-challenge/importer"
+	"github.com/music-tribe/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+const owner = "202c25c4-b2ce-4514-9045-890a1aa896ea"
+
+func TestImport(t *testing.T) {
+	e := echo.New()
+	csv := "Title,description\nDark mode,please\n"
+
+	t.Run("when the db has a nil value, we should panic", func(t *testing.T) {
+		assert.Panics(t, func() {
+			Import(nil)
+		})
+	})
+
+	t.Run("when a CSV body is posted, we should import it and return the report", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := importsmocks.NewMockImportDatabase(ctrl)
+
+		req := httptest.NewRequest(http.MethodPost, "/?map=name=Title&userId="+owner, strings.NewReader(csv))
+		req.Header.Set(echo.HeaderContentType, "text/csv; charset=utf-8")
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+
+		db.EXPECT().ImportFeatures("admin", gomock.Any()).DoAndReturn(func(_ string, fs []*domain.Feature) ([]uuid.UUID, error) {
+			assert.Equal(t, "Dark mode", fs[0].Name)
+			return nil, nil
+		})
+
+		err := Import(db)(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		report := importer.Report{}
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+		assert.Equal(t, importer.Report{Rows: 1, Imported: 1, Errors: []importer.RowError{}}, report)
+	})
+
+	t.Run("when a file is uploaded in a form with a dry run, nothing should be written", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := importsmocks.NewMockImportDatabase(ctrl)
+
+		body := &bytes.Buffer{}
+		form := multipart.NewWriter(body)
+		part, _ := form.CreateFormFile("file", "features.jsonl")
+		part.Write([]byte(`{"userId":"` + owner + `","name":"a","description":"b"}`))
+		form.Close()
+
+		req := httptest.NewRequest(http.MethodPost, "/?format=jsonl&dryRun=true", body)
+		req.Header.Set(echo.HeaderContentType, form.FormDataContentType())
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+
+		db.EXPECT().ExistingFeatureIds(gomock.Len(1)).Return(nil, nil)
+
+		err := Import(db)(ctx)
+		assert.NoError(t, err)
+		assert.Contains(t, rec.Body.String(), `"dryRun":true`)
+	})
+
+	t.Run("when the format can't be worked out, we should return a 400 error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := importsmocks.NewMockImportDatabase(ctrl)
+
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(csv))
+		req.Header.Set(echo.HeaderContentType, echo.MIMETextPlain)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+
+		err := Import(db)(ctx)
+		assert.ErrorContains(t, err, "unsupported format")
+		assert.Equal(t, http.StatusBadRequest, getStatusCode(rec, err))
+	})
+
+	t.Run("when the mapping names an unknown field, we should return a 400 error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := importsmocks.NewMockImportDatabase(ctrl)
+
+		req := httptest.NewRequest(http.MethodPost, "/?format=csv&map=title=Title", strings.NewReader(csv))
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+
+		err := Import(db)(ctx)
+		assert.ErrorContains(t, err, `unknown field "title"`)
+		assert.Equal(t, http.StatusBadRequest, getStatusCode(rec, err))
+	})
+
+	t.Run("when the file can't be read, we should return a 400 error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := importsmocks.NewMockImportDatabase(ctrl)
+
+		req := httptest.NewRequest(http.MethodPost, "/?format=csv", strings.NewReader(""))
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+
+		err := Import(db)(ctx)
+		assert.ErrorContains(t, err, "the file is empty")
+		assert.Equal(t, http.StatusBadRequest, getStatusCode(rec, err))
+	})
+
+	t.Run("when the database fails, we should return a 500 error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := importsmocks.NewMockImportDatabase(ctrl)
+
+		req := httptest.NewRequest(http.MethodPost, "/?format=csv&map=name=Title&userId="+owner, strings.NewReader(csv))
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+
+		db.EXPECT().ImportFeatures("admin", gomock.Any()).Return(nil, errors.New("db down"))
+
+		err := Import(db)(ctx)
+		assert.ErrorContains(t, err, "db down")
+		assert.Equal(t, http.StatusInternalServerError, getStatusCode(rec, err))
+	})
+}
+
+func getStatusCode(rec *httptest.ResponseRecorder, err error) int {
+	if err == nil {
+		return rec.Code
+	}
+
+	hterr := &echo.HTTPError{}
+	if errors.As(err, &hterr) {
+		return hterr.Code
+	}
+
+	return 500
+}
@@ -0,0 +1,68 @@
+package search
+
+import (
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/music-tribe/react-pairing-challenge/database"
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/react-pairing-challenge/ranking"
+)
+
+//go:generate mockgen -destination=./mocks/search.go -package=searchmocks -source=search.go
+type SearchDatabase interface {
+	Search(opts database.ListOptions) ([]*domain.Feature, error)
+}
+
+type SearchRequest struct {
+	Query string       `query:"q" validate:"max=256" example:"dark mode"`
+	Board string       `query:"board" validate:"max=64" example:"mixer"`
+	Tags  []string     `query:"tag" validate:"max=10,dive,max=64" example:"mixer"`
+	Match string       `query:"match" validate:"omitempty,oneof=any all" example:"any"`
+	Sort  ranking.Sort `query:"sort" validate:"omitempty,oneof=hot top new rising" example:"hot"`
+}
+
+// Search godoc
+// @Summary Search every users features.
+// @Description Search features across all users by text, board and tags.
+// @Produce application/json
+// @Param q query string false "Free text to match against names and descriptions"
+// @Param board query string false "Only features on this board"
+// @Param tag query []string false "Only features with these tags" collectionFormat(multi)
+// @Param match query string false "Match any or all of the tags, defaults to any" Enums(any, all)
+// @Param sort query string false "Sort order, defaults to new" Enums(hot, top, new, rising)
+// @Router /api/features [get]
+// @Success 200 {object} []domain.Feature
+// @failure 400 {object} error
+// @failure 500 {object} error
+func Search(db SearchDatabase) func(echo.Context) error {
+	if db == nil {
+		panic("search.Search: db has nil value")
+	}
+
+	return func(c echo.Context) error {
+		req := SearchRequest{}
+
+		if err := c.Bind(&req); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err)
+		}
+
+		if err := validator.New().Struct(&req); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err)
+		}
+
+		features, err := db.Search(database.ListOptions{
+			Sort:         req.Sort,
+			Board:        req.Board,
+			Tags:         domain.NormalizeTags(req.Tags),
+			MatchAllTags: req.Match == "all",
+			Text:         req.Query,
+		})
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err)
+		}
+
+		return c.JSON(http.StatusOK, features)
+	}
+}
@@ -0,0 +1,90 @@
+package search
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/music-tribe/react-pairing-challenge/database"
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	searchmocks "github.com/music-tribe/react-pairing-challenge/handlers/search/mocks"
+	"github.com/music-tribe/react-pairing-challenge/ranking"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearch(t *testing.T) {
+	e := echo.New()
+
+	t.Run("when the db has a nil value, we should panic", func(t *testing.T) {
+		assert.Panics(t, func() {
+			Search(nil)
+		})
+	})
+
+	t.Run("when the tag match mode is unknown we should return a 400 error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := searchmocks.NewMockSearchDatabase(ctrl)
+
+		req := httptest.NewRequest(http.MethodGet, "/?match=some", nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+
+		err := Search(db)(ctx)
+		assert.ErrorContains(t, err, "Error:Field validation for 'Match' failed on the 'oneof' tag")
+		assert.Equal(t, http.StatusBadRequest, getStatusCode(rec, err))
+	})
+
+	t.Run("when we get an unknown error from the db we should return a 500 error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := searchmocks.NewMockSearchDatabase(ctrl)
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+
+		db.EXPECT().Search(database.ListOptions{}).Return(nil, errors.New("some error"))
+
+		err := Search(db)(ctx)
+		assert.ErrorContains(t, err, "some error")
+		assert.Equal(t, http.StatusInternalServerError, getStatusCode(rec, err))
+	})
+
+	t.Run("when filters are given, they should be passed to the db", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := searchmocks.NewMockSearchDatabase(ctrl)
+
+		req := httptest.NewRequest(http.MethodGet, "/?q=dark%20mode&board=mixer&tag=Cloud&tag=mobile%20app&sort=top", nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+
+		db.EXPECT().Search(database.ListOptions{
+			Sort:  ranking.SortTop,
+			Board: "mixer",
+			Tags:  []string{"cloud", "mobile-app"},
+			Text:  "dark mode",
+		}).Return([]*domain.Feature{}, nil)
+
+		err := Search(db)(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, getStatusCode(rec, err))
+	})
+}
+
+func getStatusCode(rec *httptest.ResponseRecorder, err error) int {
+	if err == nil {
+		return rec.Code
+	}
+
+	hterr := &echo.HTTPError{}
+	if errors.As(err, &hterr) {
+		return hterr.Code
+	}
+
+	return 500
+}
@@ -0,0 +1,196 @@
+package socket
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/music-tribe/react-pairing-challenge/handlers/upvote"
+	"github.com/music-tribe/react-pairing-challenge/live"
+	"github.com/music-tribe/react-pairing-challenge/ratelimit"
+	"github.com/music-tribe/uuid"
+	"golang.org/x/net/websocket"
+)
+
+// conn is one client connection. Frames are read on the serving goroutine,
+// written by a single writer goroutine, and live changes are pumped in
+// from the hub by a third.
+type conn struct {
+	ws     *websocket.Conn
+	userId uuid.UUID
+	db     upvote.UpvoteDatabase
+	policy upvote.Policy
+	hub    Hub
+	// votes is checked against the upgrade request, which identifies the
+	// user and IP just as a REST vote does
+	votes   *ratelimit.Limiter
+	upgrade echo.Context
+
+	out  chan *ServerMessage
+	done chan struct{}
+	once sync.Once
+
+	mu       sync.Mutex
+	boards   map[string]bool
+	features map[uuid.UUID]bool
+}
+
+func newConn(ws *websocket.Conn, userId uuid.UUID, db upvote.UpvoteDatabase, policy upvote.Policy, hub Hub, votes *ratelimit.Limiter, upgrade echo.Context) *conn {
+	return &conn{
+		ws:       ws,
+		userId:   userId,
+		db:       db,
+		policy:   policy,
+		hub:      hub,
+		votes:    votes,
+		upgrade:  upgrade,
+		out:      make(chan *ServerMessage, DefaultQueue),
+		done:     make(chan struct{}),
+		boards:   map[string]bool{},
+		features: map[uuid.UUID]bool{},
+	}
+}
+
+func (c *conn) serve() {
+	defer c.close()
+
+	sub, _, _ := c.hub.Subscribe(live.Filter{}, "")
+	defer sub.Close()
+
+	go c.write()
+	go c.pump(sub)
+
+	c.send(&ServerMessage{Type: TypeWelcome, UserId: &c.userId})
+
+	for {
+		msg := ClientMessage{}
+		if err := websocket.JSON.Receive(c.ws, &msg); err != nil {
+			return
+		}
+
+		c.send(c.handle(&msg))
+	}
+}
+
+func (c *conn) handle(msg *ClientMessage) *ServerMessage {
+	if err := validator.New().Struct(msg); err != nil {
+		return replyError(msg.Id, echo.NewHTTPError(http.StatusBadRequest, err))
+	}
+
+	switch msg.Type {
+	case TypeSubscribe, TypeUnsubscribe:
+		c.mu.Lock()
+		for _, b := range msg.Boards {
+			c.boards[b] = msg.Type == TypeSubscribe
+		}
+		for _, id := range msg.FeatureIds {
+			c.features[id] = msg.Type == TypeSubscribe
+		}
+		c.mu.Unlock()
+		return &ServerMessage{Type: TypeAck, Id: msg.Id}
+
+	case TypeVote:
+		if d := c.votes.Allow(c.upgrade); !d.Allowed {
+			retry := int(math.Ceil(d.Result.RetryAfter.Seconds()))
+			return replyError(msg.Id, echo.NewHTTPError(http.StatusTooManyRequests, fmt.Errorf("rate limit exceeded, retry in %ds", retry)))
+		}
+
+		res, err := upvote.Cast(c.db, c.policy, upvote.UpvoteRequest{
+			UserId:    c.userId,
+			FeatureId: msg.FeatureId,
+			Direction: msg.Direction,
+			Weight:    msg.Weight,
+			Source:    "websocket",
+		})
+		if err != nil {
+			return replyError(msg.Id, err)
+		}
+		return &ServerMessage{Type: TypeVoted, Id: msg.Id, Vote: &res}
+
+	default:
+		return &ServerMessage{Type: TypePong, Id: msg.Id}
+	}
+}
+
+func replyError(id string, err error) *ServerMessage {
+	code, text := http.StatusInternalServerError, err.Error()
+	if hterr, ok := err.(*echo.HTTPError); ok {
+		code = hterr.Code
+		if inner, ok := hterr.Message.(error); ok {
+			text = inner.Error()
+		}
+	}
+
+	return &ServerMessage{Type: TypeError, Id: id, Code: code, Error: text}
+}
+
+// wants reports whether the client subscribed to m's board or feature.
+func (c *conn) wants(m *live.Message) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.boards[m.Board] || c.features[m.FeatureId]
+}
+
+// pump forwards the changes the client wants until the connection closes
+// or the hub drops the subscription for falling behind.
+func (c *conn) pump(sub *live.Subscription) {
+	for {
+		select {
+		case <-c.done:
+			return
+		case m, ok := <-sub.C:
+			if !ok {
+				c.lagged()
+				return
+			}
+			if c.wants(m) {
+				c.send(&ServerMessage{Type: TypeEvent, Event: m})
+			}
+		}
+	}
+}
+
+// send queues a frame without blocking. A client whose queue is full is
+// too slow to keep up and is disconnected.
+func (c *conn) send(m *ServerMessage) {
+	select {
+	case <-c.done:
+	case c.out <- m:
+	default:
+		c.lagged()
+	}
+}
+
+// lagged tells the client it fell behind, as best it can, and disconnects.
+func (c *conn) lagged() {
+	c.ws.SetWriteDeadline(time.Now().Add(time.Second))
+	websocket.JSON.Send(c.ws, &ServerMessage{Type: TypeLagged})
+	c.close()
+}
+
+func (c *conn) write() {
+	for {
+		select {
+		case <-c.done:
+			return
+		case m := <-c.out:
+			c.ws.SetWriteDeadline(time.Now().Add(DefaultWriteTimeout))
+			if err := websocket.JSON.Send(c.ws, m); err != nil {
+				c.close()
+				return
+			}
+		}
+	}
+}
+
+func (c *conn) close() {
+	c.once.Do(func() {
+		close(c.done)
+		c.ws.Close()
+	})
+}
@@ -0,0 +1,54 @@
+package socket
+
+import (
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/react-pairing-challenge/handlers/upvote"
+	"github.com/music-tribe/react-pairing-challenge/live"
+	"github.com/music-tribe/uuid"
+)
+
+// Message types sent by clients.
+const (
+	TypeSubscribe   = "subscribe"
+	TypeUnsubscribe = "unsubscribe"
+	TypeVote        = "vote"
+	TypePing        = "ping"
+)
+
+// Message types sent by the server.
+const (
+	TypeWelcome = "welcome"
+	TypeAck     = "ack"
+	TypeVoted   = "voted"
+	TypeEvent   = "event"
+	TypePong    = "pong"
+	TypeError   = "error"
+	// TypeLagged is sent just before the connection is closed for falling
+	// too far behind. Changes may have been missed, so the client should
+	// refetch the boards it watches when it reconnects.
+	TypeLagged = "lagged"
+)
+
+// ClientMessage is a JSON frame sent by a client. Id is echoed back on the
+// reply so clients can match replies to requests.
+type ClientMessage struct {
+	Type       string               `json:"type" validate:"required,oneof=subscribe unsubscribe vote ping" example:"subscribe"`
+	Id         string               `json:"id,omitempty" validate:"max=64" example:"1"`
+	Boards     []string             `json:"boards,omitempty" validate:"max=20,dive,required,max=64" example:"mixer"`
+	FeatureIds []uuid.UUID          `json:"featureIds,omitempty" validate:"max=100" example:"202c25c4-b2ce-4514-9045-890a1aa896ea"`
+	FeatureId  uuid.UUID            `json:"featureId,omitempty" example:"b1f01569-ecff-4c60-a716-435b2e51f1ff"`
+	Direction  domain.VoteDirection `json:"direction,omitempty" example:"up"`
+	Weight     int                  `json:"weight,omitempty" example:"1"`
+}
+
+// ServerMessage is a JSON frame sent to a client.
+type ServerMessage struct {
+	Type   string                 `json:"type" example:"event"`
+	Id     string                 `json:"id,omitempty" example:"1"`
+	UserId *uuid.UUID             `json:"userId,omitempty" example:"202c25c4-b2ce-4514-9045-890a1aa896ea"`
+	Event  *live.Message          `json:"event,omitempty"`
+	Vote   *upvote.UpvoteResponse `json:"vote,omitempty"`
+	// Code is the HTTP status the same failure would get over REST.
+	Code  int    `json:"code,omitempty" example:"409"`
+	Error string `json:"error,omitempty" example:"you've already voted for this feature request"`
+}
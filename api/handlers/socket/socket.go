@@ -0,0 +1,108 @@
+package socket
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/music-tribe/react-pairing-challenge/auth"
+	"github.com/music-tribe/react-pairing-challenge/handlers/upvote"
+	"github.com/music-tribe/react-pairing-challenge/live"
+	"github.com/music-tribe/react-pairing-challenge/ratelimit"
+	"github.com/music-tribe/uuid"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	// DefaultQueue is how many frames may wait to be written to a client
+	// before it is considered too slow and disconnected.
+	DefaultQueue = 64
+	// DefaultWriteTimeout bounds how long one frame may take to write.
+	DefaultWriteTimeout = 10 * time.Second
+	// MaxFrameBytes is the largest frame a client may send.
+	MaxFrameBytes = 4 << 10
+)
+
+type Hub interface {
+	Subscribe(filter live.Filter, lastId string) (*live.Subscription, []*live.Message, bool)
+}
+
+type SocketRequest struct {
+	UserId uuid.UUID `query:"userId" validate:"required" example:"202c25c4-b2ce-4514-9045-890a1aa896ea"`
+}
+
+// Socket opens a WebSocket for the user named by the userId query
+// parameter. The upgrade is refused unless users accepts the request's
+// token for that id, as the vote endpoints do, and every vote sent over the
+// connection is cast as that user, taking a token from votes as it would
+// over REST. allowOrigin is the API's CORS policy;
+// browsers on other origins are refused, and clients that send no Origin
+// are not browsers, so CORS doesn't bind them either.
+//
+// Socket godoc
+// @Summary Watch and vote on boards over a WebSocket.
+// @Description Upgrades to a WebSocket bound to the user id it was opened with; votes sent over it are always cast as that user.
+// @Description When USER_TOKEN_SECRET is set the user's token must be sent, as the token parameter or a bearer token, as it must for PUT /api/vote/{featureId}. Browsers may only connect from origins allowed by CORS_ALLOW_ORIGINS.
+// @Description Clients send JSON frames of type subscribe/unsubscribe (with boards and featureIds), vote (featureId, direction, weight) and ping.
+// @Description The server replies with ack, voted, pong or error frames carrying the request's id, and pushes an event frame for every change to a subscribed board or feature.
+// @Description Votes follow the same rules, and share the same rate limits, as PUT /api/vote/{featureId}; a vote over the limit gets an error frame with code 429. A client that falls behind is sent a lagged frame and disconnected.
+// @Param userId query string true "User ID the connection acts as"
+// @Param token query string false "Token for the user id, required when USER_TOKEN_SECRET is set"
+// @Router /api/ws [get]
+// @Success 101 {object} ServerMessage
+// @failure 400 {object} error
+// @failure 401 {object} error
+// @failure 403 {object} error
+func Socket(db upvote.UpvoteDatabase, policy upvote.Policy, hub Hub, allowOrigin func(origin string) bool, users *auth.UserTokens, votes *ratelimit.Limiter) func(echo.Context) error {
+	if db == nil {
+		panic("socket.Socket: db has nil value")
+	}
+	if hub == nil {
+		panic("socket.Socket: hub has nil value")
+	}
+	if allowOrigin == nil {
+		panic("socket.Socket: allowOrigin has nil value")
+	}
+	if users == nil {
+		panic("socket.Socket: users has nil value")
+	}
+	if votes == nil {
+		panic("socket.Socket: votes has nil value")
+	}
+
+	return func(c echo.Context) error {
+		req := SocketRequest{}
+
+		if err := c.Bind(&req); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err)
+		}
+
+		if err := validator.New().Struct(&req); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err)
+		}
+
+		if err := users.Authenticate(c, req.UserId); err != nil {
+			return err
+		}
+
+		// browsers always send an Origin, and don't apply CORS to
+		// WebSockets, so the handler has to
+		if origin := c.Request().Header.Get(echo.HeaderOrigin); origin != "" && !allowOrigin(origin) {
+			return echo.NewHTTPError(http.StatusForbidden, fmt.Errorf("origin %q may not open a socket", origin))
+		}
+
+		server := websocket.Server{
+			// the origin was checked above
+			Handshake: func(*websocket.Config, *http.Request) error { return nil },
+			Handler: func(ws *websocket.Conn) {
+				ws.MaxPayloadBytes = MaxFrameBytes
+				newConn(ws, req.UserId, db, policy, hub, votes, c).serve()
+			},
+		}
+		server.ServeHTTP(c.Response(), c.Request())
+
+		return nil
+	}
+}
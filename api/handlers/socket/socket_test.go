@@ -0,0 +1,322 @@
+package socket
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/music-tribe/react-pairing-challenge/auth"
+	"github.com/music-tribe/react-pairing-challenge/database"
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/react-pairing-challenge/handlers/upvote"
+	upvotemocks "github.com/music-tribe/react-pairing-challenge/handlers/upvote/mocks"
+	"github.com/music-tribe/react-pairing-challenge/live"
+	"github.com/music-tribe/react-pairing-challenge/ratelimit"
+	"github.com/music-tribe/uuid"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/websocket"
+)
+
+func TestSocket(t *testing.T) {
+	userId := uuid.New()
+
+	anyOrigin := func(string) bool { return true }
+	anyUser := auth.NewUserTokens("")
+	anyVotes := ratelimit.NewLimiter(ratelimit.Config{Group: "votes", Store: ratelimit.NewMemoryStore()})
+
+	// dial starts a server for the handler and connects an in-process
+	// client to it from the server's own origin, returning the client after
+	// reading its welcome frame
+	dial := func(t *testing.T, db upvote.UpvoteDatabase, hub *live.Hub, votes *ratelimit.Limiter) (*websocket.Conn, func()) {
+		e := echo.New()
+		var srv *httptest.Server
+		e.GET("/ws", Socket(db, upvote.DefaultPolicy(), hub, func(origin string) bool { return origin == srv.URL }, anyUser, votes))
+		srv = httptest.NewServer(e)
+
+		url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/ws?userId=" + userId.String()
+		ws, err := websocket.Dial(url, "", srv.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		welcome := receive(t, ws)
+		assert.Equal(t, TypeWelcome, welcome.Type)
+		assert.Equal(t, userId, *welcome.UserId)
+
+		return ws, func() {
+			ws.Close()
+			srv.Close()
+		}
+	}
+
+	t.Run("when the db, hub, origin check, users or vote limiter has a nil value, we should panic", func(t *testing.T) {
+		assert.Panics(t, func() {
+			Socket(nil, upvote.DefaultPolicy(), live.NewHub(), anyOrigin, anyUser, anyVotes)
+		})
+		assert.Panics(t, func() {
+			Socket(upvotemocks.NewMockUpvoteDatabase(gomock.NewController(t)), upvote.DefaultPolicy(), nil, anyOrigin, anyUser, anyVotes)
+		})
+		assert.Panics(t, func() {
+			Socket(upvotemocks.NewMockUpvoteDatabase(gomock.NewController(t)), upvote.DefaultPolicy(), live.NewHub(), nil, anyUser, anyVotes)
+		})
+		assert.Panics(t, func() {
+			Socket(upvotemocks.NewMockUpvoteDatabase(gomock.NewController(t)), upvote.DefaultPolicy(), live.NewHub(), anyOrigin, nil, anyVotes)
+		})
+		assert.Panics(t, func() {
+			Socket(upvotemocks.NewMockUpvoteDatabase(gomock.NewController(t)), upvote.DefaultPolicy(), live.NewHub(), anyOrigin, anyUser, nil)
+		})
+	})
+
+	t.Run("when user tokens are required and the upgrade has none for its user, we should return a 401 error", func(t *testing.T) {
+		users := auth.NewUserTokens("s3cret")
+		for _, token := range []string{"", users.Token(uuid.New())} {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/?userId="+userId.String()+"&token="+token, nil)
+			rec := httptest.NewRecorder()
+			ctx := e.NewContext(req, rec)
+
+			err := Socket(upvotemocks.NewMockUpvoteDatabase(gomock.NewController(t)), upvote.DefaultPolicy(), live.NewHub(), anyOrigin, users, anyVotes)(ctx)
+			assert.Equal(t, http.StatusUnauthorized, getStatusCode(rec, err))
+		}
+	})
+
+	t.Run("when user tokens are required and the upgrade has its user's, it should connect", func(t *testing.T) {
+		users := auth.NewUserTokens("s3cret")
+		e := echo.New()
+		e.GET("/ws", Socket(upvotemocks.NewMockUpvoteDatabase(gomock.NewController(t)), upvote.DefaultPolicy(), live.NewHub(), anyOrigin, users, anyVotes))
+		srv := httptest.NewServer(e)
+		defer srv.Close()
+
+		url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/ws?userId=" + userId.String() + "&token=" + users.Token(userId)
+		ws, err := websocket.Dial(url, "", srv.URL)
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer ws.Close()
+		assert.Equal(t, TypeWelcome, receive(t, ws).Type)
+	})
+
+	t.Run("when a browser connects from an origin CORS doesn't allow, we should return a 403 error", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/?userId="+userId.String(), nil)
+		req.Header.Set(echo.HeaderOrigin, "https://evil.example.net")
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+
+		allowOrigin := func(origin string) bool { return origin == "https://features.example.com" }
+		err := Socket(upvotemocks.NewMockUpvoteDatabase(gomock.NewController(t)), upvote.DefaultPolicy(), live.NewHub(), allowOrigin, anyUser, anyVotes)(ctx)
+		assert.ErrorContains(t, err, "https://evil.example.net")
+		assert.Equal(t, http.StatusForbidden, getStatusCode(rec, err))
+	})
+
+	t.Run("when the userId is missing we should return a 400 error", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+
+		err := Socket(upvotemocks.NewMockUpvoteDatabase(gomock.NewController(t)), upvote.DefaultPolicy(), live.NewHub(), anyOrigin, anyUser, anyVotes)(ctx)
+		assert.ErrorContains(t, err, "Error:Field validation for 'UserId' failed on the 'required' tag")
+		assert.Equal(t, http.StatusBadRequest, getStatusCode(rec, err))
+	})
+
+	t.Run("when a client subscribes to a board, it should only be pushed changes on that board", func(t *testing.T) {
+		hub := live.NewHub()
+		ws, stop := dial(t, upvotemocks.NewMockUpvoteDatabase(gomock.NewController(t)), hub, anyVotes)
+		defer stop()
+
+		send(t, ws, ClientMessage{Type: TypeSubscribe, Id: "1", Boards: []string{"mixer"}})
+		assert.Equal(t, ServerMessage{Type: TypeAck, Id: "1"}, *receive(t, ws))
+
+		hub.Publish(&live.Message{Id: uuid.New(), Type: domain.VoteCast, Board: "other"})
+		m := &live.Message{Id: uuid.New(), Type: domain.VoteCast, Board: "mixer", VoteCount: 5}
+		hub.Publish(m)
+
+		got := receive(t, ws)
+		assert.Equal(t, TypeEvent, got.Type)
+		assert.Equal(t, m.Id, got.Event.Id)
+		assert.Equal(t, int64(5), got.Event.VoteCount)
+	})
+
+	t.Run("when a client unsubscribes, it should no longer be pushed changes", func(t *testing.T) {
+		hub := live.NewHub()
+		ws, stop := dial(t, upvotemocks.NewMockUpvoteDatabase(gomock.NewController(t)), hub, anyVotes)
+		defer stop()
+
+		featureId := uuid.New()
+		send(t, ws, ClientMessage{Type: TypeSubscribe, Id: "1", FeatureIds: []uuid.UUID{featureId}})
+		receive(t, ws)
+		send(t, ws, ClientMessage{Type: TypeUnsubscribe, Id: "2", FeatureIds: []uuid.UUID{featureId}})
+		receive(t, ws)
+
+		hub.Publish(&live.Message{Id: uuid.New(), Type: domain.VoteCast, FeatureId: featureId})
+		send(t, ws, ClientMessage{Type: TypePing, Id: "3"})
+		assert.Equal(t, ServerMessage{Type: TypePong, Id: "3"}, *receive(t, ws))
+	})
+
+	t.Run("when a client votes, it should be cast as the connection's user", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := upvotemocks.NewMockUpvoteDatabase(ctrl)
+
+		ws, stop := dial(t, db, live.NewHub(), anyVotes)
+		defer stop()
+
+		feature := &domain.Feature{Id: uuid.New(), UserId: uuid.New(), Board: "mixer"}
+		db.EXPECT().GetById(feature.Id).Return(feature, nil)
+		db.EXPECT().GetVote(feature.Id, userId).Return(nil, database.ErrNotFound)
+		db.EXPECT().AddVote(gomock.Any(), gomock.Any()).DoAndReturn(func(v *domain.Vote, _ domain.VoteBudget) (*domain.Feature, error) {
+			assert.Equal(t, userId, v.VoterId)
+			assert.Equal(t, "websocket", v.Source)
+			return &domain.Feature{Id: feature.Id, VoteCount: 1, Upvotes: 1, Score: 1}, nil
+		})
+
+		send(t, ws, ClientMessage{Type: TypeVote, Id: "v1", FeatureId: feature.Id})
+		got := receive(t, ws)
+		assert.Equal(t, TypeVoted, got.Type)
+		assert.Equal(t, "v1", got.Id)
+		assert.Equal(t, int64(1), got.Vote.VoteCount)
+	})
+
+	t.Run("when a vote breaks the voting rules, the client should get the REST error code", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := upvotemocks.NewMockUpvoteDatabase(ctrl)
+
+		ws, stop := dial(t, db, live.NewHub(), anyVotes)
+		defer stop()
+
+		feature := &domain.Feature{Id: uuid.New(), UserId: userId}
+		db.EXPECT().GetById(feature.Id).Return(feature, nil)
+
+		send(t, ws, ClientMessage{Type: TypeVote, Id: "v2", FeatureId: feature.Id})
+		got := receive(t, ws)
+		assert.Equal(t, TypeError, got.Type)
+		assert.Equal(t, "v2", got.Id)
+		assert.Equal(t, http.StatusBadRequest, got.Code)
+		assert.Contains(t, got.Error, "your own feature request")
+	})
+
+	t.Run("when votes are rate limited, socket votes should drain the same bucket as REST votes", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := upvotemocks.NewMockUpvoteDatabase(ctrl)
+
+		cfg := ratelimit.Config{
+			Group: "votes",
+			Store: ratelimit.NewMemoryStore(),
+			Rules: []ratelimit.Rule{{
+				Identity: ratelimit.FirstOf(ratelimit.BodyField("userId"), ratelimit.Query("userId")),
+				Limit:    ratelimit.Limit{Requests: 2, Period: time.Hour},
+			}},
+		}
+		e := echo.New()
+		e.PUT("/vote", func(c echo.Context) error { return c.NoContent(http.StatusOK) }, ratelimit.New(cfg))
+		restVote := func() int {
+			req := httptest.NewRequest(http.MethodPut, "/vote", strings.NewReader(`{"userId":"`+userId.String()+`"}`))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+			return rec.Code
+		}
+
+		ws, stop := dial(t, db, live.NewHub(), ratelimit.NewLimiter(cfg))
+		defer stop()
+
+		assert.Equal(t, http.StatusOK, restVote())
+
+		feature := &domain.Feature{Id: uuid.New(), UserId: uuid.New(), Board: "mixer"}
+		db.EXPECT().GetById(feature.Id).Return(feature, nil)
+		db.EXPECT().GetVote(feature.Id, userId).Return(nil, database.ErrNotFound)
+		db.EXPECT().AddVote(gomock.Any(), gomock.Any()).Return(&domain.Feature{Id: feature.Id, VoteCount: 1, Upvotes: 1, Score: 1}, nil)
+
+		send(t, ws, ClientMessage{Type: TypeVote, Id: "v1", FeatureId: feature.Id})
+		assert.Equal(t, TypeVoted, receive(t, ws).Type)
+
+		send(t, ws, ClientMessage{Type: TypeVote, Id: "v2", FeatureId: feature.Id})
+		got := receive(t, ws)
+		assert.Equal(t, TypeError, got.Type)
+		assert.Equal(t, "v2", got.Id)
+		assert.Equal(t, http.StatusTooManyRequests, got.Code)
+		assert.Contains(t, got.Error, "rate limit exceeded")
+
+		assert.Equal(t, http.StatusTooManyRequests, restVote())
+	})
+
+	t.Run("when a frame has an unknown type, the client should get a 400 error", func(t *testing.T) {
+		ws, stop := dial(t, upvotemocks.NewMockUpvoteDatabase(gomock.NewController(t)), live.NewHub(), anyVotes)
+		defer stop()
+
+		send(t, ws, ClientMessage{Type: "shout", Id: "x"})
+		got := receive(t, ws)
+		assert.Equal(t, TypeError, got.Type)
+		assert.Equal(t, http.StatusBadRequest, got.Code)
+	})
+
+	t.Run("when a client stops reading, it should be told it lagged and be disconnected", func(t *testing.T) {
+		hub := live.NewHub()
+		ws, stop := dial(t, upvotemocks.NewMockUpvoteDatabase(gomock.NewController(t)), hub, anyVotes)
+		defer stop()
+
+		send(t, ws, ClientMessage{Type: TypeSubscribe, Id: "1", Boards: []string{"mixer"}})
+		receive(t, ws)
+
+		// far more than the hub and connection will hold for a client that
+		// isn't reading
+		for i := 0; i < 5000; i++ {
+			hub.Publish(&live.Message{Id: uuid.New(), Type: domain.VoteCast, Board: "mixer"})
+		}
+
+		lagged := false
+		for {
+			m := ServerMessage{}
+			ws.SetReadDeadline(time.Now().Add(2 * time.Second))
+			if err := websocket.JSON.Receive(ws, &m); err != nil {
+				break
+			}
+			lagged = lagged || m.Type == TypeLagged
+		}
+		assert.True(t, lagged)
+
+		deadline := time.Now().Add(2 * time.Second)
+		for hub.Subscribers() != 0 && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		assert.Zero(t, hub.Subscribers())
+	})
+}
+
+func send(t *testing.T, ws *websocket.Conn, m ClientMessage) {
+	t.Helper()
+	if err := websocket.JSON.Send(ws, m); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func receive(t *testing.T, ws *websocket.Conn) *ServerMessage {
+	t.Helper()
+	m := &ServerMessage{}
+	ws.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if err := websocket.JSON.Receive(ws, m); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func getStatusCode(rec *httptest.ResponseRecorder, err error) int {
+	if err == nil {
+		return rec.Code
+	}
+
+	hterr := &echo.HTTPError{}
+	if errors.As(err, &hterr) {
+		return hterr.Code
+	}
+
+	return 500
+}
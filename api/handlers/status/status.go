@@ -0,0 +1,67 @@
+package status
+
+import (
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/music-tribe/react-pairing-challenge/database"
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/uuid"
+)
+
+// AdminActor is recorded in the audit log for changes made through the
+// admin API.
+const AdminActor = "admin"
+
+//go:generate mockgen -destination=./mocks/status.go -package=statusmocks -source=status.go
+type StatusDatabase interface {
+	SetStatus(featureId uuid.UUID, status domain.FeatureStatus, actor string) (*domain.Feature, error)
+}
+
+type StatusRequest struct {
+	FeatureId uuid.UUID            `json:"-" param:"featureId" validate:"required"`
+	Status    domain.FeatureStatus `json:"status" validate:"required,oneof=open planned in-progress done declined" example:"planned"`
+}
+
+// SetStatus godoc
+// @Summary Change the status of a feature.
+// @Description Move a feature along the product process. Requires the admin API key.
+// @Accept application/json
+// @Produce application/json
+// @Security AdminKey
+// @Param featureId path string true "Feature UUID"
+// @Param status body StatusRequest true "Status"
+// @Router /api/admin/features/{featureId}/status [put]
+// @Success 200 {object} domain.Feature
+// @failure 400 {object} error
+// @failure 401 {object} error
+// @failure 404 {object} error
+// @failure 500 {object} error
+func SetStatus(db StatusDatabase) func(echo.Context) error {
+	if db == nil {
+		panic("status.SetStatus: db has nil value")
+	}
+
+	return func(c echo.Context) error {
+		req := StatusRequest{}
+
+		if err := c.Bind(&req); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err)
+		}
+
+		if err := validator.New().Struct(&req); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err)
+		}
+
+		feature, err := db.SetStatus(req.FeatureId, req.Status, AdminActor)
+		if err != nil {
+			if err == database.ErrNotFound {
+				return echo.NewHTTPError(http.StatusNotFound, err)
+			}
+			return echo.NewHTTPError(http.StatusInternalServerError, err)
+		}
+
+		return c.JSON(http.StatusOK, feature)
+	}
+}
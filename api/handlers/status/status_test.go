@@ -0,0 +1,100 @@
+package status
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/music-tribe/react-pairing-challenge/database"
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	statusmocks "github.com/music-tribe/react-pairing-challenge/handlers/status/mocks"
+	"github.com/music-tribe/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetStatus(t *testing.T) {
+	e := echo.New()
+
+	t.Run("when the db has a nil value, we should panic", func(t *testing.T) {
+		assert.Panics(t, func() {
+			SetStatus(nil)
+		})
+	})
+
+	t.Run("when the status is unknown we should return a 400 error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := statusmocks.NewMockStatusDatabase(ctrl)
+
+		byt := []byte(`{"status":"shipped"}`)
+		req := httptest.NewRequest(http.MethodPut, "/", bytes.NewReader(byt))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetParamNames("featureId")
+		ctx.SetParamValues(uuid.New().String())
+
+		err := SetStatus(db)(ctx)
+		assert.ErrorContains(t, err, "Error:Field validation for 'Status' failed on the 'oneof' tag")
+		assert.Equal(t, http.StatusBadRequest, getStatusCode(rec, err))
+	})
+
+	t.Run("when the feature can't be found we should return a 404 error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := statusmocks.NewMockStatusDatabase(ctrl)
+
+		featureId := uuid.New()
+		byt := []byte(`{"status":"planned"}`)
+		req := httptest.NewRequest(http.MethodPut, "/", bytes.NewReader(byt))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetParamNames("featureId")
+		ctx.SetParamValues(featureId.String())
+
+		db.EXPECT().SetStatus(featureId, domain.StatusPlanned, AdminActor).Return(nil, database.ErrNotFound)
+
+		err := SetStatus(db)(ctx)
+		assert.ErrorContains(t, err, database.ErrNotFound.Error())
+		assert.Equal(t, http.StatusNotFound, getStatusCode(rec, err))
+	})
+
+	t.Run("when the request is healthy, we should return the updated feature", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := statusmocks.NewMockStatusDatabase(ctrl)
+
+		featureId := uuid.New()
+		byt := []byte(`{"status":"in-progress"}`)
+		req := httptest.NewRequest(http.MethodPut, "/", bytes.NewReader(byt))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetParamNames("featureId")
+		ctx.SetParamValues(featureId.String())
+
+		db.EXPECT().SetStatus(featureId, domain.StatusInProgress, AdminActor).Return(&domain.Feature{Id: featureId, Status: domain.StatusInProgress}, nil)
+
+		err := SetStatus(db)(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, getStatusCode(rec, err))
+	})
+}
+
+func getStatusCode(rec *httptest.ResponseRecorder, err error) int {
+	if err == nil {
+		return rec.Code
+	}
+
+	hterr := &echo.HTTPError{}
+	if errors.As(err, &hterr) {
+		return hterr.Code
+	}
+
+	return 500
+}
@@ -0,0 +1,134 @@
+package stream
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/music-tribe/react-pairing-challenge/live"
+	"github.com/music-tribe/uuid"
+)
+
+// DefaultHeartbeat is how often a heartbeat event is sent so proxies and
+// clients can tell an idle stream from a dead one.
+const DefaultHeartbeat = 15 * time.Second
+
+// retryMs is how long clients are asked to wait before reconnecting.
+const retryMs = 3000
+
+type Hub interface {
+	Subscribe(filter live.Filter, lastId string) (*live.Subscription, []*live.Message, bool)
+}
+
+type StreamRequest struct {
+	FeatureIds []string `query:"featureId" validate:"max=50,dive,uuid" example:"202c25c4-b2ce-4514-9045-890a1aa896ea"`
+	Board      string   `query:"board" validate:"max=64" example:"mixer"`
+	// LastEventId is the Last-Event-ID header; the query parameter is for
+	// clients that can't set headers on their first connection.
+	LastEventId string `query:"lastEventId" validate:"max=64" example:"7c9e6679-7425-40de-944b-e07fc1f90ae7"`
+}
+
+// Stream godoc
+// @Summary Stream live changes to features.
+// @Description Server-Sent Events stream of new, edited, deleted and restored features, status changes and vote counts. Each event's data is a live.Message and its id can be sent back as Last-Event-ID to resume. A reset event means the missed changes are gone and the client should refetch. A heartbeat event is sent when the stream is otherwise idle.
+// @Produce text/event-stream
+// @Param featureId query []string false "Only changes to these features" collectionFormat(multi)
+// @Param board query string false "Only changes on this board"
+// @Param Last-Event-ID header string false "Resume after this event"
+// @Param lastEventId query string false "Resume after this event, for clients that can't set headers"
+// @Router /api/features/stream [get]
+// @Success 200 {object} live.Message
+// @failure 400 {object} error
+func Stream(hub Hub, heartbeat time.Duration) func(echo.Context) error {
+	if hub == nil {
+		panic("stream.Stream: hub has nil value")
+	}
+	if heartbeat <= 0 {
+		heartbeat = DefaultHeartbeat
+	}
+
+	return func(c echo.Context) error {
+		req := StreamRequest{}
+
+		if err := c.Bind(&req); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err)
+		}
+
+		if err := validator.New().Struct(&req); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err)
+		}
+
+		filter := live.Filter{Board: req.Board}
+		for _, id := range req.FeatureIds {
+			featureId, err := uuid.Parse(id)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, err)
+			}
+			filter.FeatureIds = append(filter.FeatureIds, featureId)
+		}
+
+		lastId := c.Request().Header.Get("Last-Event-ID")
+		if lastId == "" {
+			lastId = req.LastEventId
+		}
+
+		sub, backlog, resumed := hub.Subscribe(filter, lastId)
+		defer sub.Close()
+
+		res := c.Response()
+		res.Header().Set(echo.HeaderContentType, "text/event-stream")
+		res.Header().Set(echo.HeaderCacheControl, "no-cache")
+		res.Header().Set(echo.HeaderConnection, "keep-alive")
+		// stop nginx buffering the stream
+		res.Header().Set("X-Accel-Buffering", "no")
+		res.WriteHeader(http.StatusOK)
+
+		fmt.Fprintf(res, "retry: %d\n\n", retryMs)
+		if !resumed {
+			fmt.Fprint(res, "event: reset\ndata: {}\n\n")
+		}
+		for _, m := range backlog {
+			if err := writeMessage(res, m); err != nil {
+				return nil
+			}
+		}
+		res.Flush()
+
+		ticker := time.NewTicker(heartbeat)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-c.Request().Context().Done():
+				return nil
+			case m, ok := <-sub.C:
+				if !ok {
+					// dropped for falling behind; the client reconnects
+					// with Last-Event-ID and picks up where it left off
+					return nil
+				}
+				if err := writeMessage(res, m); err != nil {
+					return nil
+				}
+			case t := <-ticker.C:
+				if _, err := fmt.Fprintf(res, "event: heartbeat\ndata: {\"time\":%q}\n\n", t.UTC().Format(time.RFC3339)); err != nil {
+					return nil
+				}
+			}
+			res.Flush()
+		}
+	}
+}
+
+func writeMessage(res *echo.Response, m *live.Message) error {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(res, "id: %s\nevent: %s\ndata: %s\n\n", m.Id, m.Type, data)
+	return err
+}
@@ -0,0 +1,174 @@
+package stream
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/react-pairing-challenge/live"
+	"github.com/music-tribe/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStream(t *testing.T) {
+	// sseEvent is one event read off the stream, as field name to value
+	type sseEvent map[string]string
+
+	connect := func(t *testing.T, hub *live.Hub, heartbeat time.Duration, query string, header http.Header) (<-chan sseEvent, func()) {
+		e := echo.New()
+		e.GET("/stream", Stream(hub, heartbeat))
+		srv := httptest.NewServer(e)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/stream?"+query, nil)
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		res, err := http.DefaultClient.Do(req)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "text/event-stream", res.Header.Get(echo.HeaderContentType))
+
+		events := make(chan sseEvent, 16)
+		go func() {
+			defer close(events)
+			scanner := bufio.NewScanner(res.Body)
+			ev := sseEvent{}
+			for scanner.Scan() {
+				line := scanner.Text()
+				if line == "" {
+					events <- ev
+					ev = sseEvent{}
+					continue
+				}
+				if field, value, ok := strings.Cut(line, ": "); ok {
+					ev[field] = value
+				}
+			}
+		}()
+
+		return events, func() {
+			cancel()
+			res.Body.Close()
+			srv.Close()
+		}
+	}
+
+	next := func(t *testing.T, events <-chan sseEvent) sseEvent {
+		select {
+		case ev := <-events:
+			return ev
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for an event")
+			return nil
+		}
+	}
+
+	waitForSubscriber := func(hub *live.Hub) {
+		for hub.Subscribers() == 0 {
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	t.Run("when the hub has a nil value, we should panic", func(t *testing.T) {
+		assert.Panics(t, func() {
+			Stream(nil, 0)
+		})
+	})
+
+	t.Run("when a feature id is not a uuid we should return a 400 error", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/?featureId=nope", nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+
+		err := Stream(live.NewHub(), 0)(ctx)
+		assert.ErrorContains(t, err, "Error:Field validation for 'FeatureIds[0]' failed on the 'uuid' tag")
+		assert.Equal(t, http.StatusBadRequest, getStatusCode(rec, err))
+	})
+
+	t.Run("when a matching change is published, it should be pushed to the client", func(t *testing.T) {
+		hub := live.NewHub()
+		featureId := uuid.New()
+		events, stop := connect(t, hub, time.Hour, "featureId="+featureId.String(), nil)
+		defer stop()
+
+		assert.Equal(t, "3000", next(t, events)["retry"])
+		waitForSubscriber(hub)
+
+		hub.Publish(&live.Message{Id: uuid.New(), Type: domain.VoteCast, FeatureId: uuid.New()})
+		m := &live.Message{Id: uuid.New(), Type: domain.VoteCast, FeatureId: featureId, VoteCount: 7}
+		hub.Publish(m)
+
+		ev := next(t, events)
+		assert.Equal(t, m.Id.String(), ev["id"])
+		assert.Equal(t, string(domain.VoteCast), ev["event"])
+		assert.Contains(t, ev["data"], `"voteCount":7`)
+	})
+
+	t.Run("when the stream is idle, heartbeats should be sent", func(t *testing.T) {
+		hub := live.NewHub()
+		events, stop := connect(t, hub, 10*time.Millisecond, "", nil)
+		defer stop()
+
+		next(t, events)
+		assert.Equal(t, "heartbeat", next(t, events)["event"])
+	})
+
+	t.Run("when the client resumes with Last-Event-ID, it should get what it missed first", func(t *testing.T) {
+		hub := live.NewHub()
+		seen := &live.Message{Id: uuid.New(), Type: domain.VoteCast, FeatureId: uuid.New(), Board: "mixer"}
+		missed := &live.Message{Id: uuid.New(), Type: domain.VoteRemoved, FeatureId: uuid.New(), Board: "mixer"}
+		hub.Publish(seen)
+		hub.Publish(missed)
+
+		events, stop := connect(t, hub, time.Hour, "board=mixer", http.Header{"Last-Event-Id": {seen.Id.String()}})
+		defer stop()
+
+		next(t, events)
+		assert.Equal(t, missed.Id.String(), next(t, events)["id"])
+	})
+
+	t.Run("when the client resumes after an event that has been forgotten, it should be told to reset", func(t *testing.T) {
+		hub := live.NewHub()
+		events, stop := connect(t, hub, time.Hour, "lastEventId="+uuid.New().String(), nil)
+		defer stop()
+
+		next(t, events)
+		assert.Equal(t, "reset", next(t, events)["event"])
+	})
+
+	t.Run("when the client disconnects, its subscription should be closed", func(t *testing.T) {
+		hub := live.NewHub()
+		events, stop := connect(t, hub, time.Hour, "", nil)
+		next(t, events)
+		waitForSubscriber(hub)
+
+		stop()
+		deadline := time.Now().Add(2 * time.Second)
+		for hub.Subscribers() != 0 && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		assert.Zero(t, hub.Subscribers())
+	})
+}
+
+func getStatusCode(rec *httptest.ResponseRecorder, err error) int {
+	if err == nil {
+		return rec.Code
+	}
+
+	hterr := &echo.HTTPError{}
+	if errors.As(err, &hterr) {
+		return hterr.Code
+	}
+
+	return 500
+}
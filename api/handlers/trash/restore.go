@@ -0,0 +1,61 @@
+package trash
+
+import (
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/music-tribe/react-pairing-challenge/database"
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/uuid"
+)
+
+//go:generate mockgen -destination=./mocks/restore.go -package=trashmocks -source=restore.go
+type RestoreDatabase interface {
+	Restore(userId, featureId uuid.UUID) (*domain.Feature, error)
+}
+
+type RestoreRequest struct {
+	UserId    uuid.UUID `param:"userId" validate:"required" example:"ef2a27c4-b03d-4190-86f2-b1dc2538243e"`
+	FeatureId uuid.UUID `param:"featureId" validate:"required" example:"202c25c4-b2ce-4514-9045-890a1aa896ea"`
+}
+
+// Restore godoc
+// @Summary Restore a users deleted feature.
+// @Description Take one of this users features back out of the trash, votes included.
+// @Produce application/json
+// @Param userId path string true "User UUID"
+// @Param featureId path string true "Feature UUID"
+// @Router /api/{userId}/{featureId}/restore [post]
+// @Success 200 {object} domain.Feature
+// @failure 400 {object} error
+// @failure 404 {object} error
+// @failure 429 {object} error
+// @failure 500 {object} error
+func Restore(db RestoreDatabase) func(echo.Context) error {
+	if db == nil {
+		panic("trash.Restore: db has nil value")
+	}
+
+	return func(c echo.Context) error {
+		req := RestoreRequest{}
+
+		if err := c.Bind(&req); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err)
+		}
+
+		if err := validator.New().Struct(&req); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err)
+		}
+
+		feature, err := db.Restore(req.UserId, req.FeatureId)
+		if err != nil {
+			if err == database.ErrNotFound {
+				return echo.NewHTTPError(http.StatusNotFound, err)
+			}
+			return echo.NewHTTPError(http.StatusInternalServerError, err)
+		}
+
+		return c.JSON(http.StatusOK, feature)
+	}
+}
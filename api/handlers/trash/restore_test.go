@@ -0,0 +1,79 @@
+package trash
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/music-tribe/react-pairing-challenge/database"
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	trashmocks "github.com/music-tribe/react-pairing-challenge/handlers/trash/mocks"
+	"github.com/music-tribe/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRestore(t *testing.T) {
+	e := echo.New()
+
+	t.Run("when the db has a nil value, we should panic", func(t *testing.T) {
+		assert.Panics(t, func() {
+			Restore(nil)
+		})
+	})
+
+	t.Run("when the featureId is missing we should return a 400 error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := trashmocks.NewMockRestoreDatabase(ctrl)
+
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetParamNames("userId", "featureId")
+		ctx.SetParamValues(uuid.New().String(), uuid.Nil.String())
+
+		err := Restore(db)(ctx)
+		assert.ErrorContains(t, err, "Error:Field validation for 'FeatureId' failed on the 'required' tag")
+		assert.Equal(t, http.StatusBadRequest, getStatusCode(rec, err))
+	})
+
+	t.Run("when the feature isn't in the trash we should return a 404 error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := trashmocks.NewMockRestoreDatabase(ctrl)
+
+		userId, featureId := uuid.New(), uuid.New()
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetParamNames("userId", "featureId")
+		ctx.SetParamValues(userId.String(), featureId.String())
+
+		db.EXPECT().Restore(userId, featureId).Return(nil, database.ErrNotFound)
+
+		err := Restore(db)(ctx)
+		assert.ErrorContains(t, err, database.ErrNotFound.Error())
+		assert.Equal(t, http.StatusNotFound, getStatusCode(rec, err))
+	})
+
+	t.Run("when the request is healthy, we should get the restored feature", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := trashmocks.NewMockRestoreDatabase(ctrl)
+
+		userId, featureId := uuid.New(), uuid.New()
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetParamNames("userId", "featureId")
+		ctx.SetParamValues(userId.String(), featureId.String())
+
+		db.EXPECT().Restore(userId, featureId).Return(&domain.Feature{Id: featureId, UserId: userId}, nil)
+
+		err := Restore(db)(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, getStatusCode(rec, err))
+	})
+}
@@ -0,0 +1,53 @@
+package trash
+
+import (
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/uuid"
+)
+
+//go:generate mockgen -destination=./mocks/trash.go -package=trashmocks -source=trash.go
+type TrashDatabase interface {
+	GetTrash(userId uuid.UUID) ([]*domain.Feature, error)
+}
+
+type TrashRequest struct {
+	UserId uuid.UUID `param:"userId" validate:"required" example:"ef2a27c4-b03d-4190-86f2-b1dc2538243e"`
+}
+
+// Trash godoc
+// @Summary Get a users deleted features.
+// @Description Get the features this user has deleted that can still be restored, most recently deleted first.
+// @Produce application/json
+// @Param userId path string true "User UUID"
+// @Router /api/{userId}/trash [get]
+// @Success 200 {object} []domain.Feature
+// @failure 400 {object} error
+// @failure 500 {object} error
+func Trash(db TrashDatabase) func(echo.Context) error {
+	if db == nil {
+		panic("trash.Trash: db has nil value")
+	}
+
+	return func(c echo.Context) error {
+		req := TrashRequest{}
+
+		if err := c.Bind(&req); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err)
+		}
+
+		if err := validator.New().Struct(&req); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err)
+		}
+
+		features, err := db.GetTrash(req.UserId)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err)
+		}
+
+		return c.JSON(http.StatusOK, features)
+	}
+}
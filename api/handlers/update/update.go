@@ -7,24 +7,30 @@ import (
 	"github.com/labstack/echo/v4"
 	"github.com/music-tribe/react-pairing-challenge/database"
 	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/react-pairing-challenge/dto"
+	"github.com/music-tribe/react-pairing-challenge/handlers/categories"
+	"github.com/music-tribe/uuid"
 )
 
 //go:generate mockgen -destination=./mocks/update.go -package=updatemocks -source=update.go
 type UpdateDatabase interface {
+	categories.TagDatabase
+	Get(userId, featureId uuid.UUID) (*domain.Feature, error)
 	Update(feature *domain.Feature) error
 }
 
 // Update godoc
-// @Summary Get all of a users features.
-// @Description Get a all features releted to this userId.
+// @Summary Update a users feature.
+// @Description Update the name, description and tags of one of this users features. Tags are kept when left out, and cleared by an empty list.
 // @Accept application/json
 // @Produce text/plain
 // @Param userId path string true "User UUID"
-// @Param feature body domain.Feature true "Feature"
+// @Param feature body dto.UpdateFeature true "Feature"
 // @Router /api/{userId} [put]
 // @Success 200 {object} domain.Feature
 // @failure 400 {object} error
 // @failure 404 {object} error
+// @failure 429 {object} error
 // @failure 500 {object} error
 func Update(db UpdateDatabase) func(echo.Context) error {
 	if db == nil {
@@ -32,17 +38,33 @@ func Update(db UpdateDatabase) func(echo.Context) error {
 	}
 
 	return func(c echo.Context) error {
-		feature := domain.Feature{}
+		req := dto.UpdateFeature{}
 
-		if err := c.Bind(&feature); err != nil {
+		if err := c.Bind(&req); err != nil {
 			return echo.NewHTTPError(http.StatusBadRequest, err)
 		}
 
-		if err := validator.New().Struct(&feature); err != nil {
+		if err := validator.New().Struct(&req); err != nil {
 			return echo.NewHTTPError(http.StatusBadRequest, err)
 		}
 
-		if err := db.Update(&feature); err != nil {
+		if req.Tags != nil {
+			if err := categories.CheckTags(db, domain.NormalizeTags(*req.Tags)); err != nil {
+				return err
+			}
+		}
+
+		feature, err := db.Get(req.UserId, req.Id)
+		if err != nil {
+			if err == database.ErrNotFound {
+				return echo.NewHTTPError(http.StatusNotFound, err)
+			}
+			return echo.NewHTTPError(http.StatusInternalServerError, err)
+		}
+
+		dto.ApplyUpdate(feature, &req)
+
+		if err := db.Update(feature); err != nil {
 			if err == database.ErrNotFound {
 				return echo.NewHTTPError(http.StatusNotFound, err)
 			}
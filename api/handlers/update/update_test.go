@@ -11,6 +11,7 @@ import (
 	"github.com/labstack/echo/v4"
 	"github.com/music-tribe/react-pairing-challenge/database"
 	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/react-pairing-challenge/handlers/categories"
 	updatemocks "github.com/music-tribe/react-pairing-challenge/handlers/update/mocks"
 	"github.com/music-tribe/uuid"
 	"github.com/stretchr/testify/assert"
@@ -30,7 +31,7 @@ func TestUpdate(t *testing.T) {
 		defer ctrl.Finish()
 		db := updatemocks.NewMockUpdateDatabase(ctrl)
 
-		byt := []byte(`{"name":"","description":"do something","completed":false}`)
+		byt := []byte(`{"name":"","description":"do something"}`)
 		req := httptest.NewRequest(http.MethodPut, "/", bytes.NewReader(byt))
 		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 		rec := httptest.NewRecorder()
@@ -46,7 +47,7 @@ func TestUpdate(t *testing.T) {
 		defer ctrl.Finish()
 		db := updatemocks.NewMockUpdateDatabase(ctrl)
 
-		byt := []byte(`{"name":"hello","description":"","completed":false}`)
+		byt := []byte(`{"name":"hello","description":""}`)
 		req := httptest.NewRequest(http.MethodPut, "/", bytes.NewReader(byt))
 		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 		rec := httptest.NewRecorder()
@@ -64,7 +65,7 @@ func TestUpdate(t *testing.T) {
 
 		userId := uuid.New()
 		id := uuid.New()
-		byt := []byte(`{"name":"hello","description":"some description","completed":false, "id":"` + id.String() + `"}`)
+		byt := []byte(`{"name":"hello","description":"some description", "id":"` + id.String() + `"}`)
 		req := httptest.NewRequest(http.MethodPut, "/", bytes.NewReader(byt))
 		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 		rec := httptest.NewRecorder()
@@ -72,14 +73,7 @@ func TestUpdate(t *testing.T) {
 		ctx.SetParamNames("userId")
 		ctx.SetParamValues(userId.String())
 
-		expectfeature := &domain.Feature{
-			Id:          id,
-			UserId:      userId,
-			Name:        "hello",
-			Description: "some description",
-		}
-
-		db.EXPECT().Update(expectfeature).Return(database.ErrNotFound)
+		db.EXPECT().Get(userId, id).Return(nil, database.ErrNotFound)
 
 		err := Update(db)(ctx)
 		assert.ErrorContains(t, err, database.ErrNotFound.Error())
@@ -93,7 +87,7 @@ func TestUpdate(t *testing.T) {
 
 		userId := uuid.New()
 		id := uuid.New()
-		byt := []byte(`{"name":"hello","description":"some description","completed":false, "id":"` + id.String() + `"}`)
+		byt := []byte(`{"name":"hello","description":"some description", "id":"` + id.String() + `"}`)
 		req := httptest.NewRequest(http.MethodPut, "/", bytes.NewReader(byt))
 		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 		rec := httptest.NewRecorder()
@@ -101,6 +95,12 @@ func TestUpdate(t *testing.T) {
 		ctx.SetParamNames("userId")
 		ctx.SetParamValues(userId.String())
 
+		existing := &domain.Feature{
+			Id:          id,
+			UserId:      userId,
+			Name:        "old name",
+			Description: "old description",
+		}
 		expectfeature := &domain.Feature{
 			Id:          id,
 			UserId:      userId,
@@ -108,6 +108,7 @@ func TestUpdate(t *testing.T) {
 			Description: "some description",
 		}
 
+		db.EXPECT().Get(userId, id).Return(existing, nil)
 		db.EXPECT().Update(expectfeature).Return(errors.New("some error"))
 
 		err := Update(db)(ctx)
@@ -122,7 +123,102 @@ func TestUpdate(t *testing.T) {
 
 		userId := uuid.New()
 		id := uuid.New()
-		byt := []byte(`{"name":"hello","description":"some description","completed":false, "id":"` + id.String() + `"}`)
+		byt := []byte(`{"name":"hello","description":"some description", "id":"` + id.String() + `"}`)
+		req := httptest.NewRequest(http.MethodPut, "/", bytes.NewReader(byt))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetParamNames("userId")
+		ctx.SetParamValues(userId.String())
+
+		existing := &domain.Feature{
+			Id:          id,
+			UserId:      userId,
+			Name:        "old name",
+			Description: "old description",
+		}
+		expectfeature := &domain.Feature{
+			Id:          id,
+			UserId:      userId,
+			Name:        "hello",
+			Description: "some description",
+		}
+
+		db.EXPECT().Get(userId, id).Return(existing, nil)
+		db.EXPECT().Update(expectfeature).Return(nil)
+
+		err := Update(db)(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, updateStatusCode(rec, err))
+	})
+
+	t.Run("when the client sends votes, they should not overwrite the stored votes", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := updatemocks.NewMockUpdateDatabase(ctrl)
+
+		userId := uuid.New()
+		otherUserId := uuid.New()
+		id := uuid.New()
+		byt := []byte(`{"name":"hello","description":"some description", "id":"` + id.String() + `", "userId":"` + otherUserId.String() + `", "voteCount":1000}`)
+		req := httptest.NewRequest(http.MethodPut, "/", bytes.NewReader(byt))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetParamNames("userId")
+		ctx.SetParamValues(userId.String())
+
+		existing := &domain.Feature{
+			Id:          id,
+			UserId:      userId,
+			Name:        "old name",
+			Description: "old description",
+			VoteCount:   2,
+		}
+		expectfeature := &domain.Feature{
+			Id:          id,
+			UserId:      userId,
+			Name:        "hello",
+			Description: "some description",
+			VoteCount:   2,
+		}
+
+		db.EXPECT().Get(userId, id).Return(existing, nil)
+		db.EXPECT().Update(expectfeature).Return(nil)
+
+		err := Update(db)(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, updateStatusCode(rec, err))
+	})
+
+	t.Run("when the tags aren't existing categories we should return a 400 error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := updatemocks.NewMockUpdateDatabase(ctrl)
+
+		byt := []byte(`{"name":"hello","description":"some description", "id":"` + uuid.New().String() + `", "tags":["toaster"]}`)
+		req := httptest.NewRequest(http.MethodPut, "/", bytes.NewReader(byt))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetParamNames("userId")
+		ctx.SetParamValues(uuid.New().String())
+
+		db.EXPECT().MissingCategories([]string{"toaster"}).Return([]string{"toaster"}, nil)
+
+		err := Update(db)(ctx)
+		assert.ErrorContains(t, err, categories.ErrUnknownTags.Error())
+		assert.Equal(t, http.StatusBadRequest, updateStatusCode(rec, err))
+	})
+
+	t.Run("when the tags are existing categories, they should replace the stored tags", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := updatemocks.NewMockUpdateDatabase(ctrl)
+
+		userId := uuid.New()
+		id := uuid.New()
+		byt := []byte(`{"name":"hello","description":"some description", "id":"` + id.String() + `", "tags":["Cloud"]}`)
 		req := httptest.NewRequest(http.MethodPut, "/", bytes.NewReader(byt))
 		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 		rec := httptest.NewRecorder()
@@ -130,19 +226,99 @@ func TestUpdate(t *testing.T) {
 		ctx.SetParamNames("userId")
 		ctx.SetParamValues(userId.String())
 
+		existing := &domain.Feature{
+			Id:          id,
+			UserId:      userId,
+			Name:        "old name",
+			Description: "old description",
+			Tags:        []string{"mixer"},
+		}
 		expectfeature := &domain.Feature{
 			Id:          id,
 			UserId:      userId,
 			Name:        "hello",
 			Description: "some description",
+			Tags:        []string{"cloud"},
 		}
 
+		db.EXPECT().MissingCategories([]string{"cloud"}).Return([]string{}, nil)
+		db.EXPECT().Get(userId, id).Return(existing, nil)
+		db.EXPECT().Update(expectfeature).Return(nil)
+
+		err := Update(db)(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, updateStatusCode(rec, err))
+	})
+
+	t.Run("when the tags are left out, the stored tags should be kept", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := updatemocks.NewMockUpdateDatabase(ctrl)
+
+		userId := uuid.New()
+		id := uuid.New()
+		byt := []byte(`{"name":"hello","description":"some description", "id":"` + id.String() + `"}`)
+		req := httptest.NewRequest(http.MethodPut, "/", bytes.NewReader(byt))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetParamNames("userId")
+		ctx.SetParamValues(userId.String())
+
+		existing := &domain.Feature{Id: id, UserId: userId, Name: "old name", Description: "old description", Tags: []string{"mixer"}}
+		expectfeature := &domain.Feature{Id: id, UserId: userId, Name: "hello", Description: "some description", Tags: []string{"mixer"}}
+
+		db.EXPECT().Get(userId, id).Return(existing, nil)
+		db.EXPECT().Update(expectfeature).Return(nil)
+
+		err := Update(db)(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, updateStatusCode(rec, err))
+	})
+
+	t.Run("when the tags are an empty list, the stored tags should be cleared", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := updatemocks.NewMockUpdateDatabase(ctrl)
+
+		userId := uuid.New()
+		id := uuid.New()
+		byt := []byte(`{"name":"hello","description":"some description", "id":"` + id.String() + `", "tags":[]}`)
+		req := httptest.NewRequest(http.MethodPut, "/", bytes.NewReader(byt))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetParamNames("userId")
+		ctx.SetParamValues(userId.String())
+
+		existing := &domain.Feature{Id: id, UserId: userId, Name: "old name", Description: "old description", Tags: []string{"mixer"}}
+		expectfeature := &domain.Feature{Id: id, UserId: userId, Name: "hello", Description: "some description"}
+
+		db.EXPECT().Get(userId, id).Return(existing, nil)
 		db.EXPECT().Update(expectfeature).Return(nil)
 
 		err := Update(db)(ctx)
 		assert.NoError(t, err)
 		assert.Equal(t, http.StatusOK, updateStatusCode(rec, err))
 	})
+
+	t.Run("when there are too many tags we should return a 400 error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := updatemocks.NewMockUpdateDatabase(ctrl)
+
+		byt := []byte(`{"name":"hello","description":"some description", "id":"` + uuid.New().String() + `", "tags":["a","b","c","d","e","f","g","h","i","j","k"]}`)
+		req := httptest.NewRequest(http.MethodPut, "/", bytes.NewReader(byt))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetParamNames("userId")
+		ctx.SetParamValues(uuid.New().String())
+
+		err := Update(db)(ctx)
+		assert.ErrorContains(t, err, "'Tags' failed on the 'max' tag")
+		assert.Equal(t, http.StatusBadRequest, updateStatusCode(rec, err))
+	})
 }
 
 func updateStatusCode(rec *httptest.ResponseRecorder, err error) int {
@@ -0,0 +1,72 @@
+package upvote
+
+import (
+	"github.com/music-tribe/react-pairing-challenge/domain"
+)
+
+// Policy decides how many votes a user may hold on a board and how much each
+// of them is worth.
+type Policy struct {
+	// Budget is how many points a user may have spent on one board at a
+	// time. Zero means unlimited.
+	Budget int
+	// MaxWeight is the most points a single vote may carry.
+	MaxWeight int
+	// Quadratic makes a vote of weight w cost w*w points, so strong opinions
+	// are expensive.
+	Quadratic bool
+	// DownvoteBoards lists the boards that accept downvotes, "*" enables
+	// them everywhere.
+	DownvoteBoards []string
+}
+
+// DefaultPolicy gives every user unlimited votes of weight one.
+func DefaultPolicy() Policy {
+	return Policy{MaxWeight: 1}
+}
+
+// Unlimited reports whether users can vote without a budget.
+func (p Policy) Unlimited() bool {
+	return p.Budget <= 0
+}
+
+// AllowsDownvotes reports whether votes against features on board are enabled.
+func (p Policy) AllowsDownvotes(board string) bool {
+	for _, b := range p.DownvoteBoards {
+		if b == "*" || b == board {
+			return true
+		}
+	}
+	return false
+}
+
+// VoteBudget is the budget the store enforces as each vote is cast.
+func (p Policy) VoteBudget() domain.VoteBudget {
+	if p.Unlimited() {
+		return domain.VoteBudget{}
+	}
+	return domain.VoteBudget{Points: p.Budget, Quadratic: p.Quadratic}
+}
+
+// Cost is the number of budget points a vote of the given weight uses,
+// whichever direction it is cast in.
+func (p Policy) Cost(weight int) int {
+	return domain.VoteBudget{Quadratic: p.Quadratic}.Cost(weight)
+}
+
+// Spent totals the cost of the votes placed on board.
+func (p Policy) Spent(votes []*domain.Vote, board string) int {
+	return domain.VoteBudget{Quadratic: p.Quadratic}.Spent(votes, board)
+}
+
+// Remaining is how many points are left on board, or nil when unlimited.
+func (p Policy) Remaining(votes []*domain.Vote, board string) *int {
+	if p.Unlimited() {
+		return nil
+	}
+	remaining := p.Budget - p.Spent(votes, board)
+	if remaining < 0 {
+		remaining = 0
+	}
+	return &remaining
+}
@@ -0,0 +1,55 @@
+package upvote
+
+import (
+	"testing"
+
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicy(t *testing.T) {
+	votes := []*domain.Vote{
+		{Board: "mixer", Weight: 1},
+		{Board: "mixer", Weight: 3},
+		{Board: "cloud", Weight: 2},
+	}
+
+	t.Run("the default policy should be unlimited", func(t *testing.T) {
+		p := DefaultPolicy()
+		assert.True(t, p.Unlimited())
+		assert.Nil(t, p.Remaining(votes, "mixer"))
+	})
+
+	t.Run("a linear policy should charge the weight of each vote", func(t *testing.T) {
+		p := Policy{Budget: 10, MaxWeight: 3}
+		assert.Equal(t, 3, p.Cost(3))
+		assert.Equal(t, 4, p.Spent(votes, "mixer"))
+		assert.Equal(t, 6, *p.Remaining(votes, "mixer"))
+		assert.Equal(t, 8, *p.Remaining(votes, "cloud"))
+	})
+
+	t.Run("a quadratic policy should charge the square of each vote's weight", func(t *testing.T) {
+		p := Policy{Budget: 10, MaxWeight: 3, Quadratic: true}
+		assert.Equal(t, 9, p.Cost(3))
+		assert.Equal(t, 10, p.Spent(votes, "mixer"))
+		assert.Equal(t, 0, *p.Remaining(votes, "mixer"))
+	})
+
+	t.Run("votes without a weight should count as one", func(t *testing.T) {
+		p := Policy{Budget: 5, MaxWeight: 1}
+		assert.Equal(t, 1, p.Cost(0))
+	})
+
+	t.Run("the budget handed to the store should match the policy", func(t *testing.T) {
+		assert.Equal(t, domain.VoteBudget{}, DefaultPolicy().VoteBudget())
+		assert.Equal(t, domain.VoteBudget{Points: 10, Quadratic: true}, Policy{Budget: 10, MaxWeight: 3, Quadratic: true}.VoteBudget())
+	})
+
+	t.Run("downvotes should only be allowed on the listed boards", func(t *testing.T) {
+		p := Policy{MaxWeight: 1, DownvoteBoards: []string{"mixer"}}
+		assert.True(t, p.AllowsDownvotes("mixer"))
+		assert.False(t, p.AllowsDownvotes("cloud"))
+		assert.False(t, DefaultPolicy().AllowsDownvotes("mixer"))
+		assert.True(t, Policy{DownvoteBoards: []string{"*"}}.AllowsDownvotes("cloud"))
+	})
+}
@@ -0,0 +1,74 @@
+package upvote
+
+import (
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/music-tribe/react-pairing-challenge/auth"
+	"github.com/music-tribe/react-pairing-challenge/database"
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/uuid"
+)
+
+//go:generate mockgen -destination=./mocks/unvote.go -package=upvotemocks -source=unvote.go
+type UnvoteDatabase interface {
+	RemoveVote(featureId, voterId uuid.UUID) (*domain.Feature, error)
+}
+
+type UnvoteRequest struct {
+	UserId    uuid.UUID `query:"userId" validate:"required" example:"202c25c4-b2ce-4514-9045-890a1aa896ea"`
+	FeatureId uuid.UUID `param:"featureId" validate:"required" example:"b1f01569-ecff-4c60-a716-435b2e51f1ff"`
+}
+
+// Unvote godoc
+// @Summary Withdraws the user's vote for a feature request.
+// @Description Removes the user's vote from a feature request, returning its cost to their budget.
+// @Accept application/json
+// @Produce application/json
+// @Param featureId path string true "Feature ID"
+// @Param userId query string true "User UUID"
+// @Param Authorization header string false "Bearer token for the user id, required when USER_TOKEN_SECRET is set"
+// @Param Idempotency-Key header string false "Replays the first response to retries sent with the same key"
+// @Router /api/vote/{featureId} [delete]
+// @Success 200 {object} UpvoteResponse
+// @failure 400 {object} error
+// @failure 401 {object} error
+// @failure 404 {object} error
+// @failure 409 {object} error
+// @failure 422 {object} error
+// @failure 429 {object} error
+// @failure 500 {object} error
+func Unvote(db UnvoteDatabase, users *auth.UserTokens) func(echo.Context) error {
+	if db == nil {
+		panic("upvote.Unvote: db has nil value")
+	}
+	if users == nil {
+		panic("upvote.Unvote: users has nil value")
+	}
+
+	return func(c echo.Context) error {
+		req := UnvoteRequest{}
+		if err := c.Bind(&req); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err)
+		}
+
+		if err := validator.New().Struct(&req); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err)
+		}
+
+		if err := users.Authenticate(c, req.UserId); err != nil {
+			return err
+		}
+
+		feature, err := db.RemoveVote(req.FeatureId, req.UserId)
+		if err != nil {
+			if err == database.ErrNotFound {
+				return echo.NewHTTPError(http.StatusNotFound, err)
+			}
+			return echo.NewHTTPError(http.StatusInternalServerError, err)
+		}
+
+		return c.JSON(http.StatusOK, newUpvoteResponse(feature, "", nil))
+	}
+}
@@ -0,0 +1,128 @@
+package upvote
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/music-tribe/react-pairing-challenge/auth"
+	"github.com/music-tribe/react-pairing-challenge/database"
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	upvotemocks "github.com/music-tribe/react-pairing-challenge/handlers/upvote/mocks"
+	"github.com/music-tribe/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnvote(t *testing.T) {
+	e := echo.New()
+	anyUser := auth.NewUserTokens("")
+
+	t.Run("when the db has a nil value, we should panic", func(t *testing.T) {
+		assert.Panics(t, func() {
+			Unvote(nil, anyUser)
+		})
+		assert.Panics(t, func() {
+			Unvote(upvotemocks.NewMockUnvoteDatabase(gomock.NewController(t)), nil)
+		})
+	})
+
+	t.Run("when user tokens are required and the request has none for its user, we should return a 401 error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := upvotemocks.NewMockUnvoteDatabase(ctrl)
+
+		req := httptest.NewRequest(http.MethodDelete, "/?userId="+uuid.New().String(), nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetParamNames("featureId")
+		ctx.SetParamValues(uuid.New().String())
+
+		err := Unvote(db, auth.NewUserTokens("s3cret"))(ctx)
+		assert.Equal(t, http.StatusUnauthorized, getStatusCode(rec, err))
+	})
+
+	t.Run("when the userId is missing we should return a 400 error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := upvotemocks.NewMockUnvoteDatabase(ctrl)
+
+		req := httptest.NewRequest(http.MethodDelete, "/", nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetParamNames("featureId")
+		ctx.SetParamValues(uuid.New().String())
+
+		err := Unvote(db, anyUser)(ctx)
+		assert.ErrorContains(t, err, "Error:Field validation for 'UserId' failed on the 'required' tag")
+		assert.Equal(t, http.StatusBadRequest, getStatusCode(rec, err))
+	})
+
+	t.Run("when the user hasn't voted for the feature we should return a 404 error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := upvotemocks.NewMockUnvoteDatabase(ctrl)
+
+		userId := uuid.New()
+		featureId := uuid.New()
+		req := httptest.NewRequest(http.MethodDelete, "/?userId="+userId.String(), nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetParamNames("featureId")
+		ctx.SetParamValues(featureId.String())
+
+		db.EXPECT().RemoveVote(featureId, userId).Return(nil, database.ErrNotFound)
+
+		err := Unvote(db, anyUser)(ctx)
+		assert.ErrorContains(t, err, database.ErrNotFound.Error())
+		assert.Equal(t, http.StatusNotFound, getStatusCode(rec, err))
+	})
+
+	t.Run("when there is an internal server error we should return a 500 error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := upvotemocks.NewMockUnvoteDatabase(ctrl)
+
+		userId := uuid.New()
+		featureId := uuid.New()
+		req := httptest.NewRequest(http.MethodDelete, "/?userId="+userId.String(), nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetParamNames("featureId")
+		ctx.SetParamValues(featureId.String())
+
+		db.EXPECT().RemoveVote(featureId, userId).Return(nil, errors.New("some error"))
+
+		err := Unvote(db, anyUser)(ctx)
+		assert.ErrorContains(t, err, "some error")
+		assert.Equal(t, http.StatusInternalServerError, getStatusCode(rec, err))
+	})
+
+	t.Run("when the request is well formed we should return a 200 and the new vote count", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := upvotemocks.NewMockUnvoteDatabase(ctrl)
+
+		userId := uuid.New()
+		featureId := uuid.New()
+		req := httptest.NewRequest(http.MethodDelete, "/?userId="+userId.String(), nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetParamNames("featureId")
+		ctx.SetParamValues(featureId.String())
+
+		db.EXPECT().RemoveVote(featureId, userId).Return(&domain.Feature{Id: featureId, VoteCount: 4}, nil)
+
+		err := Unvote(db, anyUser)(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, getStatusCode(rec, err))
+
+		actual := UpvoteResponse{}
+		err = json.Unmarshal(rec.Body.Bytes(), &actual)
+		assert.NoError(t, err)
+		assert.Equal(t, UpvoteResponse{FeatureId: featureId, VoteCount: 4}, actual)
+	})
+}
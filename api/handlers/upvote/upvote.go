@@ -2,53 +2,80 @@ package upvote
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
+	"github.com/music-tribe/react-pairing-challenge/auth"
 	"github.com/music-tribe/react-pairing-challenge/database"
 	"github.com/music-tribe/react-pairing-challenge/domain"
 	"github.com/music-tribe/uuid"
 )
 
 var (
-	errVotedForOwnFeature = errors.New("sorry, you aren't allowed to vote for your own feature request")
-	errVoteAlreadyCounted = errors.New("you've already voted for this feature request")
+	errVotedForOwnFeature  = errors.New("sorry, you aren't allowed to vote for your own feature request")
+	errVoteAlreadyCounted  = errors.New("you've already voted for this feature request")
+	errVoteTooHeavy        = errors.New("vote weight is higher than this board allows")
+	errVoteBudgetExhausted = errors.New("you've used up your vote budget for this board")
+	errDownvotesDisabled   = errors.New("downvotes aren't enabled on this board")
 )
 
 //go:generate mockgen -destination=./mocks/upvote.go -package=upvotemocks -source=upvote.go
 type UpvoteDatabase interface {
 	GetById(featureId uuid.UUID) (*domain.Feature, error)
-	Update(feature *domain.Feature) error
+	GetVote(featureId, voterId uuid.UUID) (*domain.Vote, error)
+	GetVotesByVoter(voterId uuid.UUID) ([]*domain.Vote, error)
+	AddVote(vote *domain.Vote, budget domain.VoteBudget) (*domain.Feature, error)
+	ChangeVoteDirection(featureId, voterId uuid.UUID, direction domain.VoteDirection) (*domain.Feature, error)
 }
 
 type UpvoteRequest struct {
-	UserId    uuid.UUID `json:"userId" form:"userId" validate:"required" example:"202c25c4-b2ce-4514-9045-890a1aa896ea"`
-	FeatureId uuid.UUID `json:"-" param:"featureId" validate:"required" example:"b1f01569-ecff-4c60-a716-435b2e51f1ff"`
+	UserId    uuid.UUID            `json:"userId" form:"userId" validate:"required" example:"202c25c4-b2ce-4514-9045-890a1aa896ea"`
+	FeatureId uuid.UUID            `json:"-" param:"featureId" validate:"required" example:"b1f01569-ecff-4c60-a716-435b2e51f1ff"`
+	Direction domain.VoteDirection `json:"direction,omitempty" form:"direction" validate:"omitempty,oneof=up down" example:"up"`
+	Weight    int                  `json:"weight,omitempty" form:"weight" validate:"omitempty,min=1" example:"1"`
+	Source    string               `json:"source,omitempty" validate:"max=32" example:"web"`
 }
 
 type UpvoteResponse struct {
-	FeatureId uuid.UUID `json:"featureId" form:"featureId" example:"b1f01569-ecff-4c60-a716-435b2e51f1ff"`
-	VoteCount int64     `json:"voteCount" example:"45928"`
+	FeatureId       uuid.UUID            `json:"featureId" form:"featureId" example:"b1f01569-ecff-4c60-a716-435b2e51f1ff"`
+	Direction       domain.VoteDirection `json:"direction,omitempty" example:"up"`
+	VoteCount       int64                `json:"voteCount" example:"45928"`
+	Upvotes         int64                `json:"upvotes" example:"45930"`
+	Downvotes       int64                `json:"downvotes" example:"2"`
+	Score           int64                `json:"score" example:"45928"`
+	RemainingBudget *int                 `json:"remainingBudget,omitempty" example:"7"`
 }
 
 // Upvote godoc
 // @Summary Enables the user to vote for a new feature request.
 // @Description Enables the user to place one vote against another users feature request.
+// @Description On boards with downvotes enabled the vote may be cast down, and an existing vote can be switched by voting again in the other direction.
+// @Description When vote budgets are enabled each vote costs points from the user's budget for the feature's board.
 // @Accept application/json
 // @Produce text/plain
 // @Param featureId path string true "Feature ID"
 // @Param upvoteRequest body UpvoteRequest true "Upvote Request Body"
+// @Param Authorization header string false "Bearer token for the user id, required when USER_TOKEN_SECRET is set"
+// @Param Idempotency-Key header string false "Replays the first response to retries sent with the same key"
 // @Router /api/vote/{featureId} [put]
 // @Success 200 {object} UpvoteResponse
 // @failure 400 {object} error
+// @failure 401 {object} error
+// @failure 403 {object} error
 // @failure 404 {object} error
 // @failure 409 {object} error
+// @failure 422 {object} error
+// @failure 429 {object} error
 // @failure 500 {object} error
-func Upvote(db UpvoteDatabase) func(echo.Context) error {
+func Upvote(db UpvoteDatabase, policy Policy, users *auth.UserTokens) func(echo.Context) error {
 	if db == nil {
 		panic("update.Upvote: db has nil value")
 	}
+	if users == nil {
+		panic("update.Upvote: users has nil value")
+	}
 
 	return func(c echo.Context) error {
 		req := UpvoteRequest{}
@@ -56,40 +83,127 @@ func Upvote(db UpvoteDatabase) func(echo.Context) error {
 			return echo.NewHTTPError(http.StatusBadRequest, err)
 		}
 
-		if err := validator.New().Struct(&req); err != nil {
-			return echo.NewHTTPError(http.StatusBadRequest, err)
+		if err := users.Authenticate(c, req.UserId); err != nil {
+			return err
+		}
+
+		res, err := Cast(db, policy, req)
+		if err != nil {
+			return err
+		}
+
+		return c.JSON(http.StatusOK, res)
+	}
+}
+
+// Cast applies the voting rules to req and records the vote. It is shared by
+// every transport votes arrive on, so its errors are always *echo.HTTPError.
+func Cast(db UpvoteDatabase, policy Policy, req UpvoteRequest) (UpvoteResponse, error) {
+	if err := validator.New().Struct(&req); err != nil {
+		return UpvoteResponse{}, echo.NewHTTPError(http.StatusBadRequest, err)
+	}
+
+	if req.Direction == "" {
+		req.Direction = domain.Upvote
+	}
+
+	if req.Weight == 0 {
+		req.Weight = 1
+	}
+
+	if req.Weight > policy.MaxWeight {
+		return UpvoteResponse{}, echo.NewHTTPError(http.StatusBadRequest, fmt.Errorf("%w: the maximum is %d", errVoteTooHeavy, policy.MaxWeight))
+	}
+
+	feature, err := db.GetById(req.FeatureId)
+	if err != nil {
+		if err == database.ErrNotFound {
+			return UpvoteResponse{}, echo.NewHTTPError(http.StatusNotFound, err)
+		}
+		return UpvoteResponse{}, echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	if feature.UserId == req.UserId {
+		return UpvoteResponse{}, echo.NewHTTPError(http.StatusBadRequest, errVotedForOwnFeature)
+	}
+
+	if req.Direction == domain.Downvote && !policy.AllowsDownvotes(feature.Board) {
+		return UpvoteResponse{}, echo.NewHTTPError(http.StatusBadRequest, errDownvotesDisabled)
+	}
+
+	existing, err := db.GetVote(req.FeatureId, req.UserId)
+	if err != nil && err != database.ErrNotFound {
+		return UpvoteResponse{}, echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	if existing != nil {
+		if existing.Direction.Sign() == req.Direction.Sign() {
+			return UpvoteResponse{}, echo.NewHTTPError(http.StatusConflict, errVoteAlreadyCounted)
 		}
 
-		feature, err := db.GetById(req.FeatureId)
+		// switching direction keeps the vote's weight, so the budget is unchanged
+		feature, err = db.ChangeVoteDirection(req.FeatureId, req.UserId, req.Direction)
 		if err != nil {
 			if err == database.ErrNotFound {
-				return echo.NewHTTPError(http.StatusNotFound, err)
+				return UpvoteResponse{}, echo.NewHTTPError(http.StatusNotFound, err)
 			}
-			return echo.NewHTTPError(http.StatusInternalServerError, err)
+			return UpvoteResponse{}, echo.NewHTTPError(http.StatusInternalServerError, err)
 		}
 
-		if feature.UserId == req.UserId {
-			return echo.NewHTTPError(http.StatusBadRequest, errVotedForOwnFeature)
+		return newUpvoteResponse(feature, req.Direction, nil), nil
+	}
+
+	// the votes are read up front to explain a refusal and to report the
+	// budget left, but it is AddVote that holds the voter to it
+	var votes []*domain.Vote
+	if !policy.Unlimited() {
+		votes, err = db.GetVotesByVoter(req.UserId)
+		if err != nil {
+			return UpvoteResponse{}, echo.NewHTTPError(http.StatusInternalServerError, err)
 		}
 
-		for _, user := range feature.Votes {
-			if user == req.UserId {
-				return echo.NewHTTPError(http.StatusConflict, errVoteAlreadyCounted)
-			}
+		remaining := *policy.Remaining(votes, feature.Board)
+		if cost := policy.Cost(req.Weight); cost > remaining {
+			return UpvoteResponse{}, echo.NewHTTPError(http.StatusForbidden, fmt.Errorf("%w: this vote costs %d points but you have %d of %d left on the %q board",
+				errVoteBudgetExhausted, cost, remaining, policy.Budget, feature.Board))
 		}
+	}
 
-		feature.Votes = append(feature.Votes, req.UserId)
+	vote := &domain.Vote{
+		Id:        uuid.New(),
+		FeatureId: req.FeatureId,
+		VoterId:   req.UserId,
+		Direction: req.Direction,
+		Weight:    req.Weight,
+		Source:    req.Source,
+	}
 
-		if err := db.Update(feature); err != nil {
-			if err == database.ErrNotFound {
-				return echo.NewHTTPError(http.StatusNotFound, err)
-			}
-			return echo.NewHTTPError(http.StatusInternalServerError, err)
+	voted, err := db.AddVote(vote, policy.VoteBudget())
+	if err != nil {
+		if err == database.ErrNotFound {
+			return UpvoteResponse{}, echo.NewHTTPError(http.StatusNotFound, err)
 		}
+		if err == database.ErrDuplicate {
+			return UpvoteResponse{}, echo.NewHTTPError(http.StatusConflict, errVoteAlreadyCounted)
+		}
+		if err == database.ErrBudgetExhausted {
+			return UpvoteResponse{}, echo.NewHTTPError(http.StatusForbidden, fmt.Errorf("%w: you have %d points to spend on the %q board",
+				errVoteBudgetExhausted, policy.Budget, feature.Board))
+		}
+		return UpvoteResponse{}, echo.NewHTTPError(http.StatusInternalServerError, err)
+	}
+
+	return newUpvoteResponse(voted, req.Direction, policy.Remaining(append(votes, vote), voted.Board)), nil
+}
 
-		return c.JSON(http.StatusOK, UpvoteResponse{
-			FeatureId: req.FeatureId,
-			VoteCount: int64(len(feature.Votes)),
-		})
+func newUpvoteResponse(feature *domain.Feature, direction domain.VoteDirection, remaining *int) UpvoteResponse {
+	return UpvoteResponse{
+		FeatureId:       feature.Id,
+		Direction:       direction,
+		VoteCount:       feature.VoteCount,
+		Upvotes:         feature.Upvotes,
+		Downvotes:       feature.Downvotes,
+		Score:           feature.Score,
+		RemainingBudget: remaining,
 	}
 }
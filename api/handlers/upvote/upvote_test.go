@@ -10,6 +10,7 @@ import (
 
 	"github.com/golang/mock/gomock"
 	"github.com/labstack/echo/v4"
+	"github.com/music-tribe/react-pairing-challenge/auth"
 	"github.com/music-tribe/react-pairing-challenge/database"
 	"github.com/music-tribe/react-pairing-challenge/domain"
 	upvotemocks "github.com/music-tribe/react-pairing-challenge/handlers/upvote/mocks"
@@ -19,13 +20,62 @@ import (
 
 func TestUpvote(t *testing.T) {
 	e := echo.New()
+	anyUser := auth.NewUserTokens("")
 
 	t.Run("when the db has a nil value, we should panic", func(t *testing.T) {
 		assert.Panics(t, func() {
-			Upvote(nil)
+			Upvote(nil, DefaultPolicy(), anyUser)
+		})
+		assert.Panics(t, func() {
+			Upvote(upvotemocks.NewMockUpvoteDatabase(gomock.NewController(t)), DefaultPolicy(), nil)
 		})
 	})
 
+	t.Run("when user tokens are required and the request has none for its user, we should return a 401 error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := upvotemocks.NewMockUpvoteDatabase(ctrl)
+		users := auth.NewUserTokens("s3cret")
+
+		byt := []byte(`{"userId":"` + uuid.New().String() + `"}`)
+		req := httptest.NewRequest(http.MethodPut, "/", bytes.NewReader(byt))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+users.Token(uuid.New()))
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetParamNames("featureId")
+		ctx.SetParamValues(uuid.New().String())
+
+		err := Upvote(db, DefaultPolicy(), users)(ctx)
+		assert.Equal(t, http.StatusUnauthorized, getStatusCode(rec, err))
+	})
+
+	t.Run("when user tokens are required and the request has its user's, the vote should be cast", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := upvotemocks.NewMockUpvoteDatabase(ctrl)
+		users := auth.NewUserTokens("s3cret")
+
+		userId := uuid.New()
+		feature := &domain.Feature{Id: uuid.New(), UserId: uuid.New(), Board: "mixer"}
+		byt := []byte(`{"userId":"` + userId.String() + `"}`)
+		req := httptest.NewRequest(http.MethodPut, "/", bytes.NewReader(byt))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+users.Token(userId))
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetParamNames("featureId")
+		ctx.SetParamValues(feature.Id.String())
+
+		db.EXPECT().GetById(feature.Id).Return(feature, nil)
+		db.EXPECT().GetVote(feature.Id, userId).Return(nil, database.ErrNotFound)
+		db.EXPECT().AddVote(gomock.Any(), gomock.Any()).Return(&domain.Feature{Id: feature.Id, VoteCount: 1}, nil)
+
+		err := Upvote(db, DefaultPolicy(), users)(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
 	t.Run("when the userId is missing we should return a 400 error", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
@@ -39,7 +89,7 @@ func TestUpvote(t *testing.T) {
 		ctx.SetParamNames("featureId")
 		ctx.SetParamValues("")
 
-		err := Upvote(db)(ctx)
+		err := Upvote(db, DefaultPolicy(), anyUser)(ctx)
 		assert.ErrorContains(t, err, "invalid UUID length: 0,")
 		assert.Equal(t, http.StatusBadRequest, getStatusCode(rec, err))
 	})
@@ -57,7 +107,7 @@ func TestUpvote(t *testing.T) {
 		ctx.SetParamNames("featureId")
 		ctx.SetParamValues(uuid.Nil.String())
 
-		err := Upvote(db)(ctx)
+		err := Upvote(db, DefaultPolicy(), anyUser)(ctx)
 		assert.ErrorContains(t, err, "Error:Field validation for 'FeatureId' failed on the 'required' tag")
 		assert.Equal(t, http.StatusBadRequest, getStatusCode(rec, err))
 	})
@@ -75,7 +125,7 @@ func TestUpvote(t *testing.T) {
 		ctx.SetParamNames("featureId")
 		ctx.SetParamValues(uuid.New().String())
 
-		err := Upvote(db)(ctx)
+		err := Upvote(db, DefaultPolicy(), anyUser)(ctx)
 		assert.ErrorContains(t, err, "invalid UUID length: 0")
 		assert.Equal(t, http.StatusBadRequest, getStatusCode(rec, err))
 	})
@@ -93,7 +143,7 @@ func TestUpvote(t *testing.T) {
 		ctx.SetParamNames("featureId")
 		ctx.SetParamValues(uuid.New().String())
 
-		err := Upvote(db)(ctx)
+		err := Upvote(db, DefaultPolicy(), anyUser)(ctx)
 		assert.ErrorContains(t, err, "Error:Field validation for 'UserId' failed on the 'required' tag")
 		assert.Equal(t, http.StatusBadRequest, getStatusCode(rec, err))
 	})
@@ -115,7 +165,7 @@ func TestUpvote(t *testing.T) {
 
 		db.EXPECT().GetById(featureId).Return(nil, database.ErrNotFound)
 
-		err := Upvote(db)(ctx)
+		err := Upvote(db, DefaultPolicy(), anyUser)(ctx)
 		assert.ErrorContains(t, err, database.ErrNotFound.Error())
 		assert.Equal(t, http.StatusNotFound, getStatusCode(rec, err))
 	})
@@ -138,7 +188,7 @@ func TestUpvote(t *testing.T) {
 		someError := errors.New("some error")
 		db.EXPECT().GetById(featureId).Return(nil, someError)
 
-		err := Upvote(db)(ctx)
+		err := Upvote(db, DefaultPolicy(), anyUser)(ctx)
 		assert.ErrorContains(t, err, someError.Error())
 		assert.Equal(t, http.StatusInternalServerError, getStatusCode(rec, err))
 	})
@@ -163,14 +213,12 @@ func TestUpvote(t *testing.T) {
 			Id:          featureId,
 			Name:        "something",
 			Description: "hello thing",
-			Votes: []uuid.UUID{
-				userId, // a previous vote by this user
-			},
+			VoteCount:   1,
 		}
 
 		db.EXPECT().GetById(featureId).Return(&feature, nil)
 
-		err := Upvote(db)(ctx)
+		err := Upvote(db, DefaultPolicy(), anyUser)(ctx)
 		assert.ErrorContains(t, err, errVotedForOwnFeature.Error())
 		assert.Equal(t, http.StatusBadRequest, getStatusCode(rec, err))
 	})
@@ -195,14 +243,14 @@ func TestUpvote(t *testing.T) {
 			Id:          featureId,
 			Name:        "something",
 			Description: "hello thing",
-			Votes: []uuid.UUID{
-				userId, // a previous vote by this user
-			},
+			VoteCount:   1,
 		}
 
 		db.EXPECT().GetById(featureId).Return(&feature, nil)
+		db.EXPECT().GetVote(featureId, userId).Return(nil, database.ErrNotFound)
+		db.EXPECT().AddVote(gomock.Any(), gomock.Any()).Return(nil, database.ErrDuplicate)
 
-		err := Upvote(db)(ctx)
+		err := Upvote(db, DefaultPolicy(), anyUser)(ctx)
 		assert.ErrorContains(t, err, errVoteAlreadyCounted.Error())
 		assert.Equal(t, http.StatusConflict, getStatusCode(rec, err))
 	})
@@ -227,17 +275,16 @@ func TestUpvote(t *testing.T) {
 			Id:          featureId,
 			Name:        "something",
 			Description: "hello thing",
-			Votes: []uuid.UUID{
-				uuid.New(), // a previous vote
-			},
+			VoteCount:   1,
 		}
 
 		db.EXPECT().GetById(featureId).Return(&feature, nil)
+		db.EXPECT().GetVote(featureId, userId).Return(nil, database.ErrNotFound)
 
 		someError := errors.New("some error")
-		db.EXPECT().Update(&feature).Return(someError)
+		db.EXPECT().AddVote(gomock.Any(), gomock.Any()).Return(nil, someError)
 
-		err := Upvote(db)(ctx)
+		err := Upvote(db, DefaultPolicy(), anyUser)(ctx)
 		assert.ErrorContains(t, err, someError.Error())
 		assert.Equal(t, http.StatusInternalServerError, getStatusCode(rec, err))
 	})
@@ -262,21 +309,34 @@ func TestUpvote(t *testing.T) {
 			Id:          featureId,
 			Name:        "something",
 			Description: "hello thing",
-			Votes: []uuid.UUID{
-				uuid.New(), // a previous vote
-			},
+			VoteCount:   1,
 		}
 
 		db.EXPECT().GetById(featureId).Return(&feature, nil)
-
-		db.EXPECT().Update(&feature).Return(nil)
+		db.EXPECT().GetVote(featureId, userId).Return(nil, database.ErrNotFound)
+		db.EXPECT().AddVote(gomock.Any(), gomock.Any()).DoAndReturn(func(vote *domain.Vote, _ domain.VoteBudget) (*domain.Feature, error) {
+			assert.NotEqual(t, uuid.Nil, vote.Id)
+			assert.Equal(t, featureId, vote.FeatureId)
+			assert.Equal(t, userId, vote.VoterId)
+			assert.Equal(t, domain.Upvote, vote.Direction)
+			assert.Equal(t, 1, vote.Weight)
+
+			voted := feature
+			voted.VoteCount++
+			voted.Upvotes++
+			voted.Score++
+			return &voted, nil
+		})
 
 		expect := UpvoteResponse{
 			FeatureId: featureId,
-			VoteCount: int64(len(feature.Votes) + 1),
+			Direction: domain.Upvote,
+			VoteCount: feature.VoteCount + 1,
+			Upvotes:   feature.Upvotes + 1,
+			Score:     feature.Score + 1,
 		}
 
-		err := Upvote(db)(ctx)
+		err := Upvote(db, DefaultPolicy(), anyUser)(ctx)
 		assert.NoError(t, err)
 		assert.Equal(t, http.StatusOK, getStatusCode(rec, err))
 
@@ -285,6 +345,344 @@ func TestUpvote(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, expect, actual)
 	})
+
+	t.Run("when the vote is heavier than the policy allows, we should return a 400", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := upvotemocks.NewMockUpvoteDatabase(ctrl)
+
+		userId := uuid.New()
+		featureId := uuid.New()
+		byt := []byte(`{"userId":"` + userId.String() + `","weight":4}`)
+		req := httptest.NewRequest(http.MethodPut, "/", bytes.NewReader(byt))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetParamNames("featureId")
+		ctx.SetParamValues(featureId.String())
+
+		err := Upvote(db, Policy{Budget: 10, MaxWeight: 3}, anyUser)(ctx)
+		assert.ErrorContains(t, err, errVoteTooHeavy.Error())
+		assert.ErrorContains(t, err, "the maximum is 3")
+		assert.Equal(t, http.StatusBadRequest, getStatusCode(rec, err))
+	})
+
+	t.Run("when the user's budget for the board is used up, we should return a 403", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := upvotemocks.NewMockUpvoteDatabase(ctrl)
+
+		userId := uuid.New()
+		featureId := uuid.New()
+		byt := []byte(`{"userId":"` + userId.String() + `","weight":2}`)
+		req := httptest.NewRequest(http.MethodPut, "/", bytes.NewReader(byt))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetParamNames("featureId")
+		ctx.SetParamValues(featureId.String())
+
+		feature := domain.Feature{
+			UserId:      uuid.New(),
+			Id:          featureId,
+			Name:        "something",
+			Description: "hello thing",
+			Board:       "mixer",
+		}
+
+		db.EXPECT().GetById(featureId).Return(&feature, nil)
+		db.EXPECT().GetVote(featureId, userId).Return(nil, database.ErrNotFound)
+		db.EXPECT().GetVotesByVoter(userId).Return([]*domain.Vote{
+			{FeatureId: uuid.New(), VoterId: userId, Board: "mixer", Weight: 2},
+			{FeatureId: uuid.New(), VoterId: userId, Board: "cloud", Weight: 3},
+		}, nil)
+
+		err := Upvote(db, Policy{Budget: 5, MaxWeight: 3, Quadratic: true}, anyUser)(ctx)
+		assert.ErrorContains(t, err, errVoteBudgetExhausted.Error())
+		assert.ErrorContains(t, err, `this vote costs 4 points but you have 1 of 5 left on the "mixer" board`)
+		assert.Equal(t, http.StatusForbidden, getStatusCode(rec, err))
+	})
+
+	t.Run("when the store finds the budget used up by votes cast meanwhile, we should return a 403", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := upvotemocks.NewMockUpvoteDatabase(ctrl)
+
+		userId := uuid.New()
+		featureId := uuid.New()
+		byt := []byte(`{"userId":"` + userId.String() + `"}`)
+		req := httptest.NewRequest(http.MethodPut, "/", bytes.NewReader(byt))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetParamNames("featureId")
+		ctx.SetParamValues(featureId.String())
+
+		feature := domain.Feature{
+			UserId:      uuid.New(),
+			Id:          featureId,
+			Name:        "something",
+			Description: "hello thing",
+			Board:       "mixer",
+		}
+
+		db.EXPECT().GetById(featureId).Return(&feature, nil)
+		db.EXPECT().GetVote(featureId, userId).Return(nil, database.ErrNotFound)
+		db.EXPECT().GetVotesByVoter(userId).Return([]*domain.Vote{}, nil)
+		db.EXPECT().AddVote(gomock.Any(), domain.VoteBudget{Points: 1}).Return(nil, database.ErrBudgetExhausted)
+
+		err := Upvote(db, Policy{Budget: 1, MaxWeight: 1}, anyUser)(ctx)
+		assert.ErrorContains(t, err, errVoteBudgetExhausted.Error())
+		assert.ErrorContains(t, err, `you have 1 points to spend on the "mixer" board`)
+		assert.Equal(t, http.StatusForbidden, getStatusCode(rec, err))
+	})
+
+	t.Run("when the user has already voted in the same direction, we should return a 409", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := upvotemocks.NewMockUpvoteDatabase(ctrl)
+
+		userId := uuid.New()
+		featureId := uuid.New()
+		byt := []byte(`{"userId":"` + userId.String() + `"}`)
+		req := httptest.NewRequest(http.MethodPut, "/", bytes.NewReader(byt))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetParamNames("featureId")
+		ctx.SetParamValues(featureId.String())
+
+		feature := domain.Feature{
+			UserId:      uuid.New(),
+			Id:          featureId,
+			Name:        "something",
+			Description: "hello thing",
+			Board:       "mixer",
+		}
+
+		db.EXPECT().GetById(featureId).Return(&feature, nil)
+		db.EXPECT().GetVote(featureId, userId).Return(&domain.Vote{FeatureId: featureId, VoterId: userId, Board: "mixer", Weight: 1}, nil)
+
+		err := Upvote(db, Policy{Budget: 5, MaxWeight: 1}, anyUser)(ctx)
+		assert.ErrorContains(t, err, errVoteAlreadyCounted.Error())
+		assert.Equal(t, http.StatusConflict, getStatusCode(rec, err))
+	})
+
+	t.Run("when the user has budget left, the weighted vote should be cast and the remaining budget returned", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := upvotemocks.NewMockUpvoteDatabase(ctrl)
+
+		userId := uuid.New()
+		featureId := uuid.New()
+		byt := []byte(`{"userId":"` + userId.String() + `","weight":2}`)
+		req := httptest.NewRequest(http.MethodPut, "/", bytes.NewReader(byt))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetParamNames("featureId")
+		ctx.SetParamValues(featureId.String())
+
+		feature := domain.Feature{
+			UserId:      uuid.New(),
+			Id:          featureId,
+			Name:        "something",
+			Description: "hello thing",
+			Board:       "mixer",
+		}
+
+		db.EXPECT().GetById(featureId).Return(&feature, nil)
+		db.EXPECT().GetVote(featureId, userId).Return(nil, database.ErrNotFound)
+		db.EXPECT().GetVotesByVoter(userId).Return([]*domain.Vote{
+			{FeatureId: uuid.New(), VoterId: userId, Board: "mixer", Weight: 1},
+		}, nil)
+		db.EXPECT().AddVote(gomock.Any(), domain.VoteBudget{Points: 10, Quadratic: true}).DoAndReturn(func(vote *domain.Vote, _ domain.VoteBudget) (*domain.Feature, error) {
+			assert.Equal(t, 2, vote.Weight)
+
+			vote.Board = feature.Board
+			voted := feature
+			voted.VoteCount = 1
+			return &voted, nil
+		})
+
+		err := Upvote(db, Policy{Budget: 10, MaxWeight: 3, Quadratic: true}, anyUser)(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, getStatusCode(rec, err))
+
+		actual := UpvoteResponse{}
+		err = json.Unmarshal(rec.Body.Bytes(), &actual)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), actual.VoteCount)
+		assert.Equal(t, 5, *actual.RemainingBudget)
+	})
+
+	t.Run("when downvotes aren't enabled on the feature's board, we should return a 400", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := upvotemocks.NewMockUpvoteDatabase(ctrl)
+
+		userId := uuid.New()
+		featureId := uuid.New()
+		byt := []byte(`{"userId":"` + userId.String() + `","direction":"down"}`)
+		req := httptest.NewRequest(http.MethodPut, "/", bytes.NewReader(byt))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetParamNames("featureId")
+		ctx.SetParamValues(featureId.String())
+
+		feature := domain.Feature{
+			UserId: uuid.New(),
+			Id:     featureId,
+			Board:  "cloud",
+		}
+
+		db.EXPECT().GetById(featureId).Return(&feature, nil)
+
+		err := Upvote(db, Policy{MaxWeight: 1, DownvoteBoards: []string{"mixer"}}, anyUser)(ctx)
+		assert.ErrorContains(t, err, errDownvotesDisabled.Error())
+		assert.Equal(t, http.StatusBadRequest, getStatusCode(rec, err))
+	})
+
+	t.Run("when the direction is unknown, we should return a 400", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := upvotemocks.NewMockUpvoteDatabase(ctrl)
+
+		byt := []byte(`{"userId":"` + uuid.New().String() + `","direction":"sideways"}`)
+		req := httptest.NewRequest(http.MethodPut, "/", bytes.NewReader(byt))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetParamNames("featureId")
+		ctx.SetParamValues(uuid.New().String())
+
+		err := Upvote(db, DefaultPolicy(), anyUser)(ctx)
+		assert.ErrorContains(t, err, "Error:Field validation for 'Direction' failed on the 'oneof' tag")
+		assert.Equal(t, http.StatusBadRequest, getStatusCode(rec, err))
+	})
+
+	t.Run("when downvotes are enabled, a downvote should be cast and the net score returned", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := upvotemocks.NewMockUpvoteDatabase(ctrl)
+
+		userId := uuid.New()
+		featureId := uuid.New()
+		byt := []byte(`{"userId":"` + userId.String() + `","direction":"down"}`)
+		req := httptest.NewRequest(http.MethodPut, "/", bytes.NewReader(byt))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetParamNames("featureId")
+		ctx.SetParamValues(featureId.String())
+
+		feature := domain.Feature{
+			UserId:    uuid.New(),
+			Id:        featureId,
+			Board:     "mixer",
+			VoteCount: 2,
+			Upvotes:   2,
+			Score:     2,
+		}
+
+		db.EXPECT().GetById(featureId).Return(&feature, nil)
+		db.EXPECT().GetVote(featureId, userId).Return(nil, database.ErrNotFound)
+		db.EXPECT().AddVote(gomock.Any(), gomock.Any()).DoAndReturn(func(vote *domain.Vote, _ domain.VoteBudget) (*domain.Feature, error) {
+			assert.Equal(t, domain.Downvote, vote.Direction)
+
+			voted := feature
+			voted.VoteCount, voted.Downvotes, voted.Score = 3, 1, 1
+			return &voted, nil
+		})
+
+		err := Upvote(db, Policy{MaxWeight: 1, DownvoteBoards: []string{"mixer"}}, anyUser)(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, getStatusCode(rec, err))
+
+		actual := UpvoteResponse{}
+		err = json.Unmarshal(rec.Body.Bytes(), &actual)
+		assert.NoError(t, err)
+		assert.Equal(t, UpvoteResponse{
+			FeatureId: featureId,
+			Direction: domain.Downvote,
+			VoteCount: 3,
+			Upvotes:   2,
+			Downvotes: 1,
+			Score:     1,
+		}, actual)
+	})
+
+	t.Run("when the user votes the other way, their existing vote should be switched", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := upvotemocks.NewMockUpvoteDatabase(ctrl)
+
+		userId := uuid.New()
+		featureId := uuid.New()
+		byt := []byte(`{"userId":"` + userId.String() + `","direction":"down"}`)
+		req := httptest.NewRequest(http.MethodPut, "/", bytes.NewReader(byt))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetParamNames("featureId")
+		ctx.SetParamValues(featureId.String())
+
+		feature := domain.Feature{
+			UserId:    uuid.New(),
+			Id:        featureId,
+			Board:     "mixer",
+			VoteCount: 1,
+			Upvotes:   1,
+			Score:     1,
+		}
+
+		db.EXPECT().GetById(featureId).Return(&feature, nil)
+		db.EXPECT().GetVote(featureId, userId).Return(&domain.Vote{FeatureId: featureId, VoterId: userId, Direction: domain.Upvote, Weight: 1}, nil)
+		db.EXPECT().ChangeVoteDirection(featureId, userId, domain.Downvote).Return(&domain.Feature{
+			Id:        featureId,
+			VoteCount: 1,
+			Downvotes: 1,
+			Score:     -1,
+		}, nil)
+
+		err := Upvote(db, Policy{Budget: 5, MaxWeight: 1, DownvoteBoards: []string{"*"}}, anyUser)(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, getStatusCode(rec, err))
+
+		actual := UpvoteResponse{}
+		err = json.Unmarshal(rec.Body.Bytes(), &actual)
+		assert.NoError(t, err)
+		assert.Equal(t, UpvoteResponse{
+			FeatureId: featureId,
+			Direction: domain.Downvote,
+			VoteCount: 1,
+			Downvotes: 1,
+			Score:     -1,
+		}, actual)
+	})
+
+	t.Run("when the user tries to downvote their own feature, we should still return a 400", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := upvotemocks.NewMockUpvoteDatabase(ctrl)
+
+		userId := uuid.New()
+		featureId := uuid.New()
+		byt := []byte(`{"userId":"` + userId.String() + `","direction":"down"}`)
+		req := httptest.NewRequest(http.MethodPut, "/", bytes.NewReader(byt))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetParamNames("featureId")
+		ctx.SetParamValues(featureId.String())
+
+		db.EXPECT().GetById(featureId).Return(&domain.Feature{UserId: userId, Id: featureId, Board: "mixer"}, nil)
+
+		err := Upvote(db, Policy{MaxWeight: 1, DownvoteBoards: []string{"*"}}, anyUser)(ctx)
+		assert.ErrorContains(t, err, errVotedForOwnFeature.Error())
+		assert.Equal(t, http.StatusBadRequest, getStatusCode(rec, err))
+	})
 }
 
 func getStatusCode(rec *httptest.ResponseRecorder, err error) int {
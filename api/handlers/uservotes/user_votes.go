@@ -0,0 +1,115 @@
+package uservotes
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/react-pairing-challenge/handlers/upvote"
+	"github.com/music-tribe/uuid"
+)
+
+//go:generate mockgen -destination=./mocks/user_votes.go -package=uservotesmocks -source=user_votes.go
+type UserVotesDatabase interface {
+	GetVotesByVoter(voterId uuid.UUID) ([]*domain.Vote, error)
+}
+
+type UserVotesRequest struct {
+	UserId uuid.UUID `param:"userId" validate:"required" example:"ef2a27c4-b03d-4190-86f2-b1dc2538243e"`
+}
+
+type UserVotesResponse struct {
+	UserId    uuid.UUID    `json:"userId" example:"ef2a27c4-b03d-4190-86f2-b1dc2538243e"`
+	Unlimited bool         `json:"unlimited" example:"false"`
+	Boards    []BoardVotes `json:"boards"`
+}
+
+type BoardVotes struct {
+	Board     string        `json:"board" example:"mixer"`
+	Budget    int           `json:"budget,omitempty" example:"10"`
+	Spent     int           `json:"spent" example:"3"`
+	Remaining *int          `json:"remaining,omitempty" example:"7"`
+	Votes     []VoteSummary `json:"votes"`
+}
+
+type VoteSummary struct {
+	FeatureId uuid.UUID `json:"featureId" example:"b1f01569-ecff-4c60-a716-435b2e51f1ff"`
+	Weight    int       `json:"weight" example:"1"`
+	Cost      int       `json:"cost" example:"1"`
+	CastAt    time.Time `json:"castAt" example:"2024-03-03T17:45:00Z"`
+}
+
+// UserVotes godoc
+// @Summary Show where a user's votes are spent.
+// @Description List a user's active votes grouped by board, with the budget spent and remaining on each.
+// @Description Votes on features in the trash are left out until the feature is restored.
+// @Accept application/json
+// @Produce application/json
+// @Param userId path string true "User UUID"
+// @Router /api/users/{userId}/votes [get]
+// @Success 200 {object} UserVotesResponse
+// @failure 400 {object} error
+// @failure 500 {object} error
+func UserVotes(db UserVotesDatabase, policy upvote.Policy) func(echo.Context) error {
+	if db == nil {
+		panic("uservotes.UserVotes: db has nil value")
+	}
+
+	return func(c echo.Context) error {
+		req := UserVotesRequest{}
+
+		if err := c.Bind(&req); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err)
+		}
+
+		if err := validator.New().Struct(&req); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err)
+		}
+
+		votes, err := db.GetVotesByVoter(req.UserId)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err)
+		}
+
+		byBoard := map[string][]*domain.Vote{}
+		for _, vote := range votes {
+			byBoard[vote.Board] = append(byBoard[vote.Board], vote)
+		}
+
+		boards := make([]BoardVotes, 0, len(byBoard))
+		for board, vs := range byBoard {
+			bv := BoardVotes{
+				Board:     board,
+				Spent:     policy.Spent(vs, board),
+				Remaining: policy.Remaining(vs, board),
+				Votes:     make([]VoteSummary, 0, len(vs)),
+			}
+			if !policy.Unlimited() {
+				bv.Budget = policy.Budget
+			}
+
+			for _, vote := range vs {
+				bv.Votes = append(bv.Votes, VoteSummary{
+					FeatureId: vote.FeatureId,
+					Weight:    vote.Weight,
+					Cost:      policy.Cost(vote.Weight),
+					CastAt:    vote.CastAt,
+				})
+			}
+			boards = append(boards, bv)
+		}
+
+		sort.Slice(boards, func(i, j int) bool {
+			return boards[i].Board < boards[j].Board
+		})
+
+		return c.JSON(http.StatusOK, UserVotesResponse{
+			UserId:    req.UserId,
+			Unlimited: policy.Unlimited(),
+			Boards:    boards,
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package uservotes
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/react-pairing-challenge/handlers/upvote"
+	uservotesmocks "github.com/music-tribe/react-pairing-challenge/handlers/uservotes/mocks"
+	"github.com/music-tribe/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserVotes(t *testing.T) {
+	e := echo.New()
+
+	t.Run("when the db has a nil value, we should panic", func(t *testing.T) {
+		assert.Panics(t, func() {
+			UserVotes(nil, upvote.DefaultPolicy())
+		})
+	})
+
+	t.Run("when the userId is missing we should return a 400 error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := uservotesmocks.NewMockUserVotesDatabase(ctrl)
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetParamNames("userId")
+		ctx.SetParamValues("")
+
+		err := UserVotes(db, upvote.DefaultPolicy())(ctx)
+		assert.ErrorContains(t, err, "invalid UUID length: 0")
+		assert.Equal(t, http.StatusBadRequest, userVotesStatusCode(rec, err))
+	})
+
+	t.Run("when we get an unknown error from the db we should return a 500 error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := uservotesmocks.NewMockUserVotesDatabase(ctrl)
+
+		userId := uuid.New()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetParamNames("userId")
+		ctx.SetParamValues(userId.String())
+
+		db.EXPECT().GetVotesByVoter(userId).Return(nil, errors.New("some error"))
+
+		err := UserVotes(db, upvote.DefaultPolicy())(ctx)
+		assert.ErrorContains(t, err, "some error")
+		assert.Equal(t, http.StatusInternalServerError, userVotesStatusCode(rec, err))
+	})
+
+	t.Run("when the request is well formed, we should see the budget spent on each board", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := uservotesmocks.NewMockUserVotesDatabase(ctrl)
+
+		userId := uuid.New()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetParamNames("userId")
+		ctx.SetParamValues(userId.String())
+
+		castAt := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+		mixer1, mixer2, cloud := uuid.New(), uuid.New(), uuid.New()
+		db.EXPECT().GetVotesByVoter(userId).Return([]*domain.Vote{
+			{FeatureId: mixer1, VoterId: userId, Board: "mixer", Weight: 1, CastAt: castAt},
+			{FeatureId: cloud, VoterId: userId, Board: "cloud", Weight: 3, CastAt: castAt},
+			{FeatureId: mixer2, VoterId: userId, Board: "mixer", Weight: 2, CastAt: castAt},
+		}, nil)
+
+		err := UserVotes(db, upvote.Policy{Budget: 10, MaxWeight: 3, Quadratic: true})(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, userVotesStatusCode(rec, err))
+
+		one, five := 1, 5
+		expect := UserVotesResponse{
+			UserId: userId,
+			Boards: []BoardVotes{
+				{
+					Board:     "cloud",
+					Budget:    10,
+					Spent:     9,
+					Remaining: &one,
+					Votes: []VoteSummary{
+						{FeatureId: cloud, Weight: 3, Cost: 9, CastAt: castAt},
+					},
+				},
+				{
+					Board:     "mixer",
+					Budget:    10,
+					Spent:     5,
+					Remaining: &five,
+					Votes: []VoteSummary{
+						{FeatureId: mixer1, Weight: 1, Cost: 1, CastAt: castAt},
+						{FeatureId: mixer2, Weight: 2, Cost: 4, CastAt: castAt},
+					},
+				},
+			},
+		}
+
+		actual := UserVotesResponse{}
+		err = json.Unmarshal(rec.Body.Bytes(), &actual)
+		assert.NoError(t, err)
+		assert.Equal(t, expect, actual)
+	})
+}
+
+func userVotesStatusCode(rec *httptest.ResponseRecorder, err error) int {
+	if err == nil {
+		return rec.Code
+	}
+
+	hterr := &echo.HTTPError{}
+	if errors.As(err, &hterr) {
+		return hterr.Code
+	}
+
+	return 500
+}
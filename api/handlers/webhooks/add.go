@@ -0,0 +1,67 @@
+package webhooks
+
+import (
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/react-pairing-challenge/dto"
+	"github.com/music-tribe/react-pairing-challenge/webhook"
+)
+
+//go:generate mockgen -destination=./mocks/add.go -package=webhooksmocks -source=add.go
+type AddDatabase interface {
+	AddWebhook(webhook *domain.Webhook) error
+}
+
+// CreatedResponse is the only response that includes the webhook's secret.
+type CreatedResponse struct {
+	domain.Webhook
+	Secret string `json:"secret" example:"9f86d081884c7d659a2feaa0c55ad015"`
+}
+
+// Add godoc
+// @Summary Register a webhook.
+// @Description Register a URL to be sent signed events. The signing secret is returned once, in this response. Requires the admin API key.
+// @Accept application/json
+// @Produce application/json
+// @Security AdminKey
+// @Param webhook body dto.CreateWebhook true "Webhook"
+// @Router /api/admin/webhooks [post]
+// @Success 201 {object} CreatedResponse
+// @failure 400 {object} error
+// @failure 401 {object} error
+// @failure 500 {object} error
+func Add(db AddDatabase) func(echo.Context) error {
+	if db == nil {
+		panic("webhooks.Add: db has nil value")
+	}
+
+	return func(c echo.Context) error {
+		req := dto.CreateWebhook{}
+
+		if err := c.Bind(&req); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err)
+		}
+
+		if err := validator.New().Struct(&req); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err)
+		}
+
+		w := dto.NewWebhook(&req)
+		if w.Secret == "" {
+			secret, err := webhook.NewSecret()
+			if err != nil {
+				return echo.NewHTTPError(http.StatusInternalServerError, err)
+			}
+			w.Secret = secret
+		}
+
+		if err := db.AddWebhook(w); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err)
+		}
+
+		return c.JSON(http.StatusCreated, CreatedResponse{Webhook: *w, Secret: w.Secret})
+	}
+}
@@ -0,0 +1,128 @@
+package webhooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	webhooksmocks "github.com/music-tribe/react-pairing-challenge/handlers/webhooks/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdd(t *testing.T) {
+	e := echo.New()
+
+	newRequest := func(body string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte(body)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		return e.NewContext(req, rec), rec
+	}
+
+	t.Run("when the db has a nil value, we should panic", func(t *testing.T) {
+		assert.Panics(t, func() {
+			Add(nil)
+		})
+	})
+
+	t.Run("when the url is not an http url we should return a 400 error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := webhooksmocks.NewMockAddDatabase(ctrl)
+
+		ctx, rec := newRequest(`{"url":"ftp://example.com/hook"}`)
+
+		err := Add(db)(ctx)
+		assert.ErrorContains(t, err, "Error:Field validation for 'URL' failed on the 'http_url' tag")
+		assert.Equal(t, http.StatusBadRequest, getStatusCode(rec, err))
+	})
+
+	t.Run("when an event type is unknown we should return a 400 error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := webhooksmocks.NewMockAddDatabase(ctrl)
+
+		ctx, rec := newRequest(`{"url":"https://example.com/hook","eventTypes":["vote.cast","feature.exploded"]}`)
+
+		err := Add(db)(ctx)
+		assert.ErrorContains(t, err, "failed on the 'oneof' tag")
+		assert.Equal(t, http.StatusBadRequest, getStatusCode(rec, err))
+	})
+
+	t.Run("when the db fails we should return a 500 error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := webhooksmocks.NewMockAddDatabase(ctrl)
+
+		ctx, rec := newRequest(`{"url":"https://example.com/hook"}`)
+
+		errBoom := errors.New("boom")
+		db.EXPECT().AddWebhook(gomock.Any()).Return(errBoom)
+
+		err := Add(db)(ctx)
+		assert.ErrorContains(t, err, errBoom.Error())
+		assert.Equal(t, http.StatusInternalServerError, getStatusCode(rec, err))
+	})
+
+	t.Run("when no secret is given, one should be generated and returned once", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := webhooksmocks.NewMockAddDatabase(ctrl)
+
+		ctx, rec := newRequest(`{"url":"https://example.com/hook","eventTypes":["vote.cast"]}`)
+
+		var saved *domain.Webhook
+		db.EXPECT().AddWebhook(gomock.Any()).DoAndReturn(func(w *domain.Webhook) error {
+			saved = w
+			return nil
+		})
+
+		err := Add(db)(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, getStatusCode(rec, err))
+		assert.Len(t, saved.Secret, 64)
+		assert.True(t, saved.Active)
+		assert.Equal(t, []domain.EventType{domain.VoteCast}, saved.EventTypes)
+
+		res := map[string]interface{}{}
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &res))
+		assert.Equal(t, saved.Secret, res["secret"])
+	})
+
+	t.Run("when a secret and active flag are given, they should be kept", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := webhooksmocks.NewMockAddDatabase(ctrl)
+
+		ctx, rec := newRequest(`{"url":"https://example.com/hook","secret":"0123456789abcdef","active":false}`)
+
+		db.EXPECT().AddWebhook(gomock.Any()).DoAndReturn(func(w *domain.Webhook) error {
+			assert.Equal(t, "0123456789abcdef", w.Secret)
+			assert.False(t, w.Active)
+			return nil
+		})
+
+		err := Add(db)(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, getStatusCode(rec, err))
+	})
+}
+
+func getStatusCode(rec *httptest.ResponseRecorder, err error) int {
+	if err == nil {
+		return rec.Code
+	}
+
+	hterr := &echo.HTTPError{}
+	if errors.As(err, &hterr) {
+		return hterr.Code
+	}
+
+	return 500
+}
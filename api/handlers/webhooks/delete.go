@@ -0,0 +1,54 @@
+package webhooks
+
+import (
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/music-tribe/react-pairing-challenge/database"
+	"github.com/music-tribe/uuid"
+)
+
+//go:generate mockgen -destination=./mocks/delete.go -package=webhooksmocks -source=delete.go
+type DeleteDatabase interface {
+	DeleteWebhook(webhookId uuid.UUID) error
+}
+
+// Delete godoc
+// @Summary Delete a webhook.
+// @Description Delete a webhook and its delivery log. Queued deliveries are dropped. Requires the admin API key.
+// @Produce text/plain
+// @Security AdminKey
+// @Param webhookId path string true "Webhook UUID"
+// @Router /api/admin/webhooks/{webhookId} [delete]
+// @Success 200 {string} string "DELETED"
+// @failure 400 {object} error
+// @failure 401 {object} error
+// @failure 404 {object} error
+// @failure 500 {object} error
+func Delete(db DeleteDatabase) func(echo.Context) error {
+	if db == nil {
+		panic("webhooks.Delete: db has nil value")
+	}
+
+	return func(c echo.Context) error {
+		req := WebhookRequest{}
+
+		if err := c.Bind(&req); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err)
+		}
+
+		if err := validator.New().Struct(&req); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err)
+		}
+
+		if err := db.DeleteWebhook(req.WebhookId); err != nil {
+			if err == database.ErrNotFound {
+				return echo.NewHTTPError(http.StatusNotFound, err)
+			}
+			return echo.NewHTTPError(http.StatusInternalServerError, err)
+		}
+
+		return c.String(http.StatusOK, "DELETED")
+	}
+}
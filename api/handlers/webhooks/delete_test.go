@@ -0,0 +1,64 @@
+package webhooks
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/music-tribe/react-pairing-challenge/database"
+	webhooksmocks "github.com/music-tribe/react-pairing-challenge/handlers/webhooks/mocks"
+	"github.com/music-tribe/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDelete(t *testing.T) {
+	e := echo.New()
+
+	newRequest := func(webhookId string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodDelete, "/", nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetParamNames("webhookId")
+		ctx.SetParamValues(webhookId)
+		return ctx, rec
+	}
+
+	t.Run("when the db has a nil value, we should panic", func(t *testing.T) {
+		assert.Panics(t, func() {
+			Delete(nil)
+		})
+	})
+
+	t.Run("when the webhook doesn't exist we should return a 404 error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := webhooksmocks.NewMockDeleteDatabase(ctrl)
+
+		id := uuid.New()
+		ctx, rec := newRequest(id.String())
+
+		db.EXPECT().DeleteWebhook(id).Return(database.ErrNotFound)
+
+		err := Delete(db)(ctx)
+		assert.ErrorContains(t, err, database.ErrNotFound.Error())
+		assert.Equal(t, http.StatusNotFound, getStatusCode(rec, err))
+	})
+
+	t.Run("when the webhook is deleted we should return a 200", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := webhooksmocks.NewMockDeleteDatabase(ctrl)
+
+		id := uuid.New()
+		ctx, rec := newRequest(id.String())
+
+		db.EXPECT().DeleteWebhook(id).Return(nil)
+
+		err := Delete(db)(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, getStatusCode(rec, err))
+		assert.Equal(t, "DELETED", rec.Body.String())
+	})
+}
@@ -0,0 +1,75 @@
+package webhooks
+
+import (
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/music-tribe/react-pairing-challenge/database"
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/uuid"
+)
+
+// DefaultDeliveryLimit is how many deliveries are returned when the client
+// doesn't ask for a number.
+const DefaultDeliveryLimit = 50
+
+//go:generate mockgen -destination=./mocks/deliveries.go -package=webhooksmocks -source=deliveries.go
+type DeliveriesDatabase interface {
+	GetWebhook(webhookId uuid.UUID) (*domain.Webhook, error)
+	GetWebhookDeliveries(webhookId uuid.UUID, limit int64) ([]*domain.WebhookDelivery, error)
+}
+
+type DeliveriesRequest struct {
+	WebhookId uuid.UUID `param:"webhookId" validate:"required" example:"5a1c9e3b-7d2f-4e6a-8b0c-1d2e3f4a5b6c"`
+	Limit     int64     `query:"limit" validate:"min=0,max=500" example:"50"`
+}
+
+// Deliveries godoc
+// @Summary Get a webhook's delivery log.
+// @Description Get a webhook's most recent deliveries, newest first, with the response code of every attempt. Requires the admin API key.
+// @Produce application/json
+// @Security AdminKey
+// @Param webhookId path string true "Webhook UUID"
+// @Param limit query int false "Most deliveries to return, defaults to 50"
+// @Router /api/admin/webhooks/{webhookId}/deliveries [get]
+// @Success 200 {object} []domain.WebhookDelivery
+// @failure 400 {object} error
+// @failure 401 {object} error
+// @failure 404 {object} error
+// @failure 500 {object} error
+func Deliveries(db DeliveriesDatabase) func(echo.Context) error {
+	if db == nil {
+		panic("webhooks.Deliveries: db has nil value")
+	}
+
+	return func(c echo.Context) error {
+		req := DeliveriesRequest{}
+
+		if err := c.Bind(&req); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err)
+		}
+
+		if err := validator.New().Struct(&req); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err)
+		}
+
+		if req.Limit == 0 {
+			req.Limit = DefaultDeliveryLimit
+		}
+
+		if _, err := db.GetWebhook(req.WebhookId); err != nil {
+			if err == database.ErrNotFound {
+				return echo.NewHTTPError(http.StatusNotFound, err)
+			}
+			return echo.NewHTTPError(http.StatusInternalServerError, err)
+		}
+
+		ds, err := db.GetWebhookDeliveries(req.WebhookId, req.Limit)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err)
+		}
+
+		return c.JSON(http.StatusOK, ds)
+	}
+}
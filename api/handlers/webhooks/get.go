@@ -0,0 +1,60 @@
+package webhooks
+
+import (
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/music-tribe/react-pairing-challenge/database"
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/uuid"
+)
+
+//go:generate mockgen -destination=./mocks/get.go -package=webhooksmocks -source=get.go
+type GetDatabase interface {
+	GetWebhook(webhookId uuid.UUID) (*domain.Webhook, error)
+}
+
+type WebhookRequest struct {
+	WebhookId uuid.UUID `param:"webhookId" validate:"required" example:"5a1c9e3b-7d2f-4e6a-8b0c-1d2e3f4a5b6c"`
+}
+
+// Get godoc
+// @Summary Get a webhook.
+// @Description Get a single webhook. Its secret is never included. Requires the admin API key.
+// @Produce application/json
+// @Security AdminKey
+// @Param webhookId path string true "Webhook UUID"
+// @Router /api/admin/webhooks/{webhookId} [get]
+// @Success 200 {object} domain.Webhook
+// @failure 400 {object} error
+// @failure 401 {object} error
+// @failure 404 {object} error
+// @failure 500 {object} error
+func Get(db GetDatabase) func(echo.Context) error {
+	if db == nil {
+		panic("webhooks.Get: db has nil value")
+	}
+
+	return func(c echo.Context) error {
+		req := WebhookRequest{}
+
+		if err := c.Bind(&req); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err)
+		}
+
+		if err := validator.New().Struct(&req); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err)
+		}
+
+		w, err := db.GetWebhook(req.WebhookId)
+		if err != nil {
+			if err == database.ErrNotFound {
+				return echo.NewHTTPError(http.StatusNotFound, err)
+			}
+			return echo.NewHTTPError(http.StatusInternalServerError, err)
+		}
+
+		return c.JSON(http.StatusOK, w)
+	}
+}
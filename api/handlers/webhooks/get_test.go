@@ -0,0 +1,76 @@
+package webhooks
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/music-tribe/react-pairing-challenge/database"
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	webhooksmocks "github.com/music-tribe/react-pairing-challenge/handlers/webhooks/mocks"
+	"github.com/music-tribe/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGet(t *testing.T) {
+	e := echo.New()
+
+	newRequest := func(webhookId string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetParamNames("webhookId")
+		ctx.SetParamValues(webhookId)
+		return ctx, rec
+	}
+
+	t.Run("when the db has a nil value, we should panic", func(t *testing.T) {
+		assert.Panics(t, func() {
+			Get(nil)
+		})
+	})
+
+	t.Run("when the webhook id is not a uuid we should return a 400 error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := webhooksmocks.NewMockGetDatabase(ctrl)
+
+		ctx, rec := newRequest("not-a-uuid")
+
+		err := Get(db)(ctx)
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusBadRequest, getStatusCode(rec, err))
+	})
+
+	t.Run("when the webhook doesn't exist we should return a 404 error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := webhooksmocks.NewMockGetDatabase(ctrl)
+
+		id := uuid.New()
+		ctx, rec := newRequest(id.String())
+
+		db.EXPECT().GetWebhook(id).Return(nil, database.ErrNotFound)
+
+		err := Get(db)(ctx)
+		assert.ErrorContains(t, err, database.ErrNotFound.Error())
+		assert.Equal(t, http.StatusNotFound, getStatusCode(rec, err))
+	})
+
+	t.Run("when the webhook exists we should return a 200", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := webhooksmocks.NewMockGetDatabase(ctrl)
+
+		id := uuid.New()
+		ctx, rec := newRequest(id.String())
+
+		db.EXPECT().GetWebhook(id).Return(&domain.Webhook{Id: id}, nil)
+
+		err := Get(db)(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, getStatusCode(rec, err))
+	})
+}
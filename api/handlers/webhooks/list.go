@@ -0,0 +1,37 @@
+package webhooks
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/music-tribe/react-pairing-challenge/domain"
+)
+
+//go:generate mockgen -destination=./mocks/list.go -package=webhooksmocks -source=list.go
+type ListDatabase interface {
+	GetWebhooks() ([]*domain.Webhook, error)
+}
+
+// List godoc
+// @Summary List the webhooks.
+// @Description List every registered webhook. Secrets are never included. Requires the admin API key.
+// @Produce application/json
+// @Security AdminKey
+// @Router /api/admin/webhooks [get]
+// @Success 200 {object} []domain.Webhook
+// @failure 401 {object} error
+// @failure 500 {object} error
+func List(db ListDatabase) func(echo.Context) error {
+	if db == nil {
+		panic("webhooks.List: db has nil value")
+	}
+
+	return func(c echo.Context) error {
+		ws, err := db.GetWebhooks()
+		if err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err)
+		}
+
+		return c.JSON(http.StatusOK, ws)
+	}
+}
@@ -0,0 +1,61 @@
+package webhooks
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	webhooksmocks "github.com/music-tribe/react-pairing-challenge/handlers/webhooks/mocks"
+	"github.com/music-tribe/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestList(t *testing.T) {
+	e := echo.New()
+
+	t.Run("when the db has a nil value, we should panic", func(t *testing.T) {
+		assert.Panics(t, func() {
+			List(nil)
+		})
+	})
+
+	t.Run("when the db fails we should return a 500 error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := webhooksmocks.NewMockListDatabase(ctrl)
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+
+		errBoom := errors.New("boom")
+		db.EXPECT().GetWebhooks().Return(nil, errBoom)
+
+		err := List(db)(ctx)
+		assert.ErrorContains(t, err, errBoom.Error())
+		assert.Equal(t, http.StatusInternalServerError, getStatusCode(rec, err))
+	})
+
+	t.Run("when webhooks are listed, their secrets should not be included", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := webhooksmocks.NewMockListDatabase(ctrl)
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+
+		db.EXPECT().GetWebhooks().Return([]*domain.Webhook{{Id: uuid.New(), URL: "https://example.com/hook", Secret: "topsecretvalue"}}, nil)
+
+		err := List(db)(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, getStatusCode(rec, err))
+		assert.Contains(t, rec.Body.String(), "https://example.com/hook")
+		assert.False(t, strings.Contains(rec.Body.String(), "topsecretvalue"))
+	})
+}
@@ -0,0 +1,96 @@
+package webhooks
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/music-tribe/react-pairing-challenge/database"
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/uuid"
+)
+
+//go:generate mockgen -destination=./mocks/sendtest.go -package=webhooksmocks -source=sendtest.go
+type SendTestDatabase interface {
+	GetWebhook(webhookId uuid.UUID) (*domain.Webhook, error)
+	AddWebhookDelivery(delivery *domain.WebhookDelivery) error
+	RecordWebhookAttempt(deliveryId uuid.UUID, attempt domain.DeliveryAttempt, status domain.DeliveryStatus, next time.Time) error
+}
+
+type Sender interface {
+	Send(ctx context.Context, webhook *domain.Webhook, deliveryId uuid.UUID, event *domain.Event) domain.DeliveryAttempt
+}
+
+// SendTest godoc
+// @Summary Send a test event to a webhook.
+// @Description Send a signed webhook.test event straight away, even to an inactive webhook, and return the delivery with the receiver's response. Test deliveries are not retried. Requires the admin API key.
+// @Produce application/json
+// @Security AdminKey
+// @Param webhookId path string true "Webhook UUID"
+// @Router /api/admin/webhooks/{webhookId}/test [post]
+// @Success 200 {object} domain.WebhookDelivery
+// @failure 400 {object} error
+// @failure 401 {object} error
+// @failure 404 {object} error
+// @failure 500 {object} error
+func SendTest(db SendTestDatabase, sender Sender) func(echo.Context) error {
+	if db == nil {
+		panic("webhooks.SendTest: db has nil value")
+	}
+	if sender == nil {
+		panic("webhooks.SendTest: sender has nil value")
+	}
+
+	return func(c echo.Context) error {
+		req := WebhookRequest{}
+
+		if err := c.Bind(&req); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err)
+		}
+
+		if err := validator.New().Struct(&req); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err)
+		}
+
+		w, err := db.GetWebhook(req.WebhookId)
+		if err != nil {
+			if err == database.ErrNotFound {
+				return echo.NewHTTPError(http.StatusNotFound, err)
+			}
+			return echo.NewHTTPError(http.StatusInternalServerError, err)
+		}
+
+		now := time.Now().UTC()
+		delivery := &domain.WebhookDelivery{
+			Id:        uuid.New(),
+			WebhookId: w.Id,
+			Event: domain.Event{
+				Id:         uuid.New(),
+				Type:       domain.WebhookTest,
+				Actor:      "admin",
+				OccurredAt: now,
+			},
+			Status:        domain.DeliveryPending,
+			NextAttemptAt: now,
+		}
+		if err := db.AddWebhookDelivery(delivery); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err)
+		}
+
+		attempt := sender.Send(c.Request().Context(), w, delivery.Id, &delivery.Event)
+		delivery.Status = domain.DeliveryFailed
+		if attempt.Succeeded() {
+			delivery.Status = domain.DeliverySucceeded
+		}
+		if err := db.RecordWebhookAttempt(delivery.Id, attempt, delivery.Status, attempt.At); err != nil {
+			return echo.NewHTTPError(http.StatusInternalServerError, err)
+		}
+
+		delivery.Attempts = []domain.DeliveryAttempt{attempt}
+		delivery.CompletedAt = &attempt.At
+
+		return c.JSON(http.StatusOK, delivery)
+	}
+}
@@ -0,0 +1,107 @@
+package webhooks
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/music-tribe/react-pairing-challenge/database"
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	webhooksmocks "github.com/music-tribe/react-pairing-challenge/handlers/webhooks/mocks"
+	"github.com/music-tribe/react-pairing-challenge/webhook"
+	"github.com/music-tribe/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSendTest(t *testing.T) {
+	e := echo.New()
+
+	newRequest := func(webhookId string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetParamNames("webhookId")
+		ctx.SetParamValues(webhookId)
+		return ctx, rec
+	}
+
+	t.Run("when the db or sender has a nil value, we should panic", func(t *testing.T) {
+		assert.Panics(t, func() {
+			SendTest(nil, webhook.NewSender(nil))
+		})
+		assert.Panics(t, func() {
+			SendTest(webhooksmocks.NewMockSendTestDatabase(gomock.NewController(t)), nil)
+		})
+	})
+
+	t.Run("when the webhook doesn't exist we should return a 404 error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := webhooksmocks.NewMockSendTestDatabase(ctrl)
+
+		id := uuid.New()
+		ctx, rec := newRequest(id.String())
+
+		db.EXPECT().GetWebhook(id).Return(nil, database.ErrNotFound)
+
+		err := SendTest(db, webhook.NewSender(nil))(ctx)
+		assert.ErrorContains(t, err, database.ErrNotFound.Error())
+		assert.Equal(t, http.StatusNotFound, getStatusCode(rec, err))
+	})
+
+	t.Run("when the receiver accepts the test event, the delivery should be recorded as succeeded", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := webhooksmocks.NewMockSendTestDatabase(ctrl)
+
+		var gotType string
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			gotType = r.Header.Get(webhook.HeaderEventType)
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer srv.Close()
+
+		hook := &domain.Webhook{Id: uuid.New(), URL: srv.URL, Secret: "secret"}
+		ctx, rec := newRequest(hook.Id.String())
+
+		db.EXPECT().GetWebhook(hook.Id).Return(hook, nil)
+		db.EXPECT().AddWebhookDelivery(gomock.Any()).Return(nil)
+		db.EXPECT().RecordWebhookAttempt(gomock.Any(), gomock.Any(), domain.DeliverySucceeded, gomock.Any()).
+			DoAndReturn(func(_ uuid.UUID, a domain.DeliveryAttempt, _ domain.DeliveryStatus, _ time.Time) error {
+				assert.Equal(t, http.StatusOK, a.StatusCode)
+				return nil
+			})
+
+		err := SendTest(db, webhook.NewSender(nil))(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, getStatusCode(rec, err))
+		assert.Equal(t, string(domain.WebhookTest), gotType)
+		assert.Contains(t, rec.Body.String(), `"status":"succeeded"`)
+	})
+
+	t.Run("when the receiver rejects the test event, the delivery should be recorded as failed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := webhooksmocks.NewMockSendTestDatabase(ctrl)
+
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer srv.Close()
+
+		hook := &domain.Webhook{Id: uuid.New(), URL: srv.URL}
+		ctx, rec := newRequest(hook.Id.String())
+
+		db.EXPECT().GetWebhook(hook.Id).Return(hook, nil)
+		db.EXPECT().AddWebhookDelivery(gomock.Any()).Return(nil)
+		db.EXPECT().RecordWebhookAttempt(gomock.Any(), gomock.Any(), domain.DeliveryFailed, gomock.Any()).Return(nil)
+
+		err := SendTest(db, webhook.NewSender(nil))(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, getStatusCode(rec, err))
+		assert.Contains(t, rec.Body.String(), `"statusCode":401`)
+	})
+}
@@ -0,0 +1,59 @@
+package webhooks
+
+import (
+	"net/http"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/music-tribe/react-pairing-challenge/database"
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/react-pairing-challenge/dto"
+)
+
+//go:generate mockgen -destination=./mocks/update.go -package=webhooksmocks -source=update.go
+type UpdateDatabase interface {
+	UpdateWebhook(webhook *domain.Webhook) error
+}
+
+// Update godoc
+// @Summary Update a webhook.
+// @Description Change a webhook's URL, event types or active flag. Giving a secret rotates it. Requires the admin API key.
+// @Accept application/json
+// @Produce application/json
+// @Security AdminKey
+// @Param webhookId path string true "Webhook UUID"
+// @Param webhook body dto.UpdateWebhook true "Webhook"
+// @Router /api/admin/webhooks/{webhookId} [put]
+// @Success 200 {object} domain.Webhook
+// @failure 400 {object} error
+// @failure 401 {object} error
+// @failure 404 {object} error
+// @failure 500 {object} error
+func Update(db UpdateDatabase) func(echo.Context) error {
+	if db == nil {
+		panic("webhooks.Update: db has nil value")
+	}
+
+	return func(c echo.Context) error {
+		req := dto.UpdateWebhook{}
+
+		if err := c.Bind(&req); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err)
+		}
+
+		if err := validator.New().Struct(&req); err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest, err)
+		}
+
+		w := dto.UpdatedWebhook(&req)
+
+		if err := db.UpdateWebhook(w); err != nil {
+			if err == database.ErrNotFound {
+				return echo.NewHTTPError(http.StatusNotFound, err)
+			}
+			return echo.NewHTTPError(http.StatusInternalServerError, err)
+		}
+
+		return c.JSON(http.StatusOK, w)
+	}
+}
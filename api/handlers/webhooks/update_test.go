@@ -0,0 +1,81 @@
+package webhooks
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/music-tribe/react-pairing-challenge/database"
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	webhooksmocks "github.com/music-tribe/react-pairing-challenge/handlers/webhooks/mocks"
+	"github.com/music-tribe/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdate(t *testing.T) {
+	e := echo.New()
+
+	newRequest := func(webhookId, body string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodPut, "/", bytes.NewReader([]byte(body)))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.SetParamNames("webhookId")
+		ctx.SetParamValues(webhookId)
+		return ctx, rec
+	}
+
+	t.Run("when the db has a nil value, we should panic", func(t *testing.T) {
+		assert.Panics(t, func() {
+			Update(nil)
+		})
+	})
+
+	t.Run("when the secret is too short we should return a 400 error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := webhooksmocks.NewMockUpdateDatabase(ctrl)
+
+		ctx, rec := newRequest(uuid.New().String(), `{"url":"https://example.com/hook","secret":"short"}`)
+
+		err := Update(db)(ctx)
+		assert.ErrorContains(t, err, "Error:Field validation for 'Secret' failed on the 'min' tag")
+		assert.Equal(t, http.StatusBadRequest, getStatusCode(rec, err))
+	})
+
+	t.Run("when the webhook doesn't exist we should return a 404 error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := webhooksmocks.NewMockUpdateDatabase(ctrl)
+
+		ctx, rec := newRequest(uuid.New().String(), `{"url":"https://example.com/hook"}`)
+
+		db.EXPECT().UpdateWebhook(gomock.Any()).Return(database.ErrNotFound)
+
+		err := Update(db)(ctx)
+		assert.ErrorContains(t, err, database.ErrNotFound.Error())
+		assert.Equal(t, http.StatusNotFound, getStatusCode(rec, err))
+	})
+
+	t.Run("when the request is healthy, the webhook should be saved and a 200 returned", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := webhooksmocks.NewMockUpdateDatabase(ctrl)
+
+		id := uuid.New()
+		ctx, rec := newRequest(id.String(), `{"url":"https://example.com/other","eventTypes":["feature.created"],"active":false}`)
+
+		db.EXPECT().UpdateWebhook(&domain.Webhook{
+			Id:         id,
+			URL:        "https://example.com/other",
+			EventTypes: []domain.EventType{domain.FeatureCreated},
+		}).Return(nil)
+
+		err := Update(db)(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, getStatusCode(rec, err))
+	})
+}
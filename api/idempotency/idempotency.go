@@ -0,0 +1,197 @@
+// Package idempotency lets clients safely retry requests by sending an
+// Idempotency-Key header: the first response is stored and replayed to any
+// retry instead of running the request again.
+package idempotency
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/music-tribe/react-pairing-challenge/database"
+	"github.com/music-tribe/react-pairing-challenge/domain"
+)
+
+const (
+	HeaderKey = "Idempotency-Key"
+	// HeaderReplayed is set on responses replayed from an earlier request.
+	HeaderReplayed = "Idempotent-Replayed"
+
+	DefaultTTL         = 24 * time.Hour
+	DefaultLockTimeout = time.Minute
+	MaxKeyLength       = 255
+	// MaxBodyBytes bounds the requests that may be made idempotently.
+	MaxBodyBytes = 1 << 20
+)
+
+type Store interface {
+	ReserveIdempotencyKey(req *domain.IdempotentRequest) error
+	GetIdempotencyKey(key string) (*domain.IdempotentRequest, error)
+	CompleteIdempotencyKey(req *domain.IdempotentRequest) error
+	ReleaseIdempotencyKey(req *domain.IdempotentRequest) error
+}
+
+type Config struct {
+	Store Store
+	// TTL is how long a response is kept for replay. It defaults to
+	// DefaultTTL.
+	TTL time.Duration
+	// LockTimeout is how long a request may hold its key before a retry can
+	// take it over. It defaults to DefaultLockTimeout.
+	LockTimeout time.Duration
+	// Now is swapped out in tests; it defaults to time.Now.
+	Now func() time.Time
+}
+
+var (
+	errKeyTooLong   = errors.New("Idempotency-Key must be at most 255 characters")
+	errBodyTooLarge = errors.New("request body is too large to be made idempotent")
+	errKeyReused    = errors.New("Idempotency-Key has already been used for a different request")
+	errInProgress   = errors.New("a request with this Idempotency-Key is still being handled")
+)
+
+// New returns middleware making requests with an Idempotency-Key header
+// idempotent. Keys are scoped to the method and path they are sent to.
+//
+// Only successful responses are stored: when the handler fails the key is
+// released, so a retry runs the request again. A retry made while the first
+// request is still running gets a 409, and reusing a key for a request with
+// a different body or query gets a 422.
+func New(cfg Config) echo.MiddlewareFunc {
+	if cfg.Store == nil {
+		panic("idempotency.New: store has nil value")
+	}
+	if cfg.TTL <= 0 {
+		cfg.TTL = DefaultTTL
+	}
+	if cfg.LockTimeout <= 0 {
+		cfg.LockTimeout = DefaultLockTimeout
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			key := c.Request().Header.Get(HeaderKey)
+			if key == "" {
+				return next(c)
+			}
+			if len(key) > MaxKeyLength {
+				return echo.NewHTTPError(http.StatusBadRequest, errKeyTooLong)
+			}
+
+			fingerprint, err := fingerprint(c.Request())
+			if err != nil {
+				return err
+			}
+
+			r := c.Request()
+			req := &domain.IdempotentRequest{
+				Key:         r.Method + " " + r.URL.Path + " " + key,
+				Fingerprint: fingerprint,
+			}
+
+			// a key released or expired between reserving and reading it
+			// is reserved afresh
+			for attempt := 0; ; attempt++ {
+				req.ExpiresAt = cfg.Now().Add(cfg.LockTimeout)
+				err = cfg.Store.ReserveIdempotencyKey(req)
+				if err != database.ErrDuplicate {
+					break
+				}
+
+				prev, err := cfg.Store.GetIdempotencyKey(req.Key)
+				if err == database.ErrNotFound && attempt == 0 {
+					continue
+				}
+				if err != nil {
+					return echo.NewHTTPError(http.StatusInternalServerError, err)
+				}
+
+				return replay(c, req, prev)
+			}
+			if err != nil {
+				return echo.NewHTTPError(http.StatusInternalServerError, err)
+			}
+
+			res := c.Response()
+			rec := &recorder{ResponseWriter: res.Writer}
+			res.Writer = rec
+			defer func() { res.Writer = rec.ResponseWriter }()
+
+			if err := next(c); err != nil || res.Status >= http.StatusInternalServerError {
+				if relErr := cfg.Store.ReleaseIdempotencyKey(req); relErr != nil {
+					c.Logger().Errorf("idempotency: release %q >> %v", req.Key, relErr)
+				}
+				return err
+			}
+
+			req.Status = res.Status
+			req.ContentType = res.Header().Get(echo.HeaderContentType)
+			req.Body = rec.body.Bytes()
+			req.ExpiresAt = cfg.Now().Add(cfg.TTL)
+			if err := cfg.Store.CompleteIdempotencyKey(req); err != nil {
+				// the response has gone out; a retry will run the request
+				// again once the lock times out
+				c.Logger().Errorf("idempotency: complete %q >> %v", req.Key, err)
+			}
+
+			return nil
+		}
+	}
+}
+
+// fingerprint hashes what makes a request distinct under its key: its query
+// and body. The body is left for the handler to read.
+func fingerprint(r *http.Request) (string, error) {
+	h := sha256.New()
+	io.WriteString(h, r.URL.RawQuery)
+	h.Write([]byte{0})
+
+	if r.Body != nil {
+		body, err := io.ReadAll(io.LimitReader(r.Body, MaxBodyBytes+1))
+		if err != nil {
+			return "", echo.NewHTTPError(http.StatusBadRequest, err)
+		}
+		if len(body) > MaxBodyBytes {
+			return "", echo.NewHTTPError(http.StatusRequestEntityTooLarge, errBodyTooLarge)
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		h.Write(body)
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func replay(c echo.Context, req, prev *domain.IdempotentRequest) error {
+	if prev.Fingerprint != req.Fingerprint {
+		return echo.NewHTTPError(http.StatusUnprocessableEntity, errKeyReused)
+	}
+	if !prev.Completed() {
+		c.Response().Header().Set(echo.HeaderRetryAfter, "1")
+		return echo.NewHTTPError(http.StatusConflict, errInProgress)
+	}
+
+	c.Response().Header().Set(HeaderReplayed, "true")
+	if prev.ContentType == "" {
+		return c.NoContent(prev.Status)
+	}
+	return c.Blob(prev.Status, prev.ContentType, prev.Body)
+}
+
+// recorder keeps a copy of the response body as it is written.
+type recorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
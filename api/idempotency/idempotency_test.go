@@ -0,0 +1,232 @@
+package idempotency
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/music-tribe/react-pairing-challenge/database"
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+// memStore keeps keys the way the database does, in memory.
+type memStore struct {
+	mu   sync.Mutex
+	now  func() time.Time
+	reqs map[string]domain.IdempotentRequest
+}
+
+func newMemStore(now func() time.Time) *memStore {
+	return &memStore{now: now, reqs: map[string]domain.IdempotentRequest{}}
+}
+
+func (s *memStore) ReserveIdempotencyKey(req *domain.IdempotentRequest) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	req.CreatedAt = s.now()
+	if prev, ok := s.reqs[req.Key]; ok && prev.ExpiresAt.After(req.CreatedAt) {
+		return database.ErrDuplicate
+	}
+	s.reqs[req.Key] = *req
+	return nil
+}
+
+func (s *memStore) GetIdempotencyKey(key string) (*domain.IdempotentRequest, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	req, ok := s.reqs[key]
+	if !ok {
+		return nil, database.ErrNotFound
+	}
+	return &req, nil
+}
+
+func (s *memStore) CompleteIdempotencyKey(req *domain.IdempotentRequest) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if prev, ok := s.reqs[req.Key]; !ok || !prev.CreatedAt.Equal(req.CreatedAt) {
+		return database.ErrNotFound
+	}
+	s.reqs[req.Key] = *req
+	return nil
+}
+
+func (s *memStore) ReleaseIdempotencyKey(req *domain.IdempotentRequest) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if prev, ok := s.reqs[req.Key]; ok && prev.CreatedAt.Equal(req.CreatedAt) && !prev.Completed() {
+		delete(s.reqs, req.Key)
+	}
+	return nil
+}
+
+func TestNew(t *testing.T) {
+	now := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	// serve sends a POST through mw to a handler counting its calls
+	var calls int32
+	created := func(c echo.Context) error {
+		n := atomic.AddInt32(&calls, 1)
+		return c.JSON(http.StatusCreated, map[string]int32{"n": n})
+	}
+	serve := func(mw echo.MiddlewareFunc, h echo.HandlerFunc, key, body string) (*httptest.ResponseRecorder, error) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/api/202c25c4-b2ce-4514-9045-890a1aa896ea", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		if key != "" {
+			req.Header.Set(HeaderKey, key)
+		}
+		rec := httptest.NewRecorder()
+		err := mw(h)(e.NewContext(req, rec))
+		return rec, err
+	}
+
+	t.Run("when the store has a nil value, we should panic", func(t *testing.T) {
+		assert.Panics(t, func() {
+			New(Config{})
+		})
+	})
+
+	t.Run("when no key is sent, the request should always be handled", func(t *testing.T) {
+		calls = 0
+		mw := New(Config{Store: newMemStore(clock), Now: clock})
+
+		serve(mw, created, "", `{"name":"a"}`)
+		rec, err := serve(mw, created, "", `{"name":"a"}`)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"n":2}`+"\n", rec.Body.String())
+	})
+
+	t.Run("when a request is retried with its key, we should replay the first response", func(t *testing.T) {
+		calls = 0
+		mw := New(Config{Store: newMemStore(clock), Now: clock})
+
+		first, err := serve(mw, created, "k1", `{"name":"a"}`)
+		assert.NoError(t, err)
+		assert.Empty(t, first.Header().Get(HeaderReplayed))
+
+		retry, err := serve(mw, created, "k1", `{"name":"a"}`)
+		assert.NoError(t, err)
+		assert.Equal(t, int32(1), calls)
+		assert.Equal(t, http.StatusCreated, retry.Code)
+		assert.Equal(t, first.Body.String(), retry.Body.String())
+		assert.Equal(t, first.Header().Get(echo.HeaderContentType), retry.Header().Get(echo.HeaderContentType))
+		assert.Equal(t, "true", retry.Header().Get(HeaderReplayed))
+	})
+
+	t.Run("when a key is reused with a different body, we should return a 422", func(t *testing.T) {
+		calls = 0
+		mw := New(Config{Store: newMemStore(clock), Now: clock})
+
+		serve(mw, created, "k1", `{"name":"a"}`)
+		rec, err := serve(mw, created, "k1", `{"name":"b"}`)
+		assert.ErrorContains(t, err, errKeyReused.Error())
+		assert.Equal(t, http.StatusUnprocessableEntity, getStatusCode(rec, err))
+		assert.Equal(t, int32(1), calls)
+	})
+
+	t.Run("when a key has expired, the request should be handled again", func(t *testing.T) {
+		calls = 0
+		at := now
+		later := func() time.Time { return at }
+		mw := New(Config{Store: newMemStore(later), TTL: time.Hour, Now: later})
+
+		serve(mw, created, "k1", `{"name":"a"}`)
+		at = at.Add(time.Hour)
+		rec, err := serve(mw, created, "k1", `{"name":"a"}`)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"n":2}`+"\n", rec.Body.String())
+	})
+
+	t.Run("when the handler fails, the key should be released for a retry", func(t *testing.T) {
+		calls = 0
+		mw := New(Config{Store: newMemStore(clock), Now: clock})
+		failing := func(c echo.Context) error {
+			return echo.NewHTTPError(http.StatusInternalServerError, errors.New("db down"))
+		}
+
+		_, err := serve(mw, failing, "k1", `{"name":"a"}`)
+		assert.ErrorContains(t, err, "db down")
+
+		rec, err := serve(mw, created, "k1", `{"name":"a"}`)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Empty(t, rec.Header().Get(HeaderReplayed))
+	})
+
+	t.Run("when the key is too long, we should return a 400", func(t *testing.T) {
+		mw := New(Config{Store: newMemStore(clock), Now: clock})
+
+		rec, err := serve(mw, created, strings.Repeat("k", MaxKeyLength+1), `{}`)
+		assert.Equal(t, http.StatusBadRequest, getStatusCode(rec, err))
+	})
+
+	t.Run("when duplicates arrive together, the request should be handled once", func(t *testing.T) {
+		calls = 0
+		mw := New(Config{Store: newMemStore(clock), Now: clock})
+
+		release := make(chan struct{})
+		slow := func(c echo.Context) error {
+			<-release
+			return created(c)
+		}
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			rec, err := serve(mw, slow, "k1", `{"name":"a"}`)
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusCreated, rec.Code)
+		}()
+
+		// wait for the first request to hold the key
+		assert.Eventually(t, func() bool {
+			rec, err := serve(mw, created, "k1", `{"name":"a"}`)
+			return getStatusCode(rec, err) == http.StatusConflict && rec.Header().Get(echo.HeaderRetryAfter) == "1"
+		}, time.Second, time.Millisecond)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				rec, err := serve(mw, created, "k1", `{"name":"a"}`)
+				assert.Equal(t, http.StatusConflict, getStatusCode(rec, err))
+			}()
+		}
+		wg.Wait()
+
+		close(release)
+		<-done
+
+		rec, err := serve(mw, created, "k1", `{"name":"a"}`)
+		assert.NoError(t, err)
+		assert.Equal(t, "true", rec.Header().Get(HeaderReplayed))
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+}
+
+func getStatusCode(rec *httptest.ResponseRecorder, err error) int {
+	if err == nil {
+		return rec.Code
+	}
+
+	hterr := &echo.HTTPError{}
+	if errors.As(err, &hterr) {
+		return hterr.Code
+	}
+
+	return 500
+}
@@ -0,0 +1,362 @@
+// Package importer adds features in bulk from CSV and JSON files, as
+// exported from spreadsheets and other feature request tools.
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/music-tribe/react-pairing-challenge/database"
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/react-pairing-challenge/dto"
+	"github.com/music-tribe/uuid"
+)
+
+const (
+	DefaultBatchSize = 500
+	// MaxReportedErrors bounds the row errors kept in a report, so a file
+	// of garbage doesn't produce a report as big as itself.
+	MaxReportedErrors = 1000
+)
+
+// ErrBadFile is returned when an import stops because its file can't be
+// read any further.
+var ErrBadFile = errors.New("the import file can't be read")
+
+//go:generate mockgen -destination=./mocks/importer.go -package=importermocks -source=importer.go
+type Database interface {
+	MissingCategories(slugs []string) ([]string, error)
+	ExistingFeatureIds(ids []uuid.UUID) ([]uuid.UUID, error)
+	ImportFeatures(actor string, features []*domain.Feature) ([]uuid.UUID, error)
+}
+
+type Options struct {
+	Format  Format
+	Mapping Mapping
+	// UserId owns the rows that don't name an owner.
+	UserId string
+	// Actor is who the features are recorded as created by.
+	Actor string
+	// DryRun checks every row without writing anything.
+	DryRun    bool
+	BatchSize int
+}
+
+// Report sums up an import. In a dry run Imported counts the features that
+// would have been added.
+type Report struct {
+	DryRun     bool `json:"dryRun" example:"false"`
+	Rows       int  `json:"rows" example:"120"`
+	Imported   int  `json:"imported" example:"115"`
+	Duplicates int  `json:"duplicates" example:"3"`
+	Failed     int  `json:"failed" example:"2"`
+	// Errors lists the rows that failed or were skipped as duplicates.
+	Errors []RowError `json:"errors"`
+	// Truncated is set when there were more than MaxReportedErrors.
+	Truncated bool `json:"truncated,omitempty" example:"false"`
+}
+
+type RowError struct {
+	// Row counts from 1, not including a CSV header.
+	Row       int    `json:"row" example:"7"`
+	Id        string `json:"id,omitempty" example:"f6e7f8c4-3af6-4028-ac7c-30c9d79a3fa7"`
+	Duplicate bool   `json:"duplicate,omitempty" example:"false"`
+	Error     string `json:"error" example:"Key: 'ImportFeature.Name' Error:Field validation for 'Name' failed on the 'required' tag"`
+}
+
+type pending struct {
+	row     int
+	feature *domain.Feature
+}
+
+type run struct {
+	db       Database
+	opts     Options
+	validate *validator.Validate
+	report   *Report
+	// seen maps the ids read so far to their row, to catch a file that
+	// repeats itself
+	seen  map[uuid.UUID]int
+	batch []pending
+}
+
+// Import reads features from r and adds them in batches, skipping any that
+// already exist. Rows that fail validation are reported and skipped. An
+// error is only returned when the file can't be read, wrapping ErrBadFile,
+// or the database fails; the report then covers the rows handled until then.
+func Import(db Database, r io.Reader, opts Options) (*Report, error) {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultBatchSize
+	}
+
+	src, err := newSource(r, opts.Format)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBadFile, err)
+	}
+
+	run := &run{
+		db:       db,
+		opts:     opts,
+		validate: validator.New(),
+		report:   &Report{DryRun: opts.DryRun, Errors: []RowError{}},
+		seen:     map[uuid.UUID]int{},
+	}
+
+	for row := 1; ; row++ {
+		rec, err := src.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			rowErr := errRow{}
+			if !errors.As(err, &rowErr) {
+				return run.report, fmt.Errorf("%w: row %d: %v", ErrBadFile, row, err)
+			}
+			run.report.Rows++
+			run.fail(row, "", rowErr)
+			continue
+		}
+
+		run.report.Rows++
+		run.add(row, rec)
+
+		if len(run.batch) >= opts.BatchSize {
+			if err := run.flush(); err != nil {
+				return run.report, err
+			}
+		}
+	}
+
+	if err := run.flush(); err != nil {
+		return run.report, err
+	}
+
+	return run.report, nil
+}
+
+func (r *run) add(row int, rec record) {
+	req, err := r.read(rec)
+	if err != nil {
+		r.fail(row, "", err)
+		return
+	}
+
+	if err := r.validate.Struct(&req); err != nil {
+		r.fail(row, req.Id, err)
+		return
+	}
+
+	feature := dto.NewImportedFeature(&req)
+	if err := r.validate.Struct(feature); err != nil {
+		r.fail(row, feature.Id.String(), err)
+		return
+	}
+
+	if first, ok := r.seen[feature.Id]; ok {
+		r.duplicate(row, feature.Id, fmt.Sprintf("repeats row %d", first))
+		return
+	}
+	r.seen[feature.Id] = row
+
+	r.batch = append(r.batch, pending{row: row, feature: feature})
+}
+
+// read pulls the mapped fields out of a record.
+func (r *run) read(rec record) (dto.ImportFeature, error) {
+	req := dto.ImportFeature{}
+	m := r.opts.Mapping
+
+	text := func(field string) (string, error) {
+		switch v := rec[m.column(field)].(type) {
+		case nil:
+			return "", nil
+		case string:
+			return strings.TrimSpace(v), nil
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64), nil
+		case bool:
+			return strconv.FormatBool(v), nil
+		}
+		return "", fmt.Errorf("%s must be text", m.column(field))
+	}
+
+	var err error
+	fields := []struct {
+		name string
+		dst  *string
+	}{
+		{"id", &req.Id},
+		{"userId", &req.UserId},
+		{"name", &req.Name},
+		{"description", &req.Description},
+		{"board", &req.Board},
+		{"status", &req.Status},
+		{"createdAt", &req.CreatedAt},
+	}
+	for _, f := range fields {
+		if *f.dst, err = text(f.name); err != nil {
+			return req, err
+		}
+	}
+
+	if req.UserId == "" {
+		req.UserId = r.opts.UserId
+	}
+
+	switch v := rec[m.column("tags")].(type) {
+	case []interface{}:
+		for _, t := range v {
+			s, ok := t.(string)
+			if !ok {
+				return req, fmt.Errorf("%s must be a list of text", m.column("tags"))
+			}
+			req.Tags = append(req.Tags, s)
+		}
+	default:
+		s, err := text("tags")
+		if err != nil {
+			return req, err
+		}
+		req.Tags = splitTags(s)
+	}
+
+	return req, nil
+}
+
+// splitTags reads a spreadsheet cell of tags separated by commas,
+// semicolons or pipes.
+func splitTags(s string) []string {
+	var tags []string
+	for _, t := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ';' || r == '|' }) {
+		if t = strings.TrimSpace(t); t != "" {
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
+
+// flush checks the batch's tags and writes whatever passes.
+func (r *run) flush() error {
+	if len(r.batch) == 0 {
+		return nil
+	}
+	defer func() { r.batch = r.batch[:0] }()
+
+	batch, err := r.checkTags()
+	if err != nil {
+		return err
+	}
+	if len(batch) == 0 {
+		return nil
+	}
+
+	features := make([]*domain.Feature, len(batch))
+	ids := make([]uuid.UUID, len(batch))
+	for i, p := range batch {
+		features[i] = p.feature
+		ids[i] = p.feature.Id
+	}
+
+	var duplicates []uuid.UUID
+	if r.opts.DryRun {
+		duplicates, err = r.db.ExistingFeatureIds(ids)
+	} else {
+		duplicates, err = r.db.ImportFeatures(r.opts.Actor, features)
+		if err == database.ErrDuplicate {
+			// another import added some of the batch under our feet; the
+			// retry skips them
+			duplicates, err = r.db.ImportFeatures(r.opts.Actor, features)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	dup := make(map[uuid.UUID]bool, len(duplicates))
+	for _, id := range duplicates {
+		dup[id] = true
+	}
+	for _, p := range batch {
+		if dup[p.feature.Id] {
+			r.duplicate(p.row, p.feature.Id, "feature already exists")
+			continue
+		}
+		r.report.Imported++
+	}
+
+	return nil
+}
+
+// checkTags fails the rows tagged with categories that don't exist, the
+// same as add.Add would, returning the rest.
+func (r *run) checkTags() ([]pending, error) {
+	set := map[string]bool{}
+	for _, p := range r.batch {
+		for _, t := range p.feature.Tags {
+			set[t] = true
+		}
+	}
+	if len(set) == 0 {
+		return r.batch, nil
+	}
+
+	tags := make([]string, 0, len(set))
+	for t := range set {
+		tags = append(tags, t)
+	}
+	sort.Strings(tags)
+
+	missing, err := r.db.MissingCategories(tags)
+	if err != nil {
+		return nil, err
+	}
+	if len(missing) == 0 {
+		return r.batch, nil
+	}
+
+	unknown := map[string]bool{}
+	for _, t := range missing {
+		unknown[t] = true
+	}
+
+	ok := make([]pending, 0, len(r.batch))
+	for _, p := range r.batch {
+		var bad []string
+		for _, t := range p.feature.Tags {
+			if unknown[t] {
+				bad = append(bad, t)
+			}
+		}
+		if len(bad) > 0 {
+			delete(r.seen, p.feature.Id)
+			r.fail(p.row, p.feature.Id.String(), fmt.Errorf("unknown tags %s", strings.Join(bad, ", ")))
+			continue
+		}
+		ok = append(ok, p)
+	}
+
+	return ok, nil
+}
+
+func (r *run) fail(row int, id string, err error) {
+	r.report.Failed++
+	r.note(RowError{Row: row, Id: id, Error: err.Error()})
+}
+
+func (r *run) duplicate(row int, id uuid.UUID, reason string) {
+	r.report.Duplicates++
+	r.note(RowError{Row: row, Id: id.String(), Duplicate: true, Error: reason})
+}
+
+func (r *run) note(e RowError) {
+	if len(r.report.Errors) >= MaxReportedErrors {
+		r.report.Truncated = true
+		return
+	}
+	r.report.Errors = append(r.report.Errors, e)
+}
@@ -0,0 +1,197 @@
+package importer
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/music-tribe/react-pairing-challenge/database"
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	importermocks "github.com/music-tribe/react-pairing-challenge/importer/mocks"
+	"github.com/music-tribe/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+const owner = "202c25c4-b2ce-4514-9045-890a1aa896ea"
+
+func TestImport(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	t.Run("when a CSV file is imported, every valid row should be added", func(t *testing.T) {
+		db := importermocks.NewMockDatabase(ctrl)
+		csv := "\ufeffName,Details,userId,tags,status,createdAt\n" +
+			"Dark mode,Please add it," + owner + ",\"mixer, mobile-app\",planned,2023-11-02T10:00:00Z\n" +
+			"Offline sync,Work without a network,,,,\n"
+
+		db.EXPECT().MissingCategories([]string{"mixer", "mobile-app"}).Return(nil, nil)
+		db.EXPECT().ImportFeatures("admin", gomock.Any()).DoAndReturn(func(actor string, features []*domain.Feature) ([]uuid.UUID, error) {
+			assert.Len(t, features, 2)
+			assert.Equal(t, "Dark mode", features[0].Name)
+			assert.Equal(t, "Please add it", features[0].Description)
+			assert.Equal(t, []string{"mixer", "mobile-app"}, features[0].Tags)
+			assert.Equal(t, domain.StatusPlanned, features[0].Status)
+			assert.Equal(t, uuid.MustParse(owner), features[1].UserId)
+			return nil, nil
+		})
+
+		report, err := Import(db, strings.NewReader(csv), Options{
+			Format:  CSV,
+			Mapping: Mapping{"name": "Name", "description": "Details"},
+			UserId:  owner,
+			Actor:   "admin",
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, &Report{Rows: 2, Imported: 2, Errors: []RowError{}}, report)
+	})
+
+	t.Run("when rows are invalid, they should be reported and the rest imported", func(t *testing.T) {
+		db := importermocks.NewMockDatabase(ctrl)
+		jsonl := `{"userId":"` + owner + `","name":"ok","description":"fine"}` + "\n" +
+			`{"userId":"` + owner + `","name":"","description":"no name"}` + "\n" +
+			`["not","an","object"]` + "\n" +
+			`{"userId":"` + owner + `","name":"bad","description":"status","status":"shipped"}` + "\n" +
+			`{"userId":"` + owner + `","name":"tagged","description":"x","tags":["nope"]}` + "\n"
+
+		db.EXPECT().MissingCategories([]string{"nope"}).Return([]string{"nope"}, nil)
+		db.EXPECT().ImportFeatures("admin", gomock.Len(1)).Return(nil, nil)
+
+		report, err := Import(db, strings.NewReader(jsonl), Options{Format: JSON, Actor: "admin"})
+		assert.NoError(t, err)
+		assert.Equal(t, 5, report.Rows)
+		assert.Equal(t, 1, report.Imported)
+		assert.Equal(t, 4, report.Failed)
+		assert.Equal(t, []int{2, 3, 4, 5}, rows(report.Errors))
+		assert.Contains(t, report.Errors[0].Error, "'Name' failed on the 'required' tag")
+		assert.Equal(t, "is not a JSON object", report.Errors[1].Error)
+		assert.Equal(t, "unknown tags nope", report.Errors[3].Error)
+	})
+
+	t.Run("when features already exist or are repeated, they should be reported as duplicates", func(t *testing.T) {
+		db := importermocks.NewMockDatabase(ctrl)
+		existing := uuid.New()
+		json := `[
+			{"id":"` + existing.String() + `","userId":"` + owner + `","name":"old","description":"x"},
+			{"userId":"` + owner + `","name":"new","description":"x"},
+			{"userId":"` + owner + `","name":"New","description":"again"}
+		]`
+
+		db.EXPECT().ImportFeatures("admin", gomock.Len(2)).Return([]uuid.UUID{existing}, nil)
+
+		report, err := Import(db, strings.NewReader(json), Options{Format: JSON, Actor: "admin"})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, report.Imported)
+		assert.Equal(t, 2, report.Duplicates)
+		assert.Equal(t, []int{3, 1}, rows(report.Errors))
+		assert.Equal(t, "repeats row 2", report.Errors[0].Error)
+		assert.True(t, report.Errors[1].Duplicate)
+	})
+
+	t.Run("when another import races us, the batch should be retried", func(t *testing.T) {
+		db := importermocks.NewMockDatabase(ctrl)
+		jsonl := `{"userId":"` + owner + `","name":"a","description":"x"}`
+
+		gomock.InOrder(
+			db.EXPECT().ImportFeatures("", gomock.Len(1)).Return(nil, database.ErrDuplicate),
+			db.EXPECT().ImportFeatures("", gomock.Len(1)).DoAndReturn(func(_ string, fs []*domain.Feature) ([]uuid.UUID, error) {
+				return []uuid.UUID{fs[0].Id}, nil
+			}),
+		)
+
+		report, err := Import(db, strings.NewReader(jsonl), Options{Format: JSON})
+		assert.NoError(t, err)
+		assert.Equal(t, 1, report.Duplicates)
+	})
+
+	t.Run("when it is a dry run, nothing should be written", func(t *testing.T) {
+		db := importermocks.NewMockDatabase(ctrl)
+		csv := "name,description,userId\na,x," + owner + "\nb,y," + owner + "\n"
+
+		db.EXPECT().ExistingFeatureIds(gomock.Len(2)).Return(nil, nil)
+
+		report, err := Import(db, strings.NewReader(csv), Options{Format: CSV, DryRun: true})
+		assert.NoError(t, err)
+		assert.Equal(t, &Report{DryRun: true, Rows: 2, Imported: 2, Errors: []RowError{}}, report)
+	})
+
+	t.Run("when the file is bigger than a batch, it should be written in batches", func(t *testing.T) {
+		db := importermocks.NewMockDatabase(ctrl)
+		var b strings.Builder
+		b.WriteString("name,description\n")
+		for i := 0; i < 5; i++ {
+			b.WriteString("feature " + string(rune('a'+i)) + ",x\n")
+		}
+
+		db.EXPECT().ImportFeatures("", gomock.Len(2)).Return(nil, nil).Times(2)
+		db.EXPECT().ImportFeatures("", gomock.Len(1)).Return(nil, nil)
+
+		report, err := Import(db, strings.NewReader(b.String()), Options{Format: CSV, UserId: owner, BatchSize: 2})
+		assert.NoError(t, err)
+		assert.Equal(t, 5, report.Imported)
+	})
+
+	t.Run("when the database fails, we should get the report so far and the error", func(t *testing.T) {
+		db := importermocks.NewMockDatabase(ctrl)
+		csv := "name,description\na,x\nb,x\n"
+
+		gomock.InOrder(
+			db.EXPECT().ImportFeatures("", gomock.Len(1)).Return(nil, nil),
+			db.EXPECT().ImportFeatures("", gomock.Len(1)).Return(nil, errors.New("db down")),
+		)
+
+		report, err := Import(db, strings.NewReader(csv), Options{Format: CSV, UserId: owner, BatchSize: 1})
+		assert.EqualError(t, err, "db down")
+		assert.Equal(t, 1, report.Imported)
+	})
+
+	t.Run("when a CSV row has the wrong number of columns, it should be reported", func(t *testing.T) {
+		db := importermocks.NewMockDatabase(ctrl)
+		csv := "name,description\na\n"
+
+		report, err := Import(db, strings.NewReader(csv), Options{Format: CSV, UserId: owner})
+		assert.NoError(t, err)
+		assert.Equal(t, "has 1 columns, the header has 2", report.Errors[0].Error)
+	})
+
+	t.Run("when the file is empty, we should get an error", func(t *testing.T) {
+		db := importermocks.NewMockDatabase(ctrl)
+
+		_, err := Import(db, strings.NewReader(" \n"), Options{Format: JSON})
+		assert.ErrorIs(t, err, ErrBadFile)
+		assert.ErrorContains(t, err, "the file is empty")
+	})
+
+	t.Run("when the file breaks off, we should get an error", func(t *testing.T) {
+		db := importermocks.NewMockDatabase(ctrl)
+
+		report, err := Import(db, strings.NewReader(`{"name":"a"`), Options{Format: JSON})
+		assert.ErrorIs(t, err, ErrBadFile)
+		assert.Equal(t, 0, report.Rows)
+	})
+}
+
+func TestParseMapping(t *testing.T) {
+	t.Run("when the mapping is well formed, it should be parsed", func(t *testing.T) {
+		m, err := ParseMapping([]string{"name=Title", "userId=Owner Id"})
+		assert.NoError(t, err)
+		assert.Equal(t, Mapping{"name": "Title", "userId": "Owner Id"}, m)
+		assert.Equal(t, "description", m.column("description"))
+	})
+
+	t.Run("when a field is unknown or a column is missing, we should get an error", func(t *testing.T) {
+		_, err := ParseMapping([]string{"title=Title"})
+		assert.ErrorContains(t, err, `unknown field "title"`)
+
+		_, err = ParseMapping([]string{"name"})
+		assert.ErrorContains(t, err, "must look like field=column")
+	})
+}
+
+func rows(errs []RowError) []int {
+	rs := make([]int, len(errs))
+	for i, e := range errs {
+		rs[i] = e.Row
+	}
+	return rs
+}
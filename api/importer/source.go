@@ -0,0 +1,191 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type Format string
+
+const (
+	CSV Format = "csv"
+	// JSON reads JSON Lines, or a single JSON array of objects.
+	JSON Format = "json"
+)
+
+// ParseFormat accepts the names clients use for the supported formats.
+func ParseFormat(s string) (Format, error) {
+	switch strings.ToLower(s) {
+	case "csv":
+		return CSV, nil
+	case "json", "jsonl", "ndjson":
+		return JSON, nil
+	}
+	return "", fmt.Errorf("unsupported format %q, want csv or jsonl", s)
+}
+
+// Fields are the feature fields a column may be mapped to.
+var Fields = []string{"id", "userId", "name", "description", "board", "tags", "status", "createdAt"}
+
+// Mapping names the column each field is read from. Fields that aren't
+// mapped are read from the column of the same name.
+type Mapping map[string]string
+
+// ParseMapping reads mappings written as "field=column".
+func ParseMapping(pairs []string) (Mapping, error) {
+	m := Mapping{}
+	for _, pair := range pairs {
+		field, column, ok := strings.Cut(pair, "=")
+		if !ok || column == "" {
+			return nil, fmt.Errorf("mapping %q must look like field=column", pair)
+		}
+		if !isField(field) {
+			return nil, fmt.Errorf("mapping %q: unknown field %q, want one of %s", pair, field, strings.Join(Fields, ", "))
+		}
+		m[field] = column
+	}
+	return m, nil
+}
+
+func (m Mapping) column(field string) string {
+	if c, ok := m[field]; ok {
+		return c
+	}
+	return field
+}
+
+func isField(s string) bool {
+	for _, f := range Fields {
+		if f == s {
+			return true
+		}
+	}
+	return false
+}
+
+// record is one row of the file, keyed by column.
+type record map[string]interface{}
+
+// errRow marks a row that couldn't be read, but doesn't stop the rest of
+// the file being read.
+type errRow struct{ err error }
+
+func (e errRow) Error() string { return e.err.Error() }
+
+// source reads the records of a file one at a time, returning io.EOF after
+// the last.
+type source interface {
+	next() (record, error)
+}
+
+func newSource(r io.Reader, format Format) (source, error) {
+	switch format {
+	case CSV:
+		return newCSVSource(r)
+	case JSON:
+		return newJSONSource(r)
+	}
+	return nil, fmt.Errorf("unsupported format %q", format)
+}
+
+type csvSource struct {
+	r      *csv.Reader
+	header []string
+}
+
+func newCSVSource(r io.Reader) (*csvSource, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, errors.New("the file is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading the header >> %w", err)
+	}
+	// spreadsheets like to start their exports with a byte order mark
+	header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	for i := range header {
+		header[i] = strings.TrimSpace(header[i])
+	}
+
+	return &csvSource{r: cr, header: header}, nil
+}
+
+func (s *csvSource) next() (record, error) {
+	row, err := s.r.Read()
+	if err != nil {
+		if errors.Is(err, csv.ErrFieldCount) {
+			return nil, errRow{fmt.Errorf("has %d columns, the header has %d", len(row), len(s.header))}
+		}
+		return nil, err
+	}
+
+	rec := make(record, len(row))
+	for i, v := range row {
+		rec[s.header[i]] = v
+	}
+	return rec, nil
+}
+
+type jsonSource struct {
+	dec   *json.Decoder
+	array bool
+}
+
+func newJSONSource(r io.Reader) (*jsonSource, error) {
+	br := bufio.NewReader(r)
+
+	// peek past any whitespace to tell an array from JSON Lines
+	var first byte
+	for {
+		b, err := br.Peek(1)
+		if err == io.EOF {
+			return nil, errors.New("the file is empty")
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.ContainsAny(b, " \t\r\n") {
+			first = b[0]
+			break
+		}
+		br.ReadByte()
+	}
+
+	s := &jsonSource{dec: json.NewDecoder(br), array: first == '['}
+	if s.array {
+		// consume the opening bracket
+		if _, err := s.dec.Token(); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func (s *jsonSource) next() (record, error) {
+	if s.array && !s.dec.More() {
+		return nil, io.EOF
+	}
+
+	var raw json.RawMessage
+	if err := s.dec.Decode(&raw); err != nil {
+		if err == io.EOF && s.array {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+
+	rec := record{}
+	if err := json.Unmarshal(raw, &rec); err != nil {
+		return nil, errRow{errors.New("is not a JSON object")}
+	}
+	return rec, nil
+}
@@ -0,0 +1,152 @@
+package live
+
+import (
+	"context"
+	"sync"
+
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/uuid"
+)
+
+const (
+	// DefaultHistory is how many recent messages are kept for clients
+	// resuming after a dropped connection.
+	DefaultHistory = 1000
+	// DefaultBuffer is how many messages may queue for one subscriber
+	// before it is considered too slow and dropped.
+	DefaultBuffer = 64
+)
+
+// Hub fans messages out to subscribers. Publishing never blocks: a
+// subscriber that falls DefaultBuffer messages behind is dropped and is
+// expected to reconnect and resume from the last message it saw.
+type Hub struct {
+	mu      sync.Mutex
+	subs    map[*Subscription]struct{}
+	history []*Message
+	seen    map[uuid.UUID]bool
+	next    int
+	full    bool
+	buffer  int
+}
+
+func NewHub() *Hub {
+	return &Hub{
+		subs:    map[*Subscription]struct{}{},
+		history: make([]*Message, DefaultHistory),
+		seen:    map[uuid.UUID]bool{},
+		buffer:  DefaultBuffer,
+	}
+}
+
+// Subscription receives the messages matching its filter on C until it is
+// closed, either by the subscriber or by the hub when it falls behind.
+type Subscription struct {
+	C      <-chan *Message
+	c      chan *Message
+	filter Filter
+	hub    *Hub
+	once   sync.Once
+}
+
+// Close stops the subscription. It is safe to call more than once.
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+
+	s.close()
+}
+
+// close must be called with the hub locked.
+func (s *Subscription) close() {
+	s.once.Do(func() {
+		delete(s.hub.subs, s)
+		close(s.c)
+	})
+}
+
+// Subscribe starts a subscription. When lastId is the id of a message
+// still in the hub's history, the matching messages published after it are
+// returned as a backlog to send before reading from the subscription;
+// resumed reports whether that was possible. An empty lastId never
+// resumes and is not reported as a failure.
+func (h *Hub) Subscribe(filter Filter, lastId string) (sub *Subscription, backlog []*Message, resumed bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	c := make(chan *Message, h.buffer)
+	sub = &Subscription{C: c, c: c, filter: filter, hub: h}
+	h.subs[sub] = struct{}{}
+
+	if lastId == "" {
+		return sub, nil, true
+	}
+
+	found := false
+	for _, m := range h.ordered() {
+		if found && filter.Matches(m) {
+			backlog = append(backlog, m)
+		}
+		if m.Id.String() == lastId {
+			found = true
+		}
+	}
+
+	return sub, backlog, found
+}
+
+// Publish sends m to every matching subscriber. A message that was already
+// published is ignored, since the outbox delivers events at least once.
+func (h *Hub) Publish(m *Message) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.seen[m.Id] {
+		return
+	}
+
+	if old := h.history[h.next]; old != nil {
+		delete(h.seen, old.Id)
+	}
+	h.seen[m.Id] = true
+	h.history[h.next] = m
+	h.next = (h.next + 1) % len(h.history)
+	h.full = h.full || h.next == 0
+
+	for sub := range h.subs {
+		if !sub.filter.Matches(m) {
+			continue
+		}
+
+		select {
+		case sub.c <- m:
+		default:
+			sub.close()
+		}
+	}
+}
+
+// Handle publishes the streamed events. It is an events.Handler.
+func (h *Hub) Handle(_ context.Context, event *domain.Event) error {
+	if m := NewMessage(event); m != nil {
+		h.Publish(m)
+	}
+	return nil
+}
+
+// Subscribers reports how many subscriptions are open.
+func (h *Hub) Subscribers() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return len(h.subs)
+}
+
+// ordered returns the history oldest first. It must be called with the hub
+// locked.
+func (h *Hub) ordered() []*Message {
+	if !h.full {
+		return h.history[:h.next]
+	}
+	return append(append([]*Message(nil), h.history[h.next:]...), h.history[:h.next]...)
+}
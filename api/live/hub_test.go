@@ -0,0 +1,139 @@
+package live
+
+import (
+	"context"
+	"testing"
+
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHub(t *testing.T) {
+	newMessage := func(featureId uuid.UUID, board string) *Message {
+		return &Message{Id: uuid.New(), Type: domain.VoteCast, FeatureId: featureId, Board: board}
+	}
+
+	t.Run("when a message is published, every matching subscriber should receive it", func(t *testing.T) {
+		hub := NewHub()
+		featureId := uuid.New()
+
+		all, _, _ := hub.Subscribe(Filter{}, "")
+		mine, _, _ := hub.Subscribe(Filter{FeatureIds: []uuid.UUID{featureId}}, "")
+		other, _, _ := hub.Subscribe(Filter{Board: "other"}, "")
+		defer all.Close()
+		defer mine.Close()
+		defer other.Close()
+
+		m := newMessage(featureId, "mixer")
+		hub.Publish(m)
+
+		assert.Equal(t, m, <-all.C)
+		assert.Equal(t, m, <-mine.C)
+		assert.Len(t, other.C, 0)
+	})
+
+	t.Run("when a message is published twice, it should only be sent once", func(t *testing.T) {
+		hub := NewHub()
+		sub, _, _ := hub.Subscribe(Filter{}, "")
+		defer sub.Close()
+
+		m := newMessage(uuid.New(), "mixer")
+		hub.Publish(m)
+		hub.Publish(m)
+
+		assert.Len(t, sub.C, 1)
+	})
+
+	t.Run("when a subscriber falls behind, it should be dropped without blocking the publisher", func(t *testing.T) {
+		hub := NewHub()
+		slow, _, _ := hub.Subscribe(Filter{}, "")
+		fast, _, _ := hub.Subscribe(Filter{}, "")
+
+		for i := 0; i < DefaultBuffer+1; i++ {
+			hub.Publish(newMessage(uuid.New(), "mixer"))
+			<-fast.C
+		}
+
+		for range slow.C {
+		}
+		assert.Equal(t, 1, hub.Subscribers())
+
+		fast.Close()
+		fast.Close()
+		assert.Zero(t, hub.Subscribers())
+	})
+
+	t.Run("when a subscriber resumes, it should get the matching messages it missed", func(t *testing.T) {
+		hub := NewHub()
+		featureId := uuid.New()
+
+		seen := newMessage(featureId, "mixer")
+		missed := newMessage(featureId, "mixer")
+		hub.Publish(seen)
+		hub.Publish(newMessage(uuid.New(), "mixer"))
+		hub.Publish(missed)
+
+		sub, backlog, resumed := hub.Subscribe(Filter{FeatureIds: []uuid.UUID{featureId}}, seen.Id.String())
+		defer sub.Close()
+		assert.True(t, resumed)
+		assert.Equal(t, []*Message{missed}, backlog)
+	})
+
+	t.Run("when the last message has left the history, the subscriber should be told it can't resume", func(t *testing.T) {
+		hub := NewHub()
+		first := newMessage(uuid.New(), "mixer")
+		hub.Publish(first)
+		for i := 0; i < DefaultHistory; i++ {
+			hub.Publish(newMessage(uuid.New(), "mixer"))
+		}
+
+		sub, backlog, resumed := hub.Subscribe(Filter{}, first.Id.String())
+		defer sub.Close()
+		assert.False(t, resumed)
+		assert.Empty(t, backlog)
+
+		// the evicted message can be published again
+		hub.Publish(first)
+		assert.Len(t, sub.C, 1)
+	})
+
+	t.Run("when an event is handled, only streamed types should be published", func(t *testing.T) {
+		hub := NewHub()
+		sub, _, _ := hub.Subscribe(Filter{}, "")
+		defer sub.Close()
+
+		feature := &domain.Feature{Id: uuid.New(), Board: "mixer", VoteCount: 3}
+		assert.NoError(t, hub.Handle(context.Background(), &domain.Event{Id: uuid.New(), Type: domain.FeaturePurged, FeatureId: feature.Id, Feature: feature}))
+		assert.NoError(t, hub.Handle(context.Background(), &domain.Event{Id: uuid.New(), Type: domain.VoteCast, FeatureId: feature.Id, Feature: feature}))
+
+		assert.Len(t, sub.C, 1)
+		m := <-sub.C
+		assert.Equal(t, domain.VoteCast, m.Type)
+		assert.Equal(t, int64(3), m.VoteCount)
+		assert.Nil(t, m.Feature)
+	})
+
+	t.Run("when a feature is edited, deleted or restored, it should be published with its fields", func(t *testing.T) {
+		hub := NewHub()
+		sub, _, _ := hub.Subscribe(Filter{Board: "mixer"}, "")
+		defer sub.Close()
+
+		feature := &domain.Feature{Id: uuid.New(), Board: "mixer", Name: "Dark mode", Description: "darker", Tags: []string{"ui"}, Status: domain.StatusPlanned}
+		for _, typ := range []domain.EventType{domain.FeatureUpdated, domain.FeatureDeleted, domain.FeatureRestored} {
+			assert.NoError(t, hub.Handle(context.Background(), &domain.Event{Id: uuid.New(), Type: typ, FeatureId: feature.Id, Feature: feature}))
+		}
+
+		if !assert.Len(t, sub.C, 3) {
+			return
+		}
+		m := <-sub.C
+		assert.Equal(t, domain.FeatureUpdated, m.Type)
+		assert.Equal(t, "Dark mode", m.Name)
+		assert.Equal(t, "darker", m.Description)
+		assert.Equal(t, []string{"ui"}, m.Tags)
+		assert.Equal(t, domain.StatusPlanned, m.Status)
+		assert.Equal(t, domain.FeatureDeleted, (<-sub.C).Type)
+		assert.Equal(t, domain.FeatureRestored, (<-sub.C).Type)
+	})
+}
@@ -0,0 +1,96 @@
+// Package live fans out changes to features to the clients watching them
+// in real time.
+package live
+
+import (
+	"time"
+
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/uuid"
+)
+
+// Streamed lists the event types pushed to live clients.
+var Streamed = map[domain.EventType]bool{
+	domain.FeatureCreated:       true,
+	domain.FeatureUpdated:       true,
+	domain.FeatureStatusChanged: true,
+	domain.FeatureDeleted:       true,
+	domain.FeatureRestored:      true,
+	domain.FeatureVotesChanged:  true,
+	domain.VoteCast:             true,
+	domain.VoteChanged:          true,
+	domain.VoteRemoved:          true,
+}
+
+// Message is what live clients are told about a change. It carries the
+// feature's current fields and counts rather than the change itself, so a
+// client that misses a message is still correct after the next one.
+type Message struct {
+	Id          uuid.UUID            `json:"id" example:"7c9e6679-7425-40de-944b-e07fc1f90ae7"`
+	Type        domain.EventType     `json:"type" example:"vote.cast"`
+	FeatureId   uuid.UUID            `json:"featureId" example:"202c25c4-b2ce-4514-9045-890a1aa896ea"`
+	Board       string               `json:"board" example:"mixer"`
+	Name        string               `json:"name" example:"Dark mode"`
+	Description string               `json:"description" example:"A darker theme for late sessions"`
+	Tags        []string             `json:"tags" example:"ui,mixer"`
+	Status      domain.FeatureStatus `json:"status" example:"open"`
+	VoteCount   int64                `json:"voteCount" example:"42"`
+	Upvotes     int64                `json:"upvotes" example:"45"`
+	Downvotes   int64                `json:"downvotes" example:"3"`
+	Score       int64                `json:"score" example:"44"`
+	// Feature is only set for feature.created messages.
+	Feature    *domain.Feature `json:"feature,omitempty"`
+	OccurredAt time.Time       `json:"occurredAt" example:"2024-03-03T17:45:00Z"`
+}
+
+// NewMessage summarises event for live clients. It returns nil for events
+// that aren't streamed.
+func NewMessage(event *domain.Event) *Message {
+	if !Streamed[event.Type] || event.Feature == nil {
+		return nil
+	}
+
+	f := event.Feature
+	m := &Message{
+		Id:          event.Id,
+		Type:        event.Type,
+		FeatureId:   event.FeatureId,
+		Board:       f.Board,
+		Name:        f.Name,
+		Description: f.Description,
+		Tags:        f.Tags,
+		Status:      f.Status,
+		VoteCount:   f.VoteCount,
+		Upvotes:     f.Upvotes,
+		Downvotes:   f.Downvotes,
+		Score:       f.Score,
+		OccurredAt:  event.OccurredAt,
+	}
+	if event.Type == domain.FeatureCreated {
+		m.Feature = f
+	}
+
+	return m
+}
+
+// Filter narrows a subscription to some features or a board. The zero
+// Filter matches everything.
+type Filter struct {
+	FeatureIds []uuid.UUID
+	Board      string
+}
+
+func (f Filter) Matches(m *Message) bool {
+	if f.Board != "" && m.Board != f.Board {
+		return false
+	}
+	if len(f.FeatureIds) == 0 {
+		return true
+	}
+	for _, id := range f.FeatureIds {
+		if id == m.FeatureId {
+			return true
+		}
+	}
+	return false
+}
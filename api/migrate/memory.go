@@ -0,0 +1,73 @@
+package migrate
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/music-tribe/react-pairing-challenge/domain"
+)
+
+// MemoryStore keeps migration records and the lock in process memory. It
+// suits tests, and migrating stores that live no longer than the process.
+type MemoryStore struct {
+	mu          sync.Mutex
+	applied     map[int]domain.SchemaMigration
+	owner       string
+	lockExpires time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{applied: map[int]domain.SchemaMigration{}}
+}
+
+func (s *MemoryStore) AppliedMigrations(ctx context.Context) ([]*domain.SchemaMigration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	recs := make([]*domain.SchemaMigration, 0, len(s.applied))
+	for _, rec := range s.applied {
+		rec := rec
+		recs = append(recs, &rec)
+	}
+	sort.Slice(recs, func(i, j int) bool { return recs[i].Version < recs[j].Version })
+	return recs, nil
+}
+
+func (s *MemoryStore) RecordMigration(ctx context.Context, m *domain.SchemaMigration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.applied[m.Version] = *m
+	return nil
+}
+
+func (s *MemoryStore) RemoveMigration(ctx context.Context, version int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.applied, version)
+	return nil
+}
+
+func (s *MemoryStore) LockMigrations(ctx context.Context, owner string, now, expires time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.owner != "" && s.owner != owner && now.Before(s.lockExpires) {
+		return false, nil
+	}
+	s.owner, s.lockExpires = owner, expires
+	return true, nil
+}
+
+func (s *MemoryStore) UnlockMigrations(ctx context.Context, owner string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.owner == owner {
+		s.owner = ""
+	}
+	return nil
+}
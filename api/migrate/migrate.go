@@ -0,0 +1,355 @@
+// Package migrate applies versioned changes to stored data in order,
+// recording each one so it runs exactly once, whichever replica gets there
+// first.
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/music-tribe/react-pairing-challenge/domain"
+)
+
+var (
+	// ErrLocked is returned when another process held the migration lock
+	// for the whole of the lock timeout.
+	ErrLocked = errors.New("migrations are locked by another process")
+	// ErrIrreversible is returned when a rollback would need to undo a
+	// migration that has no Down.
+	ErrIrreversible = errors.New("migration can't be rolled back")
+)
+
+// Migration is one change to stored data. Versions order migrations and
+// are never reused once released.
+type Migration struct {
+	Version int
+	Name    string
+	Up      func(ctx context.Context) error
+	// Down undoes Up. Migrations without one can't be rolled back.
+	Down func(ctx context.Context) error
+}
+
+// Store records which migrations have been applied and holds the lock that
+// keeps two processes from migrating at once.
+type Store interface {
+	// AppliedMigrations returns the recorded migrations, oldest version
+	// first.
+	AppliedMigrations(ctx context.Context) ([]*domain.SchemaMigration, error)
+	RecordMigration(ctx context.Context, m *domain.SchemaMigration) error
+	RemoveMigration(ctx context.Context, version int) error
+	// LockMigrations takes or extends the lock for owner until expires,
+	// reporting false while another owner holds a lock that hasn't
+	// expired by now.
+	LockMigrations(ctx context.Context, owner string, now, expires time.Time) (bool, error)
+	// UnlockMigrations releases owner's lock, if it still holds it.
+	UnlockMigrations(ctx context.Context, owner string) error
+}
+
+type Logger interface {
+	Infof(string, ...interface{})
+}
+
+type Config struct {
+	Store      Store
+	Migrations []Migration
+	Logger     Logger
+	// Owner names this process in the lock. It defaults to the host name
+	// and process id.
+	Owner string
+	// LockTTL is how long the lock is held without being renewed, which
+	// bounds how long a crashed process blocks the others. It is renewed
+	// before each migration, so must outlast the slowest one.
+	LockTTL time.Duration
+	// LockTimeout is how long to wait for another process's lock.
+	LockTimeout time.Duration
+	// Poll is how often a locked-out process retries the lock.
+	Poll time.Duration
+	Now  func() time.Time
+}
+
+// Options narrow a run.
+type Options struct {
+	// To is the version to stop at. Up runs every pending migration up to
+	// and including it, zero for all of them; Down rolls back every
+	// applied migration above it, zero for all of them.
+	To int
+	// DryRun reports what would run without running it or taking the lock.
+	DryRun bool
+}
+
+// Step is a migration that was, or in a dry run would have been, run.
+type Step struct {
+	Version int    `json:"version"`
+	Name    string `json:"name"`
+	// Direction is "up" or "down".
+	Direction string `json:"direction"`
+}
+
+// Status is a migration and whether it has been applied.
+type Status struct {
+	Version   int        `json:"version"`
+	Name      string     `json:"name"`
+	AppliedAt *time.Time `json:"appliedAt,omitempty"`
+	// Unknown marks a recorded migration this build doesn't know, left by
+	// a newer version of the service.
+	Unknown bool `json:"unknown,omitempty"`
+}
+
+type Migrator struct {
+	store       Store
+	migrations  []Migration
+	logger      Logger
+	owner       string
+	lockTTL     time.Duration
+	lockTimeout time.Duration
+	poll        time.Duration
+	now         func() time.Time
+}
+
+// New panics if the migrations are misnumbered, as that is a programming
+// mistake rather than something to recover from.
+func New(cfg Config) *Migrator {
+	if cfg.Store == nil {
+		panic("migrate.New: store has nil value")
+	}
+
+	migrations := append([]Migration(nil), cfg.Migrations...)
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	for i, m := range migrations {
+		if m.Version < 1 || m.Name == "" || m.Up == nil {
+			panic(fmt.Sprintf("migrate.New: migration %d %q needs a positive version, a name and an Up", m.Version, m.Name))
+		}
+		if i > 0 && migrations[i-1].Version == m.Version {
+			panic(fmt.Sprintf("migrate.New: version %d is used twice", m.Version))
+		}
+	}
+
+	m := &Migrator{
+		store:       cfg.Store,
+		migrations:  migrations,
+		logger:      cfg.Logger,
+		owner:       cfg.Owner,
+		lockTTL:     cfg.LockTTL,
+		lockTimeout: cfg.LockTimeout,
+		poll:        cfg.Poll,
+		now:         cfg.Now,
+	}
+	if m.owner == "" {
+		host, _ := os.Hostname()
+		m.owner = fmt.Sprintf("%s:%d", host, os.Getpid())
+	}
+	if m.lockTTL <= 0 {
+		m.lockTTL = 15 * time.Minute
+	}
+	if m.lockTimeout <= 0 {
+		m.lockTimeout = 5 * time.Minute
+	}
+	if m.poll <= 0 {
+		m.poll = time.Second
+	}
+	if m.now == nil {
+		m.now = time.Now
+	}
+	return m
+}
+
+// Status lists every known migration, and any recorded migration this
+// build doesn't know, by version.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		s := Status{Version: mig.Version, Name: mig.Name}
+		if rec, ok := applied[mig.Version]; ok {
+			at := rec.AppliedAt
+			s.AppliedAt = &at
+			delete(applied, mig.Version)
+		}
+		statuses = append(statuses, s)
+	}
+	for _, rec := range applied {
+		at := rec.AppliedAt
+		statuses = append(statuses, Status{Version: rec.Version, Name: rec.Name, AppliedAt: &at, Unknown: true})
+	}
+
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+	return statuses, nil
+}
+
+// Up runs the pending migrations, oldest first, holding the lock while it
+// does. A process that has to wait for the lock picks up where the holder
+// left off, which is usually with nothing left to do.
+func (m *Migrator) Up(ctx context.Context, opts Options) ([]Step, error) {
+	return m.run(ctx, opts, "up")
+}
+
+// Down rolls back applied migrations above opts.To, newest first. Nothing
+// is rolled back if any of them is irreversible.
+func (m *Migrator) Down(ctx context.Context, opts Options) ([]Step, error) {
+	return m.run(ctx, opts, "down")
+}
+
+func (m *Migrator) run(ctx context.Context, opts Options, direction string) ([]Step, error) {
+	if opts.DryRun {
+		plan, err := m.plan(ctx, opts.To, direction)
+		if err != nil {
+			return nil, err
+		}
+		return steps(plan, direction), nil
+	}
+
+	if err := m.lock(ctx); err != nil {
+		return nil, err
+	}
+	defer func() {
+		// the lock is released even when ctx is why we're stopping
+		if err := m.store.UnlockMigrations(context.Background(), m.owner); err != nil {
+			m.infof("migrate: releasing the lock >> %v", err)
+		}
+	}()
+
+	// planned under the lock, so nothing another process ran is repeated
+	plan, err := m.plan(ctx, opts.To, direction)
+	if err != nil {
+		return nil, err
+	}
+
+	done := make([]Step, 0, len(plan))
+	for _, mig := range plan {
+		if err := m.renew(ctx); err != nil {
+			return done, err
+		}
+
+		started := m.now()
+		if direction == "up" {
+			err = mig.Up(ctx)
+		} else {
+			err = mig.Down(ctx)
+		}
+		if err != nil {
+			return done, fmt.Errorf("migrate: %s %d %s >> %w", direction, mig.Version, mig.Name, err)
+		}
+
+		if direction == "up" {
+			err = m.store.RecordMigration(ctx, &domain.SchemaMigration{
+				Version:   mig.Version,
+				Name:      mig.Name,
+				AppliedAt: started.UTC().Truncate(time.Millisecond),
+				Duration:  m.now().Sub(started),
+			})
+		} else {
+			err = m.store.RemoveMigration(ctx, mig.Version)
+		}
+		if err != nil {
+			return done, err
+		}
+
+		m.infof("migrate: %s %d %s in %s", direction, mig.Version, mig.Name, m.now().Sub(started))
+		done = append(done, Step{Version: mig.Version, Name: mig.Name, Direction: direction})
+	}
+
+	return done, nil
+}
+
+// plan lists the migrations to run, in the order to run them.
+func (m *Migrator) plan(ctx context.Context, to int, direction string) ([]Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var plan []Migration
+	if direction == "up" {
+		for _, mig := range m.migrations {
+			if _, ok := applied[mig.Version]; !ok && (to == 0 || mig.Version <= to) {
+				plan = append(plan, mig)
+			}
+		}
+		return plan, nil
+	}
+
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		mig := m.migrations[i]
+		if _, ok := applied[mig.Version]; !ok || mig.Version <= to {
+			continue
+		}
+		if mig.Down == nil {
+			return nil, fmt.Errorf("%w: %d %s", ErrIrreversible, mig.Version, mig.Name)
+		}
+		plan = append(plan, mig)
+	}
+	return plan, nil
+}
+
+func (m *Migrator) applied(ctx context.Context) (map[int]*domain.SchemaMigration, error) {
+	recs, err := m.store.AppliedMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int]*domain.SchemaMigration, len(recs))
+	for _, rec := range recs {
+		applied[rec.Version] = rec
+	}
+	return applied, nil
+}
+
+// lock waits up to the lock timeout for the lock.
+func (m *Migrator) lock(ctx context.Context) error {
+	deadline := m.now().Add(m.lockTimeout)
+	for {
+		now := m.now()
+		ok, err := m.store.LockMigrations(ctx, m.owner, now, now.Add(m.lockTTL))
+		if err != nil {
+			return err
+		}
+		if ok {
+			return nil
+		}
+		if !now.Before(deadline) {
+			return ErrLocked
+		}
+
+		m.infof("migrate: waiting for another process to finish migrating")
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(m.poll):
+		}
+	}
+}
+
+// renew extends the lock, failing if it has been lost to another process.
+func (m *Migrator) renew(ctx context.Context) error {
+	now := m.now()
+	ok, err := m.store.LockMigrations(ctx, m.owner, now, now.Add(m.lockTTL))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrLocked
+	}
+	return nil
+}
+
+func (m *Migrator) infof(format string, args ...interface{}) {
+	if m.logger != nil {
+		m.logger.Infof(format, args...)
+	}
+}
+
+func steps(plan []Migration, direction string) []Step {
+	s := make([]Step, len(plan))
+	for i, mig := range plan {
+		s[i] = Step{Version: mig.Version, Name: mig.Name, Direction: direction}
+	}
+	return s
+}
@@ -0,0 +1,202 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recorder is a fake database the test migrations change.
+type recorder struct {
+	mu  sync.Mutex
+	ran []string
+}
+
+func (r *recorder) step(name string) func(context.Context) error {
+	return func(context.Context) error {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.ran = append(r.ran, name)
+		return nil
+	}
+}
+
+func migrations(r *recorder) []Migration {
+	return []Migration{
+		{Version: 2, Name: "second", Up: r.step("up 2"), Down: r.step("down 2")},
+		{Version: 1, Name: "first", Up: r.step("up 1"), Down: r.step("down 1")},
+		{Version: 3, Name: "third", Up: r.step("up 3"), Down: r.step("down 3")},
+	}
+}
+
+func TestMigrator(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("when migrations are misnumbered, we should panic", func(t *testing.T) {
+		up := func(context.Context) error { return nil }
+		assert.Panics(t, func() {
+			New(Config{Store: NewMemoryStore(), Migrations: []Migration{{Version: 1, Name: "a", Up: up}, {Version: 1, Name: "b", Up: up}}})
+		})
+		assert.Panics(t, func() { New(Config{Store: NewMemoryStore(), Migrations: []Migration{{Version: 0, Name: "a", Up: up}}}) })
+		assert.Panics(t, func() { New(Config{}) })
+	})
+
+	t.Run("when migrating up, we should run pending migrations in version order once", func(t *testing.T) {
+		r := &recorder{}
+		store := NewMemoryStore()
+		m := New(Config{Store: store, Migrations: migrations(r)})
+
+		done, err := m.Up(ctx, Options{})
+		assert.NoError(t, err)
+		assert.Equal(t, []Step{{1, "first", "up"}, {2, "second", "up"}, {3, "third", "up"}}, done)
+		assert.Equal(t, []string{"up 1", "up 2", "up 3"}, r.ran)
+
+		done, err = m.Up(ctx, Options{})
+		assert.NoError(t, err)
+		assert.Empty(t, done)
+		assert.Len(t, r.ran, 3)
+
+		applied, _ := store.AppliedMigrations(ctx)
+		assert.Len(t, applied, 3)
+		assert.Equal(t, "third", applied[2].Name)
+	})
+
+	t.Run("when migrating up to a version, we should stop there", func(t *testing.T) {
+		r := &recorder{}
+		m := New(Config{Store: NewMemoryStore(), Migrations: migrations(r)})
+
+		_, err := m.Up(ctx, Options{To: 2})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"up 1", "up 2"}, r.ran)
+
+		statuses, err := m.Status(ctx)
+		assert.NoError(t, err)
+		assert.NotNil(t, statuses[1].AppliedAt)
+		assert.Nil(t, statuses[2].AppliedAt)
+	})
+
+	t.Run("when it is a dry run, we should report the plan without running it", func(t *testing.T) {
+		r := &recorder{}
+		store := NewMemoryStore()
+		m := New(Config{Store: store, Migrations: migrations(r)})
+
+		done, err := m.Up(ctx, Options{DryRun: true})
+		assert.NoError(t, err)
+		assert.Len(t, done, 3)
+		assert.Empty(t, r.ran)
+
+		applied, _ := store.AppliedMigrations(ctx)
+		assert.Empty(t, applied)
+	})
+
+	t.Run("when migrating down, we should roll back newest first to the version given", func(t *testing.T) {
+		r := &recorder{}
+		m := New(Config{Store: NewMemoryStore(), Migrations: migrations(r)})
+		_, err := m.Up(ctx, Options{})
+		assert.NoError(t, err)
+
+		done, err := m.Down(ctx, Options{To: 1})
+		assert.NoError(t, err)
+		assert.Equal(t, []Step{{3, "third", "down"}, {2, "second", "down"}}, done)
+
+		done, err = m.Up(ctx, Options{})
+		assert.NoError(t, err)
+		assert.Len(t, done, 2)
+		assert.Equal(t, []string{"up 1", "up 2", "up 3", "down 3", "down 2", "up 2", "up 3"}, r.ran)
+	})
+
+	t.Run("when a migration to roll back is irreversible, we should roll back nothing", func(t *testing.T) {
+		r := &recorder{}
+		list := migrations(r)
+		list[1].Down = nil // version 1
+		m := New(Config{Store: NewMemoryStore(), Migrations: list})
+		_, err := m.Up(ctx, Options{})
+		assert.NoError(t, err)
+
+		_, err = m.Down(ctx, Options{})
+		assert.ErrorIs(t, err, ErrIrreversible)
+		assert.Len(t, r.ran, 3)
+
+		_, err = m.Down(ctx, Options{To: 1})
+		assert.NoError(t, err)
+	})
+
+	t.Run("when a migration fails, we should keep what ran before it and stop", func(t *testing.T) {
+		r := &recorder{}
+		list := migrations(r)
+		list[0].Up = func(context.Context) error { return errors.New("boom") } // version 2
+		store := NewMemoryStore()
+		m := New(Config{Store: store, Migrations: list})
+
+		done, err := m.Up(ctx, Options{})
+		assert.ErrorContains(t, err, "up 2 second >> boom")
+		assert.Len(t, done, 1)
+		assert.Equal(t, []string{"up 1"}, r.ran)
+
+		applied, _ := store.AppliedMigrations(ctx)
+		assert.Len(t, applied, 1)
+
+		ok, _ := store.LockMigrations(ctx, "someone else", time.Now(), time.Now().Add(time.Minute))
+		assert.True(t, ok, "the lock should have been released")
+	})
+
+	t.Run("when another process holds the lock past the timeout, we should return ErrLocked", func(t *testing.T) {
+		r := &recorder{}
+		store := NewMemoryStore()
+		_, _ = store.LockMigrations(ctx, "other", time.Now(), time.Now().Add(time.Hour))
+		m := New(Config{Store: store, Migrations: migrations(r), LockTimeout: 20 * time.Millisecond, Poll: 5 * time.Millisecond})
+
+		_, err := m.Up(ctx, Options{})
+		assert.ErrorIs(t, err, ErrLocked)
+		assert.Empty(t, r.ran)
+	})
+
+	t.Run("when another process's lock has expired, we should take it over", func(t *testing.T) {
+		r := &recorder{}
+		store := NewMemoryStore()
+		_, _ = store.LockMigrations(ctx, "crashed", time.Now().Add(-time.Hour), time.Now().Add(-time.Minute))
+		m := New(Config{Store: store, Migrations: migrations(r)})
+
+		_, err := m.Up(ctx, Options{})
+		assert.NoError(t, err)
+		assert.Len(t, r.ran, 3)
+	})
+
+	t.Run("when replicas migrate at once, each migration should run once", func(t *testing.T) {
+		r := &recorder{}
+		store := NewMemoryStore()
+
+		var wg sync.WaitGroup
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				m := New(Config{Store: store, Migrations: migrations(r), Owner: string(rune('a' + i)), Poll: time.Millisecond})
+				_, err := m.Up(ctx, Options{})
+				assert.NoError(t, err)
+			}(i)
+		}
+		wg.Wait()
+
+		assert.Equal(t, []string{"up 1", "up 2", "up 3"}, r.ran)
+	})
+
+	t.Run("when the store knows a migration this build doesn't, status should flag it", func(t *testing.T) {
+		r := &recorder{}
+		store := NewMemoryStore()
+		m := New(Config{Store: store, Migrations: migrations(r)})
+		_, err := m.Up(ctx, Options{})
+		assert.NoError(t, err)
+
+		older := New(Config{Store: store, Migrations: migrations(r)[:2]})
+		statuses, err := older.Status(ctx)
+		assert.NoError(t, err)
+		assert.Len(t, statuses, 3)
+		assert.True(t, statuses[2].Unknown)
+		assert.Equal(t, "third", statuses[2].Name)
+	})
+}
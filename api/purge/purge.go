@@ -0,0 +1,64 @@
+// Package purge permanently removes features that have sat in the trash for
+// longer than the retention period.
+package purge
+
+import (
+	"context"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+//go:generate mockgen -destination=./mocks/purge.go -package=purgemocks -source=purge.go
+type PurgeDatabase interface {
+	PurgeDeleted(before time.Time) (int64, error)
+}
+
+type Purger struct {
+	db        PurgeDatabase
+	retention time.Duration
+	interval  time.Duration
+	logger    echo.Logger
+	now       func() time.Time
+}
+
+func New(db PurgeDatabase, retention, interval time.Duration, logger echo.Logger) *Purger {
+	if db == nil {
+		panic("purge.New: db has nil value")
+	}
+
+	return &Purger{
+		db:        db,
+		retention: retention,
+		interval:  interval,
+		logger:    logger,
+		now:       time.Now,
+	}
+}
+
+// Once purges every feature deleted more than the retention period ago.
+func (p *Purger) Once() (int64, error) {
+	return p.db.PurgeDeleted(p.now().UTC().Add(-p.retention))
+}
+
+// Run purges once straight away and then every interval until ctx is done.
+// Failures are logged and retried on the next tick.
+func (p *Purger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		n, err := p.Once()
+		if err != nil {
+			p.logger.Errorf("purge.Run: PurgeDeleted >> %v", err)
+		} else if n > 0 {
+			p.logger.Infof("purge.Run: purged %d deleted features", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package purge
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	purgemocks "github.com/music-tribe/react-pairing-challenge/purge/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPurger(t *testing.T) {
+	logger := echo.New().Logger
+	now := time.Date(2024, 3, 31, 9, 30, 0, 0, time.UTC)
+
+	t.Run("when the db has a nil value, we should panic", func(t *testing.T) {
+		assert.Panics(t, func() {
+			New(nil, time.Hour, time.Hour, logger)
+		})
+	})
+
+	t.Run("when we purge, the cutoff should be the retention period ago", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := purgemocks.NewMockPurgeDatabase(ctrl)
+
+		p := New(db, 30*24*time.Hour, time.Hour, logger)
+		p.now = func() time.Time { return now }
+
+		db.EXPECT().PurgeDeleted(time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)).Return(int64(2), nil)
+
+		n, err := p.Once()
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), n)
+	})
+
+	t.Run("when the db fails, run should keep going until it is cancelled", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := purgemocks.NewMockPurgeDatabase(ctrl)
+
+		p := New(db, time.Hour, time.Millisecond, logger)
+		p.now = func() time.Time { return now }
+
+		ctx, cancel := context.WithCancel(context.Background())
+		calls := 0
+		db.EXPECT().PurgeDeleted(now.Add(-time.Hour)).DoAndReturn(func(time.Time) (int64, error) {
+			calls++
+			if calls == 2 {
+				cancel()
+			}
+			return 0, errors.New("some error")
+		}).MinTimes(2)
+
+		done := make(chan struct{})
+		go func() {
+			p.Run(ctx)
+			close(done)
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("run didn't stop after cancel")
+		}
+	})
+}
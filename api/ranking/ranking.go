@@ -0,0 +1,132 @@
+// Package ranking scores feature requests so that lists can be ordered by
+// more than their raw vote count.
+//
+// Hot and rising are time decayed sums of votes: every vote is worth its
+// weight when cast and halves in value every half life. Comparing the decayed
+// sums of two features at any moment gives the same answer as comparing
+//
+//	ln(sum(weight * e^((castAt - epoch) / tau)))
+//
+// which never changes as time passes, so it can be stored on the feature,
+// indexed and sorted on directly. Each vote folds into the stored score with
+// a single log-add-exp, so nothing has to be recomputed at query time.
+package ranking
+
+import (
+	"math"
+	"time"
+
+	"github.com/music-tribe/react-pairing-challenge/domain"
+)
+
+type Sort string
+
+const (
+	// SortHot favours features with a lot of recent votes.
+	SortHot Sort = "hot"
+	// SortTop orders by vote count, regardless of age.
+	SortTop Sort = "top"
+	// SortNew orders by creation time, newest first.
+	SortNew Sort = "new"
+	// SortRising favours features gaining votes in the last few hours.
+	SortRising Sort = "rising"
+)
+
+const (
+	// HotHalfLife is how long it takes a vote to lose half its hot value.
+	HotHalfLife = 24 * time.Hour
+	// RisingHalfLife is much shorter so only very recent activity counts.
+	RisingHalfLife = 2 * time.Hour
+)
+
+// epoch anchors the exponent so stored scores stay small numbers.
+var epoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Scores are the stored, time invariant ranking scores of a feature.
+type Scores struct {
+	Hot    float64
+	Rising float64
+}
+
+// Initial returns the scores of a feature with no votes. Creating a feature
+// counts as a single vote so brand new requests get a chance to be seen.
+func Initial(createdAt time.Time) Scores {
+	return Scores{
+		Hot:    Exponent(createdAt, 1, HotHalfLife),
+		Rising: Exponent(createdAt, 1, RisingHalfLife),
+	}
+}
+
+// AddVote folds a vote of the given weight into s.
+func (s Scores) AddVote(castAt time.Time, weight int) Scores {
+	return Scores{
+		Hot:    LogAddExp(s.Hot, Exponent(castAt, weight, HotHalfLife)),
+		Rising: LogAddExp(s.Rising, Exponent(castAt, weight, RisingHalfLife)),
+	}
+}
+
+// Recompute calculates the scores of a feature from scratch. Only upvotes
+// make a feature hot or rising; downvotes are reflected in its net score.
+func Recompute(createdAt time.Time, votes []*domain.Vote) Scores {
+	s := Initial(createdAt)
+	for _, vote := range votes {
+		if vote.Direction == domain.Downvote {
+			continue
+		}
+		s = s.AddVote(vote.CastAt, vote.Weight)
+	}
+	return s
+}
+
+// Tally is the vote totals of a feature.
+type Tally struct {
+	Votes     int64
+	Upvotes   int64
+	Downvotes int64
+	// Score is the net weighted score, upvotes minus downvotes.
+	Score int64
+}
+
+// Count totals votes.
+func Count(votes []*domain.Vote) Tally {
+	t := Tally{}
+	for _, vote := range votes {
+		t.Votes++
+		if vote.Direction == domain.Downvote {
+			t.Downvotes++
+		} else {
+			t.Upvotes++
+		}
+		t.Score += vote.Direction.Sign() * Weight(vote.Weight)
+	}
+	return t
+}
+
+// Weight is the effective weight of a vote; votes without one count once.
+func Weight(weight int) int64 {
+	if weight < 1 {
+		return 1
+	}
+	return int64(weight)
+}
+
+// Exponent is the log space contribution of a single vote cast at t.
+func Exponent(t time.Time, weight int, halfLife time.Duration) float64 {
+	tau := halfLife.Seconds() / math.Ln2
+	return math.Log(float64(Weight(weight))) + t.Sub(epoch).Seconds()/tau
+}
+
+// LogAddExp returns ln(e^a + e^b) without overflowing.
+func LogAddExp(a, b float64) float64 {
+	if a < b {
+		a, b = b, a
+	}
+	return a + math.Log1p(math.Exp(b-a))
+}
+
+// Decayed converts a stored score into the decayed vote total at now, which
+// is easier to reason about when displaying or debugging a score.
+func Decayed(score float64, halfLife time.Duration, now time.Time) float64 {
+	tau := halfLife.Seconds() / math.Ln2
+	return math.Exp(score - now.Sub(epoch).Seconds()/tau)
+}
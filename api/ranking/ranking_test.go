@@ -0,0 +1,95 @@
+package ranking
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogAddExp(t *testing.T) {
+	t.Run("it should match the naive calculation for small values", func(t *testing.T) {
+		assert.InDelta(t, math.Log(math.Exp(1)+math.Exp(2)), LogAddExp(1, 2), 1e-9)
+		assert.InDelta(t, math.Log(math.Exp(1)+math.Exp(2)), LogAddExp(2, 1), 1e-9)
+	})
+
+	t.Run("it should not overflow for large values", func(t *testing.T) {
+		assert.InDelta(t, 1000+math.Ln2, LogAddExp(1000, 1000), 1e-9)
+	})
+}
+
+func TestScores(t *testing.T) {
+	created := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("a vote should be worth half as much after one half life", func(t *testing.T) {
+		vote := Exponent(created, 1, HotHalfLife)
+		assert.InDelta(t, 1, Decayed(vote, HotHalfLife, created), 1e-9)
+		assert.InDelta(t, 0.5, Decayed(vote, HotHalfLife, created.Add(HotHalfLife)), 1e-9)
+	})
+
+	t.Run("adding votes one at a time should match recomputing from scratch", func(t *testing.T) {
+		votes := []*domain.Vote{
+			{CastAt: created.Add(time.Hour), Weight: 1},
+			{CastAt: created.Add(5 * time.Hour), Weight: 3},
+			{CastAt: created.Add(30 * time.Hour), Weight: 1},
+		}
+
+		s := Initial(created)
+		for _, vote := range votes {
+			s = s.AddVote(vote.CastAt, vote.Weight)
+		}
+
+		expect := Recompute(created, votes)
+		assert.InDelta(t, expect.Hot, s.Hot, 1e-9)
+		assert.InDelta(t, expect.Rising, s.Rising, 1e-9)
+	})
+
+	t.Run("a new request with a few recent votes should be hotter than an old one with many stale votes", func(t *testing.T) {
+		old := Initial(created)
+		for i := 0; i < 50; i++ {
+			old = old.AddVote(created.Add(time.Duration(i)*time.Hour), 1)
+		}
+
+		recent := created.Add(30 * 24 * time.Hour)
+		fresh := Initial(recent)
+		for i := 0; i < 3; i++ {
+			fresh = fresh.AddVote(recent.Add(time.Duration(i)*time.Minute), 1)
+		}
+
+		assert.Greater(t, fresh.Hot, old.Hot)
+		assert.Greater(t, fresh.Rising, old.Rising)
+	})
+
+	t.Run("a heavier vote should count for more", func(t *testing.T) {
+		light := Initial(created).AddVote(created, 1)
+		heavy := Initial(created).AddVote(created, 3)
+		assert.Greater(t, heavy.Hot, light.Hot)
+	})
+}
+
+func TestCount(t *testing.T) {
+	t.Run("it should total up and down votes and the weighted net score", func(t *testing.T) {
+		votes := []*domain.Vote{
+			{Weight: 2},
+			{Direction: domain.Upvote, Weight: 1},
+			{Direction: domain.Downvote, Weight: 3},
+			{Direction: domain.Downvote},
+		}
+
+		assert.Equal(t, Tally{Votes: 4, Upvotes: 2, Downvotes: 2, Score: -1}, Count(votes))
+	})
+}
+
+func TestRecompute(t *testing.T) {
+	created := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("downvotes should not make a feature hot", func(t *testing.T) {
+		votes := []*domain.Vote{
+			{CastAt: created.Add(time.Hour), Direction: domain.Downvote, Weight: 1},
+		}
+
+		assert.Equal(t, Initial(created), Recompute(created, votes))
+	})
+}
@@ -0,0 +1,118 @@
+package ratelimit
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// maxIdentityBody bounds how much of a request body is read looking for an
+// identity.
+const maxIdentityBody = 64 << 10
+
+// Identity names who a request comes from, or returns "" when it can't
+// tell. Names are prefixed with their kind so different kinds never share
+// a bucket.
+type Identity func(c echo.Context) string
+
+// IP identifies requests by client address, as found by the server's
+// IPExtractor.
+func IP() Identity {
+	return func(c echo.Context) string {
+		return "ip:" + c.RealIP()
+	}
+}
+
+// IPExtractor tells echo where the client address of a request is. Left
+// to itself echo believes any X-Forwarded-For or X-Real-IP header, so a
+// client could name a new address on every request and never be limited.
+// Requests are taken to come from the connection's own address, unless it
+// is one of trusted, the proxies in front of the server; then the nearest
+// address in X-Forwarded-For that isn't a trusted proxy is used.
+func IPExtractor(trusted []*net.IPNet) echo.IPExtractor {
+	if len(trusted) == 0 {
+		return echo.ExtractIPDirect()
+	}
+
+	opts := []echo.TrustOption{echo.TrustLoopback(false), echo.TrustLinkLocal(false), echo.TrustPrivateNet(false)}
+	for _, r := range trusted {
+		opts = append(opts, echo.TrustIPRange(r))
+	}
+	return echo.ExtractIPFromXFFHeader(opts...)
+}
+
+// Param identifies requests by the user id in a path parameter.
+func Param(name string) Identity {
+	return func(c echo.Context) string {
+		return prefixed("user:", c.Param(name))
+	}
+}
+
+// Query identifies requests by the user id in a query parameter.
+func Query(name string) Identity {
+	return func(c echo.Context) string {
+		return prefixed("user:", c.QueryParam(name))
+	}
+}
+
+// BodyField identifies requests by the user id in a field of a JSON body.
+// The body is left in place for the handler.
+func BodyField(name string) Identity {
+	return func(c echo.Context) string {
+		req := c.Request()
+		if req.Body == nil || !strings.HasPrefix(req.Header.Get(echo.HeaderContentType), echo.MIMEApplicationJSON) {
+			return ""
+		}
+
+		body, err := io.ReadAll(io.LimitReader(req.Body, maxIdentityBody))
+		req.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), req.Body))
+		if err != nil {
+			return ""
+		}
+
+		fields := map[string]interface{}{}
+		if json.Unmarshal(body, &fields) != nil {
+			return ""
+		}
+		id, _ := fields[name].(string)
+		return prefixed("user:", id)
+	}
+}
+
+// APIKey identifies requests by their bearer token. Only a hash of the
+// token is kept.
+func APIKey() Identity {
+	return func(c echo.Context) string {
+		token, ok := strings.CutPrefix(c.Request().Header.Get(echo.HeaderAuthorization), "Bearer ")
+		if !ok || token == "" {
+			return ""
+		}
+		sum := sha256.Sum256([]byte(token))
+		return "key:" + hex.EncodeToString(sum[:8])
+	}
+}
+
+// FirstOf uses the first identity that can name the request.
+func FirstOf(identities ...Identity) Identity {
+	return func(c echo.Context) string {
+		for _, identify := range identities {
+			if id := identify(c); id != "" {
+				return id
+			}
+		}
+		return ""
+	}
+}
+
+func prefixed(prefix, id string) string {
+	if id == "" {
+		return ""
+	}
+	return prefix + id
+}
@@ -0,0 +1,59 @@
+package ratelimit
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdentity(t *testing.T) {
+	e := echo.New()
+
+	t.Run("when the user id is in a JSON body, it should be read and the body left for the handler", func(t *testing.T) {
+		body := `{"userId":"202c25c4-b2ce-4514-9045-890a1aa896ea","direction":"up"}`
+		req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		ctx := e.NewContext(req, httptest.NewRecorder())
+
+		assert.Equal(t, "user:202c25c4-b2ce-4514-9045-890a1aa896ea", BodyField("userId")(ctx))
+
+		rest, err := io.ReadAll(ctx.Request().Body)
+		assert.NoError(t, err)
+		assert.Equal(t, body, string(rest))
+	})
+
+	t.Run("when the body isn't JSON, no user should be found", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader("userId=1"))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+		ctx := e.NewContext(req, httptest.NewRecorder())
+
+		assert.Empty(t, BodyField("userId")(ctx))
+	})
+
+	t.Run("when there is no user, the first identity that names the request should be used", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodDelete, "/?userId=abc", nil)
+		req.RemoteAddr = "203.0.113.9:4242"
+		ctx := e.NewContext(req, httptest.NewRecorder())
+
+		assert.Equal(t, "user:abc", FirstOf(Param("userId"), Query("userId"), IP())(ctx))
+		assert.Equal(t, "ip:203.0.113.9", FirstOf(Param("userId"), IP())(ctx))
+	})
+
+	t.Run("when a bearer token is sent, only a hash of it should be used", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer s3cret")
+		ctx := e.NewContext(req, httptest.NewRecorder())
+
+		id := APIKey()(ctx)
+		assert.True(t, strings.HasPrefix(id, "key:"))
+		assert.NotContains(t, id, "s3cret")
+
+		req.Header.Del(echo.HeaderAuthorization)
+		assert.Empty(t, APIKey()(ctx))
+	})
+}
@@ -0,0 +1,85 @@
+// Package ratelimit throttles clients with token buckets, one per route
+// group and identity.
+package ratelimit
+
+import (
+	"math"
+	"time"
+)
+
+// Limit allows Requests requests per Period. A client may spend its whole
+// allowance in a burst; it then refills evenly over the period.
+type Limit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// Unlimited reports whether the limit lets everything through.
+func (l Limit) Unlimited() bool {
+	return l.Requests <= 0 || l.Period <= 0
+}
+
+// perSecond is how many tokens the bucket regains each second.
+func (l Limit) perSecond() float64 {
+	return float64(l.Requests) / l.Period.Seconds()
+}
+
+// Result is the outcome of taking a token from a bucket.
+type Result struct {
+	Allowed bool
+	// Remaining is how many requests could be made straight away.
+	Remaining int
+	// Reset is how long until the bucket is full again.
+	Reset time.Duration
+	// RetryAfter is how long until the next request would be allowed,
+	// zero when it would be allowed now.
+	RetryAfter time.Duration
+}
+
+// Store holds the buckets. Implementations must take tokens atomically so
+// that limits hold across every replica sharing the store.
+type Store interface {
+	// Take removes a token from key's bucket, created full on first use,
+	// reporting whether there was one to take.
+	Take(key string, limit Limit, now time.Time) (Result, error)
+	// Peek reports what Take would, without taking a token.
+	Peek(key string, limit Limit, now time.Time) (Result, error)
+}
+
+// bucket is a token bucket's state at a point in time.
+type bucket struct {
+	tokens float64
+	at     time.Time
+}
+
+// take refills b up to now and tries to take a token from it.
+func (b *bucket) take(limit Limit, now time.Time) Result {
+	capacity := float64(limit.Requests)
+	rate := limit.perSecond()
+
+	if elapsed := now.Sub(b.at).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(capacity, b.tokens+elapsed*rate)
+		b.at = now
+	}
+
+	res := Result{}
+	if b.tokens >= 1 {
+		b.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = seconds((1 - b.tokens) / rate)
+	}
+	res.Remaining = int(b.tokens)
+	res.Reset = seconds((capacity - b.tokens) / rate)
+
+	return res
+}
+
+// peek is take on a copy of b, leaving b as it was.
+func (b bucket) peek(limit Limit, now time.Time) Result {
+	return b.take(limit, now)
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
@@ -0,0 +1,82 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStore(t *testing.T) {
+	now := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	limit := Limit{Requests: 3, Period: 3 * time.Second}
+
+	t.Run("when a bucket is used up, requests should be refused until it refills", func(t *testing.T) {
+		s := NewMemoryStore()
+
+		for i := 2; i >= 0; i-- {
+			res, err := s.Take("a", limit, now)
+			assert.NoError(t, err)
+			assert.True(t, res.Allowed)
+			assert.Equal(t, i, res.Remaining)
+		}
+
+		res, _ := s.Take("a", limit, now)
+		assert.False(t, res.Allowed)
+		assert.Equal(t, time.Second, res.RetryAfter)
+		assert.Equal(t, 3*time.Second, res.Reset)
+
+		res, _ = s.Take("a", limit, now.Add(time.Second))
+		assert.True(t, res.Allowed)
+		assert.Equal(t, 0, res.Remaining)
+	})
+
+	t.Run("when a bucket is peeked at, no token should be taken", func(t *testing.T) {
+		s := NewMemoryStore()
+		one := Limit{Requests: 1, Period: time.Minute}
+
+		for i := 0; i < 2; i++ {
+			res, err := s.Peek("a", one, now)
+			assert.NoError(t, err)
+			assert.True(t, res.Allowed)
+		}
+		assert.Equal(t, 0, s.Len())
+
+		s.Take("a", one, now)
+		res, _ := s.Peek("a", one, now)
+		assert.False(t, res.Allowed)
+		assert.Equal(t, time.Minute, res.RetryAfter)
+	})
+
+	t.Run("when keys differ, they should have their own buckets", func(t *testing.T) {
+		s := NewMemoryStore()
+		one := Limit{Requests: 1, Period: time.Minute}
+
+		res, _ := s.Take("a", one, now)
+		assert.True(t, res.Allowed)
+		res, _ = s.Take("b", one, now)
+		assert.True(t, res.Allowed)
+		res, _ = s.Take("a", one, now)
+		assert.False(t, res.Allowed)
+	})
+
+	t.Run("when a bucket has been idle long enough, it should never hold more than its limit", func(t *testing.T) {
+		s := NewMemoryStore()
+		s.Take("a", limit, now)
+
+		res, _ := s.Take("a", limit, now.Add(time.Hour))
+		assert.Equal(t, 2, res.Remaining)
+	})
+
+	t.Run("when buckets have refilled, they should be swept", func(t *testing.T) {
+		s := NewMemoryStore()
+		s.Take("idle", limit, now)
+
+		later := now.Add(time.Minute)
+		for i := 0; i < sweepEvery; i++ {
+			s.Take("busy", Limit{Requests: 1, Period: time.Hour}, later)
+		}
+
+		assert.Equal(t, 1, s.Len())
+	})
+}
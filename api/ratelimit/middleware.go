@@ -0,0 +1,152 @@
+package ratelimit
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Rule limits each identity to Limit.
+type Rule struct {
+	Identity Identity
+	Limit    Limit
+}
+
+type Config struct {
+	// Group names the routes sharing these buckets.
+	Group string
+	Store Store
+	// Rules must all allow a request for it to go through.
+	Rules []Rule
+	// Now is swapped out in tests; it defaults to time.Now.
+	Now func() time.Time
+}
+
+var errLimited = errors.New("rate limit exceeded")
+
+// Limiter checks requests against a group's rules.
+type Limiter struct {
+	cfg Config
+}
+
+// Decision is the outcome of checking a request against every rule.
+type Decision struct {
+	Allowed bool
+	// Limit and Result are those of the rule refusing the request, or when
+	// none did, of the rule closest to its limit. Result is nil when no rule
+	// applied.
+	Limit  Limit
+	Result *Result
+}
+
+func NewLimiter(cfg Config) *Limiter {
+	if cfg.Store == nil {
+		panic("ratelimit.NewLimiter: store has nil value")
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	return &Limiter{cfg: cfg}
+}
+
+// Allow takes a token for c from the bucket of every rule identifying it,
+// unless one of them would refuse it, in which case none is charged. If the
+// store fails the rule is skipped, so an outage of a shared store doesn't
+// take the API down with it.
+func (l *Limiter) Allow(c echo.Context) Decision {
+	now := l.cfg.Now()
+
+	type bucketRule struct {
+		key   string
+		limit Limit
+	}
+	var rules []bucketRule
+	for _, rule := range l.cfg.Rules {
+		if rule.Limit.Unlimited() {
+			continue
+		}
+		if id := rule.Identity(c); id != "" {
+			rules = append(rules, bucketRule{key: l.cfg.Group + "|" + id, limit: rule.Limit})
+		}
+	}
+
+	// a request refused by one rule mustn't use up the others
+	refused := Decision{}
+	for _, rule := range rules {
+		res, err := l.cfg.Store.Peek(rule.key, rule.limit, now)
+		if err != nil {
+			c.Logger().Errorf("ratelimit.Allow: Peek >> %v", err)
+			continue
+		}
+		if !res.Allowed && (refused.Result == nil || res.RetryAfter > refused.Result.RetryAfter) {
+			r := res
+			refused = Decision{Limit: rule.limit, Result: &r}
+		}
+	}
+	if refused.Result != nil {
+		return refused
+	}
+
+	d := Decision{Allowed: true}
+	for _, rule := range rules {
+		res, err := l.cfg.Store.Take(rule.key, rule.limit, now)
+		if err != nil {
+			c.Logger().Errorf("ratelimit.Allow: Take >> %v", err)
+			continue
+		}
+
+		// another request may have taken the last token since the peek
+		if !res.Allowed && (refused.Result == nil || res.RetryAfter > refused.Result.RetryAfter) {
+			r := res
+			refused = Decision{Limit: rule.limit, Result: &r}
+		}
+		if d.Result == nil || res.Remaining < d.Result.Remaining {
+			r := res
+			d.Limit, d.Result = rule.limit, &r
+		}
+	}
+	if refused.Result != nil {
+		return refused
+	}
+	return d
+}
+
+// New returns middleware enforcing cfg's rules. Responses carry the
+// RateLimit-* headers of the rule closest to its limit; refused requests
+// get a 429 with Retry-After.
+func New(cfg Config) echo.MiddlewareFunc {
+	if cfg.Store == nil {
+		panic("ratelimit.New: store has nil value")
+	}
+	limiter := NewLimiter(cfg)
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			d := limiter.Allow(c)
+
+			if res := d.Result; res != nil {
+				h := c.Response().Header()
+				h.Set("RateLimit-Limit", strconv.Itoa(d.Limit.Requests))
+				h.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+				h.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(res.Reset)))
+				h.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", d.Limit.Requests, ceilSeconds(d.Limit.Period)))
+			}
+
+			if !d.Allowed {
+				c.Response().Header().Set(echo.HeaderRetryAfter, strconv.Itoa(ceilSeconds(d.Result.RetryAfter)))
+				return echo.NewHTTPError(http.StatusTooManyRequests, errLimited)
+			}
+
+			return next(c)
+		}
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
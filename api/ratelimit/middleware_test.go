@@ -0,0 +1,230 @@
+package ratelimit
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+type failingStore struct{}
+
+func (failingStore) Take(string, Limit, time.Time) (Result, error) {
+	return Result{}, errors.New("store unavailable")
+}
+
+func (failingStore) Peek(string, Limit, time.Time) (Result, error) {
+	return Result{}, errors.New("store unavailable")
+}
+
+func TestNew(t *testing.T) {
+	now := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	ok := func(c echo.Context) error { return c.String(http.StatusOK, "OK") }
+
+	// serve runs a request from addr through the middleware
+	serve := func(mw echo.MiddlewareFunc, addr string) (*httptest.ResponseRecorder, error) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.RemoteAddr = addr + ":1234"
+		rec := httptest.NewRecorder()
+		err := mw(ok)(e.NewContext(req, rec))
+		return rec, err
+	}
+
+	t.Run("when the store has a nil value, we should panic", func(t *testing.T) {
+		assert.Panics(t, func() {
+			New(Config{})
+		})
+	})
+
+	t.Run("when a client is within its limit, it should be let through with the rate limit headers", func(t *testing.T) {
+		mw := New(Config{
+			Group: "writes",
+			Store: NewMemoryStore(),
+			Rules: []Rule{{Identity: IP(), Limit: Limit{Requests: 2, Period: time.Minute}}},
+			Now:   func() time.Time { return now },
+		})
+
+		rec, err := serve(mw, "203.0.113.1")
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "2", rec.Header().Get("RateLimit-Limit"))
+		assert.Equal(t, "1", rec.Header().Get("RateLimit-Remaining"))
+		assert.Equal(t, "30", rec.Header().Get("RateLimit-Reset"))
+		assert.Equal(t, "2;w=60", rec.Header().Get("RateLimit-Policy"))
+	})
+
+	t.Run("when a client goes over its limit, it should get a 429 until the bucket refills", func(t *testing.T) {
+		clock := now
+		mw := New(Config{
+			Group: "votes",
+			Store: NewMemoryStore(),
+			Rules: []Rule{{Identity: IP(), Limit: Limit{Requests: 2, Period: time.Minute}}},
+			Now:   func() time.Time { return clock },
+		})
+
+		serve(mw, "203.0.113.1")
+		serve(mw, "203.0.113.1")
+
+		rec, err := serve(mw, "203.0.113.1")
+		assert.ErrorContains(t, err, errLimited.Error())
+		assert.Equal(t, http.StatusTooManyRequests, getStatusCode(rec, err))
+		assert.Equal(t, "30", rec.Header().Get(echo.HeaderRetryAfter))
+		assert.Equal(t, "0", rec.Header().Get("RateLimit-Remaining"))
+
+		// another client is unaffected
+		_, err = serve(mw, "203.0.113.2")
+		assert.NoError(t, err)
+
+		clock = clock.Add(30 * time.Second)
+		_, err = serve(mw, "203.0.113.1")
+		assert.NoError(t, err)
+	})
+
+	t.Run("when any rule refuses a request, it should get a 429", func(t *testing.T) {
+		mw := New(Config{
+			Group: "writes",
+			Store: NewMemoryStore(),
+			Rules: []Rule{
+				{Identity: IP(), Limit: Limit{Requests: 100, Period: time.Minute}},
+				{Identity: func(echo.Context) string { return "user:same" }, Limit: Limit{Requests: 1, Period: time.Hour}},
+			},
+			Now: func() time.Time { return now },
+		})
+
+		_, err := serve(mw, "203.0.113.1")
+		assert.NoError(t, err)
+
+		rec, err := serve(mw, "203.0.113.2")
+		assert.Equal(t, http.StatusTooManyRequests, getStatusCode(rec, err))
+		assert.Equal(t, "3600", rec.Header().Get(echo.HeaderRetryAfter))
+		assert.Equal(t, "1", rec.Header().Get("RateLimit-Limit"))
+	})
+
+	t.Run("when one rule refuses a request, the other rules should not be charged for it", func(t *testing.T) {
+		store := NewMemoryStore()
+		user := func(echo.Context) string { return "user:same" }
+		mw := New(Config{
+			Group: "votes",
+			Store: store,
+			Rules: []Rule{
+				{Identity: user, Limit: Limit{Requests: 2, Period: time.Hour}},
+				{Identity: IP(), Limit: Limit{Requests: 1, Period: time.Hour}},
+			},
+			Now: func() time.Time { return now },
+		})
+
+		_, err := serve(mw, "203.0.113.1")
+		assert.NoError(t, err)
+		for i := 0; i < 3; i++ {
+			rec, err := serve(mw, "203.0.113.1")
+			assert.Equal(t, http.StatusTooManyRequests, getStatusCode(rec, err))
+		}
+
+		// the user spent one of its two tokens; the refusals took none
+		res, _ := store.Peek("votes|user:same", Limit{Requests: 2, Period: time.Hour}, now)
+		assert.True(t, res.Allowed)
+		_, err = serve(mw, "203.0.113.2")
+		assert.NoError(t, err)
+	})
+
+	t.Run("when a rule is unlimited or can't identify the client, it should be skipped", func(t *testing.T) {
+		mw := New(Config{
+			Group: "writes",
+			Store: NewMemoryStore(),
+			Rules: []Rule{
+				{Identity: IP(), Limit: Limit{}},
+				{Identity: APIKey(), Limit: Limit{Requests: 1, Period: time.Hour}},
+			},
+			Now: func() time.Time { return now },
+		})
+
+		for i := 0; i < 3; i++ {
+			rec, err := serve(mw, "203.0.113.1")
+			assert.NoError(t, err)
+			assert.Empty(t, rec.Header().Get("RateLimit-Limit"))
+		}
+	})
+
+	t.Run("when a client makes up X-Forwarded-For addresses, it should still get a 429", func(t *testing.T) {
+		mw := New(Config{
+			Group: "votes",
+			Store: NewMemoryStore(),
+			Rules: []Rule{{Identity: IP(), Limit: Limit{Requests: 2, Period: time.Minute}}},
+			Now:   func() time.Time { return now },
+		})
+
+		e := echo.New()
+		e.IPExtractor = IPExtractor(nil)
+		var rec *httptest.ResponseRecorder
+		var err error
+		for i := 1; i <= 3; i++ {
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			req.RemoteAddr = "203.0.113.1:1234"
+			req.Header.Set(echo.HeaderXForwardedFor, fmt.Sprintf("198.51.100.%d", i))
+			req.Header.Set(echo.HeaderXRealIP, fmt.Sprintf("198.51.100.%d", i))
+			rec = httptest.NewRecorder()
+			err = mw(ok)(e.NewContext(req, rec))
+		}
+		assert.Equal(t, http.StatusTooManyRequests, getStatusCode(rec, err))
+	})
+
+	t.Run("when requests come through a trusted proxy, the client it forwards for should be limited", func(t *testing.T) {
+		mw := New(Config{
+			Group: "votes",
+			Store: NewMemoryStore(),
+			Rules: []Rule{{Identity: IP(), Limit: Limit{Requests: 2, Period: time.Minute}}},
+			Now:   func() time.Time { return now },
+		})
+
+		_, proxies, _ := net.ParseCIDR("10.0.0.0/8")
+		e := echo.New()
+		e.IPExtractor = IPExtractor([]*net.IPNet{proxies})
+		serve := func(forwarded string) (*httptest.ResponseRecorder, error) {
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			req.RemoteAddr = "10.0.0.2:1234"
+			req.Header.Set(echo.HeaderXForwardedFor, forwarded)
+			rec := httptest.NewRecorder()
+			return rec, mw(ok)(e.NewContext(req, rec))
+		}
+
+		// the client's own guesses come before the address the proxy saw
+		serve("198.51.100.1, 203.0.113.1")
+		serve("198.51.100.2, 203.0.113.1")
+		rec, err := serve("198.51.100.3, 203.0.113.1")
+		assert.Equal(t, http.StatusTooManyRequests, getStatusCode(rec, err))
+
+		_, err = serve("203.0.113.2")
+		assert.NoError(t, err)
+	})
+
+	t.Run("when the store fails, the request should be let through", func(t *testing.T) {
+		mw := New(Config{
+			Group: "writes",
+			Store: failingStore{},
+			Rules: []Rule{{Identity: IP(), Limit: Limit{Requests: 1, Period: time.Minute}}},
+		})
+
+		_, err := serve(mw, "203.0.113.1")
+		assert.NoError(t, err)
+	})
+}
+
+func getStatusCode(rec *httptest.ResponseRecorder, err error) int {
+	if err == nil {
+		return rec.Code
+	}
+
+	hterr := &echo.HTTPError{}
+	if errors.As(err, &hterr) {
+		return hterr.Code
+	}
+
+	return 500
+}
@@ -1,19 +1,42 @@
 package main
 
 import (
+	"context"
 	"net/http"
-	"os"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
-	"github.com/music-tribe/react-pairing-challenge/database"
+	"github.com/music-tribe/react-pairing-challenge/auth"
+	"github.com/music-tribe/react-pairing-challenge/cache"
+	"github.com/music-tribe/react-pairing-challenge/config"
 	_ "github.com/music-tribe/react-pairing-challenge/docs/features-api"
+	"github.com/music-tribe/react-pairing-challenge/events"
 	"github.com/music-tribe/react-pairing-challenge/handlers/add"
+	"github.com/music-tribe/react-pairing-challenge/handlers/audit"
+	"github.com/music-tribe/react-pairing-challenge/handlers/cachestats"
+	"github.com/music-tribe/react-pairing-challenge/handlers/categories"
 	"github.com/music-tribe/react-pairing-challenge/handlers/delete"
+	"github.com/music-tribe/react-pairing-challenge/handlers/export"
 	"github.com/music-tribe/react-pairing-challenge/handlers/get"
 	"github.com/music-tribe/react-pairing-challenge/handlers/getall"
+	"github.com/music-tribe/react-pairing-challenge/handlers/history"
+	"github.com/music-tribe/react-pairing-challenge/handlers/imports"
+	"github.com/music-tribe/react-pairing-challenge/handlers/search"
+	"github.com/music-tribe/react-pairing-challenge/handlers/socket"
+	"github.com/music-tribe/react-pairing-challenge/handlers/status"
+	"github.com/music-tribe/react-pairing-challenge/handlers/stream"
+	"github.com/music-tribe/react-pairing-challenge/handlers/trash"
 	"github.com/music-tribe/react-pairing-challenge/handlers/update"
 	"github.com/music-tribe/react-pairing-challenge/handlers/upvote"
+	"github.com/music-tribe/react-pairing-challenge/handlers/uservotes"
+	"github.com/music-tribe/react-pairing-challenge/handlers/webhooks"
+	"github.com/music-tribe/react-pairing-challenge/idempotency"
+	"github.com/music-tribe/react-pairing-challenge/live"
+	"github.com/music-tribe/react-pairing-challenge/migrate"
+	"github.com/music-tribe/react-pairing-challenge/purge"
+	"github.com/music-tribe/react-pairing-challenge/ratelimit"
+	"github.com/music-tribe/react-pairing-challenge/storage"
+	"github.com/music-tribe/react-pairing-challenge/webhook"
 	echoSwagger "github.com/swaggo/echo-swagger"
 )
 
@@ -27,33 +50,165 @@ import (
 // @host localhost:8083
 // @BasePath /
 // @schemes http
+
+// @securityDefinitions.apikey AdminKey
+// @in header
+// @name Authorization
+// @description Bearer token matching ADMIN_API_KEY, e.g. "Bearer s3cret".
 func main() {
 	e := echo.New()
 
 	e.Use(middleware.Recover())
-	e.Use(middleware.CORS())
 
-	db, err := database.OpenMongoConnection(os.Getenv("DB_URL"), e.Logger)
+	cfg, err := config.Load()
+	if err != nil {
+		e.Logger.Fatal(err)
+	}
+
+	// the socket handler checks origins with the same policy
+	e.Use(middleware.CORSWithConfig(middleware.CORSConfig{
+		AllowOriginFunc: func(origin string) (bool, error) { return cfg.OriginAllowed(origin), nil },
+	}))
+
+	// the per-IP rate limits need addresses clients can't make up
+	e.IPExtractor = ratelimit.IPExtractor(cfg.RateLimit.TrustedProxies)
+
+	db, err := storage.Open(cfg.DBURL, e.Logger)
 	if err != nil {
 		e.Logger.Fatal(err)
 	}
 
+	// replicas starting together wait on the migration lock, and find the
+	// first one has already done the work
+	if cfg.MigrateOnStart {
+		migrator := migrate.New(migrate.Config{Store: db, Migrations: db.Migrations(), Logger: e.Logger})
+		if _, err := migrator.Up(context.Background(), migrate.Options{}); err != nil {
+			e.Logger.Fatal(err)
+		}
+	}
+
+	// every reader and writer below goes through the cache, which passes
+	// straight through to the database when it is disabled
+	store := cache.New(db, cache.Options{
+		Enabled: cfg.Cache.Enabled,
+		Size:    cfg.Cache.Size,
+		TTL:     cfg.Cache.TTL,
+	})
+
+	// subscribers register on the bus before the dispatcher starts delivering
+	bus := events.NewBus()
+	sender := webhook.NewSender(nil)
+	webhookWorker := webhook.NewWorker(store, sender, e.Logger)
+	bus.Subscribe("webhooks", webhookWorker.Enqueue)
+	go webhookWorker.Run(context.Background())
+
+	// live clients follow the change streams when enabled, so they also see
+	// writes made through other replicas
+	hub := live.NewHub()
+	if cfg.Watch.Enabled {
+		changes := events.NewBus()
+		changes.Subscribe("live", hub.Handle)
+		changes.Subscribe("cache", store.Handle)
+		go events.NewWatcher(store, changes, cfg.Watch.Consumer, e.Logger).Run(context.Background())
+	} else {
+		bus.Subscribe("live", hub.Handle)
+	}
+
+	go events.NewDispatcher(store, bus, e.Logger).Run(context.Background())
+
+	go purge.New(store, cfg.Trash.Retention, cfg.Trash.PurgeInterval, e.Logger).Run(context.Background())
+
 	e.GET("/status", Status)
 
+	// writes and votes are throttled per user and per IP; the buckets are
+	// per replica, so the effective limit grows with the replica count
+	limits := ratelimit.NewMemoryStore()
+	writeLimit := rateLimit(cfg.RateLimit, limits, "writes",
+		ratelimit.Rule{Identity: ratelimit.Param("userId"), Limit: limit(cfg.RateLimit.WritesPerUser)},
+		ratelimit.Rule{Identity: ratelimit.IP(), Limit: limit(cfg.RateLimit.WritesPerIP)},
+	)
+	voteRules := []ratelimit.Rule{
+		{Identity: ratelimit.FirstOf(ratelimit.BodyField("userId"), ratelimit.Query("userId")), Limit: limit(cfg.RateLimit.VotesPerUser)},
+		{Identity: ratelimit.IP(), Limit: limit(cfg.RateLimit.VotesPerIP)},
+	}
+	voteLimit := rateLimit(cfg.RateLimit, limits, "votes", voteRules...)
+	// votes sent over the socket draw on the same buckets
+	if !cfg.RateLimit.Enabled {
+		voteRules = nil
+	}
+	socketVotes := ratelimit.NewLimiter(ratelimit.Config{Group: "votes", Store: limits, Rules: voteRules})
+	adminLimit := rateLimit(cfg.RateLimit, limits, "admin",
+		ratelimit.Rule{Identity: ratelimit.APIKey(), Limit: limit(cfg.RateLimit.AdminPerKey)},
+	)
+
+	// retried creates and votes replay their first response
+	idempotent := idempotency.New(idempotency.Config{Store: store, TTL: cfg.IdempotencyTTL})
+
 	grp := e.Group("/api")
-	grp.POST("/:userId", add.Add(db))
-	grp.GET("/:userId", getall.GetAll(db))
-	grp.PUT("/:userId", update.Update(db))
-	grp.GET("/:userId/:featureId", get.Get(db))
-	grp.DELETE("/:userId/:featureId", delete.Delete(db))
+	grp.POST("/:userId", add.Add(store), append(writeLimit, idempotent)...)
+	grp.GET("/:userId", getall.GetAll(store))
+	grp.PUT("/:userId", update.Update(store), writeLimit...)
+	grp.GET("/:userId/:featureId", get.Get(store))
+	grp.DELETE("/:userId/:featureId", delete.Delete(store), writeLimit...)
+	grp.GET("/:userId/trash", trash.Trash(store))
+	grp.POST("/:userId/:featureId/restore", trash.Restore(store), writeLimit...)
+
+	// votes, over REST or the socket, must prove the user they are cast as
+	users := auth.NewUserTokens(cfg.UserTokenSecret)
+	if !users.Enabled() {
+		e.Logger.Warn("USER_TOKEN_SECRET isn't set, so votes are cast as whichever user id they name")
+	}
+	votingPolicy := upvote.Policy{
+		Budget:         cfg.Voting.Budget,
+		MaxWeight:      cfg.Voting.MaxWeight,
+		Quadratic:      cfg.Voting.Quadratic,
+		DownvoteBoards: cfg.Voting.DownvoteBoards,
+	}
+	grp.PUT("/vote/:featureId", upvote.Upvote(store, votingPolicy, users), append(voteLimit, idempotent)...)
+	grp.DELETE("/vote/:featureId", upvote.Unvote(store, users), append(voteLimit, idempotent)...)
+	grp.GET("/users/:userId/votes", uservotes.UserVotes(store, votingPolicy))
+	grp.GET("/ws", socket.Socket(store, votingPolicy, hub, cfg.OriginAllowed, users, socketVotes))
+
+	grp.GET("/features", search.Search(store))
+	grp.GET("/features/stream", stream.Stream(hub, stream.DefaultHeartbeat))
+	grp.GET("/features/export", export.Export(store))
+	grp.GET("/features/:featureId/history", history.History(store))
+	grp.GET("/categories", categories.List(store))
 
-	grp.PUT("/vote/:featureId", upvote.Upvote(db))
+	admin := grp.Group("/admin", append([]echo.MiddlewareFunc{auth.AdminKey(cfg.AdminAPIKey)}, adminLimit...)...)
+	admin.GET("/audit", audit.Audit(store))
+	admin.GET("/cache", cachestats.CacheStats(store))
+	admin.POST("/categories", categories.Add(store))
+	admin.PUT("/categories/:slug", categories.Update(store))
+	admin.DELETE("/categories/:slug", categories.Delete(store))
+	admin.PUT("/features/:featureId/status", status.SetStatus(store))
+	admin.POST("/import", imports.Import(store))
+	admin.GET("/webhooks", webhooks.List(store))
+	admin.POST("/webhooks", webhooks.Add(store))
+	admin.GET("/webhooks/:webhookId", webhooks.Get(store))
+	admin.PUT("/webhooks/:webhookId", webhooks.Update(store))
+	admin.DELETE("/webhooks/:webhookId", webhooks.Delete(store))
+	admin.GET("/webhooks/:webhookId/deliveries", webhooks.Deliveries(store))
+	admin.POST("/webhooks/:webhookId/test", webhooks.SendTest(store, sender))
 
 	e.GET("/swagger/*", echoSwagger.WrapHandler)
 
 	e.Logger.Fatal(e.Start(":8083"))
 }
 
+// rateLimit returns the middleware enforcing rules on a route group, or none
+// when rate limiting is turned off.
+func rateLimit(cfg config.RateLimit, store ratelimit.Store, group string, rules ...ratelimit.Rule) []echo.MiddlewareFunc {
+	if !cfg.Enabled {
+		return nil
+	}
+	return []echo.MiddlewareFunc{ratelimit.New(ratelimit.Config{Group: group, Store: store, Rules: rules})}
+}
+
+func limit(r config.Rate) ratelimit.Limit {
+	return ratelimit.Limit{Requests: r.Requests, Period: r.Period}
+}
+
 // Status godoc
 // @Summary Show if the server is alive.
 // @Description get the status of server.
@@ -0,0 +1,52 @@
+// Package storage opens the storage backend named by the service's DB_URL.
+package storage
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/music-tribe/react-pairing-challenge/database"
+	"github.com/music-tribe/react-pairing-challenge/database/sqldb"
+)
+
+type Logger interface {
+	Infof(string, ...interface{})
+	Errorf(string, ...interface{})
+}
+
+// Open connects to the backend dbURL's scheme names:
+//
+//	mongodb://, mongodb+srv://  MongoDB
+//	postgres://, postgresql://  PostgreSQL
+//	sqlite://                   SQLite, e.g. sqlite:///var/lib/features.db
+func Open(dbURL string, logger Logger) (database.Store, error) {
+	u, err := url.Parse(dbURL)
+	if err != nil {
+		return nil, fmt.Errorf("storage.Open: DB_URL can't be parsed: %w", err)
+	}
+
+	// each case checks err itself, so a failed open is a nil Store rather
+	// than a Store holding a nil pointer
+	switch u.Scheme {
+	case "mongodb", "mongodb+srv":
+		db, err := database.OpenMongoConnection(dbURL, logger)
+		if err != nil {
+			return nil, err
+		}
+		return db, nil
+	case "postgres", "postgresql":
+		db, err := sqldb.Open(sqldb.Postgres, dbURL, logger)
+		if err != nil {
+			return nil, err
+		}
+		return db, nil
+	case "sqlite":
+		db, err := sqldb.Open(sqldb.SQLite, dbURL, logger)
+		if err != nil {
+			return nil, err
+		}
+		return db, nil
+	}
+
+	return nil, fmt.Errorf("storage.Open: DB_URL has unsupported scheme %q", u.Scheme)
+}
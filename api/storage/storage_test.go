@@ -0,0 +1,33 @@
+package storage
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpen(t *testing.T) {
+	t.Run("when the scheme isn't supported, we should get an error", func(t *testing.T) {
+		_, err := Open("mysql://localhost:3306/features", echo.New().Logger)
+		assert.ErrorContains(t, err, `unsupported scheme "mysql"`)
+	})
+
+	t.Run("when the url can't be parsed, we should get an error", func(t *testing.T) {
+		_, err := Open("postgres://%zz", echo.New().Logger)
+		assert.ErrorContains(t, err, "can't be parsed")
+	})
+	t.Run("when the scheme is sqlite, we should get a store backed by that file", func(t *testing.T) {
+		db, err := Open("sqlite://"+filepath.Join(t.TempDir(), "features.db"), echo.New().Logger)
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer db.Close()
+
+		applied, err := db.AppliedMigrations(context.Background())
+		assert.NoError(t, err)
+		assert.Empty(t, applied)
+	})
+}
@@ -0,0 +1,81 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/uuid"
+)
+
+const (
+	DefaultTimeout = 10 * time.Second
+	UserAgent      = "features-api-webhooks/1.0"
+	// maxDrain bounds how much of a response body is read so the
+	// connection can be reused.
+	maxDrain = 64 << 10
+)
+
+// Sender POSTs signed events to webhooks.
+type Sender struct {
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewSender returns a Sender using client, or a client with DefaultTimeout
+// when client is nil.
+func NewSender(client *http.Client) *Sender {
+	if client == nil {
+		client = &http.Client{Timeout: DefaultTimeout}
+	}
+
+	return &Sender{client: client, now: time.Now}
+}
+
+// Send POSTs event to webhook and reports how it went. Failures are
+// recorded on the returned attempt rather than returned as errors.
+func (s *Sender) Send(ctx context.Context, webhook *domain.Webhook, deliveryId uuid.UUID, event *domain.Event) domain.DeliveryAttempt {
+	start := s.now()
+	attempt := domain.DeliveryAttempt{At: start.UTC()}
+	finish := func(err error) domain.DeliveryAttempt {
+		if err != nil {
+			attempt.Error = err.Error()
+		}
+		attempt.DurationMs = s.now().Sub(start).Milliseconds()
+		return attempt
+	}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return finish(err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return finish(err)
+	}
+
+	ts := start.Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", UserAgent)
+	req.Header.Set(HeaderEventId, event.Id.String())
+	req.Header.Set(HeaderEventType, string(event.Type))
+	req.Header.Set(HeaderDeliveryId, deliveryId.String())
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(ts, 10))
+	req.Header.Set(HeaderSignature, Sign(webhook.Secret, ts, body))
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return finish(err)
+	}
+	defer res.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(res.Body, maxDrain))
+
+	attempt.StatusCode = res.StatusCode
+	return finish(nil)
+}
@@ -0,0 +1,84 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSender_Send(t *testing.T) {
+	ctx := context.Background()
+	event := &domain.Event{Id: uuid.New(), Type: domain.VoteCast, FeatureId: uuid.New()}
+	deliveryId := uuid.New()
+
+	t.Run("when the receiver accepts the event, it should get a signed payload", func(t *testing.T) {
+		var got *http.Request
+		var body []byte
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			got = r
+			body, _ = io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer srv.Close()
+
+		webhook := &domain.Webhook{Id: uuid.New(), URL: srv.URL, Secret: "secret"}
+		attempt := NewSender(nil).Send(ctx, webhook, deliveryId, event)
+
+		assert.True(t, attempt.Succeeded())
+		assert.Equal(t, http.StatusNoContent, attempt.StatusCode)
+		assert.Equal(t, http.MethodPost, got.Method)
+		assert.Equal(t, "application/json", got.Header.Get("Content-Type"))
+		assert.Equal(t, event.Id.String(), got.Header.Get(HeaderEventId))
+		assert.Equal(t, string(domain.VoteCast), got.Header.Get(HeaderEventType))
+		assert.Equal(t, deliveryId.String(), got.Header.Get(HeaderDeliveryId))
+		assert.True(t, Verify("secret", got.Header.Get(HeaderSignature), got.Header.Get(HeaderTimestamp), body, time.Now(), time.Minute))
+
+		sent := new(domain.Event)
+		assert.NoError(t, json.Unmarshal(body, sent))
+		assert.Equal(t, event.Id, sent.Id)
+	})
+
+	t.Run("when the receiver responds with an error status, the attempt should fail", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer srv.Close()
+
+		attempt := NewSender(nil).Send(ctx, &domain.Webhook{URL: srv.URL}, deliveryId, event)
+		assert.False(t, attempt.Succeeded())
+		assert.Equal(t, http.StatusBadGateway, attempt.StatusCode)
+	})
+
+	t.Run("when the receiver is too slow, the attempt should record the timeout", func(t *testing.T) {
+		release := make(chan struct{})
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			<-release
+		}))
+		defer srv.Close()
+		defer close(release)
+
+		sender := NewSender(&http.Client{Timeout: 20 * time.Millisecond})
+		attempt := sender.Send(ctx, &domain.Webhook{URL: srv.URL}, deliveryId, event)
+		assert.False(t, attempt.Succeeded())
+		assert.Zero(t, attempt.StatusCode)
+		assert.NotEmpty(t, attempt.Error)
+	})
+
+	t.Run("when the url cannot be reached, the attempt should record the error", func(t *testing.T) {
+		srv := httptest.NewServer(http.NotFoundHandler())
+		url := srv.URL
+		srv.Close()
+
+		attempt := NewSender(nil).Send(ctx, &domain.Webhook{URL: url}, deliveryId, event)
+		assert.False(t, attempt.Succeeded())
+		assert.NotEmpty(t, attempt.Error)
+	})
+}
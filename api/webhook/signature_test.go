@@ -0,0 +1,42 @@
+package webhook
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSign(t *testing.T) {
+	t.Run("when we sign a known payload, we should get the expected signature", func(t *testing.T) {
+		// echo -n '1709285400.{"a":1}' | openssl dgst -sha256 -hmac secret
+		got := Sign("secret", 1709285400, []byte(`{"a":1}`))
+		assert.Equal(t, "sha256=96f1c2eef06aea9ea786d15aaf5cd85ca21beab5c16c84995e5521bec9a439c0", got)
+	})
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	body := []byte(`{"type":"vote.cast"}`)
+	ts := strconv.FormatInt(now.Unix(), 10)
+	sig := Sign("secret", now.Unix(), body)
+
+	t.Run("when the signature matches, we should accept it", func(t *testing.T) {
+		assert.True(t, Verify("secret", sig, ts, body, now, 5*time.Minute))
+	})
+
+	t.Run("when the body or secret differ, we should reject it", func(t *testing.T) {
+		assert.False(t, Verify("secret", sig, ts, []byte(`{"type":"vote.removed"}`), now, 5*time.Minute))
+		assert.False(t, Verify("other", sig, ts, body, now, 5*time.Minute))
+	})
+
+	t.Run("when the timestamp is outside the tolerance, we should reject it", func(t *testing.T) {
+		assert.False(t, Verify("secret", sig, ts, body, now.Add(6*time.Minute), 5*time.Minute))
+		assert.True(t, Verify("secret", sig, ts, body, now.Add(6*time.Minute), 0))
+	})
+
+	t.Run("when the timestamp is not a number, we should reject it", func(t *testing.T) {
+		assert.False(t, Verify("secret", sig, "yesterday", body, now, 0))
+	})
+}
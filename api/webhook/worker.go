@@ -0,0 +1,195 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/music-tribe/react-pairing-challenge/database"
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/uuid"
+)
+
+//go:generate mockgen -destination=./mocks/worker.go -package=webhookmocks -source=worker.go
+type WebhookDatabase interface {
+	ActiveWebhooks(t domain.EventType) ([]*domain.Webhook, error)
+	AddWebhookDelivery(delivery *domain.WebhookDelivery) error
+	ClaimWebhookDeliveries(now, until time.Time, limit int64) ([]*domain.WebhookDelivery, error)
+	GetWebhook(webhookId uuid.UUID) (*domain.Webhook, error)
+	RecordWebhookAttempt(deliveryId uuid.UUID, attempt domain.DeliveryAttempt, status domain.DeliveryStatus, next time.Time) error
+}
+
+const (
+	DefaultInterval    = time.Second
+	DefaultBatchSize   = 50
+	DefaultMaxAttempts = 8
+	// DefaultBaseDelay is the wait before the first retry; each further
+	// retry waits twice as long, up to DefaultMaxDelay.
+	DefaultBaseDelay = 10 * time.Second
+	DefaultMaxDelay  = time.Hour
+	// DefaultLease is how long a worker holds a delivery it has claimed
+	// before another may take it over. It outlasts the sender's timeout, so
+	// a delivery is only sent twice when its worker stops part way.
+	DefaultLease = time.Minute
+)
+
+// Worker queues a delivery for each webhook interested in an event and
+// sends them, retrying failed deliveries with exponential backoff. Any
+// number of workers may share a database: each delivery is claimed by one
+// of them before it is sent.
+type Worker struct {
+	db          WebhookDatabase
+	sender      *Sender
+	logger      echo.Logger
+	interval    time.Duration
+	batchSize   int64
+	maxAttempts int
+	baseDelay   time.Duration
+	maxDelay    time.Duration
+	lease       time.Duration
+	now         func() time.Time
+}
+
+func NewWorker(db WebhookDatabase, sender *Sender, logger echo.Logger) *Worker {
+	if db == nil {
+		panic("webhook.NewWorker: db has nil value")
+	}
+	if sender == nil {
+		panic("webhook.NewWorker: sender has nil value")
+	}
+
+	return &Worker{
+		db:          db,
+		sender:      sender,
+		logger:      logger,
+		interval:    DefaultInterval,
+		batchSize:   DefaultBatchSize,
+		maxAttempts: DefaultMaxAttempts,
+		baseDelay:   DefaultBaseDelay,
+		maxDelay:    DefaultMaxDelay,
+		lease:       DefaultLease,
+		now:         time.Now,
+	}
+}
+
+// Enqueue queues event for every active webhook that wants it. It is an
+// events.Handler; queueing is idempotent, so the outbox redelivering an
+// event doesn't send it twice.
+func (w *Worker) Enqueue(_ context.Context, event *domain.Event) error {
+	webhooks, err := w.db.ActiveWebhooks(event.Type)
+	if err != nil {
+		return err
+	}
+
+	now := w.now().UTC()
+	for _, webhook := range webhooks {
+		delivery := &domain.WebhookDelivery{
+			Id:            uuid.New(),
+			WebhookId:     webhook.Id,
+			Event:         *event,
+			Status:        domain.DeliveryPending,
+			NextAttemptAt: now,
+		}
+		if err := w.db.AddWebhookDelivery(delivery); err != nil && err != database.ErrDuplicate {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Run sends due deliveries every interval until ctx is done.
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := w.DeliverOnce(ctx); err != nil {
+			w.logger.Errorf("webhook.Run: DeliverOnce >> %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DeliverOnce sends up to a batch of due deliveries, returning how many
+// succeeded. Deliveries are claimed one at a time, so each lease only has
+// to cover a single send.
+func (w *Worker) DeliverOnce(ctx context.Context) (int, error) {
+	succeeded := 0
+	for i := int64(0); i < w.batchSize; i++ {
+		if ctx.Err() != nil {
+			return succeeded, ctx.Err()
+		}
+
+		now := w.now().UTC()
+		claimed, err := w.db.ClaimWebhookDeliveries(now, now.Add(w.lease), 1)
+		if err != nil {
+			return succeeded, err
+		}
+		if len(claimed) == 0 {
+			break
+		}
+
+		ok, err := w.deliver(ctx, claimed[0])
+		if err != nil {
+			return succeeded, err
+		}
+		if ok {
+			succeeded++
+		}
+	}
+
+	return succeeded, nil
+}
+
+func (w *Worker) deliver(ctx context.Context, delivery *domain.WebhookDelivery) (bool, error) {
+	webhook, err := w.db.GetWebhook(delivery.WebhookId)
+	if err == database.ErrNotFound {
+		// the webhook was removed while the delivery was queued
+		attempt := domain.DeliveryAttempt{At: w.now().UTC(), Error: "webhook not found"}
+		return false, w.db.RecordWebhookAttempt(delivery.Id, attempt, domain.DeliveryFailed, attempt.At)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if !webhook.Active {
+		attempt := domain.DeliveryAttempt{At: w.now().UTC(), Error: "webhook is inactive"}
+		return false, w.db.RecordWebhookAttempt(delivery.Id, attempt, domain.DeliveryFailed, attempt.At)
+	}
+
+	attempt := w.sender.Send(ctx, webhook, delivery.Id, &delivery.Event)
+	if attempt.Succeeded() {
+		return true, w.db.RecordWebhookAttempt(delivery.Id, attempt, domain.DeliverySucceeded, attempt.At)
+	}
+	if attempt.Error == "" {
+		attempt.Error = fmt.Sprintf("unexpected status %d", attempt.StatusCode)
+	}
+
+	attempts := len(delivery.Attempts)
+	if attempts+1 >= w.maxAttempts {
+		w.logger.Errorf("webhook.deliver: giving up on delivery %s to %s >> %s", delivery.Id, webhook.URL, attempt.Error)
+		return false, w.db.RecordWebhookAttempt(delivery.Id, attempt, domain.DeliveryFailed, attempt.At)
+	}
+
+	next := w.now().UTC().Add(w.backoff(attempts))
+	return false, w.db.RecordWebhookAttempt(delivery.Id, attempt, domain.DeliveryPending, next)
+}
+
+// backoff is the wait after the given number of earlier failed attempts.
+func (w *Worker) backoff(attempts int) time.Duration {
+	delay := w.baseDelay
+	for i := 0; i < attempts && delay < w.maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > w.maxDelay {
+		delay = w.maxDelay
+	}
+	return delay
+}
@@ -0,0 +1,264 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/music-tribe/react-pairing-challenge/database"
+	"github.com/music-tribe/react-pairing-challenge/database/sqldb"
+	"github.com/music-tribe/react-pairing-challenge/domain"
+	"github.com/music-tribe/react-pairing-challenge/migrate"
+	webhookmocks "github.com/music-tribe/react-pairing-challenge/webhook/mocks"
+	"github.com/music-tribe/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorker(t *testing.T) {
+	logger := echo.New().Logger
+	now := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	ctx := context.Background()
+
+	newWorker := func(db WebhookDatabase) *Worker {
+		w := NewWorker(db, NewSender(nil), logger)
+		w.now = func() time.Time { return now }
+		return w
+	}
+
+	// queue has db hand out deliveries one claim at a time, then none
+	queue := func(db *webhookmocks.MockWebhookDatabase, deliveries ...*domain.WebhookDelivery) {
+		for _, d := range deliveries {
+			db.EXPECT().ClaimWebhookDeliveries(now, now.Add(DefaultLease), int64(1)).Return([]*domain.WebhookDelivery{d}, nil)
+		}
+		db.EXPECT().ClaimWebhookDeliveries(now, now.Add(DefaultLease), int64(1)).Return([]*domain.WebhookDelivery{}, nil)
+	}
+
+	receiver := func(status int) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(status)
+		}))
+	}
+
+	t.Run("when the db or sender has a nil value, we should panic", func(t *testing.T) {
+		assert.Panics(t, func() {
+			NewWorker(nil, NewSender(nil), logger)
+		})
+		assert.Panics(t, func() {
+			NewWorker(webhookmocks.NewMockWebhookDatabase(gomock.NewController(t)), nil, logger)
+		})
+	})
+
+	t.Run("when an event is enqueued, a delivery should be queued for each interested webhook", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := webhookmocks.NewMockWebhookDatabase(ctrl)
+
+		event := &domain.Event{Id: uuid.New(), Type: domain.VoteCast}
+		hooks := []*domain.Webhook{{Id: uuid.New()}, {Id: uuid.New()}}
+
+		db.EXPECT().ActiveWebhooks(domain.VoteCast).Return(hooks, nil)
+		for _, hook := range hooks {
+			hook := hook
+			db.EXPECT().AddWebhookDelivery(gomock.Any()).DoAndReturn(func(d *domain.WebhookDelivery) error {
+				assert.Equal(t, hook.Id, d.WebhookId)
+				assert.Equal(t, event.Id, d.Event.Id)
+				assert.Equal(t, domain.DeliveryPending, d.Status)
+				assert.Equal(t, now, d.NextAttemptAt)
+				return nil
+			})
+		}
+
+		assert.NoError(t, newWorker(db).Enqueue(ctx, event))
+	})
+
+	t.Run("when an event was already queued for a webhook, enqueueing it again should succeed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := webhookmocks.NewMockWebhookDatabase(ctrl)
+
+		db.EXPECT().ActiveWebhooks(domain.VoteCast).Return([]*domain.Webhook{{Id: uuid.New()}}, nil)
+		db.EXPECT().AddWebhookDelivery(gomock.Any()).Return(database.ErrDuplicate)
+
+		assert.NoError(t, newWorker(db).Enqueue(ctx, &domain.Event{Type: domain.VoteCast}))
+	})
+
+	t.Run("when the webhooks can't be read, enqueue should return the error so the event is retried", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := webhookmocks.NewMockWebhookDatabase(ctrl)
+
+		errBoom := errors.New("boom")
+		db.EXPECT().ActiveWebhooks(domain.VoteCast).Return(nil, errBoom)
+
+		assert.ErrorIs(t, newWorker(db).Enqueue(ctx, &domain.Event{Type: domain.VoteCast}), errBoom)
+	})
+
+	t.Run("when the receiver accepts a delivery, it should be marked succeeded", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := webhookmocks.NewMockWebhookDatabase(ctrl)
+
+		srv := receiver(http.StatusOK)
+		defer srv.Close()
+
+		hook := &domain.Webhook{Id: uuid.New(), URL: srv.URL, Active: true}
+		delivery := &domain.WebhookDelivery{Id: uuid.New(), WebhookId: hook.Id}
+
+		queue(db, delivery)
+		db.EXPECT().GetWebhook(hook.Id).Return(hook, nil)
+		db.EXPECT().RecordWebhookAttempt(delivery.Id, gomock.Any(), domain.DeliverySucceeded, gomock.Any()).
+			DoAndReturn(func(_ uuid.UUID, a domain.DeliveryAttempt, _ domain.DeliveryStatus, _ time.Time) error {
+				assert.Equal(t, http.StatusOK, a.StatusCode)
+				return nil
+			})
+
+		n, err := newWorker(db).DeliverOnce(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, n)
+	})
+
+	t.Run("when the receiver fails, the delivery should be retried with backoff", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := webhookmocks.NewMockWebhookDatabase(ctrl)
+
+		srv := receiver(http.StatusInternalServerError)
+		defer srv.Close()
+
+		hook := &domain.Webhook{Id: uuid.New(), URL: srv.URL, Active: true}
+		delivery := &domain.WebhookDelivery{Id: uuid.New(), WebhookId: hook.Id, Attempts: make([]domain.DeliveryAttempt, 2)}
+
+		queue(db, delivery)
+		db.EXPECT().GetWebhook(hook.Id).Return(hook, nil)
+		db.EXPECT().RecordWebhookAttempt(delivery.Id, gomock.Any(), domain.DeliveryPending, now.Add(4*DefaultBaseDelay)).
+			DoAndReturn(func(_ uuid.UUID, a domain.DeliveryAttempt, _ domain.DeliveryStatus, _ time.Time) error {
+				assert.Equal(t, "unexpected status 500", a.Error)
+				return nil
+			})
+
+		n, err := newWorker(db).DeliverOnce(ctx)
+		assert.NoError(t, err)
+		assert.Zero(t, n)
+	})
+
+	t.Run("when a delivery runs out of attempts, it should be marked failed", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := webhookmocks.NewMockWebhookDatabase(ctrl)
+
+		srv := receiver(http.StatusServiceUnavailable)
+		defer srv.Close()
+
+		hook := &domain.Webhook{Id: uuid.New(), URL: srv.URL, Active: true}
+		delivery := &domain.WebhookDelivery{Id: uuid.New(), WebhookId: hook.Id, Attempts: make([]domain.DeliveryAttempt, DefaultMaxAttempts-1)}
+
+		queue(db, delivery)
+		db.EXPECT().GetWebhook(hook.Id).Return(hook, nil)
+		db.EXPECT().RecordWebhookAttempt(delivery.Id, gomock.Any(), domain.DeliveryFailed, gomock.Any()).Return(nil)
+
+		_, err := newWorker(db).DeliverOnce(ctx)
+		assert.NoError(t, err)
+	})
+
+	t.Run("when the webhook was removed or disabled, the delivery should be marked failed without sending", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := webhookmocks.NewMockWebhookDatabase(ctrl)
+
+		gone := &domain.WebhookDelivery{Id: uuid.New(), WebhookId: uuid.New()}
+		disabled := &domain.WebhookDelivery{Id: uuid.New(), WebhookId: uuid.New()}
+
+		queue(db, gone, disabled)
+		db.EXPECT().GetWebhook(gone.WebhookId).Return(nil, database.ErrNotFound)
+		db.EXPECT().RecordWebhookAttempt(gone.Id, gomock.Any(), domain.DeliveryFailed, now).Return(nil)
+		db.EXPECT().GetWebhook(disabled.WebhookId).Return(&domain.Webhook{Id: disabled.WebhookId, URL: "http://127.0.0.1:1"}, nil)
+		db.EXPECT().RecordWebhookAttempt(disabled.Id, gomock.Any(), domain.DeliveryFailed, now).Return(nil)
+
+		_, err := newWorker(db).DeliverOnce(ctx)
+		assert.NoError(t, err)
+	})
+
+	t.Run("when the deliveries can't be read, we should return the error", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		db := webhookmocks.NewMockWebhookDatabase(ctrl)
+
+		errBoom := errors.New("boom")
+		db.EXPECT().ClaimWebhookDeliveries(now, now.Add(DefaultLease), int64(1)).Return(nil, errBoom)
+
+		_, err := newWorker(db).DeliverOnce(ctx)
+		assert.ErrorIs(t, err, errBoom)
+	})
+}
+
+func TestWorker_SharedStore(t *testing.T) {
+	ctx := context.Background()
+	logger := echo.New().Logger
+
+	db, err := sqldb.Open(sqldb.SQLite, "sqlite://"+filepath.Join(t.TempDir(), "features.db"), logger)
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	defer db.Close()
+	_, err = migrate.New(migrate.Config{Store: db, Migrations: db.Migrations()}).Up(ctx, migrate.Options{})
+	assert.NoError(t, err)
+
+	var (
+		mu       sync.Mutex
+		received = map[string]int{}
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		received[r.Header.Get(HeaderDeliveryId)]++
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	hook := &domain.Webhook{Id: uuid.New(), URL: srv.URL, Secret: "s", Active: true}
+	assert.NoError(t, db.AddWebhook(hook))
+	for i := 0; i < 30; i++ {
+		event := domain.Event{Id: uuid.New(), Type: domain.VoteCast}
+		assert.NoError(t, db.AddWebhookDelivery(&domain.WebhookDelivery{
+			Id: uuid.New(), WebhookId: hook.Id, Event: event, Status: domain.DeliveryPending, NextAttemptAt: time.Now().Add(-time.Second),
+		}))
+	}
+
+	t.Run("when two workers share a store, each delivery should be sent once", func(t *testing.T) {
+		var wg sync.WaitGroup
+		sent := make([]int, 2)
+		for i := range sent {
+			i := i
+			w := NewWorker(db, NewSender(nil), logger)
+			w.batchSize = 100
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				n, err := w.DeliverOnce(ctx)
+				assert.NoError(t, err)
+				sent[i] = n
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, 30, sent[0]+sent[1])
+		assert.Len(t, received, 30)
+		for id, n := range received {
+			assert.Equal(t, 1, n, "delivery %s", id)
+		}
+
+		ds, err := db.GetWebhookDeliveries(hook.Id, 100)
+		assert.NoError(t, err)
+		for _, d := range ds {
+			assert.Equal(t, domain.DeliverySucceeded, d.Status)
+			assert.Len(t, d.Attempts, 1)
+		}
+	})
+}